@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"spaces only", "  \t ", nil},
+		{"plain words", "save  my-session", []string{"save", "my-session"}},
+		{"tabs", "a\tb", []string{"a", "b"}},
+		{"double quotes", `save "my session"`, []string{"save", "my session"}},
+		{"single quotes", `save 'my session'`, []string{"save", "my session"}},
+		{"empty quoted arg", `set stop ""`, []string{"set", "stop", ""}},
+		{"quotes inside word", `a"b c"d`, []string{"ab cd"}},
+		{"escaped space", `my\ session`, []string{"my session"}},
+		{"escaped quote in double quotes", `"say \"hi\""`, []string{`say "hi"`}},
+		{"backslash kept in double quotes", `"a\nb"`, []string{`a\nb`}},
+		{"backslash literal in single quotes", `'a\b'`, []string{`a\b`}},
+		{"trailing backslash", `a\`, []string{`a\`}},
+		{"unicode", "сохранить «сессия»", []string{"сохранить", "«сессия»"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitArgs(tt.line)
+			if err != nil {
+				t.Fatalf("splitArgs(%q) error: %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitArgsUnclosedQuote(t *testing.T) {
+	for _, line := range []string{`save "my session`, `save 'x`} {
+		_, err := splitArgs(line)
+		if code := i18n.Code(err); code != "cli.unclosed_quote" {
+			t.Errorf("splitArgs(%q) error code = %q, want cli.unclosed_quote", line, code)
+		}
+	}
+}
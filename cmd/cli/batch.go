@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+)
+
+// BatchItem представляет один запрос во входном JSONL файле пакетного режима.
+// Строки выходного файла тоже являются запросами: по ним пакет повторяется
+// с теми же параметрами генерации и seed
+type BatchItem struct {
+	ID           string                   `json:"id"`
+	Prompt       string                   `json:"prompt"`
+	SystemPrompt string                   `json:"system_prompt,omitempty"`
+	Temperature  *float64                 `json:"temperature,omitempty"`
+	Seed         int                      `json:"seed,omitempty"`
+	Session      string                   `json:"session,omitempty"`
+	Generation   *model.GenerationOptions `json:"generation,omitempty"` // Temperature и Seed запроса имеют приоритет
+}
+
+// BatchSummary содержит итоги пакетной обработки
+type BatchSummary struct {
+	Processed int    `json:"processed"`
+	Skipped   int    `json:"skipped"`
+	Failed    int    `json:"failed"`
+	OutFile   string `json:"out_file"`
+}
+
+// BatchResult представляет одну строку выходного JSONL файла пакетного режима
+type BatchResult struct {
+	ID           string                   `json:"id"`
+	Prompt       string                   `json:"prompt"`
+	SystemPrompt string                   `json:"system_prompt,omitempty"`
+	Response     string                   `json:"response"`
+	Session      string                   `json:"session,omitempty"`
+	Temperature  *float64                 `json:"temperature,omitempty"`
+	Seed         int                      `json:"seed,omitempty"`
+	Generation   *model.GenerationOptions `json:"generation,omitempty"` // Параметры, с которыми выполнена генерация
+	PromptTokens int                      `json:"prompt_tokens"`
+	TokensUsed   int                      `json:"tokens_used"`
+	LatencyMs    int64                    `json:"latency_ms"`
+	Error        string                   `json:"error,omitempty"`
+	ErrorCode    string                   `json:"error_code,omitempty"` // Код сообщения ошибки, не зависящий от языка
+	Executions   []ExecutionReport        `json:"executions,omitempty"`
+	Timestamp    time.Time                `json:"timestamp"`
+}
+
+// runBatchCommand обрабатывает подкоманду batch
+func runBatchCommand(args []string) {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	configPath := flags.String("config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	inPath := flags.String("in", "", i18n.T("cli.batch_flag_in"))
+	outPath := flags.String("out", "", i18n.T("cli.batch_flag_out"))
+	resume := flags.Bool("resume", false, i18n.T("cli.batch_flag_resume"))
+	runCode := flags.Bool("run-code", false, i18n.T("cli.batch_flag_run_code"))
+	profile := flags.String("profile", "", i18n.T("cli.batch_flag_profile"))
+	seed := flags.Int("seed", 0, i18n.T("cli.batch_flag_seed"))
+	outputFormat := flags.String("output", OUTPUT_TEXT, i18n.T("cli.flag_report_output"))
+	addLangFlag(flags)
+	flags.Parse(args)
+
+	if err := setOutputFormat(*outputFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *inPath == "" || *outPath == "" {
+		fmt.Fprintln(textOut, i18n.T("cli.batch_usage"))
+		flags.PrintDefaults()
+		os.Exit(1)
+	}
+
+	items, err := readBatchItems(*inPath)
+	if err != nil {
+		batchFailed(i18n.T("cli.batch_input_read_failed", i18n.LocalizeError(err)), err)
+	}
+
+	// При продолжении пропускаем запросы, уже обработанные без ошибок. Записи
+	// об ошибках удаляются из выходного файла: эти запросы выполняются заново
+	completed := make(map[string]bool)
+	if *resume {
+		completed, err = compactBatchOutput(*outPath)
+		if err != nil {
+			batchFailed(i18n.T("cli.batch_output_read_failed", i18n.LocalizeError(err)), err)
+		}
+	}
+
+	fileFlags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if *resume {
+		fileFlags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	out, err := os.OpenFile(*outPath, fileFlags, 0644)
+	if err != nil {
+		batchFailed(i18n.T("cli.batch_output_open_failed", i18n.LocalizeError(err)), err)
+	}
+	defer out.Close()
+
+	initComponents(*configPath)
+	defer modelInstance.Close()
+
+	// Запросы пакета не должны зависеть от памяти пользователя и изменять ее
+	modelInstance.SetMemory(nil)
+
+	if *profile != "" {
+		if err := modelInstance.UseProfile(*profile); err != nil {
+			batchFailed(i18n.T("cli.batch_profile_failed", i18n.LocalizeError(err)), err)
+		}
+	}
+	if *runCode && !modelInstance.Profile().Allows(model.TOOL_CODE) {
+		err := i18n.NewError("cli.profile_code_denied", modelInstance.Profile().Name).WithKind(model.ErrToolDenied)
+		batchFailed(i18n.LocalizeError(err), err)
+	}
+
+	var processed, skipped, failed int
+	currentSession := ""
+
+	for i, item := range items {
+		if completed[item.ID] {
+			skipped++
+			continue
+		}
+
+		fmt.Fprintln(textOut, i18n.T("cli.batch_progress", i+1, len(items), item.ID))
+
+		result := processBatchItem(item, &currentSession, *runCode, *seed)
+		if result.Error != "" {
+			failed++
+		}
+		processed++
+
+		// Записываем результат сразу, чтобы обработку можно было продолжить после прерывания
+		line, err := json.Marshal(result)
+		if err != nil {
+			logger.Error("Failed to marshal batch result %s: %v", item.ID, err)
+			continue
+		}
+		if _, err := out.Write(append(line, '\n')); err != nil {
+			logger.Error("Failed to write batch result %s: %v", item.ID, err)
+			batchFailed(i18n.T("cli.batch_write_failed", i18n.LocalizeError(err)), err)
+		}
+		out.Sync()
+	}
+
+	fmt.Fprintln(textOut, i18n.T("cli.batch_done", processed, skipped, failed))
+	fmt.Fprintln(textOut, i18n.T("cli.batch_saved", *outPath))
+
+	if jsonOutput {
+		emitJSON("batch", BatchSummary{
+			Processed: processed,
+			Skipped:   skipped,
+			Failed:    failed,
+			OutFile:   *outPath,
+		}, nil)
+	}
+}
+
+// batchFailed сообщает об ошибке, прерывающей пакетную обработку, и
+// завершает программу. В режиме JSON ошибка выводится в конверте batch
+func batchFailed(message string, err error) {
+	if jsonOutput {
+		emitJSON("batch", nil, err)
+	} else {
+		fmt.Fprintln(textOut, message)
+	}
+	os.Exit(1)
+}
+
+// processBatchItem обрабатывает один запрос пакета. Seed используется для
+// запросов без своего seed; 0 - случайный
+func processBatchItem(item BatchItem, currentSession *string, runCode bool, seed int) BatchResult {
+	result := BatchResult{
+		ID:           item.ID,
+		Prompt:       item.Prompt,
+		SystemPrompt: item.SystemPrompt,
+		Session:      item.Session,
+		Temperature:  item.Temperature,
+		Seed:         item.Seed,
+		Timestamp:    time.Now(),
+	}
+
+	var generation model.GenerationOptions
+	if item.Generation != nil {
+		generation = *item.Generation
+	}
+	override := model.GenerationOptions{Temperature: item.Temperature}
+	if item.Seed != 0 {
+		override.Seed = model.Int(item.Seed)
+	}
+	generation = generation.Merge(override)
+	if model.ValueOf(generation.Seed) == 0 && seed != 0 {
+		generation.Seed = model.Int(seed)
+	}
+
+	// Запросы без сессии обрабатываются независимо друг от друга
+	if item.Session == "" {
+		modelInstance.ResetSession()
+		*currentSession = ""
+	} else if item.Session != *currentSession {
+		err := modelInstance.LoadSession(item.Session)
+		switch {
+		case err == nil:
+		case errors.Is(err, storage.ErrSessionNotFound):
+			logger.Info("Starting new batch session %s", item.Session)
+			modelInstance.ResetSession()
+		default:
+			// Существующую сессию, которую не удалось прочитать, нельзя
+			// перезаписывать: запрос пропускается с ошибкой
+			logger.Error("Failed to load batch session %s: %v", item.Session, err)
+			modelInstance.ResetSession()
+			*currentSession = ""
+			result.Error = i18n.LocalizeError(err)
+			result.ErrorCode = i18n.Code(err)
+			return result
+		}
+		*currentSession = item.Session
+	}
+
+	response, err := modelInstance.ProcessWithOptions(item.Prompt, model.ProcessOptions{
+		SystemPrompt: item.SystemPrompt,
+		Generation:   generation,
+		Priority:     model.PRIORITY_BATCH,
+	})
+	if response != nil {
+		result.Temperature = response.Generation.Temperature
+		result.Seed = model.ValueOf(response.Generation.Seed)
+		result.Generation = &response.Generation
+	}
+	if err != nil {
+		// Неудачный ход (например, извинение после таймаута) не должен
+		// попасть в сессию со следующим запросом: при продолжении пакета
+		// этот запрос выполняется заново. Следующий запрос перечитает
+		// сессию из хранилища
+		modelInstance.ResetSession()
+		*currentSession = ""
+		result.Error = i18n.LocalizeError(err)
+		result.ErrorCode = i18n.Code(err)
+		return result
+	}
+
+	result.Response = response.Text
+	result.PromptTokens = response.PromptTokens
+	result.TokensUsed = response.TokensUsed
+	result.LatencyMs = response.Latency.Milliseconds()
+
+	if runCode {
+		for _, block := range sandbox.ExtractCodeBlocks(response.Text) {
+			result.Executions = append(result.Executions, runBatchCode(block))
+		}
+	}
+
+	if item.Session != "" {
+		if err := modelInstance.SaveSession(item.Session); err != nil {
+			logger.Error("Failed to save batch session %s: %v", item.Session, err)
+		}
+	}
+
+	return result
+}
+
+// runBatchCode выполняет блок кода в песочнице
+func runBatchCode(block sandbox.CodeBlock) ExecutionReport {
+	res, err := sandboxEnv.RunCode(block.Code, block.Language)
+	if err != nil {
+		return ExecutionReport{Language: block.Language, Failure: i18n.LocalizeError(err), FailureCode: i18n.Code(err)}
+	}
+	recordExecution(block.Language, block.Code, res)
+
+	return newExecutionReport(block.Language, "", res)
+}
+
+// readBatchItems читает запросы из JSONL файла
+func readBatchItems(path string) ([]BatchItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var items []BatchItem
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), MAX_INPUT_SIZE)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var item BatchItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			return nil, i18n.WrapError(err, "cli.batch_line", lineNo)
+		}
+		if item.Prompt == "" {
+			return nil, i18n.NewError("cli.batch_no_prompt", lineNo)
+		}
+
+		// Без явного идентификатора используем номер строки
+		if item.ID == "" {
+			item.ID = fmt.Sprintf("%d", lineNo)
+		}
+		if seen[item.ID] {
+			return nil, i18n.NewError("cli.batch_duplicate_id", lineNo, item.ID)
+		}
+		seen[item.ID] = true
+
+		items = append(items, item)
+	}
+
+	return items, scanner.Err()
+}
+
+// compactBatchOutput оставляет в выходном файле только успешно обработанные
+// запросы и возвращает их идентификаторы. Записи об ошибках и строка, оборванная
+// при прерывании, удаляются, чтобы повторная обработка не дублировала запросы
+func compactBatchOutput(path string) (map[string]bool, error) {
+	completed := make(map[string]bool)
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return completed, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), MAX_INPUT_SIZE)
+
+	var kept bytes.Buffer
+	for scanner.Scan() {
+		var result BatchResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			continue
+		}
+		if result.Error != "" || completed[result.ID] {
+			continue
+		}
+		completed[result.ID] = true
+		kept.Write(scanner.Bytes())
+		kept.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Файл заменяется целиком, чтобы прерывание не оставило его усеченным
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(kept.Bytes()); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return completed, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/model/modeltest"
+	"smollm-sandbox/internal/storage"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	os.Exit(m.Run())
+}
+
+func TestCompactBatchOutput(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantCompleted map[string]bool
+		wantLines     []string
+	}{
+		{
+			name:          "empty",
+			content:       "",
+			wantCompleted: map[string]bool{},
+		},
+		{
+			name: "failed records removed",
+			content: `{"id":"1","response":"ok"}` + "\n" +
+				`{"id":"2","error":"boom"}` + "\n" +
+				`{"id":"3","response":"ok"}` + "\n",
+			wantCompleted: map[string]bool{"1": true, "3": true},
+			wantLines:     []string{`{"id":"1","response":"ok"}`, `{"id":"3","response":"ok"}`},
+		},
+		{
+			name:          "truncated last line removed",
+			content:       `{"id":"1","response":"ok"}` + "\n" + `{"id":"2","resp`,
+			wantCompleted: map[string]bool{"1": true},
+			wantLines:     []string{`{"id":"1","response":"ok"}`},
+		},
+		{
+			name: "success after earlier failure kept once",
+			content: `{"id":"1","error":"boom"}` + "\n" +
+				`{"id":"1","response":"ok"}` + "\n" +
+				`{"id":"1","response":"again"}` + "\n",
+			wantCompleted: map[string]bool{"1": true},
+			wantLines:     []string{`{"id":"1","response":"ok"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			completed, err := compactBatchOutput(path)
+			if err != nil {
+				t.Fatalf("compactBatchOutput: %v", err)
+			}
+			if !reflect.DeepEqual(completed, tt.wantCompleted) {
+				t.Errorf("completed = %v, want %v", completed, tt.wantCompleted)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if len(data) == 0 {
+				lines = nil
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("output lines = %q, want %q", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestCompactBatchOutputMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.jsonl")
+	completed, err := compactBatchOutput(path)
+	if err != nil || len(completed) != 0 {
+		t.Fatalf("compactBatchOutput(missing) = %v, %v", completed, err)
+	}
+}
+
+func TestProcessBatchItemSessions(t *testing.T) {
+	logger = logging.NewLogger()
+	m, _ := modeltest.NewModel(t)
+	root := t.TempDir()
+	sessions := storage.NewSessionManager(storage.NewFileSystem(root), "sessions")
+	m.SetSessionStore(sessions)
+	modelInstance = m
+	t.Cleanup(func() { modelInstance = nil })
+
+	corrupt := filepath.Join(root, "sessions", "broken.json")
+	if err := os.MkdirAll(filepath.Dir(corrupt), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	current := ""
+	result := processBatchItem(BatchItem{ID: "1", Prompt: "hi", Session: "broken"}, &current, false, 0)
+	if result.Error == "" {
+		t.Fatal("expected an error for an unreadable session")
+	}
+	if data, _ := os.ReadFile(corrupt); string(data) != "{not json" {
+		t.Errorf("unreadable session was overwritten: %q", data)
+	}
+
+	result = processBatchItem(BatchItem{ID: "2", Prompt: "hi", Session: "fresh"}, &current, false, 0)
+	if result.Error != "" {
+		t.Fatalf("new session: %s", result.Error)
+	}
+	if !sessions.SessionExists("fresh") {
+		t.Error("new session was not saved")
+	}
+}
+
+func TestProcessBatchItemFailureNotSaved(t *testing.T) {
+	logger = logging.NewLogger()
+	m, server := modeltest.NewModel(t)
+	server.SetReply(func(request model.InferenceRequest) (*model.InferenceResponse, int) {
+		if strings.Contains(request.Prompt, "fail") {
+			return nil, http.StatusInternalServerError
+		}
+		return &model.InferenceResponse{Text: modeltest.REPLY}, http.StatusOK
+	})
+	sessions := storage.NewSessionManager(storage.NewFileSystem(t.TempDir()), "sessions")
+	m.SetSessionStore(sessions)
+	modelInstance = m
+	t.Cleanup(func() { modelInstance = nil })
+
+	current := ""
+	for _, item := range []BatchItem{
+		{ID: "1", Prompt: "first", Session: "chat"},
+		{ID: "2", Prompt: "fail", Session: "chat"},
+		{ID: "3", Prompt: "third", Session: "chat"},
+	} {
+		result := processBatchItem(item, &current, false, 0)
+		if failed := result.Error != ""; failed != (item.Prompt == "fail") {
+			t.Fatalf("item %s: error = %q", item.ID, result.Error)
+		}
+	}
+
+	// Неудачный запрос не остается в сессии, иначе при --resume он
+	// продублируется
+	session, err := sessions.LoadSession("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prompts []string
+	for _, msg := range session.VisibleMessages() {
+		if msg.Role == "user" {
+			prompts = append(prompts, msg.Content)
+		}
+	}
+	if !reflect.DeepEqual(prompts, []string{"first", "third"}) {
+		t.Errorf("saved user messages = %q, want first and third", prompts)
+	}
+}
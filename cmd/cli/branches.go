@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// retryResponse заново генерирует последний ответ модели
+func retryResponse() {
+	fmt.Fprintln(textOut, "\n"+i18n.T("cli.regenerating"))
+	result, err := modelInstance.Retry(model.ProcessOptions{})
+	if err != nil && result == nil {
+		printMessage(i18n.T("cli.retry_failed"), err)
+		return
+	}
+	printResponse(lastUserInput(), result, err)
+}
+
+// editMessage заменяет сообщение пользователя с указанным номером и получает новый ответ
+func editMessage(args []string) {
+	if len(args) == 0 {
+		printConversation()
+		return
+	}
+
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		printMessage(i18n.T("cli.usage_edit"), err)
+		return
+	}
+
+	text := strings.Join(args[1:], " ")
+
+	// Без текста читаем новое сообщение многострочным блоком
+	if len(args) == 1 {
+		if console == nil {
+			fmt.Fprintln(textOut, i18n.T("cli.usage_edit_text"))
+			return
+		}
+		fmt.Fprintln(textOut, i18n.T("cli.enter_edit", index, BLOCK_TERMINATOR))
+		text, err = console.ReadBlock(BLOCK_TERMINATOR)
+		if err != nil {
+			return
+		}
+	}
+
+	if strings.TrimSpace(text) == "" {
+		fmt.Fprintln(textOut, i18n.T("cli.edit_empty"))
+		return
+	}
+
+	fmt.Fprintln(textOut, "\n"+i18n.T("cli.processing"))
+	result, err := modelInstance.Edit(index, text, model.ProcessOptions{})
+	if err != nil && result == nil {
+		printMessage(i18n.T("cli.edit_failed"), err)
+		return
+	}
+	printResponse(text, result, err)
+}
+
+// switchBranch выводит список веток или переключается на ветку с указанным номером
+func switchBranch(args []string) {
+	if len(args) == 0 {
+		printBranches()
+		return
+	}
+
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		printMessage(i18n.T("cli.usage_branch"), err)
+		return
+	}
+
+	if err := modelInstance.SwitchBranch(index); err != nil {
+		printMessage(i18n.T("cli.branch_failed"), err)
+		return
+	}
+
+	if jsonOutput {
+		printConversation()
+		return
+	}
+	fmt.Fprintln(textOut, i18n.T("cli.branch_active", index))
+	printConversation()
+}
+
+// printBranches выводит ветки диалога текущей сессии
+func printBranches() {
+	branches := modelInstance.Branches()
+
+	if jsonOutput {
+		reports := make([]BranchReport, 0, len(branches))
+		for i, branch := range branches {
+			reports = append(reports, BranchReport{
+				Index:   i + 1,
+				LeafID:  branch.LeafID,
+				Length:  branch.Length,
+				Preview: branch.Preview,
+				Active:  branch.Active,
+			})
+		}
+		emitJSON("branches", reports, nil)
+		return
+	}
+
+	for i, branch := range branches {
+		marker := " "
+		if branch.Active {
+			marker = "*"
+		}
+		fmt.Fprintln(textOut, i18n.T("cli.branch_line", marker, i+1, branch.Length, previewText(branch.Preview)))
+	}
+	fmt.Fprintln(textOut, i18n.T("cli.branch_hint"))
+}
+
+// printConversation выводит пронумерованные сообщения активной ветки
+func printConversation() {
+	messages := modelInstance.Messages()
+
+	if jsonOutput {
+		reports := make([]ConversationMessage, 0, len(messages))
+		for i, msg := range messages {
+			reports = append(reports, ConversationMessage{
+				Index:   i + 1,
+				ID:      msg.ID,
+				Role:    msg.Role,
+				Content: msg.Content,
+			})
+		}
+		emitJSON("conversation", reports, nil)
+		return
+	}
+
+	if len(messages) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.branch_empty"))
+		return
+	}
+
+	for i, msg := range messages {
+		fmt.Fprintf(textOut, "%d. %s: %s\n", i+1, msg.Role, previewText(msg.Content))
+	}
+}
+
+// lastUserInput возвращает последнее сообщение пользователя активной ветки
+func lastUserInput() string {
+	messages := modelInstance.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// previewText сокращает текст до одной строки для списков
+func previewText(text string) string {
+	const maxPreview = 60
+
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) > maxPreview {
+		return string(runes[:maxPreview]) + "..."
+	}
+	return text
+}
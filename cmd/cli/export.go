@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"smollm-sandbox/internal/export"
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+)
+
+// ExportReport содержит итоги экспорта диалогов
+type ExportReport struct {
+	Format        string   `json:"format"`
+	OutFile       string   `json:"out_file,omitempty"`
+	Conversations int      `json:"conversations"`
+	Sessions      []string `json:"sessions"`
+}
+
+// runExportCommand обрабатывает подкоманду export
+func runExportCommand(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := flags.String("config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	format := flags.String("format", export.FORMAT_MARKDOWN, i18n.T("cli.export_flag_format", strings.Join(export.Formats(), ", ")))
+	outPath := flags.String("out", "", i18n.T("cli.export_flag_out"))
+	all := flags.Bool("all", false, i18n.T("cli.export_flag_all"))
+	minRating := flags.Int("min-rating", 0, i18n.T("cli.export_flag_min_rating"))
+	outputFormat := flags.String("output", OUTPUT_TEXT, i18n.T("cli.flag_report_output"))
+	addLangFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.export_usage"))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if err := setOutputFormat(*outputFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	exportFormat, err := export.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// В режиме JSON stdout занят отчетом, поэтому нужен выходной файл
+	if jsonOutput && *outPath == "" {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.export_json_needs_out"))
+		os.Exit(2)
+	}
+
+	names := flags.Args()
+	if !*all && len(names) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	initStorage(*configPath)
+
+	if *all {
+		names = sessionNames()
+	}
+
+	report, err := exportSessions(names, exportFormat, *outPath, *minRating)
+	if jsonOutput {
+		emitJSON("export", report, err)
+	}
+	if err != nil {
+		if !jsonOutput {
+			fmt.Fprintln(textOut, i18n.T("cli.export_failed_with", i18n.LocalizeError(err)))
+		}
+		os.Exit(1)
+	}
+
+	if *outPath != "" {
+		fmt.Fprintln(textOut, i18n.T("cli.export_done", report.Conversations, *outPath))
+	}
+}
+
+// exportSessions загружает сессии, при необходимости фильтрует их по оценкам
+// и записывает в файл или stdout
+func exportSessions(names []string, format, outPath string, minRating int) (*ExportReport, error) {
+	conversations := make([]export.Conversation, 0, len(names))
+	for _, name := range names {
+		context, err := sessionManager.LoadSession(name)
+		if err != nil {
+			return nil, i18n.WrapError(err, "cli.export_session", name)
+		}
+		conversations = append(conversations, export.Conversation{Name: name, Context: context})
+	}
+
+	if minRating > 0 {
+		collector := feedback.NewCollector(filepath.Join(getHomeDir(), "feedback"))
+		if err := collector.LoadFeedbackFromDisk(); err != nil {
+			return nil, i18n.WrapError(err, "cli.feedback_load_failed")
+		}
+		conversations = export.FilterByRating(conversations, collector.GetAllFeedback(), minRating)
+	}
+
+	if err := writeExport(conversations, format, outPath); err != nil {
+		return nil, err
+	}
+
+	report := &ExportReport{
+		Format:        format,
+		OutFile:       outPath,
+		Conversations: len(conversations),
+		Sessions:      make([]string, 0, len(conversations)),
+	}
+	for _, conv := range conversations {
+		report.Sessions = append(report.Sessions, conv.Name)
+	}
+
+	return report, nil
+}
+
+// writeExport записывает диалоги в файл или в stdout, если путь не указан
+func writeExport(conversations []export.Conversation, format, outPath string) error {
+	var w io.Writer = os.Stdout
+	if outPath != "" {
+		file, err := os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	return export.Write(w, conversations, format)
+}
+
+// exportCurrent экспортирует текущий диалог интерактивного режима
+func exportCurrent(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.usage_export", strings.Join(export.Formats(), "|")))
+		return
+	}
+
+	format, err := export.ParseFormat(args[0])
+	if err != nil {
+		printMessage(i18n.T("cli.export_failed"), err)
+		return
+	}
+
+	context := modelInstance.GetContext()
+	outPath := context.SessionID + export.Extension(format)
+	if len(args) > 1 {
+		outPath = args[1]
+	}
+
+	conversations := []export.Conversation{{Name: context.SessionID, Context: context}}
+	if err := writeExport(conversations, format, outPath); err != nil {
+		printMessage(i18n.T("cli.export_failed"), err)
+		return
+	}
+
+	printMessage(i18n.T("cli.export_saved", outPath), nil)
+}
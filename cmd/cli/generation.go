@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// setCommand обрабатывает команду /set [name value...|reset]
+func setCommand(args []string) {
+	if len(args) == 0 {
+		printGeneration(modelInstance.GenerationOptions())
+		return
+	}
+
+	if args[0] == "reset" {
+		modelInstance.ResetGenerationOptions()
+		printMessage(i18n.T("cli.generation_reset"), nil)
+		return
+	}
+
+	if err := modelInstance.SetGenerationOption(args[0], args[1:]); err != nil {
+		printMessage(i18n.T("cli.generation_set_failed"), err)
+		return
+	}
+	printMessage(i18n.T("cli.generation_set", args[0]), nil)
+}
+
+// printGeneration выводит параметры генерации текущей сессии
+func printGeneration(opts model.GenerationOptions) {
+	if jsonOutput {
+		emitJSON("generation", opts, nil)
+		return
+	}
+
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	for _, name := range model.GenerationParameters {
+		fmt.Fprintf(w, "%s\t%s\n", name, generationValue(opts, name))
+	}
+	w.Flush()
+	fmt.Fprintln(textOut, "\n"+i18n.T("cli.generation_hint"))
+}
+
+// generationValue форматирует значение параметра генерации для вывода
+func generationValue(opts model.GenerationOptions, name string) string {
+	switch name {
+	case model.GEN_TEMPERATURE:
+		return formatFloat(model.ValueOf(opts.Temperature))
+	case model.GEN_TOP_P:
+		return formatFloat(model.ValueOf(opts.TopP))
+	case model.GEN_TOP_K:
+		return strconv.Itoa(model.ValueOf(opts.TopK))
+	case model.GEN_MIN_P:
+		return formatFloat(model.ValueOf(opts.MinP))
+	case model.GEN_REPETITION_PENALTY:
+		return formatFloat(model.ValueOf(opts.RepetitionPenalty))
+	case model.GEN_FREQUENCY_PENALTY:
+		return formatFloat(model.ValueOf(opts.FrequencyPenalty))
+	case model.GEN_PRESENCE_PENALTY:
+		return formatFloat(model.ValueOf(opts.PresencePenalty))
+	case model.GEN_STOP:
+		quoted := make([]string, len(opts.Stop))
+		for i, stop := range opts.Stop {
+			quoted[i] = strconv.Quote(stop)
+		}
+		return strings.Join(quoted, " ")
+	case model.GEN_MAX_TOKENS:
+		return strconv.Itoa(model.ValueOf(opts.MaxTokens))
+	case model.GEN_SEED:
+		return strconv.Itoa(model.ValueOf(opts.Seed))
+	}
+	return ""
+}
+
+// formatFloat форматирует число без лишних нулей
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// scanLangFlag находит значение флага --lang до разбора остальных флагов
+func scanLangFlag(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		if value, ok := strings.CutPrefix(name, "lang="); ok {
+			return value
+		}
+		if name == "lang" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// addLangFlag регистрирует флаг --lang в наборе флагов команды. Разбор
+// флагов присваивает langFlag то же значение, что нашел scanLangFlag
+func addLangFlag(fs *flag.FlagSet) {
+	fs.StringVar(&langFlag, "lang", "", i18n.T("cli.flag_lang", strings.Join(i18n.Languages(), ", ")))
+}
@@ -1,19 +1,24 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"smollm-sandbox/internal/config"
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/rag"
 	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/search"
 	"smollm-sandbox/internal/storage"
 )
 
@@ -22,65 +27,101 @@ const (
 )
 
 var (
-	logger        *logging.Logger
-	modelInstance *model.SmolLM
-	sandboxEnv    *sandbox.Environment
-	store         *storage.FileSystem
-	inputScanner  *bufio.Scanner
+	logger         *logging.Logger
+	modelInstance  *model.SmolLM
+	sandboxEnv     *sandbox.Environment
+	store          *storage.FileSystem
+	sessionManager *storage.SessionManager
+	searchIndex    *search.Index
+	ragIndex       *rag.Index
+	userMemory     *storage.UserMemory
+	cfg            *config.Config
+	console        *REPL
+
+	shutdownMu    sync.Mutex
+	shutdownHooks []func() // Вызываются обработчиком сигналов (onShutdown)
+
+	// langFlag - язык интерфейса из флага --lang; пусто - язык из конфигурации
+	langFlag string
 )
 
 func main() {
+	// Язык нужен до определения флагов: от него зависят их описания
+	langFlag = scanLangFlag(os.Args[1:])
+	i18n.SetLanguage(langFlag)
+
+	// Подкоманды со своими наборами флагов
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "batch":
+			runBatchCommand(os.Args[2:])
+			return
+		case "sessions":
+			runSessionsCommand(os.Args[2:])
+			return
+		case "export":
+			runExportCommand(os.Args[2:])
+			return
+		case "search":
+			runSearchCommand(os.Args[2:])
+			return
+		case "rag":
+			runRagCommand(os.Args[2:])
+			return
+		case "thoughts":
+			runThoughtsCommand(os.Args[2:])
+			return
+		}
+	}
+
 	// Определение флагов
-	versionFlag := flag.Bool("version", false, "Вывести версию и выйти")
-	configPath := flag.String("config", "configs/config.yaml", "Путь к файлу конфигурации")
-	sessionFlag := flag.String("session", "", "Имя сессии (для сохранения/загрузки)")
-	interactiveFlag := flag.Bool("interactive", false, "Интерактивный режим")
-	thoughtFlag := flag.Bool("thought", false, "Режим размышления (без ввода пользователя)")
-	thoughtTimeFlag := flag.Int("thought-time", 60, "Время размышления в секундах")
-	inputFlag := flag.String("input", "", "Входной текст или файл")
+	versionFlag := flag.Bool("version", false, i18n.T("cli.flag_version"))
+	configPath := flag.String("config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	sessionFlag := flag.String("session", "", i18n.T("cli.flag_session"))
+	profileFlag := flag.String("profile", "", i18n.T("cli.flag_profile"))
+	interactiveFlag := flag.Bool("interactive", false, i18n.T("cli.flag_interactive"))
+	thoughtFlag := flag.Bool("thought", false, i18n.T("cli.flag_thought"))
+	thoughtTimeFlag := flag.Int("thought-time", 60, i18n.T("cli.flag_thought_time"))
+	seedFlag := flag.Int("seed", 0, i18n.T("cli.flag_seed"))
+	inputFlag := flag.String("input", "", i18n.T("cli.flag_input"))
+	outputFlag := flag.String("output", OUTPUT_TEXT, i18n.T("cli.flag_output"))
+	addLangFlag(flag.CommandLine)
 
 	flag.Parse()
 
+	if err := setOutputFormat(*outputFlag); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
 	// Проверка версии
 	if *versionFlag {
-		fmt.Printf("SmolLM Sandbox v%s\n", VERSION)
+		if jsonOutput {
+			emitJSON("version", VersionReport{Version: VERSION}, nil)
+		} else {
+			fmt.Printf("SmolLM Sandbox v%s\n", VERSION)
+		}
 		os.Exit(0)
 	}
 
-	// Инициализация логирования
-	logger = logging.NewLogger()
-	logger.Info("Starting SmolLM Sandbox v%s", VERSION)
-
-	// Загрузка конфигурации
-	logger.Info("Loading configuration from %s", *configPath)
-	// TODO: Реализовать загрузку конфигурации
-
-	// Инициализация хранилища
-	homeDir := getHomeDir()
-	store = storage.NewFileSystem(homeDir)
-
-	// Инициализация модели
-	logger.Info("Initializing SmolLM2 model")
-	modelInstance = model.NewSmolLM()
-
-	// Инициализация песочницы
-	logger.Info("Setting up sandbox environment")
-	sandboxEnv = sandbox.NewEnvironment()
-
-	// Инициализация сканера ввода
-	inputScanner = bufio.NewScanner(os.Stdin)
-
-	// Настройка обработки сигналов для корректного завершения
-	setupSignalHandler()
+	initComponents(*configPath)
 
 	// Загрузка сессии, если указана
 	if *sessionFlag != "" {
 		logger.Info("Loading session: %s", *sessionFlag)
 		if err := modelInstance.LoadSession(*sessionFlag); err != nil {
 			logger.Error("Failed to load session: %v", err)
-			fmt.Printf("Ошибка загрузки сессии: %v\n", err)
+			printMessage(i18n.T("cli.session_load_failed"), err)
 		} else {
-			fmt.Printf("Сессия '%s' успешно загружена\n", *sessionFlag)
+			printMessage(i18n.T("cli.session_loaded", *sessionFlag), nil)
+		}
+	}
+
+	// Явно указанный профиль заменяет профиль загруженной сессии
+	if *profileFlag != "" {
+		if err := modelInstance.UseProfile(*profileFlag); err != nil {
+			printMessage(i18n.T("cli.profile_select_failed"), err)
+			os.Exit(1)
 		}
 	}
 
@@ -88,7 +129,7 @@ func main() {
 	if *interactiveFlag {
 		runInteractiveMode()
 	} else if *thoughtFlag {
-		runThoughtMode(*thoughtTimeFlag)
+		runThoughtMode(*thoughtTimeFlag, *seedFlag)
 	} else if *inputFlag != "" {
 		processInput(*inputFlag)
 	} else {
@@ -101,19 +142,117 @@ func main() {
 	logger.Info("SmolLM Sandbox finished")
 }
 
+// initComponents загружает конфигурацию и инициализирует логирование, хранилище, модель и песочницу
+func initComponents(configPath string) {
+	initStorage(configPath)
+
+	// Инициализация модели
+	logger.Info("Initializing SmolLM2 model")
+	modelInstance = model.NewSmolLMWithOptions(model.Options{
+		Path:         config.ExpandPath(cfg.Model.Path),
+		Backend:      cfg.Model.Backend,
+		Generation:   cfg.Model.Parameters,
+		ThinkingSeed: cfg.Model.Thinking.Seed,
+		Timeout:      time.Duration(cfg.Model.Timeout) * time.Second,
+		Parallel:     cfg.Model.Queue.Parallel,
+		QueueSize:    cfg.Model.Queue.MaxSize,
+	})
+	modelInstance.SetSessionStore(sessionManager)
+	initResponseCache()
+	initProfiles()
+	initMemory()
+
+	// Подключение поиска по папке документов. Индекс обновляется при запуске;
+	// при ошибке используется ранее построенный
+	if cfg.RAG.Enabled {
+		ingestDocuments()
+		modelInstance.SetRetriever(ragIndex)
+	}
+
+	// Инициализация песочницы
+	logger.Info("Setting up sandbox environment")
+	sandboxEnv = sandbox.NewEnvironment()
+
+	// Настройка обработки сигналов для корректного завершения
+	setupSignalHandler()
+}
+
+// initStorage загружает конфигурацию и инициализирует логирование и хранилище
+func initStorage(configPath string) {
+	// Загрузка конфигурации
+	var err error
+	cfg, err = config.Load(configPath)
+
+	// Инициализация логирования. Логи никогда не попадают в stdout в режиме JSON
+	logging.SetDefaultLogConfig(logging.LogConfig{
+		Level:         logging.ParseLevel(cfg.Logging.Level),
+		EnableFile:    cfg.Logging.File != "",
+		FilePath:      config.ExpandPath(cfg.Logging.File),
+		EnableConsole: cfg.Logging.Console,
+		Console:       textOut,
+	})
+	logger = logging.NewLogger()
+	logger.Info("Starting SmolLM Sandbox v%s", VERSION)
+
+	logger.Info("Loading configuration from %s", configPath)
+	if err != nil {
+		logger.Warn("Using default configuration: %v", err)
+	}
+
+	// Флаг --lang важнее языка из конфигурации
+	lang := cfg.Language
+	if langFlag != "" {
+		lang = langFlag
+	}
+	if i18n.Normalize(lang) == "" {
+		logger.Warn("Unsupported interface language %q, using %s", lang, i18n.DEFAULT_LANG)
+	}
+	i18n.SetLanguage(lang)
+
+	// Инициализация хранилища
+	homeDir := getHomeDir()
+	store = storage.NewFileSystem(homeDir)
+	sessionManager = storage.NewSessionManager(store, cfg.Storage.SessionsDir)
+
+	// Поисковый индекс обновляется при каждом сохранении сессии
+	searchIndex = search.NewIndex(store)
+	sessionManager.AddListener(searchIndex)
+
+	ragIndex = rag.NewIndex(store, cfg.RAG)
+}
+
+// initResponseCache подключает кэш ответов модели во временной директории хранилища
+func initResponseCache() {
+	if !cfg.Model.Cache.Enabled {
+		return
+	}
+
+	cache, err := storage.NewResponseCache(store, cfg.Model.Cache.MaxSize)
+	if err != nil {
+		logger.Warn("Response cache is unavailable: %v", err)
+		return
+	}
+	modelInstance.SetResponseCache(cache)
+}
+
 func runInteractiveMode() {
-	fmt.Println("SmolLM Sandbox v" + VERSION + " интерактивный режим")
-	fmt.Println("Введите текст для общения с нейросетью или команду (/help для списка команд)")
-	fmt.Println("Для выхода введите 'exit'")
+	fmt.Fprintln(textOut, i18n.T("cli.interactive_title", VERSION))
+	fmt.Fprintln(textOut, i18n.T("cli.interactive_hint"))
+	fmt.Fprintln(textOut, i18n.T("cli.interactive_multiline", BLOCK_TERMINATOR))
+	fmt.Fprintln(textOut, i18n.T("cli.interactive_exit"))
 
-	for {
-		fmt.Print("\n> ")
-		var input string
+	console = NewREPL(cfg.CLI.Prompt, config.ExpandPath(cfg.CLI.HistoryFile))
+	defer console.Close()
+	onShutdown(console.Close)
 
-		if inputScanner.Scan() {
-			input = inputScanner.Text()
-		} else {
-			break // Обрабатываем ошибку или EOF
+	for {
+		fmt.Fprintln(textOut)
+		input, err := console.ReadInput()
+		if err != nil {
+			if err != io.EOF {
+				logger.Error("Failed to read input: %v", err)
+			}
+			break
 		}
 
 		input = strings.TrimSpace(input)
@@ -132,30 +271,64 @@ func runInteractiveMode() {
 		}
 
 		// Обработка ввода
-		fmt.Println("\nОбработка запроса... Пожалуйста, подождите.")
-		response := modelInstance.Process(input)
-		fmt.Printf("\n%s\n", response)
+		fmt.Fprintln(textOut, "\n"+i18n.T("cli.processing"))
+		result, err := modelInstance.ProcessWithOptions(input, model.ProcessOptions{})
+		printResponse(input, result, err)
 	}
 }
 
-func runThoughtMode(seconds int) {
-	fmt.Printf("Запуск режима размышления на %d секунд\n", seconds)
+func runThoughtMode(seconds, seed int) {
+	fmt.Fprintln(textOut, i18n.T("cli.thought_start", seconds))
 
 	// Создание файла для записи размышлений
+	thoughtFile := newThoughtFile("thought")
+
+	fmt.Fprintln(textOut, i18n.T("cli.thought_wait"))
+	fmt.Fprintln(textOut, i18n.T("cli.thought_target", thoughtFile))
+
+	// Запускаем размышление
+	start := time.Now()
+	run, err := modelInstance.ThinkWithOptions(model.ThinkOptions{Seconds: seconds, Seed: seed}, thoughtFile)
+	if err != nil {
+		logger.Error("Thinking mode failed: %v", err)
+	}
+
+	if err := searchIndex.IndexThought(thoughtFile); err != nil {
+		logger.Warn("Failed to index thoughts: %v", err)
+	}
+
+	fmt.Fprintln(textOut, i18n.T("cli.thought_done"))
+	fmt.Fprintln(textOut, i18n.T("cli.thought_saved", thoughtFile))
+	fmt.Fprintln(textOut, i18n.T("cli.thought_seed", run.Seed, model.ThoughtRecordPath(thoughtFile)))
+
+	if jsonOutput {
+		content, readErr := os.ReadFile(thoughtFile)
+		if err == nil {
+			err = readErr
+		}
+		emitJSON("thought", ThoughtReport{
+			File:       thoughtFile,
+			Record:     model.ThoughtRecordPath(thoughtFile),
+			Seconds:    seconds,
+			Seed:       run.Seed,
+			Generation: run.Generation,
+			Thoughts:   len(run.Thoughts),
+			DurationMs: time.Since(start).Milliseconds(),
+			Content:    string(content),
+		}, err)
+	}
+}
+
+// newThoughtFile возвращает путь нового журнала размышлений с префиксом
+// prefix и создает директорию журналов
+func newThoughtFile(prefix string) string {
 	timestamp := time.Now().Format("20060102_150405")
-	thoughtFile := filepath.Join(getHomeDir(), "thoughts", fmt.Sprintf("thought_%s.txt", timestamp))
+	thoughtFile := filepath.Join(getHomeDir(), "thoughts", fmt.Sprintf("%s_%s.txt", prefix, timestamp))
 
 	// Убедиться, что директория существует
 	os.MkdirAll(filepath.Dir(thoughtFile), 0755)
 
-	fmt.Println("Запуск режима размышления. Это займет некоторое время...")
-	fmt.Printf("Размышления будут сохранены в: %s\n", thoughtFile)
-
-	// Запускаем размышление
-	modelInstance.Think(seconds, thoughtFile)
-
-	fmt.Println("Режим размышления завершен!")
-	fmt.Printf("Размышления записаны в файл: %s\n", thoughtFile)
+	return thoughtFile
 }
 
 func processInput(input string) {
@@ -164,118 +337,245 @@ func processInput(input string) {
 		// Читаем файл
 		content, err := os.ReadFile(input)
 		if err != nil {
-			logger.Error("Не удалось прочитать файл: %v", err)
-			fmt.Printf("Ошибка: не удалось прочитать файл: %v\n", err)
+			logger.Error("Failed to read file: %v", err)
+			printMessage(i18n.T("cli.file_read_failed"), err)
 			return
 		}
 		input = string(content)
-		fmt.Println("Обработка содержимого файла...")
+		fmt.Fprintln(textOut, i18n.T("cli.processing_file"))
 	} else {
-		fmt.Println("Обработка текста...")
+		fmt.Fprintln(textOut, i18n.T("cli.processing_text"))
 	}
 
-	response := modelInstance.Process(input)
-	fmt.Printf("\n%s\n", response)
+	result, err := modelInstance.ProcessWithOptions(input, model.ProcessOptions{})
+	printResponse(input, result, err)
 }
 
 func handleCommand(cmd string) {
-	parts := strings.Split(cmd[1:], " ")
+	parts, err := splitArgs(cmd[1:])
+	if err != nil {
+		printMessage(i18n.T("cli.command_parse_failed"), err)
+		return
+	}
+	if len(parts) == 0 {
+		return
+	}
 	command := parts[0]
 	args := parts[1:]
 
 	switch command {
 	case "save":
 		if len(args) == 0 {
-			fmt.Println("Необходимо указать имя сессии: /save session_name")
+			fmt.Fprintln(textOut, i18n.T("cli.usage_save"))
 			return
 		}
 
 		// Сохраняем сессию
 		if err := modelInstance.SaveSession(args[0]); err != nil {
 			logger.Error("Failed to save session: %v", err)
-			fmt.Printf("Ошибка сохранения сессии: %v\n", err)
+			printMessage(i18n.T("cli.session_save_failed"), err)
 		} else {
-			fmt.Printf("Сессия сохранена как: %s\n", args[0])
+			printMessage(i18n.T("cli.session_saved", args[0]), nil)
 		}
 
 	case "load":
 		if len(args) == 0 {
-			fmt.Println("Необходимо указать имя сессии: /load session_name")
+			fmt.Fprintln(textOut, i18n.T("cli.usage_load"))
 			return
 		}
 
 		// Загружаем сессию
 		if err := modelInstance.LoadSession(args[0]); err != nil {
 			logger.Error("Failed to load session: %v", err)
-			fmt.Printf("Ошибка загрузки сессии: %v\n", err)
+			printMessage(i18n.T("cli.session_load_failed"), err)
+		} else {
+			printMessage(i18n.T("cli.session_loaded", args[0]), nil)
+		}
+
+	case "sessions":
+		action := "list"
+		if len(args) > 0 {
+			action = args[0]
+			args = args[1:]
+		}
+		if err := sessionsAction(action, args); err != nil && !jsonOutput {
+			fmt.Fprintln(textOut, i18n.T("cli.error", i18n.LocalizeError(err)))
+		}
+
+	case "retry":
+		retryResponse()
+
+	case "edit":
+		editMessage(args)
+
+	case "branch":
+		switchBranch(args)
+
+	case "export":
+		exportCurrent(args)
+
+	case "search":
+		if len(args) == 0 {
+			fmt.Fprintln(textOut, i18n.T("cli.usage_search"))
+			return
+		}
+		results, err := searchIndex.Search(search.Query{Text: strings.Join(args, " ")})
+		if jsonOutput {
+			emitJSON("search", results, err)
+		} else if err != nil {
+			fmt.Fprintln(textOut, i18n.T("cli.search_failed", i18n.LocalizeError(err)))
 		} else {
-			fmt.Printf("Сессия '%s' успешно загружена\n", args[0])
+			printSearchResults(results)
 		}
 
+	case "profile":
+		profileCommand(args)
+
+	case "set":
+		setCommand(args)
+
+	case "memory":
+		memoryCommand(args)
+
+	case "remember":
+		rememberFact(args)
+
 	case "run":
 		if len(args) == 0 {
-			fmt.Println("Необходимо указать исполняемый файл: /run filename")
+			fmt.Fprintln(textOut, i18n.T("cli.usage_run"))
+			return
+		}
+		if !codeAllowed() {
 			return
 		}
 		// Запуск кода в песочнице
-		output, err := sandboxEnv.Execute(args[0])
-		if err != nil {
-			fmt.Printf("Ошибка выполнения: %v\n", err)
-		} else {
-			fmt.Println(output)
+		result, err := sandboxEnv.RunFile(args[0])
+		language := strings.TrimPrefix(filepath.Ext(args[0]), ".")
+		printExecution(language, args[0], result, err)
+		if err == nil {
+			code, _ := os.ReadFile(args[0])
+			recordExecution(language, string(code), result)
 		}
 
 	case "code":
-		if len(args) < 2 {
-			fmt.Println("Необходимо указать язык и код: /code [python|js|go] \"код для выполнения\"")
+		if len(args) == 0 {
+			fmt.Fprintln(textOut, i18n.T("cli.usage_code"))
+			return
+		}
+
+		if !codeAllowed() {
 			return
 		}
 
 		language := args[0]
 		code := strings.Join(args[1:], " ")
 
+		// Без кода читаем многострочный блок до терминатора
+		if len(args) == 1 {
+			if console == nil {
+				fmt.Fprintln(textOut, i18n.T("cli.usage_code"))
+				return
+			}
+			fmt.Fprintln(textOut, i18n.T("cli.enter_code", BLOCK_TERMINATOR))
+			code, err = console.ReadBlock(BLOCK_TERMINATOR)
+			if err != nil {
+				return
+			}
+		}
+
 		// Выполняем код в песочнице
-		output, err := sandboxEnv.ExecuteCode(code, language)
-		if err != nil {
-			fmt.Printf("Ошибка выполнения кода: %v\n", err)
-		} else {
-			fmt.Println(output)
+		result, err := sandboxEnv.RunCode(code, language)
+		printExecution(language, "", result, err)
+		if err == nil {
+			recordExecution(language, code, result)
 		}
 
+	case "paste":
+		if console == nil {
+			fmt.Fprintln(textOut, i18n.T("cli.paste_interactive_only"))
+			return
+		}
+
+		fmt.Fprintln(textOut, i18n.T("cli.enter_paste", BLOCK_TERMINATOR))
+		text, err := console.ReadBlock(BLOCK_TERMINATOR)
+		if err != nil || strings.TrimSpace(text) == "" {
+			return
+		}
+
+		fmt.Fprintln(textOut, "\n"+i18n.T("cli.processing"))
+		result, err := modelInstance.ProcessWithOptions(text, model.ProcessOptions{})
+		printResponse(text, result, err)
+
 	case "help":
-		fmt.Println("Доступные команды:")
-		fmt.Println("  /save [session_name] - Сохранить текущую сессию")
-		fmt.Println("  /load [session_name] - Загрузить сохраненную сессию")
-		fmt.Println("  /run [filename] - Запустить файл в песочнице")
-		fmt.Println("  /code [language] [code] - Выполнить строку кода")
-		fmt.Println("  /help - Показать эту справку")
-		fmt.Println("  exit - Выйти из программы")
+		fmt.Fprintln(textOut, i18n.T("cli.help_title"))
+		fmt.Fprintln(textOut, "  /save [session_name] - "+i18n.T("cli.help_save"))
+		fmt.Fprintln(textOut, "  /load [session_name] - "+i18n.T("cli.help_load"))
+		fmt.Fprintln(textOut, "  /sessions [list|show|delete|rename|export|import|fork|migrate] - "+i18n.T("cli.help_sessions"))
+		fmt.Fprintln(textOut, "  /retry - "+i18n.T("cli.help_retry"))
+		fmt.Fprintln(textOut, "  /edit [N] [text] - "+i18n.T("cli.help_edit"))
+		fmt.Fprintln(textOut, "  /branch [N] - "+i18n.T("cli.help_branch"))
+		fmt.Fprintln(textOut, "  /export [format] [file] - "+i18n.T("cli.help_export"))
+		fmt.Fprintln(textOut, "  /search [query] - "+i18n.T("cli.help_search"))
+		fmt.Fprintln(textOut, "  /profile [name] - "+i18n.T("cli.help_profile"))
+		fmt.Fprintln(textOut, "  /set [param value|reset] - "+i18n.T("cli.help_set"))
+		fmt.Fprintln(textOut, "  /memory [list|forget N|forget all] - "+i18n.T("cli.help_memory"))
+		fmt.Fprintln(textOut, "  /remember [text] - "+i18n.T("cli.help_remember"))
+		fmt.Fprintln(textOut, "  /run [filename] - "+i18n.T("cli.help_run"))
+		fmt.Fprintln(textOut, "  /code [language] [code] - "+i18n.T("cli.help_code"))
+		fmt.Fprintln(textOut, "  /paste - "+i18n.T("cli.help_paste", BLOCK_TERMINATOR))
+		fmt.Fprintln(textOut, "  /help - "+i18n.T("cli.help_help"))
+		fmt.Fprintln(textOut, "  exit - "+i18n.T("cli.help_exit"))
 
 	default:
-		fmt.Printf("Неизвестная команда: %s\n", command)
-		fmt.Println("Введите /help для списка команд")
+		if jsonOutput {
+			emitJSON("message", nil, i18n.NewError("cli.unknown_command", command))
+			return
+		}
+		fmt.Fprintln(textOut, i18n.T("cli.unknown_command", command))
+		fmt.Fprintln(textOut, i18n.T("cli.help_hint"))
 	}
 }
 
 func getHomeDir() string {
+	// Корневая директория хранилища из конфигурации
+	if cfg != nil && cfg.Storage.RootDir != "" {
+		return config.ExpandPath(cfg.Storage.RootDir)
+	}
+
 	// В продакшне здесь будет домашняя директория учетной записи нейросети
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		logger.Error("Не удалось определить домашнюю директорию: %v", err)
+		logger.Error("Failed to determine home directory: %v", err)
 		return "/tmp/smollm-sandbox"
 	}
 	return filepath.Join(homeDir, ".smollm-sandbox")
 }
 
 func printUsage() {
-	fmt.Println("SmolLM Sandbox - песочница для нейросети SmolLM2")
-	fmt.Println("\nИспользование:")
+	fmt.Fprintln(textOut, i18n.T("cli.usage_title"))
+	fmt.Fprintln(textOut, "\n"+i18n.T("cli.usage_header"))
 	flag.PrintDefaults()
-	fmt.Println("\nПримеры:")
-	fmt.Println("  smollm-cli --interactive                # Запуск в интерактивном режиме")
-	fmt.Println("  smollm-cli --thought --thought-time=300 # Запуск режима размышления на 5 минут")
-	fmt.Println("  smollm-cli --input=\"Напиши простой скрипт на Python\" # Обработка текста")
-	fmt.Println("  smollm-cli --input=input.txt            # Обработка файла")
+	fmt.Fprintln(textOut, "\n"+i18n.T("cli.examples_header"))
+	fmt.Fprintln(textOut, "  smollm-cli --interactive                # "+i18n.T("cli.example_interactive"))
+	fmt.Fprintln(textOut, "  smollm-cli --thought --thought-time=300 # "+i18n.T("cli.example_thought"))
+	fmt.Fprintln(textOut, "  smollm-cli thoughts replay thought.txt  # "+i18n.T("cli.example_thoughts_replay"))
+	fmt.Fprintln(textOut, "  smollm-cli --input=\""+i18n.T("cli.example_prompt")+"\" # "+i18n.T("cli.example_input_text"))
+	fmt.Fprintln(textOut, "  smollm-cli --input=input.txt            # "+i18n.T("cli.example_input_file"))
+	fmt.Fprintln(textOut, "  smollm-cli --output=json --input=\"2+2?\" # "+i18n.T("cli.example_json"))
+	fmt.Fprintln(textOut, "  smollm-cli batch --in prompts.jsonl --out results.jsonl # "+i18n.T("cli.example_batch"))
+	fmt.Fprintln(textOut, "  smollm-cli sessions list                # "+i18n.T("cli.example_sessions"))
+	fmt.Fprintln(textOut, "  smollm-cli --profile=coder --interactive # "+i18n.T("cli.example_profile"))
+	fmt.Fprintln(textOut, "  smollm-cli rag ingest                   # "+i18n.T("cli.example_rag"))
+	fmt.Fprintln(textOut, "  smollm-cli --lang=en --interactive      # "+i18n.T("cli.example_lang"))
+}
+
+// onShutdown регистрирует функцию, которую обработчик сигналов вызывает
+// перед завершением процесса
+func onShutdown(hook func()) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+
+	shutdownHooks = append(shutdownHooks, hook)
 }
 
 func setupSignalHandler() {
@@ -284,7 +584,14 @@ func setupSignalHandler() {
 
 	go func() {
 		<-c
-		fmt.Println("\nПолучен сигнал завершения. Освобождение ресурсов...")
+		fmt.Fprintln(textOut, "\n"+i18n.T("cli.shutdown"))
+
+		// Восстанавливаем терминал и сохраняем историю REPL
+		shutdownMu.Lock()
+		for _, hook := range shutdownHooks {
+			hook()
+		}
+		shutdownMu.Unlock()
 
 		// Закрываем соединения и освобождаем ресурсы
 		if modelInstance != nil {
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/storage"
+)
+
+// Идентификатор пользователя, если имя учетной записи определить не удалось
+const DEFAULT_USER_ID = "local"
+
+// MemoryReport описывает факт долговременной памяти
+type MemoryReport struct {
+	Index  int    `json:"index"`
+	Text   string `json:"text"`
+	Source string `json:"source"`
+}
+
+// initMemory подключает долговременную память текущего пользователя ОС
+func initMemory() {
+	var err error
+	userMemory, err = storage.NewUserMemory(store, localUserID())
+	if err != nil {
+		logger.Warn("User memory is unavailable: %v", err)
+		return
+	}
+	modelInstance.SetMemory(userMemory)
+}
+
+// localUserID возвращает идентификатор пользователя для памяти CLI
+func localUserID() string {
+	current, err := user.Current()
+	if err != nil || current.Username == "" {
+		return DEFAULT_USER_ID
+	}
+
+	// Оставляем только допустимые в имени директории символы
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, current.Username)
+}
+
+// memoryCommand обрабатывает команду /memory [list|forget N|forget all]
+func memoryCommand(args []string) {
+	if userMemory == nil {
+		printMessage(i18n.T("cli.memory_unavailable"), i18n.NewError("cli.memory_open_failed"))
+		return
+	}
+
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "list":
+		facts, err := userMemory.Facts()
+		if jsonOutput {
+			emitJSON("memory", memoryReports(facts), err)
+			return
+		}
+		if err != nil {
+			fmt.Fprintln(textOut, i18n.T("cli.memory_read_failed", i18n.LocalizeError(err)))
+			return
+		}
+		printMemory(facts)
+
+	case "forget":
+		if len(args) < 2 {
+			fmt.Fprintln(textOut, i18n.T("cli.usage_forget"))
+			return
+		}
+		if args[1] == "all" {
+			if err := userMemory.Clear(); err != nil {
+				printMessage(i18n.T("cli.memory_clear_failed"), err)
+				return
+			}
+			printMessage(i18n.T("cli.memory_cleared"), nil)
+			return
+		}
+
+		index, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Fprintln(textOut, i18n.T("cli.forget_not_number"))
+			return
+		}
+		fact, err := userMemory.Forget(index)
+		if err != nil {
+			printMessage(i18n.T("cli.forget_failed"), err)
+			return
+		}
+		printMessage(i18n.T("cli.forgotten", fact.Text), nil)
+
+	default:
+		fmt.Fprintln(textOut, i18n.T("cli.memory_unknown_action", action))
+	}
+}
+
+// rememberFact обрабатывает команду /remember текст
+func rememberFact(args []string) {
+	if userMemory == nil {
+		printMessage(i18n.T("cli.memory_unavailable"), i18n.NewError("cli.memory_open_failed"))
+		return
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.usage_remember"))
+		return
+	}
+
+	fact, err := userMemory.Remember(strings.Join(args, " "), model.MEMORY_SOURCE_USER)
+	if err != nil {
+		printMessage(i18n.T("cli.remember_failed"), err)
+		return
+	}
+	printMessage(i18n.T("cli.remembered", fact.Text), nil)
+}
+
+// memoryReports нумерует факты так же, как /memory forget
+func memoryReports(facts []model.MemoryFact) []MemoryReport {
+	reports := make([]MemoryReport, 0, len(facts))
+	for i, fact := range facts {
+		reports = append(reports, MemoryReport{
+			Index:  i + 1,
+			Text:   fact.Text,
+			Source: fact.Source,
+		})
+	}
+	return reports
+}
+
+// printMemory выводит сохраненные факты
+func printMemory(facts []model.MemoryFact) {
+	if len(facts) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.memory_empty"))
+		return
+	}
+
+	fmt.Fprintln(textOut, i18n.T("cli.memory_title"))
+	for i, fact := range facts {
+		source := ""
+		if fact.Source == model.MEMORY_SOURCE_MODEL {
+			source = i18n.T("cli.memory_by_model")
+		}
+		fmt.Fprintf(textOut, "  %d. %s%s\n", i+1, fact.Text, source)
+	}
+}
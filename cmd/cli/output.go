@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+)
+
+// Форматы вывода CLI
+const (
+	OUTPUT_TEXT = "text"
+	OUTPUT_JSON = "json"
+)
+
+var (
+	// jsonOutput включает машиночитаемый вывод результатов в stdout
+	jsonOutput bool
+	// textOut принимает текстовые сообщения для человека; в режиме JSON это stderr
+	textOut io.Writer = os.Stdout
+)
+
+// JSONEnvelope представляет одну строку машиночитаемого вывода
+type JSONEnvelope struct {
+	Type  string `json:"type"`            // "version", "response", "execution", "session", "sessions", "migration", "branches", "conversation", "export", "generation", "search", "ingest", "passages", "memory", "profiles", "thought", "thought_replay", "batch", "message"
+	OK    bool   `json:"ok"`              // Успешно ли выполнена операция
+	Error string `json:"error,omitempty"` // Текст ошибки на языке интерфейса, если OK == false
+	Code  string `json:"code,omitempty"`  // Код сообщения ошибки (например, storage.session_not_found)
+	Data  any    `json:"data,omitempty"`  // Данные, зависящие от типа
+}
+
+// VersionReport содержит версию программы
+type VersionReport struct {
+	Version string `json:"version"`
+}
+
+// ResponseReport содержит ответ модели
+type ResponseReport struct {
+	Input        string `json:"input"`
+	Response     string `json:"response"`
+	PromptTokens int    `json:"prompt_tokens"`
+	TokensUsed   int    `json:"tokens_used"`
+	LatencyMs    int64  `json:"latency_ms"`
+
+	Citations  []model.Passage    `json:"citations,omitempty"`
+	Remembered []model.MemoryFact `json:"remembered,omitempty"`
+}
+
+// ExecutionReport содержит результат выполнения кода в песочнице
+type ExecutionReport struct {
+	Language      string `json:"language"`
+	File          string `json:"file,omitempty"`
+	Success       bool   `json:"success"`
+	ExitCode      int    `json:"exit_code"`
+	Output        string `json:"output"`
+	Error         string `json:"error,omitempty"`
+	Failure       string `json:"failure,omitempty"`      // Причина неудачи: таймаут, лимит вывода, ошибка компиляции
+	FailureCode   string `json:"failure_code,omitempty"` // Код сообщения причины неудачи
+	Compiled      bool   `json:"compiled"`
+	CompileTimeMs int64  `json:"compile_time_ms"`
+	ExecuteTimeMs int64  `json:"execute_time_ms"`
+}
+
+// ThoughtReport содержит результат режима размышления
+type ThoughtReport struct {
+	File       string                  `json:"file"`
+	Record     string                  `json:"record"` // Запись для thoughts replay
+	Seconds    int                     `json:"seconds"`
+	Seed       int                     `json:"seed"`
+	Generation model.GenerationOptions `json:"generation"`
+	Thoughts   int                     `json:"thoughts"`
+	DurationMs int64                   `json:"duration_ms"`
+	Content    string                  `json:"content"`
+}
+
+// BranchReport описывает ветку диалога
+type BranchReport struct {
+	Index   int    `json:"index"`
+	LeafID  string `json:"leaf_id"`
+	Length  int    `json:"length"`
+	Preview string `json:"preview"`
+	Active  bool   `json:"active"`
+}
+
+// ConversationMessage описывает сообщение активной ветки
+type ConversationMessage struct {
+	Index   int    `json:"index"`
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// MessageReport содержит информационное сообщение
+type MessageReport struct {
+	Message string `json:"message"`
+}
+
+// setOutputFormat включает указанный формат вывода
+func setOutputFormat(format string) error {
+	switch format {
+	case OUTPUT_TEXT:
+		jsonOutput = false
+		textOut = os.Stdout
+	case OUTPUT_JSON:
+		jsonOutput = true
+		textOut = os.Stderr
+	default:
+		return i18n.NewError("cli.unknown_output_format", format, OUTPUT_TEXT, OUTPUT_JSON)
+	}
+	return nil
+}
+
+// emitJSON выводит одну строку машиночитаемого вывода в stdout
+func emitJSON(kind string, data any, err error) {
+	envelope := JSONEnvelope{
+		Type: kind,
+		OK:   err == nil,
+		Data: data,
+	}
+	if err != nil {
+		envelope.Error = i18n.LocalizeError(err)
+		envelope.Code = i18n.Code(err)
+	}
+
+	line, marshalErr := json.Marshal(envelope)
+	if marshalErr != nil {
+		logger.Error("Failed to marshal JSON output: %v", marshalErr)
+		return
+	}
+	os.Stdout.Write(append(line, '\n'))
+}
+
+// newExecutionReport преобразует результат песочницы в отчет
+func newExecutionReport(language, file string, result *sandbox.ExecuteResult) ExecutionReport {
+	return ExecutionReport{
+		Language:      language,
+		File:          file,
+		Success:       result.Success,
+		ExitCode:      result.ExitCode,
+		Output:        result.Output,
+		Error:         result.Error,
+		Failure:       i18n.LocalizeError(result.Failure),
+		FailureCode:   i18n.Code(result.Failure),
+		Compiled:      result.Compiled,
+		CompileTimeMs: result.CompileTime.Milliseconds(),
+		ExecuteTimeMs: result.ExecuteTime.Milliseconds(),
+	}
+}
+
+// recordExecution сохраняет результат выполнения в текущем диалоге
+func recordExecution(language, code string, result *sandbox.ExecuteResult) {
+	modelInstance.RecordExecution(model.ExecutionRecord{
+		Language: language,
+		Code:     code,
+		Success:  result.Success,
+		ExitCode: result.ExitCode,
+		Output:   result.Output,
+		Error:    result.Error,
+	})
+}
+
+// printResponse выводит ответ модели
+func printResponse(input string, result *model.ProcessResult, err error) {
+	if jsonOutput {
+		var report *ResponseReport
+		if result != nil {
+			report = &ResponseReport{
+				Input:        input,
+				Response:     result.Text,
+				PromptTokens: result.PromptTokens,
+				TokensUsed:   result.TokensUsed,
+				LatencyMs:    result.Latency.Milliseconds(),
+				Citations:    result.Citations,
+				Remembered:   result.Remembered,
+			}
+		}
+		emitJSON("response", report, err)
+		return
+	}
+
+	if err != nil {
+		// Недоступность модели не исправится повтором запроса
+		if errors.Is(err, model.ErrModelUnavailable) {
+			fmt.Fprintln(textOut, "\n"+i18n.T("cli.model_unavailable"))
+		} else {
+			fmt.Fprintln(textOut, "\n"+i18n.T("cli.response_failed"))
+		}
+		return
+	}
+	fmt.Fprintf(textOut, "\n%s\n", result.Text)
+	printCitations(result.Citations)
+	for _, fact := range result.Remembered {
+		fmt.Fprintln(textOut, i18n.T("cli.remembered_inline", fact.Text))
+	}
+}
+
+// printExecution выводит результат выполнения кода
+func printExecution(language, file string, result *sandbox.ExecuteResult, err error) {
+	if jsonOutput {
+		var report *ExecutionReport
+		if result != nil {
+			r := newExecutionReport(language, file, result)
+			report = &r
+		}
+		emitJSON("execution", report, err)
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintln(textOut, i18n.T("cli.execution_failed", i18n.LocalizeError(err)))
+		return
+	}
+	fmt.Fprintln(textOut, sandbox.FormatResult(result, i18n.Default()))
+}
+
+// printMessage выводит информационное сообщение или ошибку операции
+func printMessage(message string, err error) {
+	if jsonOutput {
+		var report *MessageReport
+		if message != "" {
+			report = &MessageReport{Message: message}
+		}
+		emitJSON("message", report, err)
+		return
+	}
+
+	if err != nil {
+		fmt.Fprintf(textOut, "%s: %s\n", message, i18n.LocalizeError(err))
+		return
+	}
+	fmt.Fprintln(textOut, message)
+}
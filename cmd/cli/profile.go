@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// ProfileReport описывает профиль модели
+type ProfileReport struct {
+	model.Profile
+	Active bool `json:"active"`
+}
+
+// initProfiles загружает профили из конфигурации и выбирает профиль новых сессий
+func initProfiles() {
+	profiles, err := cfg.Profiles.LoadProfiles()
+	if err != nil {
+		logger.Warn("Failed to load profiles: %v", err)
+	}
+	if err := modelInstance.SetProfiles(profiles); err != nil {
+		logger.Error("Invalid profile configuration: %v", err)
+		return
+	}
+
+	if cfg.Profiles.Default != "" {
+		if err := modelInstance.UseProfile(cfg.Profiles.Default); err != nil {
+			logger.Warn("Default profile is unavailable: %v", err)
+		}
+	}
+}
+
+// profileCommand обрабатывает команду /profile [name]
+func profileCommand(args []string) {
+	if len(args) == 0 {
+		printProfiles()
+		return
+	}
+
+	if err := modelInstance.UseProfile(args[0]); err != nil {
+		printMessage(i18n.T("cli.profile_select_failed"), err)
+		return
+	}
+	printMessage(i18n.T("cli.profile_selected", args[0]), nil)
+}
+
+// printProfiles выводит доступные профили
+func printProfiles() {
+	current := modelInstance.Profile().Name
+	profiles := modelInstance.Profiles()
+
+	if jsonOutput {
+		reports := make([]ProfileReport, 0, len(profiles))
+		for _, profile := range profiles {
+			reports = append(reports, ProfileReport{Profile: profile, Active: profile.Name == current})
+		}
+		emitJSON("profiles", reports, nil)
+		return
+	}
+
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.T("cli.profiles_header"))
+	for _, profile := range profiles {
+		marker := ""
+		if profile.Name == current {
+			marker = "*"
+		}
+		language := profile.Language
+		if language == "" {
+			language = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\t%s\n", marker, profile.Name, profile.Temperature, language, profile.Description)
+	}
+	w.Flush()
+	fmt.Fprintln(textOut, "\n"+i18n.T("cli.profile_hint"))
+}
+
+// codeAllowed проверяет, разрешено ли профилю сессии выполнение кода
+func codeAllowed() bool {
+	if modelInstance.Profile().Allows(model.TOOL_CODE) {
+		return true
+	}
+
+	printMessage(i18n.T("cli.code_unavailable"), i18n.NewError("model.profile_code_denied", modelInstance.Profile().Name).WithKind(model.ErrToolDenied))
+	return false
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/rag"
+)
+
+// Максимальное время индексации папки документов
+const RAG_INGEST_TIMEOUT = 5 * time.Minute
+
+// runRagCommand обрабатывает подкоманду rag
+func runRagCommand(args []string) {
+	flags := flag.NewFlagSet("rag", flag.ExitOnError)
+	configPath := flags.String("config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	outputFormat := flags.String("output", OUTPUT_TEXT, i18n.T("cli.flag_output"))
+	addLangFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.rag_usage"))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if err := setOutputFormat(*outputFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	initStorage(*configPath)
+
+	var err error
+	switch flags.Arg(0) {
+	case "ingest":
+		var report *rag.IngestReport
+		report, err = ingestDocuments()
+		printIngestReport(report, err)
+
+	case "query":
+		query := strings.Join(flags.Args()[1:], " ")
+		if strings.TrimSpace(query) == "" {
+			flags.Usage()
+			os.Exit(2)
+		}
+
+		var passages []model.Passage
+		passages, err = ragIndex.Retrieve(context.Background(), query)
+		if jsonOutput {
+			emitJSON("passages", passages, err)
+		} else if err == nil {
+			printPassages(passages)
+		}
+
+	default:
+		err = i18n.NewError("cli.rag_unknown_action", flags.Arg(0))
+		if jsonOutput {
+			emitJSON("message", nil, err)
+		}
+	}
+
+	if err != nil {
+		if !jsonOutput {
+			fmt.Fprintln(textOut, i18n.T("cli.error", i18n.LocalizeError(err)))
+		}
+		os.Exit(1)
+	}
+}
+
+// ingestDocuments обновляет индекс папки документов
+func ingestDocuments() (*rag.IngestReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RAG_INGEST_TIMEOUT)
+	defer cancel()
+
+	report, err := ragIndex.Ingest(ctx)
+	if err != nil {
+		logger.Error("Failed to ingest documents: %v", err)
+		return nil, err
+	}
+	return report, nil
+}
+
+// printIngestReport выводит итоги индексации папки документов
+func printIngestReport(report *rag.IngestReport, err error) {
+	if jsonOutput {
+		emitJSON("ingest", report, err)
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	method := "BM25"
+	if report.Embedded {
+		method = i18n.T("cli.rag_method_embeddings")
+	}
+	fmt.Fprintln(textOut, i18n.T("cli.rag_report", report.DocsDir, report.Files, report.Chunks, report.Updated, report.Removed, method))
+}
+
+// printPassages выводит найденные фрагменты документов
+func printPassages(passages []model.Passage) {
+	if len(passages) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.rag_no_passages"))
+		return
+	}
+
+	for i, passage := range passages {
+		fmt.Fprintf(textOut, "[%d] %s:%d-%d (%.3f)\n%s\n\n",
+			i+1, passage.Source, passage.StartLine, passage.EndLine, passage.Score, passage.Text)
+	}
+}
+
+// printCitations выводит источники, на которые может ссылаться ответ
+func printCitations(passages []model.Passage) {
+	if len(passages) == 0 {
+		return
+	}
+
+	fmt.Fprintln(textOut, "\n"+i18n.T("cli.rag_sources"))
+	for i, passage := range passages {
+		fmt.Fprintf(textOut, "  [%d] %s:%d-%d\n", i+1, passage.Source, passage.StartLine, passage.EndLine)
+	}
+}
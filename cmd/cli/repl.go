@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+	"smollm-sandbox/internal/i18n"
+)
+
+const (
+	// Максимальное количество записей в истории ввода
+	MAX_HISTORY_ENTRIES = 1000
+	// Приглашение для продолжения многострочного ввода
+	CONTINUATION_PROMPT = "... "
+	// Терминатор многострочного ввода для /paste и /code
+	BLOCK_TERMINATOR = "/end"
+	// Граница блока кода в стиле Markdown
+	CODE_FENCE = "```"
+	// Максимальный размер одной строки при чтении не из терминала
+	MAX_INPUT_SIZE = 1024 * 1024
+)
+
+// replCommands содержит команды, доступные для автодополнения
+var replCommands = []string{"/save", "/load", "/sessions", "/retry", "/edit", "/branch", "/export", "/search", "/profile", "/set", "/memory", "/remember", "/run", "/code", "/paste", "/help", "exit"}
+
+// sessionCommands содержит команды, аргументом которых является имя сессии
+var sessionCommands = map[string]bool{"/save": true, "/load": true}
+
+// REPL обеспечивает построчный ввод с редактированием, историей и автодополнением
+type REPL struct {
+	fd       int
+	terminal *term.Terminal
+	scanner  *bufio.Scanner
+	history  *fileHistory
+	prompt   string
+
+	mu       sync.Mutex
+	rawState *term.State // Состояние терминала до перехода в raw-режим; nil - не в raw-режиме
+}
+
+// NewREPL создает новый REPL. Если стандартный ввод не является терминалом,
+// используется простое построчное чтение без редактирования
+func NewREPL(prompt, historyFile string) *REPL {
+	r := &REPL{
+		fd:     int(os.Stdin.Fd()),
+		prompt: prompt,
+	}
+
+	if !term.IsTerminal(r.fd) {
+		r.scanner = bufio.NewScanner(os.Stdin)
+		r.scanner.Buffer(make([]byte, 64*1024), MAX_INPUT_SIZE)
+		return r
+	}
+
+	r.history = newFileHistory(historyFile, MAX_HISTORY_ENTRIES)
+	if err := r.history.load(); err != nil {
+		logger.Warn("Failed to load history: %v", err)
+	}
+
+	r.terminal = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, textOut}, prompt)
+	r.terminal.History = r.history
+	r.terminal.AutoCompleteCallback = r.complete
+
+	return r
+}
+
+// ReadLine читает одну строку с основным приглашением
+func (r *REPL) ReadLine() (string, error) {
+	return r.readLine(r.prompt)
+}
+
+// ReadBlock читает строки до терминатора и возвращает их, объединенные переводами строк
+func (r *REPL) ReadBlock(terminator string) (string, error) {
+	var lines []string
+	for {
+		line, err := r.readLine(CONTINUATION_PROMPT)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(line) == terminator {
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// ReadInput читает законченный ввод пользователя: одну строку или многострочный
+// блок, начатый с ``` и закрытый строкой ```
+func (r *REPL) ReadInput() (string, error) {
+	line, err := r.ReadLine()
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(line), CODE_FENCE) {
+		return line, nil
+	}
+
+	// Однострочный блок вида ```код```
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) > 2*len(CODE_FENCE) && strings.HasSuffix(trimmed, CODE_FENCE) {
+		return line, nil
+	}
+
+	body, err := r.ReadBlock(CODE_FENCE)
+	if err != nil {
+		return "", err
+	}
+
+	return line + "\n" + body + "\n" + CODE_FENCE, nil
+}
+
+// readLine читает строку, временно переводя терминал в raw-режим
+func (r *REPL) readLine(prompt string) (string, error) {
+	if r.terminal == nil {
+		fmt.Fprint(textOut, prompt)
+		if r.scanner.Scan() {
+			return r.scanner.Text(), nil
+		}
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	oldState, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", i18n.WrapError(err, "cli.raw_mode_failed")
+	}
+	r.mu.Lock()
+	r.rawState = oldState
+	r.mu.Unlock()
+	defer r.restoreTerminal()
+
+	if width, height, err := term.GetSize(r.fd); err == nil {
+		r.terminal.SetSize(width, height)
+	}
+
+	r.terminal.SetPrompt(prompt)
+	line, err := r.terminal.ReadLine()
+	if errors.Is(err, term.ErrPasteIndicator) {
+		// Вставленный текст обрабатываем как обычный ввод
+		err = nil
+	}
+
+	return line, err
+}
+
+// complete реализует автодополнение команд и имен сессий по клавише Tab
+func (r *REPL) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	prefix := line[:pos]
+	suffix := line[pos:]
+	fields := strings.Fields(prefix)
+
+	var candidates []string
+	var word string
+
+	switch {
+	case len(fields) <= 1 && !strings.HasSuffix(prefix, " "):
+		// Дополняем имя команды
+		if len(fields) == 1 {
+			word = fields[0]
+		}
+		candidates = replCommands
+	case len(fields) == 1 && sessionCommands[fields[0]],
+		len(fields) == 2 && sessionCommands[fields[0]] && !strings.HasSuffix(prefix, " "):
+		// Дополняем имя сессии
+		if len(fields) == 2 {
+			word = fields[1]
+		}
+		candidates = sessionNames()
+	default:
+		return line, pos, true
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	if len(matches) == 0 {
+		return line, pos, true
+	}
+
+	completion := commonPrefix(matches)
+	if len(matches) == 1 {
+		completion += " "
+	}
+
+	newPrefix := prefix[:len(prefix)-len(word)] + completion
+	return newPrefix + suffix, len(newPrefix), true
+}
+
+// restoreTerminal возвращает терминал из raw-режима
+func (r *REPL) restoreTerminal() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rawState != nil {
+		term.Restore(r.fd, r.rawState)
+		r.rawState = nil
+	}
+}
+
+// Close восстанавливает терминал и сохраняет историю. Может вызываться
+// повторно и из обработчика сигналов, пока REPL ждет ввода
+func (r *REPL) Close() {
+	r.restoreTerminal()
+	if r.history != nil {
+		r.history.close()
+	}
+}
+
+// commonPrefix возвращает общий префикс списка строк
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+
+	first, last := sorted[0], sorted[len(sorted)-1]
+	i := 0
+	for i < len(first) && i < len(last) && first[i] == last[i] {
+		i++
+	}
+
+	return first[:i]
+}
+
+// fileHistory реализует term.History с сохранением записей в файл
+type fileHistory struct {
+	mu      sync.Mutex
+	path    string
+	entries []string // От старых к новым
+	limit   int
+	file    *os.File
+}
+
+// newFileHistory создает историю, хранящуюся в указанном файле
+func newFileHistory(path string, limit int) *fileHistory {
+	return &fileHistory{
+		path:  path,
+		limit: limit,
+	}
+}
+
+// load читает историю из файла и открывает его для дозаписи
+func (h *fileHistory) load() error {
+	if h.path == "" {
+		return nil
+	}
+
+	if data, err := os.ReadFile(h.path); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if line != "" {
+				h.entries = append(h.entries, line)
+			}
+		}
+		if len(h.entries) > h.limit {
+			h.entries = h.entries[len(h.entries)-h.limit:]
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	h.file = file
+
+	return nil
+}
+
+// Add добавляет запись в историю и дописывает ее в файл
+func (h *fileHistory) Add(entry string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return
+	}
+
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > h.limit {
+		h.entries = h.entries[1:]
+	}
+
+	if h.file != nil {
+		h.file.WriteString(entry + "\n")
+	}
+}
+
+// Len возвращает количество записей в истории
+func (h *fileHistory) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.entries)
+}
+
+// At возвращает запись по индексу; индекс 0 соответствует самой новой записи
+func (h *fileHistory) At(idx int) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// close перезаписывает файл истории с учетом ограничения и закрывает его
+func (h *fileHistory) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.file == nil {
+		return
+	}
+	h.file.Close()
+	h.file = nil
+
+	data := strings.Join(h.entries, "\n")
+	if data != "" {
+		data += "\n"
+	}
+	os.WriteFile(h.path, []byte(data), 0600)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestREPLCloseTrimsHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	history := newFileHistory(path, 2)
+	if err := history.load(); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []string{"one", "two", "three"} {
+		history.Add(entry)
+	}
+
+	// Close вызывается и обработчиком сигналов, и при выходе из REPL
+	r := &REPL{history: history}
+	r.Close()
+	r.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != "two\nthree\n" {
+		t.Errorf("history file = %q, want the last two entries", got)
+	}
+}
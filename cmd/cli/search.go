@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/search"
+)
+
+// runSearchCommand обрабатывает подкоманду search
+func runSearchCommand(args []string) {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	configPath := flags.String("config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	docType := flags.String("type", "", i18n.T("cli.search_flag_type"))
+	since := flags.String("since", "", i18n.T("cli.search_flag_since"))
+	limit := flags.Int("limit", search.DEFAULT_LIMIT, i18n.T("cli.search_flag_limit"))
+	rebuild := flags.Bool("rebuild", false, i18n.T("cli.search_flag_rebuild"))
+	outputFormat := flags.String("output", OUTPUT_TEXT, i18n.T("cli.flag_output"))
+	addLangFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.search_usage"))
+		flags.PrintDefaults()
+	}
+
+	// Флаги допускаются и до, и после запроса
+	var terms []string
+	for {
+		flags.Parse(args)
+		if flags.NArg() == 0 {
+			break
+		}
+		terms = append(terms, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if err := setOutputFormat(*outputFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	query := strings.Join(terms, " ")
+	if strings.TrimSpace(query) == "" && !*rebuild {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	switch *docType {
+	case "", search.TYPE_SESSION, search.TYPE_THOUGHT, search.TYPE_FEEDBACK:
+	default:
+		fmt.Fprintln(os.Stderr, i18n.T("cli.search_unknown_type", *docType))
+		os.Exit(2)
+	}
+
+	sinceTime, err := search.ParseSince(*since)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	initStorage(*configPath)
+
+	if *rebuild || !searchIndex.Exists() {
+		fmt.Fprintln(textOut, i18n.T("cli.search_rebuilding"))
+		if err := rebuildSearchIndex(); err != nil {
+			if jsonOutput {
+				emitJSON("search", nil, err)
+			} else {
+				fmt.Fprintln(textOut, i18n.T("cli.search_rebuild_failed", i18n.LocalizeError(err)))
+			}
+			os.Exit(1)
+		}
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+
+	results, err := searchIndex.Search(search.Query{
+		Text:  query,
+		Type:  *docType,
+		Since: sinceTime,
+		Limit: *limit,
+	})
+	if jsonOutput {
+		emitJSON("search", results, err)
+	}
+	if err != nil {
+		if !jsonOutput {
+			fmt.Fprintln(textOut, i18n.T("cli.search_failed", i18n.LocalizeError(err)))
+		}
+		os.Exit(1)
+	}
+
+	if !jsonOutput {
+		printSearchResults(results)
+	}
+}
+
+// rebuildSearchIndex перестраивает индекс по всем данным хранилища
+func rebuildSearchIndex() error {
+	collector := feedback.NewCollector(filepath.Join(getHomeDir(), "feedback"))
+	if err := collector.LoadFeedbackFromDisk(); err != nil {
+		logger.Warn("Failed to load feedback for indexing: %v", err)
+	}
+
+	return searchIndex.Rebuild(sessionManager, filepath.Join(getHomeDir(), "thoughts"), collector.GetAllFeedback())
+}
+
+// printSearchResults выводит результаты поиска
+func printSearchResults(results []search.Result) {
+	if len(results) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.search_nothing"))
+		return
+	}
+
+	for i, result := range results {
+		fmt.Fprintf(textOut, "%d. [%s] %s - %s (%.2f)\n", i+1, result.Type, result.Title, formatSessionTime(result.Timestamp), result.Score)
+		fmt.Fprintf(textOut, "   %s\n", result.Snippet)
+	}
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/storage"
+)
+
+// SessionDetails содержит метаинформацию и полный контекст сессии
+type SessionDetails struct {
+	Meta    storage.SessionMeta `json:"meta"`
+	Context *model.Context      `json:"context"`
+}
+
+// runSessionsCommand обрабатывает подкоманду sessions
+func runSessionsCommand(args []string) {
+	flags := flag.NewFlagSet("sessions", flag.ExitOnError)
+	configPath := flags.String("config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	outputFormat := flags.String("output", OUTPUT_TEXT, i18n.T("cli.flag_output"))
+	addLangFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.sessions_usage"))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if err := setOutputFormat(*outputFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	initStorage(*configPath)
+
+	action := "list"
+	if flags.NArg() > 0 {
+		action = flags.Arg(0)
+	}
+
+	var actionArgs []string
+	if flags.NArg() > 1 {
+		actionArgs = flags.Args()[1:]
+	}
+
+	if err := sessionsAction(action, actionArgs); err != nil {
+		if !jsonOutput {
+			fmt.Fprintln(textOut, i18n.T("cli.error", i18n.LocalizeError(err)))
+		}
+		os.Exit(1)
+	}
+}
+
+// sessionsAction выполняет действие над сохраненными сессиями. Используется
+// подкомандой sessions и командой /sessions интерактивного режима
+func sessionsAction(action string, args []string) error {
+	var err error
+
+	switch action {
+	case "list":
+		var sessions []storage.SessionMeta
+		sessions, err = sessionManager.ListSessions()
+		if err == nil && !jsonOutput {
+			printSessionsTable(sessions)
+		}
+		if jsonOutput {
+			emitJSON("sessions", sessions, err)
+		}
+		return err
+
+	case "show":
+		if len(args) < 1 {
+			err = i18n.NewError("cli.usage_sessions_show")
+			break
+		}
+		var details SessionDetails
+		details.Meta, err = sessionManager.GetSessionMeta(args[0])
+		if err == nil {
+			details.Context, err = sessionManager.LoadSession(args[0])
+		}
+		if jsonOutput {
+			emitJSON("session", details, err)
+			return err
+		}
+		if err == nil {
+			printSessionDetails(details)
+		}
+		return err
+
+	case "delete":
+		if len(args) < 1 {
+			err = i18n.NewError("cli.usage_sessions_delete")
+			break
+		}
+		err = sessionManager.DeleteSession(args[0])
+		if err == nil {
+			printMessage(i18n.T("cli.session_deleted", args[0]), nil)
+		}
+
+	case "rename":
+		if len(args) < 2 {
+			err = i18n.NewError("cli.usage_sessions_rename")
+			break
+		}
+		err = sessionManager.RenameSession(args[0], args[1])
+		if err == nil {
+			printMessage(i18n.T("cli.session_renamed", args[0], args[1]), nil)
+		}
+
+	case "fork":
+		if len(args) < 2 {
+			err = i18n.NewError("cli.usage_sessions_fork")
+			break
+		}
+		err = sessionManager.ForkSession(args[0], args[1])
+		if err == nil {
+			printMessage(i18n.T("cli.session_forked", args[0], args[1]), nil)
+		}
+
+	case "export":
+		if len(args) < 1 {
+			err = i18n.NewError("cli.usage_sessions_export")
+			break
+		}
+		// Без файла экспортируем в stdout
+		if len(args) < 2 || args[1] == "-" {
+			return sessionManager.ExportSession(args[0], os.Stdout)
+		}
+		err = exportSessionToFile(args[0], args[1])
+		if err == nil {
+			printMessage(i18n.T("cli.session_exported", args[0], args[1]), nil)
+		}
+
+	case "import":
+		if len(args) < 1 {
+			err = i18n.NewError("cli.usage_sessions_import")
+			break
+		}
+		name := strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+		if len(args) > 1 {
+			name = args[1]
+		}
+		err = importSessionFromFile(args[0], name)
+		if err == nil {
+			printMessage(i18n.T("cli.session_imported", name), nil)
+		}
+
+	case "migrate":
+		migrateFlags := flag.NewFlagSet("sessions migrate", flag.ContinueOnError)
+		migrateFlags.SetOutput(textOut)
+		dryRun := migrateFlags.Bool("dry-run", false, i18n.T("cli.migrate_flag_dry_run"))
+		if err = migrateFlags.Parse(args); err != nil {
+			break
+		}
+
+		var reports []storage.SessionMigrationReport
+		reports, err = sessionManager.MigrateSessions(*dryRun)
+		if jsonOutput {
+			emitJSON("migration", reports, err)
+			return err
+		}
+		if err == nil {
+			printMigrationReports(reports, *dryRun)
+		}
+		return err
+
+	default:
+		err = i18n.NewError("cli.sessions_unknown_action", action)
+	}
+
+	if err != nil && jsonOutput {
+		emitJSON("message", nil, err)
+	}
+	return err
+}
+
+// exportSessionToFile записывает сессию в файл
+func exportSessionToFile(name, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return sessionManager.ExportSession(name, file)
+}
+
+// importSessionFromFile загружает сессию из файла
+func importSessionFromFile(path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return sessionManager.ImportSession(name, file)
+}
+
+// printSessionsTable выводит список сессий в виде таблицы
+func printSessionsTable(sessions []storage.SessionMeta) {
+	if len(sessions) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.sessions_empty"))
+		return
+	}
+
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.T("cli.sessions_header"))
+	for _, session := range sessions {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+			session.Name,
+			session.MessageCount,
+			formatSessionTime(session.CreatedAt),
+			formatSessionTime(session.UpdatedAt))
+	}
+	w.Flush()
+}
+
+// printMigrationReports выводит результаты миграции сессий
+func printMigrationReports(reports []storage.SessionMigrationReport, dryRun bool) {
+	if len(reports) == 0 {
+		fmt.Fprintln(textOut, i18n.T("cli.sessions_empty"))
+		return
+	}
+
+	migrated, failed := 0, 0
+	w := tabwriter.NewWriter(textOut, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, i18n.T("cli.migrate_header"))
+	for _, report := range reports {
+		status := i18n.T("cli.migrate_status_current")
+		switch {
+		case report.Error != "":
+			status = i18n.T("cli.migrate_status_error", i18n.LocalizeError(report.Err()))
+			failed++
+		case report.Migrated && dryRun:
+			status = i18n.T("cli.migrate_status_pending")
+			migrated++
+		case report.Migrated:
+			status = i18n.T("cli.migrate_status_done", report.Backup)
+			migrated++
+		}
+		fmt.Fprintf(w, "%s\t%d -> %d\t%s\n", report.Name, report.FromVersion, report.ToVersion, status)
+	}
+	w.Flush()
+
+	if dryRun {
+		fmt.Fprintln(textOut, "\n"+i18n.T("cli.migrate_dry_run_summary", migrated, failed))
+	} else {
+		fmt.Fprintln(textOut, "\n"+i18n.T("cli.migrate_summary", migrated, failed))
+	}
+}
+
+// printSessionDetails выводит метаинформацию и сообщения сессии
+func printSessionDetails(details SessionDetails) {
+	fmt.Fprintln(textOut, i18n.T("cli.session_detail_name", details.Meta.Name))
+	fmt.Fprintln(textOut, i18n.T("cli.session_detail_id", details.Context.SessionID))
+	fmt.Fprintln(textOut, i18n.T("cli.session_detail_format", details.Meta.SchemaVersion))
+	fmt.Fprintln(textOut, i18n.T("cli.session_detail_messages", details.Meta.MessageCount))
+	fmt.Fprintln(textOut, i18n.T("cli.session_detail_created", formatSessionTime(details.Meta.CreatedAt)))
+	fmt.Fprintln(textOut, i18n.T("cli.session_detail_updated", formatSessionTime(details.Meta.UpdatedAt)))
+
+	if branches := details.Context.Branches(); len(branches) > 1 {
+		fmt.Fprintln(textOut, i18n.T("cli.session_detail_branches", len(branches)))
+	}
+
+	for _, msg := range details.Context.ActiveBranch() {
+		fmt.Fprintf(textOut, "\n[%s] %s:\n%s\n", formatSessionTime(msg.Timestamp), msg.Role, msg.Content)
+	}
+}
+
+// formatSessionTime форматирует время для табличного вывода
+func formatSessionTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// sessionNames возвращает имена сохраненных сессий для автодополнения
+func sessionNames() []string {
+	sessions, err := sessionManager.ListSessions()
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		names = append(names, session.Name)
+	}
+	return names
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// ThoughtReplayReport содержит результат повтора размышления
+type ThoughtReplayReport struct {
+	Original  string              `json:"original"` // Запись исходного размышления
+	File      string              `json:"file"`     // Журнал повтора
+	Backend   string              `json:"backend"`
+	Seed      int                 `json:"seed"`
+	Identical bool                `json:"identical"`
+	Steps     []ThoughtStepReport `json:"steps"`
+}
+
+// ThoughtStepReport сравнивает одну мысль исходного размышления и повтора
+type ThoughtStepReport struct {
+	Index    int    `json:"index"`
+	Equal    bool   `json:"equal"`
+	Original string `json:"original"`
+	Replay   string `json:"replay"`
+}
+
+// runThoughtsCommand обрабатывает подкоманду thoughts
+func runThoughtsCommand(args []string) {
+	flags := flag.NewFlagSet("thoughts", flag.ExitOnError)
+	configPath := flags.String("config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	outputFormat := flags.String("output", OUTPUT_TEXT, i18n.T("cli.flag_output"))
+	addLangFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, i18n.T("cli.thoughts_usage"))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if err := setOutputFormat(*outputFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if flags.NArg() != 2 || flags.Arg(0) != "replay" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	initComponents(*configPath)
+	defer modelInstance.Close()
+
+	identical, err := replayThoughts(flags.Arg(1))
+	if err != nil {
+		if !jsonOutput {
+			fmt.Fprintln(textOut, i18n.T("cli.error", i18n.LocalizeError(err)))
+		}
+		os.Exit(1)
+	}
+	if !identical {
+		os.Exit(1)
+	}
+}
+
+// replayThoughts повторяет размышление из записи path и сравнивает мысли
+// с исходными. Возвращает true, если все мысли совпали
+func replayThoughts(path string) (bool, error) {
+	original, err := model.LoadThoughtRun(path)
+	if err != nil {
+		if jsonOutput {
+			emitJSON("thought_replay", nil, err)
+		}
+		return false, err
+	}
+
+	// Повтор воспроизводит мысли только тем же способом генерации
+	if backend := modelInstance.Backend(); backend != original.Backend {
+		logger.Warn("Thought run used backend %s, replaying with %s", original.Backend, backend)
+		if !jsonOutput {
+			fmt.Fprintln(textOut, i18n.T("cli.thoughts_backend", original.Backend, backend))
+		}
+	}
+
+	thoughtFile := newThoughtFile("replay")
+	if !jsonOutput {
+		fmt.Fprintln(textOut, i18n.T("cli.thoughts_replaying", len(original.Thoughts), original.Seed))
+	}
+
+	replay, err := modelInstance.ReplayThoughts(original, thoughtFile)
+	if err != nil {
+		if jsonOutput {
+			emitJSON("thought_replay", nil, err)
+		}
+		return false, err
+	}
+
+	report := ThoughtReplayReport{
+		Original:  model.ThoughtRecordPath(path),
+		File:      thoughtFile,
+		Backend:   replay.Backend,
+		Seed:      replay.Seed,
+		Identical: true,
+	}
+	for i, text := range original.Thoughts {
+		step := ThoughtStepReport{Index: i + 1, Original: text}
+		if i < len(replay.Thoughts) {
+			step.Replay = replay.Thoughts[i]
+		}
+		step.Equal = step.Original == step.Replay
+		report.Identical = report.Identical && step.Equal
+		report.Steps = append(report.Steps, step)
+	}
+
+	if jsonOutput {
+		emitJSON("thought_replay", report, nil)
+	} else {
+		printThoughtDiff(report)
+	}
+	return report.Identical, nil
+}
+
+// printThoughtDiff выводит различия мыслей повтора и исходного размышления
+func printThoughtDiff(report ThoughtReplayReport) {
+	for _, step := range report.Steps {
+		if step.Equal {
+			fmt.Fprintln(textOut, i18n.T("cli.thoughts_step_equal", step.Index))
+			continue
+		}
+
+		fmt.Fprintln(textOut, i18n.T("cli.thoughts_step_differs", step.Index))
+		for _, line := range strings.Split(step.Original, "\n") {
+			fmt.Fprintln(textOut, "- "+line)
+		}
+		for _, line := range strings.Split(step.Replay, "\n") {
+			fmt.Fprintln(textOut, "+ "+line)
+		}
+	}
+
+	fmt.Fprintln(textOut, i18n.T("cli.thought_saved", report.File))
+	if report.Identical {
+		fmt.Fprintln(textOut, i18n.T("cli.thoughts_identical"))
+	} else {
+		fmt.Fprintln(textOut, i18n.T("cli.thoughts_different"))
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// Причины завершения генерации в ответе
+const (
+	FINISH_STOP   = "stop"   // Модель закончила ответ или встретила стоп-строку
+	FINISH_LENGTH = "length" // Достигнуто ограничение max_tokens
+)
+
+// ChatCompletionRequest - запрос /v1/chat/completions. Кроме полей API
+// OpenAI принимаются top_k, min_p и repetition_penalty
+type ChatCompletionRequest struct {
+	Model               string         `json:"model"` // Любое значение: сервер обслуживает одну модель
+	Messages            []ChatMessage  `json:"messages"`
+	Stream              bool           `json:"stream"`
+	StreamOptions       *StreamOptions `json:"stream_options"`
+	Temperature         *float64       `json:"temperature"` // 0 - жадный выбор токена
+	TopP                *float64       `json:"top_p"`
+	TopK                *int           `json:"top_k"`
+	MinP                *float64       `json:"min_p"`
+	RepetitionPenalty   *float64       `json:"repetition_penalty"`
+	FrequencyPenalty    *float64       `json:"frequency_penalty"`
+	PresencePenalty     *float64       `json:"presence_penalty"`
+	MaxTokens           *int           `json:"max_tokens"`
+	MaxCompletionTokens *int           `json:"max_completion_tokens"`
+	Stop                StopList       `json:"stop"` // Дополняет стоп-строки модели
+	Seed                *int           `json:"seed"`
+	User                string         `json:"user"`
+	N                   int            `json:"n"`
+}
+
+// StreamOptions содержит настройки потоковой выдачи
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // Добавить статистику токенов в последний фрагмент
+}
+
+// ChatMessage - сообщение диалога
+type ChatMessage struct {
+	Role    string         `json:"role"`
+	Content MessageContent `json:"content"`
+}
+
+// MessageContent - текст сообщения: строка или список текстовых частей
+type MessageContent string
+
+// UnmarshalJSON разбирает текст сообщения в любом из форматов API
+func (c *MessageContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = MessageContent(text)
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != "text" {
+			return i18n.NewError("server.unsupported_content", part.Type)
+		}
+		texts = append(texts, part.Text)
+	}
+	*c = MessageContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+// StopList - стоп-строки: одна строка или список
+type StopList []string
+
+// UnmarshalJSON разбирает стоп-строки в любом из форматов API
+func (s *StopList) UnmarshalJSON(data []byte) error {
+	var stop string
+	if err := json.Unmarshal(data, &stop); err == nil {
+		*s = StopList{stop}
+		return nil
+	}
+
+	var stops []string
+	if err := json.Unmarshal(data, &stops); err != nil {
+		return err
+	}
+	*s = stops
+	return nil
+}
+
+// ChatCompletion - ответ /v1/chat/completions
+type ChatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   *Usage       `json:"usage,omitempty"`
+}
+
+// ChatChoice - вариант ответа
+type ChatChoice struct {
+	Index        int             `json:"index"`
+	Message      *ChatReply      `json:"message,omitempty"`
+	Delta        *ChatReply      `json:"delta,omitempty"` // Фрагмент ответа при потоковой выдаче
+	FinishReason *string         `json:"finish_reason"`
+	Citations    []model.Passage `json:"citations,omitempty"` // Фрагменты документов, добавленные в контекст
+}
+
+// ChatReply - сообщение ассистента или его фрагмент
+type ChatReply struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+// Usage содержит статистику токенов запроса
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ModelList - ответ /v1/models
+type ModelList struct {
+	Object string      `json:"object"`
+	Data   []ModelInfo `json:"data"`
+}
+
+// ModelInfo описывает модель
+type ModelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// handleModels возвращает единственную модель сервера
+func handleModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, ModelList{
+		Object: "list",
+		Data: []ModelInfo{{
+			ID:      cfg.Model.Name,
+			Object:  "model",
+			Created: logger.GetMetrics().StartTime.Unix(),
+			OwnedBy: "smollm-sandbox",
+		}},
+	})
+}
+
+// handleChatCompletions генерирует ответ на диалог из запроса. Отключение
+// клиента отменяет запрос, в том числе пока он ждет в очереди
+func handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var request ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, ERROR_INVALID_REQUEST, i18n.T("server.invalid_json", i18n.LocalizeError(err)))
+		return
+	}
+	if request.N > 1 {
+		writeError(w, http.StatusBadRequest, ERROR_INVALID_REQUEST, i18n.T("server.n_unsupported"))
+		return
+	}
+
+	generation, err := request.generation()
+	if err != nil {
+		writeModelError(w, err)
+		return
+	}
+
+	// Стоп-строки модели отделяют реплики диалога, поэтому стоп-строки
+	// запроса добавляются к стоп-строкам профиля, а не заменяют их
+	opts := model.ProcessOptions{
+		Context:    r.Context(),
+		Owner:      requestOwner(r, request.User),
+		Generation: generation,
+		Stop:       request.Stop,
+	}
+	completion := ChatCompletion{
+		ID:      newCompletionID(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   cfg.Model.Name,
+	}
+
+	if request.Stream {
+		streamCompletion(w, r, request, opts, completion)
+		return
+	}
+
+	result, err := modelInstance.Complete(request.messages(), opts)
+	if err != nil {
+		if r.Context().Err() != nil {
+			logger.Info("Client disconnected before completion %s", completion.ID)
+			return
+		}
+		writeModelError(w, err)
+		return
+	}
+
+	finish := finishReason(result)
+	completion.Choices = []ChatChoice{{
+		Message:      &ChatReply{Role: "assistant", Content: result.Text},
+		FinishReason: &finish,
+		Citations:    result.Citations,
+	}}
+	completion.Usage = usage(result)
+	writeJSON(w, http.StatusOK, completion)
+}
+
+// streamCompletion генерирует ответ с потоковой выдачей фрагментов в формате
+// server-sent events. Пока запрос ждет в очереди, клиент получает
+// комментарии с его позицией
+func streamCompletion(w http.ResponseWriter, r *http.Request, request ChatCompletionRequest, opts model.ProcessOptions, completion ChatCompletion) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, ERROR_SERVER, i18n.T("server.streaming_unsupported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	completion.Object = "chat.completion.chunk"
+	send := func(choice ChatChoice, usage *Usage) {
+		chunk := completion
+		chunk.Choices = []ChatChoice{choice}
+		chunk.Usage = usage
+
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			logger.Error("Failed to encode completion chunk: %v", err)
+			return
+		}
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	send(ChatChoice{Delta: &ChatReply{Role: "assistant"}}, nil)
+
+	opts.OnQueued = func(position int) {
+		fmt.Fprintf(w, ": queued %d\n\n", position)
+		flusher.Flush()
+	}
+	opts.OnText = func(delta string) {
+		send(ChatChoice{Delta: &ChatReply{Content: delta}}, nil)
+	}
+
+	result, err := modelInstance.Complete(request.messages(), opts)
+	if err != nil {
+		if r.Context().Err() != nil {
+			logger.Info("Client disconnected during completion %s", completion.ID)
+			return
+		}
+		_, kind := errorStatus(err)
+		data, _ := json.Marshal(ErrorResponse{Error: newAPIError(err, kind)})
+		fmt.Fprintf(w, "data: %s\n\ndata: [DONE]\n\n", data)
+		flusher.Flush()
+		return
+	}
+
+	var stats *Usage
+	if request.StreamOptions != nil && request.StreamOptions.IncludeUsage {
+		stats = usage(result)
+	}
+	finish := finishReason(result)
+	send(ChatChoice{Delta: &ChatReply{}, FinishReason: &finish, Citations: result.Citations}, stats)
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+// messages преобразует сообщения запроса в сообщения модели. Роль
+// developer новых клиентов OpenAI равнозначна system
+func (request ChatCompletionRequest) messages() []model.Message {
+	messages := make([]model.Message, 0, len(request.Messages))
+	for _, msg := range request.Messages {
+		role := msg.Role
+		if role == "developer" {
+			role = "system"
+		}
+		messages = append(messages, model.Message{Role: role, Content: string(msg.Content), Timestamp: time.Now()})
+	}
+	return messages
+}
+
+// generation возвращает параметры генерации запроса поверх параметров модели
+func (request ChatCompletionRequest) generation() (model.GenerationOptions, error) {
+	opts := model.GenerationOptions{
+		Temperature:       request.Temperature,
+		TopP:              request.TopP,
+		TopK:              request.TopK,
+		MinP:              request.MinP,
+		RepetitionPenalty: request.RepetitionPenalty,
+		FrequencyPenalty:  request.FrequencyPenalty,
+		PresencePenalty:   request.PresencePenalty,
+		MaxTokens:         request.MaxTokens,
+		Seed:              request.Seed,
+	}
+	if request.MaxCompletionTokens != nil {
+		opts.MaxTokens = request.MaxCompletionTokens
+	}
+
+	return opts, opts.Validate()
+}
+
+// finishReason возвращает причину завершения генерации
+func finishReason(result *model.ProcessResult) string {
+	if limit := model.ValueOf(result.Generation.MaxTokens); limit > 0 && result.TokensUsed-result.PromptTokens >= limit {
+		return FINISH_LENGTH
+	}
+	return FINISH_STOP
+}
+
+// usage возвращает статистику токенов результата
+func usage(result *model.ProcessResult) *Usage {
+	return &Usage{
+		PromptTokens:     result.PromptTokens,
+		CompletionTokens: result.TokensUsed - result.PromptTokens,
+		TotalTokens:      result.TokensUsed,
+	}
+}
+
+// newCompletionID возвращает идентификатор ответа
+func newCompletionID() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return "chatcmpl-" + hex.EncodeToString(buf)
+}
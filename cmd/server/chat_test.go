@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"smollm-sandbox/internal/config"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/model/modeltest"
+	"smollm-sandbox/internal/sandbox"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	logger = logging.NewLogger()
+	cfg = config.Default()
+	os.Exit(m.Run())
+}
+
+// newTestAPI запускает API сервера с моделью на поддельном сервере модели
+func newTestAPI(t *testing.T, keys ...string) (*httptest.Server, *modeltest.Server) {
+	t.Helper()
+	m, backend := modeltest.NewModel(t)
+	modelInstance = m
+	apiKeys = keys
+	t.Cleanup(func() {
+		modelInstance = nil
+		apiKeys = nil
+	})
+
+	api := httptest.NewServer(newRouter())
+	t.Cleanup(api.Close)
+	return api, backend
+}
+
+// postChat отправляет запрос /v1/chat/completions
+func postChat(t *testing.T, api *httptest.Server, body string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(api.URL+"/v1/chat/completions", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// readEvents читает события server-sent events до [DONE]. Комментарии
+// возвращаются с префиксом ":"
+func readEvents(t *testing.T, body io.Reader) []string {
+	t.Helper()
+	var events []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			continue
+		case line == "data: [DONE]":
+			return events
+		case strings.HasPrefix(line, "data: "):
+			events = append(events, strings.TrimPrefix(line, "data: "))
+		case strings.HasPrefix(line, ":"):
+			events = append(events, line)
+		default:
+			t.Fatalf("unexpected line %q", line)
+		}
+	}
+	t.Fatalf("stream ended without [DONE]: %v", scanner.Err())
+	return nil
+}
+
+func TestChatCompletionsStream(t *testing.T) {
+	api, backend := newTestAPI(t)
+
+	// Стоп-строки текущей сессии модели не попадают в запросы API
+	if err := modelInstance.SetGenerationOption(model.GEN_STOP, []string{"SESSION"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := postChat(t, api, `{"model":"any","stream":true,"stream_options":{"include_usage":true},
+		"temperature":0,"stop":"END","messages":[{"role":"user","content":"Hi"}]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	var chunks []ChatCompletion
+	for _, event := range readEvents(t, resp.Body) {
+		if strings.HasPrefix(event, ":") {
+			continue
+		}
+		var chunk ChatCompletion
+		if err := json.Unmarshal([]byte(event), &chunk); err != nil {
+			t.Fatalf("chunk %q: %v", event, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want role, content and finish", len(chunks))
+	}
+
+	for _, chunk := range chunks {
+		if chunk.Object != "chat.completion.chunk" || chunk.ID != chunks[0].ID || len(chunk.Choices) != 1 {
+			t.Errorf("chunk = %+v", chunk)
+		}
+	}
+	if delta := chunks[0].Choices[0].Delta; delta == nil || delta.Role != "assistant" {
+		t.Errorf("first delta = %+v, want assistant role", delta)
+	}
+	if delta := chunks[1].Choices[0].Delta; delta == nil || delta.Content != modeltest.REPLY {
+		t.Errorf("content delta = %+v", delta)
+	}
+
+	last := chunks[2]
+	if finish := last.Choices[0].FinishReason; finish == nil || *finish != FINISH_STOP {
+		t.Errorf("finish_reason = %v", finish)
+	}
+	wantUsage := &Usage{modeltest.PROMPT_TOKENS, modeltest.REPLY_TOKENS, modeltest.PROMPT_TOKENS + modeltest.REPLY_TOKENS}
+	if !reflect.DeepEqual(last.Usage, wantUsage) {
+		t.Errorf("usage = %+v, want %+v", last.Usage, wantUsage)
+	}
+
+	requests := backend.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("model got %d requests", len(requests))
+	}
+	if requests[0].Temperature != 0 || !strings.Contains(requests[0].Prompt, "Hi") {
+		t.Errorf("model request = %+v", requests[0])
+	}
+	if stop := requests[0].StopTokens; len(stop) < 2 || stop[len(stop)-1] != "END" || slices.Contains(stop, "SESSION") {
+		t.Errorf("stop tokens = %q, want model stops plus END", stop)
+	}
+}
+
+func TestChatCompletionsStreamError(t *testing.T) {
+	api, backend := newTestAPI(t)
+	backend.SetReply(func(model.InferenceRequest) (*model.InferenceResponse, int) {
+		return nil, http.StatusServiceUnavailable
+	})
+
+	resp := postChat(t, api, `{"stream":true,"messages":[{"role":"user","content":"Hi"}]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	events := readEvents(t, resp.Body)
+	var failure ErrorResponse
+	if err := json.Unmarshal([]byte(events[len(events)-1]), &failure); err != nil {
+		t.Fatal(err)
+	}
+	if failure.Error.Type != ERROR_UNAVAILABLE || failure.Error.Code != "model.api_status" {
+		t.Errorf("error = %+v", failure.Error)
+	}
+}
+
+func TestChatCompletions(t *testing.T) {
+	api, _ := newTestAPI(t)
+
+	resp := postChat(t, api, `{"max_tokens":5,"messages":[{"role":"system","content":"Be brief"},
+		{"role":"user","content":[{"type":"text","text":"Hi"}]}]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+
+	var completion ChatCompletion
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		t.Fatal(err)
+	}
+	if len(completion.Choices) != 1 || completion.Choices[0].Message.Content != modeltest.REPLY {
+		t.Fatalf("completion = %+v", completion)
+	}
+	// Ответ занял все max_tokens
+	if finish := completion.Choices[0].FinishReason; finish == nil || *finish != FINISH_LENGTH {
+		t.Errorf("finish_reason = %v, want %s", finish, FINISH_LENGTH)
+	}
+}
+
+func TestChatCompletionsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []string
+		auth   string
+		body   string
+		status int
+		kind   string
+	}{
+		{"invalid json", nil, "", `{"messages":`, http.StatusBadRequest, ERROR_INVALID_REQUEST},
+		{"several choices", nil, "", `{"n":2,"messages":[{"role":"user","content":"Hi"}]}`, http.StatusBadRequest, ERROR_INVALID_REQUEST},
+		{"invalid generation", nil, "", `{"temperature":5,"messages":[{"role":"user","content":"Hi"}]}`, http.StatusBadRequest, ERROR_INVALID_REQUEST},
+		{"no user message", nil, "", `{"messages":[{"role":"system","content":"Hi"}]}`, http.StatusBadRequest, ERROR_INVALID_REQUEST},
+		{"missing key", []string{"secret"}, "", `{"messages":[{"role":"user","content":"Hi"}]}`, http.StatusUnauthorized, ERROR_AUTHENTICATION},
+		{"wrong key", []string{"secret"}, "Bearer other", `{"messages":[{"role":"user","content":"Hi"}]}`, http.StatusUnauthorized, ERROR_AUTHENTICATION},
+		{"valid key", []string{"secret"}, "Bearer secret", `{"messages":[{"role":"user","content":"Hi"}]}`, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, _ := newTestAPI(t, tt.keys...)
+
+			req, err := http.NewRequest(http.MethodPost, api.URL+"/v1/chat/completions", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.kind == "" {
+				return
+			}
+			var failure ErrorResponse
+			if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil {
+				t.Fatal(err)
+			}
+			if failure.Error.Type != tt.kind || failure.Error.Message == "" {
+				t.Errorf("error = %+v, want type %s", failure.Error, tt.kind)
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		kind   string
+	}{
+		{i18n.NewError("model.complete_no_user").WithKind(model.ErrInvalidMessages), http.StatusBadRequest, ERROR_INVALID_REQUEST},
+		{model.ErrQueueFull, http.StatusTooManyRequests, ERROR_RATE_LIMIT},
+		{&model.APIError{StatusCode: http.StatusBadGateway}, http.StatusServiceUnavailable, ERROR_UNAVAILABLE},
+		{&model.APIError{StatusCode: http.StatusInternalServerError}, http.StatusInternalServerError, ERROR_SERVER},
+		{fmt.Errorf("queue: %w", model.ErrCanceled), STATUS_CLIENT_CLOSED, ERROR_CANCELED},
+		{context.Canceled, STATUS_CLIENT_CLOSED, ERROR_CANCELED},
+		{sandbox.ErrFileNotFound, http.StatusNotFound, ERROR_NOT_FOUND},
+		{sandbox.ErrUnsupportedFileType, http.StatusUnsupportedMediaType, ERROR_INVALID_REQUEST},
+		{errors.New("unknown"), http.StatusInternalServerError, ERROR_SERVER},
+	}
+
+	for _, tt := range tests {
+		if status, kind := errorStatus(tt.err); status != tt.status || kind != tt.kind {
+			t.Errorf("errorStatus(%v) = %d, %s; want %d, %s", tt.err, status, kind, tt.status, tt.kind)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+)
+
+// Типы ошибок в ответах API, как в API OpenAI
+const (
+	ERROR_INVALID_REQUEST = "invalid_request_error"
+	ERROR_AUTHENTICATION  = "authentication_error"
+	ERROR_NOT_FOUND       = "not_found_error"
+	ERROR_RATE_LIMIT      = "rate_limit_error"
+	ERROR_CANCELED        = "request_canceled"
+	ERROR_TIMEOUT         = "timeout_error"
+	ERROR_UNAVAILABLE     = "service_unavailable"
+	ERROR_SERVER          = "server_error"
+)
+
+// STATUS_CLIENT_CLOSED - код статуса запроса, отмененного клиентом (как в nginx)
+const STATUS_CLIENT_CLOSED = 499
+
+// ErrorResponse - ответ API с ошибкой
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// APIError описывает ошибку запроса
+type APIError struct {
+	Message string `json:"message"`        // Текст ошибки на языке сервера
+	Type    string `json:"type"`           // Один из ERROR_*
+	Code    string `json:"code,omitempty"` // Код сообщения ошибки (например, model.queue_full)
+}
+
+// writeError отправляет ответ с ошибкой
+func writeError(w http.ResponseWriter, status int, kind, message string) {
+	writeJSON(w, status, ErrorResponse{Error: APIError{Message: message, Type: kind}})
+}
+
+// writeModelError отправляет ответ с ошибкой модели или песочницы,
+// выбирая код статуса по виду ошибки
+func writeModelError(w http.ResponseWriter, err error) {
+	status, kind := errorStatus(err)
+	writeJSON(w, status, ErrorResponse{Error: newAPIError(err, kind)})
+}
+
+// newAPIError описывает ошибку err для ответа API
+func newAPIError(err error, kind string) APIError {
+	return APIError{
+		Message: i18n.LocalizeError(err),
+		Type:    kind,
+		Code:    i18n.Code(err),
+	}
+}
+
+// errorStatus возвращает код статуса HTTP и тип ошибки API для err
+func errorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, model.ErrInvalidMessages),
+		errors.Is(err, model.ErrInvalidGeneration),
+		errors.Is(err, sandbox.ErrUnsupportedLanguage):
+		return http.StatusBadRequest, ERROR_INVALID_REQUEST
+	case errors.Is(err, sandbox.ErrUnsupportedFileType):
+		return http.StatusUnsupportedMediaType, ERROR_INVALID_REQUEST
+	case errors.Is(err, sandbox.ErrFileNotFound):
+		return http.StatusNotFound, ERROR_NOT_FOUND
+	case errors.Is(err, model.ErrGenerationTimeout):
+		return http.StatusGatewayTimeout, ERROR_TIMEOUT
+	case errors.Is(err, model.ErrCanceled), errors.Is(err, context.Canceled):
+		return STATUS_CLIENT_CLOSED, ERROR_CANCELED
+	case errors.Is(err, model.ErrQueueFull):
+		return http.StatusTooManyRequests, ERROR_RATE_LIMIT
+	case errors.Is(err, model.ErrModelUnavailable):
+		return http.StatusServiceUnavailable, ERROR_UNAVAILABLE
+	}
+	return http.StatusInternalServerError, ERROR_SERVER
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"smollm-sandbox/internal/config"
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/rag"
+	"smollm-sandbox/internal/rpc"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	VERSION = "0.1.0"
+
+	// Переменная окружения с ключом доступа в дополнение к server.api_keys
+	API_KEY_ENV = "SMOLLM_API_KEY"
+
+	// Максимальный размер тела запроса в байтах
+	MAX_REQUEST_SIZE = 1 << 20
+
+	// Время на завершение активных запросов при остановке сервера
+	SHUTDOWN_TIMEOUT = 10 * time.Second
+)
+
+var (
+	logger         *logging.Logger
+	modelInstance  *model.SmolLM
+	sandboxEnv     *sandbox.Environment
+	store          *storage.FileSystem
+	sessionManager *storage.SessionManager
+	collector      *feedback.Collector
+	cfg            *config.Config
+	configPath     string
+	addr           string
+	grpcAddr       string
+	apiKeys        []string
+)
+
+func main() {
+	// Определение флагов
+	flag.StringVar(&configPath, "config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	flag.StringVar(&addr, "addr", "", i18n.T("server.flag_addr"))
+	flag.StringVar(&grpcAddr, "grpc-addr", "", i18n.T("server.flag_grpc_addr"))
+	flag.Parse()
+
+	// Загрузка конфигурации
+	var cfgErr error
+	cfg, cfgErr = config.Load(configPath)
+	i18n.SetLanguage(cfg.Language)
+
+	// Адрес и ключи из конфигурации, если не заданы флагом и окружением
+	if addr == "" {
+		addr = cfg.Server.Addr
+	}
+	if grpcAddr == "" {
+		grpcAddr = cfg.Server.GRPCAddr
+	}
+	apiKeys = append(apiKeys, cfg.Server.APIKeys...)
+	if key := os.Getenv(API_KEY_ENV); key != "" {
+		apiKeys = append(apiKeys, key)
+	}
+
+	// Без ключей сервер, а с ним и песочница, доступен только локально
+	for _, listenAddr := range []string{addr, grpcAddr} {
+		if listenAddr != "" && len(apiKeys) == 0 && !isLoopback(listenAddr) {
+			log.Fatal(i18n.T("server.no_api_key", listenAddr, API_KEY_ENV))
+		}
+	}
+
+	// Инициализация логирования
+	logging.SetDefaultLogConfig(logging.LogConfig{
+		Level:         logging.ParseLevel(cfg.Logging.Level),
+		EnableFile:    cfg.Logging.File != "",
+		FilePath:      config.ExpandPath(cfg.Logging.File),
+		EnableConsole: cfg.Logging.Console,
+		Console:       os.Stdout,
+	})
+	logger = logging.NewLogger()
+	logger.Info("Starting SmolLM API server")
+
+	logger.Info("Loading configuration from %s", configPath)
+	if cfgErr != nil {
+		logger.Warn("Using default configuration: %v", cfgErr)
+	}
+	if len(apiKeys) == 0 {
+		logger.Warn("No API keys configured, accepting unauthenticated requests on %s", addr)
+	}
+
+	// Инициализация хранилища. Сессии и обратная связь нужны только gRPC сервису
+	store = storage.NewFileSystem(getHomeDir())
+	sessionManager = storage.NewSessionManager(store, cfg.Storage.SessionsDir)
+	collector = feedback.NewCollector(filepath.Join(getHomeDir(), "feedback"))
+
+	// Инициализация модели. Сервер не хранит диалоги: историю присылает клиент
+	logger.Info("Initializing SmolLM2 model")
+	modelInstance = model.NewSmolLMWithOptions(model.Options{
+		Path:         config.ExpandPath(cfg.Model.Path),
+		Backend:      cfg.Model.Backend,
+		Generation:   cfg.Model.Parameters,
+		ThinkingSeed: cfg.Model.Thinking.Seed,
+		Timeout:      time.Duration(cfg.Model.Timeout) * time.Second,
+		Parallel:     cfg.Model.Queue.Parallel,
+		QueueSize:    cfg.Model.Queue.MaxSize,
+	})
+	defer modelInstance.Close()
+	initResponseCache()
+
+	// Профили (персоны) модели
+	profiles, err := cfg.Profiles.LoadProfiles()
+	if err != nil {
+		logger.Warn("Failed to load profiles: %v", err)
+	}
+	if err := modelInstance.SetProfiles(profiles); err != nil {
+		logger.Error("Invalid profile configuration: %v", err)
+	} else if cfg.Profiles.Default != "" {
+		if err := modelInstance.UseProfile(cfg.Profiles.Default); err != nil {
+			logger.Warn("Default profile is unavailable: %v", err)
+		}
+	}
+
+	// Подключение поиска по папке документов. Индексация выполняется в фоне,
+	// до ее завершения используется ранее построенный индекс
+	if cfg.RAG.Enabled {
+		ragIndex := rag.NewIndex(store, cfg.RAG)
+		modelInstance.SetRetriever(ragIndex)
+		go func() {
+			if _, err := ragIndex.Ingest(context.Background()); err != nil {
+				logger.Error("Failed to ingest documents: %v", err)
+			}
+		}()
+	}
+
+	// Инициализация песочницы
+	if cfg.Server.Sandbox {
+		logger.Info("Setting up sandbox environment")
+		sandboxEnv = sandbox.NewEnvironment()
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           newRouter(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// gRPC сервис на отдельном адресе
+	var grpcServer *grpc.Server
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			logger.Error("Failed to listen on %s: %v", grpcAddr, err)
+			return
+		}
+
+		service, err := rpc.NewServer(rpc.Options{
+			Model:    modelInstance,
+			Sandbox:  sandboxEnv,
+			Sessions: sessionManager,
+			Feedback: collector,
+			APIKeys:  apiKeys,
+		})
+		if err != nil {
+			listener.Close()
+			logger.Error("Failed to create gRPC service: %s", i18n.LocalizeError(err))
+			return
+		}
+		grpcServer = service.NewGRPCServer()
+
+		logger.Info("gRPC service listening on %s", grpcAddr)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Error("gRPC service failed: %v", err)
+			}
+		}()
+	}
+
+	// Остановка по сигналу дожидается активных запросов
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutting down API server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+		defer cancel()
+		if grpcServer != nil {
+			stopGRPC(shutdownCtx, grpcServer)
+		}
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Listening on %s", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("API server failed: %v", err)
+	}
+	logger.Info("SmolLM API server finished")
+}
+
+// stopGRPC останавливает gRPC сервис, дожидаясь активных вызовов не дольше,
+// чем до отмены ctx
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
+
+// newRouter возвращает обработчик всех эндпоинтов API с проверкой ключа
+func newRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/models", handleModels)
+	mux.HandleFunc("POST /v1/chat/completions", handleChatCompletions)
+	if sandboxEnv != nil {
+		mux.HandleFunc("GET /v1/sandbox/languages", handleSandboxLanguages)
+		mux.HandleFunc("POST /v1/sandbox/execute", handleSandboxExecute)
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, ERROR_NOT_FOUND, i18n.T("server.not_found", r.Method, r.URL.Path))
+	})
+
+	return authenticate(mux)
+}
+
+// authenticate пропускает запросы с ключом из заголовка Authorization:
+// Bearer <ключ>. Без настроенных ключей проверка отключена
+func authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(apiKeys) > 0 && apiKeyIndex(r) < 0 {
+			logger.Warn("Unauthorized request from %s to %s", r.RemoteAddr, r.URL.Path)
+			writeError(w, http.StatusUnauthorized, ERROR_AUTHENTICATION, i18n.T("server.unauthorized"))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, MAX_REQUEST_SIZE)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiKeyIndex возвращает номер ключа запроса в apiKeys или -1
+func apiKeyIndex(r *http.Request) int {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return -1
+	}
+
+	for i, candidate := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			return i
+		}
+	}
+	return -1
+}
+
+// requestOwner возвращает пользователя запроса для очереди генерации:
+// номер ключа доступа и поле user запроса, если клиент его передал
+func requestOwner(r *http.Request, user string) string {
+	owner := "local"
+	if index := apiKeyIndex(r); index >= 0 {
+		owner = fmt.Sprintf("key%d", index+1)
+	}
+	if user != "" {
+		owner += ":" + user
+	}
+	return owner
+}
+
+// isLoopback проверяет, что сервер слушает только локальный адрес
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// initResponseCache подключает кэш ответов модели во временной директории хранилища
+func initResponseCache() {
+	if !cfg.Model.Cache.Enabled {
+		return
+	}
+
+	cache, err := storage.NewResponseCache(store, cfg.Model.Cache.MaxSize)
+	if err != nil {
+		logger.Warn("Response cache is unavailable: %v", err)
+		return
+	}
+	modelInstance.SetResponseCache(cache)
+}
+
+// getHomeDir возвращает домашнюю директорию
+func getHomeDir() string {
+	// Корневая директория хранилища из конфигурации
+	if cfg != nil && cfg.Storage.RootDir != "" {
+		return config.ExpandPath(cfg.Storage.RootDir)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		logger.Error("Failed to determine home directory: %v", err)
+		return "/tmp/smollm-sandbox"
+	}
+	return filepath.Join(homeDir, ".smollm-sandbox")
+}
+
+// writeJSON отправляет ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.Warn("Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Песочница использует общую рабочую директорию, поэтому запросы
+// выполнения кода обрабатываются по одному
+var sandboxMutex sync.Mutex
+
+// ExecuteRequest - запрос /v1/sandbox/execute
+type ExecuteRequest struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// ExecuteReport - результат выполнения кода в песочнице
+type ExecuteReport struct {
+	Success       bool   `json:"success"`
+	ExitCode      int    `json:"exit_code"`
+	Output        string `json:"output"`
+	Error         string `json:"error,omitempty"`   // Вывод программы или компилятора в stderr
+	Failure       string `json:"failure,omitempty"` // Причина неудачи на языке сервера
+	Compiled      bool   `json:"compiled"`
+	CompileTimeMs int64  `json:"compile_time_ms,omitempty"`
+	ExecuteTimeMs int64  `json:"execute_time_ms"`
+	Language      string `json:"language"`
+}
+
+// handleSandboxLanguages возвращает языки, поддерживаемые песочницей
+func handleSandboxLanguages(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string][]string{
+		"languages": sandboxEnv.GetSupportedLanguages(),
+	})
+}
+
+// handleSandboxExecute выполняет код из запроса в песочнице. Ошибка
+// компиляции или выполнения программы - успешный ответ с success = false
+func handleSandboxExecute(w http.ResponseWriter, r *http.Request) {
+	var request ExecuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, ERROR_INVALID_REQUEST, i18n.T("server.invalid_json", i18n.LocalizeError(err)))
+		return
+	}
+	if request.Code == "" {
+		writeError(w, http.StatusBadRequest, ERROR_INVALID_REQUEST, i18n.T("server.no_code"))
+		return
+	}
+
+	sandboxMutex.Lock()
+	result, err := sandboxEnv.RunCode(request.Code, request.Language)
+	sandboxMutex.Unlock()
+	if err != nil {
+		writeModelError(w, err)
+		return
+	}
+
+	report := ExecuteReport{
+		Success:       result.Success,
+		ExitCode:      result.ExitCode,
+		Output:        result.Output,
+		Error:         result.Error,
+		Compiled:      result.Compiled,
+		CompileTimeMs: result.CompileTime.Milliseconds(),
+		ExecuteTimeMs: result.ExecuteTime.Milliseconds(),
+		Language:      request.Language,
+	}
+	if result.Failure != nil {
+		report.Failure = i18n.LocalizeError(result.Failure)
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"smollm-sandbox/internal/export"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/search"
+	"smollm-sandbox/internal/storage"
+)
+
+// chatSessionName возвращает имя сохраненной сессии чата. У каждого чата
+// своя сессия, поэтому пользователи не видят и не меняют чужие диалоги
+func chatSessionName(chatID int64) string {
+	return fmt.Sprintf("tg_%d", chatID)
+}
+
+// ensureChatSession создает сессию чата с профилем по умолчанию, если ее
+// еще нет, и возвращает ее имя
+func ensureChatSession(chatID int64) (string, error) {
+	name := chatSessionName(chatID)
+	if sessionManager.SessionExists(name) {
+		return name, nil
+	}
+
+	session, err := modelInstance.NewSessionContext("")
+	if err != nil {
+		return "", err
+	}
+	// Сессию мог одновременно создать другой запрос того же чата
+	if err := sessionManager.CreateSession(name, session); err != nil && !errors.Is(err, storage.ErrSessionExists) {
+		return "", err
+	}
+	return name, nil
+}
+
+// loadChatSession загружает сессию чата, создавая ее при первом обращении
+func loadChatSession(chatID int64) (*model.Context, error) {
+	name, err := ensureChatSession(chatID)
+	if err != nil {
+		return nil, err
+	}
+	return sessionManager.LoadSession(name)
+}
+
+// chatReply отвечает на сообщение text в сессии чата
+func chatReply(chatID int64, text string, opts model.ProcessOptions) (*model.ProcessResult, error) {
+	name, err := ensureChatSession(chatID)
+	if err != nil {
+		return nil, err
+	}
+	return modelInstance.CompleteSessionIn(sessionManager, name, text, opts)
+}
+
+// chatRetry заново генерирует последний ответ в сессии чата
+func chatRetry(chatID int64, opts model.ProcessOptions) (*model.ProcessResult, error) {
+	name, err := ensureChatSession(chatID)
+	if err != nil {
+		return nil, err
+	}
+	return modelInstance.RetrySessionIn(sessionManager, name, opts)
+}
+
+// chatEdit заменяет сообщение пользователя с номером index в сессии чата и
+// генерирует на него новый ответ
+func chatEdit(chatID int64, index int, content string, opts model.ProcessOptions) (*model.ProcessResult, error) {
+	name, err := ensureChatSession(chatID)
+	if err != nil {
+		return nil, err
+	}
+	return modelInstance.EditSessionIn(sessionManager, name, index, content, opts)
+}
+
+// chatSwitchBranch делает активной ветку с номером index в сессии чата
+func chatSwitchBranch(chatID int64, index int) error {
+	name, err := ensureChatSession(chatID)
+	if err != nil {
+		return err
+	}
+	return modelInstance.SwitchBranchIn(sessionManager, name, index)
+}
+
+// chatUseProfile переключает сессию чата на профиль profile
+func chatUseProfile(chatID int64, profile string) error {
+	name, err := ensureChatSession(chatID)
+	if err != nil {
+		return err
+	}
+	return modelInstance.UseProfileIn(sessionManager, name, profile)
+}
+
+// chatExport выгружает в w диалог чата из хранилища сессий в формате format
+func chatExport(w io.Writer, chatID int64, format string) error {
+	session, err := loadChatSession(chatID)
+	if err != nil {
+		return err
+	}
+	conversations := []export.Conversation{{Name: chatSessionName(chatID), Context: session}}
+	return export.Write(w, conversations, format)
+}
+
+// chatSearchQuery возвращает запрос /search пользователя userID в чате
+// chatID. Администраторы ищут по всем документам, остальные пользователи -
+// только по диалогу своего чата
+func chatSearchQuery(userID, chatID int64, text string) search.Query {
+	query := search.Query{Text: text, Limit: 5}
+	if !isAdmin(userID) {
+		query.Type = search.TYPE_SESSION
+		query.Sources = []string{chatSessionName(chatID)}
+	}
+	return query
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"smollm-sandbox/internal/export"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/model/modeltest"
+	"smollm-sandbox/internal/storage"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	os.Exit(m.Run())
+}
+
+// setupChats подключает модель с тестовым сервером и хранилище сессий во
+// временной директории
+func setupChats(t *testing.T) *modeltest.Server {
+	t.Helper()
+
+	m, server := modeltest.NewModel(t)
+	modelInstance = m
+	sessionManager = storage.NewSessionManager(storage.NewFileSystem(t.TempDir()), "sessions")
+	return server
+}
+
+func TestChatSessionsIsolated(t *testing.T) {
+	server := setupChats(t)
+	const first, second = 1001, -2002
+
+	if _, err := chatReply(first, "secret of the first chat", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chatReply(second, "hello from the second chat", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Запрос второго чата не видит сообщений первого
+	requests := server.Requests()
+	if len(requests) != 2 || strings.Contains(requests[1].Prompt, "secret") {
+		t.Fatalf("second chat prompt leaks the first chat: %+v", requests)
+	}
+
+	// Повтор и правка во втором чате не меняют первый
+	if _, err := chatRetry(second, model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chatEdit(second, 1, "edited in the second chat", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := chatSwitchBranch(second, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := loadChatSession(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branches := session.Branches(); len(branches) != 1 {
+		t.Errorf("first chat has %d branches, want 1", len(branches))
+	}
+	messages := session.VisibleMessages()
+	if len(messages) != 2 || messages[0].Content != "secret of the first chat" {
+		t.Errorf("first chat messages = %+v", messages)
+	}
+
+	other, err := loadChatSession(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(other.Branches()) != 3 {
+		t.Errorf("second chat has %d branches, want 3", len(other.Branches()))
+	}
+	loc := i18n.New("en")
+	for _, text := range []string{formatConversation(loc, other), formatBranches(loc, other)} {
+		if strings.Contains(text, "secret") {
+			t.Errorf("second chat listing shows the first chat:\n%s", text)
+		}
+	}
+}
+
+func TestChatExportOwnSession(t *testing.T) {
+	setupChats(t)
+	const first, second = 1001, 2002
+
+	if _, err := chatReply(first, "secret of the first chat", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chatReply(second, "hello from the second chat", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := chatExport(&buf, second, export.FORMAT_MARKDOWN); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "secret") || !strings.Contains(out, "hello from the second chat") {
+		t.Errorf("export of the second chat:\n%s", out)
+	}
+}
+
+func TestChatProfileIsolated(t *testing.T) {
+	server := setupChats(t)
+	if err := modelInstance.SetProfiles([]model.Profile{{Name: "coder", SystemPrompt: "You write code."}}); err != nil {
+		t.Fatal(err)
+	}
+	const first, second = 1001, 2002
+
+	if err := chatUseProfile(first, "coder"); err != nil {
+		t.Fatal(err)
+	}
+	if err := chatUseProfile(second, "missing"); err == nil {
+		t.Error("unknown profile was selected")
+	}
+	if _, err := chatReply(first, "hi", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chatReply(second, "hi", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if !strings.Contains(requests[0].Prompt, "You write code.") {
+		t.Errorf("first chat prompt = %q, want the coder profile", requests[0].Prompt)
+	}
+	if strings.Contains(requests[1].Prompt, "You write code.") {
+		t.Errorf("second chat prompt = %q, want the default profile", requests[1].Prompt)
+	}
+	if name := modelInstance.Profile().Name; name != model.DEFAULT_PROFILE {
+		t.Errorf("model profile = %q, want %q", name, model.DEFAULT_PROFILE)
+	}
+}
+
+func TestChatSearchQuery(t *testing.T) {
+	adminUsers = []int64{42}
+	t.Cleanup(func() { adminUsers = nil })
+
+	tests := []struct {
+		name    string
+		userID  int64
+		sources []string
+	}{
+		{"user searches own chat", 7, []string{"tg_-100"}},
+		{"admin searches everything", 42, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := chatSearchQuery(tt.userID, -100, "text")
+			if !slices.Equal(query.Sources, tt.sources) {
+				t.Errorf("Sources = %v, want %v", query.Sources, tt.sources)
+			}
+		})
+	}
+}
@@ -1,20 +1,29 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
+	"smollm-sandbox/internal/config"
+	"smollm-sandbox/internal/export"
 	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/rag"
 	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/search"
 	"smollm-sandbox/internal/storage"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -25,43 +34,107 @@ const (
 )
 
 var (
-	logger        *logging.Logger
-	modelInstance *model.SmolLM
-	sandboxEnv    *sandbox.Environment
-	store         *storage.FileSystem
-	collector     *feedback.Collector
-	configPath    string
-	token         string
-	allowedUsers  []int64
-	adminUsers    []int64
+	logger         *logging.Logger
+	modelInstance  *model.SmolLM
+	sandboxEnv     *sandbox.Environment
+	store          *storage.FileSystem
+	sessionManager *storage.SessionManager
+	searchIndex    *search.Index
+	collector      *feedback.Collector
+	cfg            *config.Config
+	configPath     string
+	token          string
+	allowedUsers   []int64
+	adminUsers     []int64
 )
 
 func main() {
 	// Определение флагов
-	flag.StringVar(&configPath, "config", "configs/config.yaml", "Путь к файлу конфигурации")
-	flag.StringVar(&token, "token", "", "Токен Telegram бота")
+	flag.StringVar(&configPath, "config", "configs/config.yaml", i18n.T("cli.flag_config"))
+	flag.StringVar(&token, "token", "", i18n.T("tg.flag_token"))
 	flag.Parse()
 
+	// Загрузка конфигурации
+	var cfgErr error
+	cfg, cfgErr = config.Load(configPath)
+
+	// Язык ответов пользователям, чей язык Telegram не поддерживается
+	i18n.SetLanguage(cfg.Language)
+
+	// Токен и списки пользователей из конфигурации, если не заданы флагами
+	if token == "" {
+		token = cfg.Telegram.Token
+	}
+	allowedUsers = cfg.Telegram.AllowedUsers
+	adminUsers = cfg.Telegram.AdminUsers
+
 	// Проверка токена
 	if token == "" {
-		log.Fatal("Не указан токен Telegram бота")
+		log.Fatal(i18n.T("tg.no_token"))
 	}
 
 	// Инициализация логирования
+	logging.SetDefaultLogConfig(logging.LogConfig{
+		Level:         logging.ParseLevel(cfg.Logging.Level),
+		EnableFile:    cfg.Logging.File != "",
+		FilePath:      config.ExpandPath(cfg.Logging.File),
+		EnableConsole: cfg.Logging.Console,
+		Console:       os.Stdout,
+	})
 	logger = logging.NewLogger()
 	logger.Info("Starting SmolLM Telegram Bot")
 
-	// Загрузка конфигурации
 	logger.Info("Loading configuration from %s", configPath)
-	// TODO: Реализовать загрузку конфигурации
+	if cfgErr != nil {
+		logger.Warn("Using default configuration: %v", cfgErr)
+	}
 
 	// Инициализация хранилища
 	homeDir := getHomeDir()
 	store = storage.NewFileSystem(homeDir)
+	sessionManager = storage.NewSessionManager(store, cfg.Storage.SessionsDir)
+	searchIndex = search.NewIndex(store)
+	sessionManager.AddListener(searchIndex)
 
 	// Инициализация модели
 	logger.Info("Initializing SmolLM2 model")
-	modelInstance = model.NewSmolLM()
+	modelInstance = model.NewSmolLMWithOptions(model.Options{
+		Path:         config.ExpandPath(cfg.Model.Path),
+		Backend:      cfg.Model.Backend,
+		Generation:   cfg.Model.Parameters,
+		ThinkingSeed: cfg.Model.Thinking.Seed,
+		Timeout:      time.Duration(cfg.Model.Timeout) * time.Second,
+		Parallel:     cfg.Model.Queue.Parallel,
+		QueueSize:    cfg.Model.Queue.MaxSize,
+	})
+	modelInstance.SetSessionStore(sessionManager)
+	initResponseCache()
+
+	// Профили (персоны) модели. Профиль по умолчанию получают новые сессии
+	// чатов, дальше каждый чат выбирает свой командой /profile
+	profiles, err := cfg.Profiles.LoadProfiles()
+	if err != nil {
+		logger.Warn("Failed to load profiles: %v", err)
+	}
+	if err := modelInstance.SetProfiles(profiles); err != nil {
+		logger.Error("Invalid profile configuration: %v", err)
+	} else if cfg.Profiles.Default != "" {
+		if err := modelInstance.UseProfile(cfg.Profiles.Default); err != nil {
+			logger.Warn("Default profile is unavailable: %v", err)
+		}
+	}
+
+	// Подключение поиска по папке документов. Индексация выполняется в фоне,
+	// до ее завершения используется ранее построенный индекс
+	if cfg.RAG.Enabled {
+		ragIndex := rag.NewIndex(store, cfg.RAG)
+		modelInstance.SetRetriever(ragIndex)
+		go func() {
+			if _, err := ragIndex.Ingest(context.Background()); err != nil {
+				logger.Error("Failed to ingest documents: %v", err)
+			}
+		}()
+	}
 
 	// Инициализация песочницы
 	logger.Info("Setting up sandbox environment")
@@ -71,6 +144,18 @@ func main() {
 	feedbackDir := filepath.Join(homeDir, "feedback")
 	collector = feedback.NewCollector(feedbackDir)
 
+	// Первый запуск: строим поисковый индекс по уже накопленным данным
+	if !searchIndex.Exists() {
+		go func() {
+			if err := collector.LoadFeedbackFromDisk(); err != nil {
+				logger.Warn("Failed to load feedback for indexing: %v", err)
+			}
+			if err := searchIndex.Rebuild(sessionManager, filepath.Join(homeDir, "thoughts"), collector.GetAllFeedback()); err != nil {
+				logger.Error("Failed to build search index: %v", err)
+			}
+		}()
+	}
+
 	// Инициализация бота
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
@@ -103,10 +188,15 @@ func main() {
 
 // getHomeDir возвращает домашнюю директорию
 func getHomeDir() string {
+	// Корневая директория хранилища из конфигурации
+	if cfg != nil && cfg.Storage.RootDir != "" {
+		return config.ExpandPath(cfg.Storage.RootDir)
+	}
+
 	// В продакшне здесь будет домашняя директория учетной записи нейросети
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		logger.Error("Не удалось определить домашнюю директорию: %v", err)
+		logger.Error("Failed to determine home directory: %v", err)
 		return "/tmp/smollm-sandbox"
 	}
 	return filepath.Join(homeDir, ".smollm-sandbox")
@@ -127,12 +217,16 @@ func isUserAllowed(userID int64) bool {
 	}
 
 	// Проверяем, является ли пользователь администратором
+	return isAdmin(userID)
+}
+
+// isAdmin проверяет, является ли пользователь администратором
+func isAdmin(userID int64) bool {
 	for _, id := range adminUsers {
 		if id == userID {
 			return true
 		}
 	}
-
 	return false
 }
 
@@ -147,7 +241,15 @@ func handleMessage(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 	}
 
 	// Обработка обычного текста
-	response := modelInstance.Process(message.Text)
+	loc := userLocalizer(message.From)
+	response := loc.T("cli.response_failed")
+	if result, err := chatReply(message.Chat.ID, message.Text, processOptions(bot, message)); err == nil {
+		response = result.Text + formatCitations(loc, result.Citations)
+	} else if errors.Is(err, model.ErrModelUnavailable) {
+		response = loc.T("cli.model_unavailable")
+	} else if errors.Is(err, model.ErrQueueFull) {
+		response = loc.Error(err)
+	}
 
 	// Отправляем ответ
 	msg := tgbotapi.NewMessage(message.Chat.ID, response)
@@ -160,21 +262,15 @@ func handleMessage(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 
 // handleCommand обрабатывает команды бота
 func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
+	loc := userLocalizer(message.From)
+
 	switch message.Command() {
 	case "start":
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Привет! Я SmolLM бот v%s. Напиши мне что-нибудь, и я отвечу.", VERSION))
+		msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.start", VERSION))
 		bot.Send(msg)
 
 	case "help":
-		helpText := `
-Команды бота:
-/start - Начать общение
-/help - Показать справку
-/think [время] - Запустить режим размышления
-/run - Выполнить код (отправь код в следующем сообщении)
-/status - Показать статус бота
-/feedback [оценка] [комментарий] - Отправить обратную связь
-`
+		helpText := loc.T("tg.help")
 		msg := tgbotapi.NewMessage(message.Chat.ID, helpText)
 		bot.Send(msg)
 
@@ -194,7 +290,7 @@ func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 		}
 
 		// Отправляем начальное сообщение
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Запускаю режим размышления на %d секунд...", seconds))
+		msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.thinking", seconds))
 		bot.Send(msg)
 
 		// Создаем временный файл для мыслей
@@ -205,13 +301,23 @@ func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 		os.MkdirAll(filepath.Dir(thoughtFile), 0755)
 
 		// Запускаем размышление
-		modelInstance.Think(seconds, thoughtFile)
+		// Размышление уступает очередь ответам в диалоге
+		if _, err := modelInstance.ThinkWithOptions(model.ThinkOptions{
+			Seconds: seconds,
+			Owner:   fmt.Sprintf("tg_%d", message.From.ID),
+		}, thoughtFile); err != nil {
+			logger.Error("Error in thinking mode: %v", err)
+		}
+
+		if err := searchIndex.IndexThought(thoughtFile); err != nil {
+			logger.Warn("Failed to index thoughts: %v", err)
+		}
 
 		// Читаем файл
 		thoughts, err := os.ReadFile(thoughtFile)
 		if err != nil {
 			logger.Error("Failed to read thoughts file: %v", err)
-			msg = tgbotapi.NewMessage(message.Chat.ID, "Произошла ошибка при чтении файла размышлений.")
+			msg = tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.thought_read_failed"))
 			bot.Send(msg)
 			return
 		}
@@ -235,7 +341,7 @@ func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 				}
 
 				partText := thoughtText[start:end]
-				msg = tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Часть %d/%d:\n%s", i+1, parts, partText))
+				msg = tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.thought_part", i+1, parts, partText))
 				bot.Send(msg)
 
 				// Небольшая пауза, чтобы не превысить лимиты API
@@ -243,13 +349,165 @@ func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 			}
 		}
 
+	case "retry":
+		result, err := chatRetry(message.Chat.ID, processOptions(bot, message))
+		var text string
+		if result != nil {
+			text = result.Text + formatCitations(loc, result.Citations)
+		} else {
+			text = loc.T("tg.retry_failed", loc.Error(err))
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		bot.Send(msg)
+
+	case "edit":
+		args := strings.TrimSpace(message.CommandArguments())
+		if args == "" {
+			session, err := loadChatSession(message.Chat.ID)
+			if err != nil {
+				logger.Error("Failed to load chat session: %v", err)
+				msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.session_unavailable", loc.Error(err)))
+				bot.Send(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(message.Chat.ID, formatConversation(loc, session))
+			bot.Send(msg)
+			return
+		}
+
+		parts := strings.SplitN(args, " ", 2)
+		index, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+			msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.usage_edit"))
+			bot.Send(msg)
+			return
+		}
+
+		result, err := chatEdit(message.Chat.ID, index, parts[1], processOptions(bot, message))
+		var text string
+		if result != nil {
+			text = result.Text + formatCitations(loc, result.Citations)
+		} else {
+			text = loc.T("tg.edit_failed", loc.Error(err))
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		bot.Send(msg)
+
+	case "branch":
+		args := strings.TrimSpace(message.CommandArguments())
+		if args == "" {
+			session, err := loadChatSession(message.Chat.ID)
+			if err != nil {
+				logger.Error("Failed to load chat session: %v", err)
+				msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.session_unavailable", loc.Error(err)))
+				bot.Send(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(message.Chat.ID, formatBranches(loc, session))
+			bot.Send(msg)
+			return
+		}
+
+		index, err := strconv.Atoi(args)
+		if err == nil {
+			err = chatSwitchBranch(message.Chat.ID, index)
+		}
+		var session *model.Context
+		if err == nil {
+			session, err = loadChatSession(message.Chat.ID)
+		}
+		var text string
+		if err != nil {
+			text = loc.T("tg.branch_failed", loc.Error(err))
+		} else {
+			text = loc.T("cli.branch_active", index) + "\n\n" + formatConversation(loc, session)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		bot.Send(msg)
+
+	case "profile":
+		session, err := loadChatSession(message.Chat.ID)
+		if err != nil {
+			logger.Error("Failed to load chat session: %v", err)
+			msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.session_unavailable", loc.Error(err)))
+			bot.Send(msg)
+			return
+		}
+
+		// Профиль выбирается только для диалога этого чата
+		text := formatProfiles(loc, session)
+		if name := strings.TrimSpace(message.CommandArguments()); name != "" {
+			if err := chatUseProfile(message.Chat.ID, name); err != nil {
+				text = loc.T("tg.profile_failed", loc.Error(err))
+			} else {
+				text = loc.T("cli.profile_selected", name)
+			}
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		bot.Send(msg)
+
+	case "memory":
+		msg := tgbotapi.NewMessage(message.Chat.ID, memoryCommand(loc, message.From.ID, message.CommandArguments()))
+		bot.Send(msg)
+
+	case "remember":
+		text := loc.T("tg.usage_remember")
+		if fact := strings.TrimSpace(message.CommandArguments()); fact != "" {
+			text = rememberFact(loc, message.From.ID, fact)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		bot.Send(msg)
+
+	case "search":
+		query := strings.TrimSpace(message.CommandArguments())
+		if query == "" {
+			msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.usage_search"))
+			bot.Send(msg)
+			return
+		}
+
+		results, err := searchIndex.Search(chatSearchQuery(message.From.ID, message.Chat.ID, query))
+		text := formatSearchResults(loc, results)
+		if err != nil {
+			text = loc.T("cli.search_failed", loc.Error(err))
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		bot.Send(msg)
+
+	case "export":
+		format := export.FORMAT_MARKDOWN
+		if args := strings.TrimSpace(message.CommandArguments()); args != "" {
+			var err error
+			if format, err = export.ParseFormat(args); err != nil {
+				msg := tgbotapi.NewMessage(message.Chat.ID, loc.Error(err))
+				bot.Send(msg)
+				return
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := chatExport(&buf, message.Chat.ID, format); err != nil {
+			logger.Error("Failed to export conversation: %v", err)
+			msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.export_failed"))
+			bot.Send(msg)
+			return
+		}
+
+		doc := tgbotapi.NewDocument(message.Chat.ID, tgbotapi.FileBytes{
+			Name:  chatSessionName(message.Chat.ID) + export.Extension(format),
+			Bytes: buf.Bytes(),
+		})
+		if _, err := bot.Send(doc); err != nil {
+			logger.Error("Failed to send export: %v", err)
+		}
+
 	case "run":
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Отправь мне код для выполнения в следующем сообщении.")
+		msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.run_prompt"))
 		bot.Send(msg)
 		// TODO: Реализовать запуск кода
 
 	case "status":
-		stats := getSystemStatus()
+		stats := getSystemStatus(loc)
 		msg := tgbotapi.NewMessage(message.Chat.ID, stats)
 		bot.Send(msg)
 
@@ -270,7 +528,7 @@ func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 
 		// Проверяем рейтинг
 		if rating < 1 || rating > 5 {
-			msg := tgbotapi.NewMessage(message.Chat.ID, "Оценка должна быть от 1 до 5.")
+			msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.invalid_rating"))
 			bot.Send(msg)
 			return
 		}
@@ -280,30 +538,266 @@ func handleCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 			"user_id":   message.From.ID,
 			"user_name": message.From.UserName,
 		}
+
+		// Связываем оценку с диалогом чата, чтобы ее можно было учесть при экспорте
+		if session, err := loadChatSession(message.Chat.ID); err == nil {
+			metadata[feedback.META_SESSION_ID] = session.SessionID
+			if last, ok := session.GetLastAssistantMessage(); ok {
+				metadata[feedback.META_MESSAGE_ID] = last.ID
+			}
+		} else {
+			logger.Warn("Failed to load chat session for feedback: %v", err)
+		}
 		id, err := collector.AddFeedback(feedback.ModelOutput, "Telegram feedback", rating, comment, metadata)
 
 		if err != nil {
 			logger.Error("Failed to save feedback: %v", err)
-			msg := tgbotapi.NewMessage(message.Chat.ID, "Произошла ошибка при сохранении обратной связи.")
+			msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.feedback_failed"))
 			bot.Send(msg)
 			return
 		}
 
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("Спасибо за обратную связь! ID: %s", id))
+		if item, ok := collector.GetFeedback(id); ok {
+			if err := searchIndex.IndexFeedback(item); err != nil {
+				logger.Warn("Failed to index feedback: %v", err)
+			}
+		}
+
+		msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.feedback_saved", id))
 		bot.Send(msg)
 
 	default:
-		msg := tgbotapi.NewMessage(message.Chat.ID, "Неизвестная команда. Введите /help для справки.")
+		msg := tgbotapi.NewMessage(message.Chat.ID, loc.T("tg.unknown_command"))
 		bot.Send(msg)
 	}
 }
 
+// formatConversation возвращает пронумерованные сообщения активной ветки
+// сессии чата
+func formatConversation(loc *i18n.Localizer, session *model.Context) string {
+	messages := session.VisibleMessages()
+	if len(messages) == 0 {
+		return loc.T("cli.branch_empty")
+	}
+
+	var sb strings.Builder
+	for i, msg := range messages {
+		sb.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, msg.Role, previewText(msg.Content)))
+	}
+	return sb.String()
+}
+
+// formatBranches возвращает список веток диалога сессии чата
+func formatBranches(loc *i18n.Localizer, session *model.Context) string {
+	var sb strings.Builder
+	for i, branch := range session.Branches() {
+		marker := " "
+		if branch.Active {
+			marker = "*"
+		}
+		sb.WriteString(loc.T("cli.branch_line", marker, i+1, branch.Length, previewText(branch.Preview)) + "\n")
+	}
+	sb.WriteString(loc.T("cli.branch_hint"))
+	return sb.String()
+}
+
+// formatSearchResults возвращает результаты поиска с именами сессий и фрагментами
+func formatSearchResults(loc *i18n.Localizer, results []search.Result) string {
+	if len(results) == 0 {
+		return loc.T("cli.search_nothing")
+	}
+
+	var sb strings.Builder
+	for i, result := range results {
+		sb.WriteString(fmt.Sprintf("%d. [%s] %s, %s\n%s\n\n", i+1, result.Type, result.Title,
+			result.Timestamp.Format("2006-01-02 15:04"), result.Snippet))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// initResponseCache подключает кэш ответов модели во временной директории хранилища
+func initResponseCache() {
+	if !cfg.Model.Cache.Enabled {
+		return
+	}
+
+	cache, err := storage.NewResponseCache(store, cfg.Model.Cache.MaxSize)
+	if err != nil {
+		logger.Warn("Response cache is unavailable: %v", err)
+		return
+	}
+	modelInstance.SetResponseCache(cache)
+}
+
+// openUserMemory открывает долговременную память пользователя Telegram
+func openUserMemory(userID int64) (*storage.UserMemory, error) {
+	return storage.NewUserMemory(store, fmt.Sprintf("tg_%d", userID))
+}
+
+// processOptions возвращает параметры запроса к модели с памятью
+// пользователя. Пока запрос ждет в очереди, пользователь видит его позицию
+func processOptions(bot *tgbotapi.BotAPI, message *tgbotapi.Message) model.ProcessOptions {
+	userID := message.From.ID
+	opts := model.ProcessOptions{
+		Owner:    fmt.Sprintf("tg_%d", userID),
+		OnQueued: queueNotifier(bot, message),
+	}
+
+	memory, err := openUserMemory(userID)
+	if err != nil {
+		logger.Warn("User memory is unavailable for %d: %v", userID, err)
+		return opts
+	}
+	opts.Memory = memory
+
+	return opts
+}
+
+// queueNotifier возвращает функцию, которая сообщает пользователю позицию
+// его запроса в очереди: первое сообщение отправляется, затем изменяется
+func queueNotifier(bot *tgbotapi.BotAPI, message *tgbotapi.Message) func(position int) {
+	loc := userLocalizer(message.From)
+	notice := 0
+
+	return func(position int) {
+		text := loc.T("tg.queued", position)
+		if notice != 0 {
+			if _, err := bot.Send(tgbotapi.NewEditMessageText(message.Chat.ID, notice, text)); err != nil {
+				logger.Warn("Failed to update queue position: %v", err)
+			}
+			return
+		}
+
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		msg.ReplyToMessageID = message.MessageID
+		sent, err := bot.Send(msg)
+		if err != nil {
+			logger.Warn("Failed to send queue position: %v", err)
+			return
+		}
+		notice = sent.MessageID
+	}
+}
+
+// memoryCommand выполняет /memory [forget N|forget all] и возвращает ответ
+func memoryCommand(loc *i18n.Localizer, userID int64, args string) string {
+	memory, err := openUserMemory(userID)
+	if err != nil {
+		return loc.T("tg.memory_unavailable", loc.Error(err))
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 || fields[0] == "list" {
+		facts, err := memory.Facts()
+		if err != nil {
+			return loc.T("cli.memory_read_failed", loc.Error(err))
+		}
+		if len(facts) == 0 {
+			return loc.T("tg.memory_empty")
+		}
+
+		var sb strings.Builder
+		sb.WriteString(loc.T("cli.memory_title") + "\n")
+		for i, fact := range facts {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, fact.Text))
+		}
+		sb.WriteString("\n" + loc.T("tg.memory_forget_hint"))
+		return sb.String()
+	}
+
+	if fields[0] != "forget" || len(fields) < 2 {
+		return loc.T("tg.usage_memory")
+	}
+	if fields[1] == "all" {
+		if err := memory.Clear(); err != nil {
+			return loc.T("cli.memory_clear_failed") + ": " + loc.Error(err)
+		}
+		return loc.T("cli.memory_cleared")
+	}
+
+	index, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return loc.T("tg.usage_forget")
+	}
+	fact, err := memory.Forget(index)
+	if err != nil {
+		return loc.T("cli.forget_failed") + ": " + loc.Error(err)
+	}
+	return loc.T("cli.forgotten", fact.Text)
+}
+
+// rememberFact сохраняет факт, добавленный пользователем командой /remember
+func rememberFact(loc *i18n.Localizer, userID int64, text string) string {
+	memory, err := openUserMemory(userID)
+	if err != nil {
+		return loc.T("tg.memory_unavailable", loc.Error(err))
+	}
+
+	fact, err := memory.Remember(text, model.MEMORY_SOURCE_USER)
+	if err != nil {
+		return loc.T("cli.remember_failed") + ": " + loc.Error(err)
+	}
+	return loc.T("cli.remembered", fact.Text)
+}
+
+// formatProfiles возвращает список профилей с отметкой профиля сессии чата
+func formatProfiles(loc *i18n.Localizer, session *model.Context) string {
+	current, _ := session.GetProperty(model.META_PROFILE)
+
+	var sb strings.Builder
+	sb.WriteString(loc.T("tg.profiles_title") + "\n")
+	for _, profile := range modelInstance.Profiles() {
+		marker := "  "
+		if profile.Name == current {
+			marker = "* "
+		}
+		sb.WriteString(fmt.Sprintf("%s%s - %s\n", marker, profile.Name, profile.Description))
+	}
+	sb.WriteString("\n" + loc.T("tg.profile_hint"))
+	return sb.String()
+}
+
+// formatCitations возвращает список источников, добавляемый к ответу модели
+func formatCitations(loc *i18n.Localizer, passages []model.Passage) string {
+	if len(passages) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n" + loc.T("cli.rag_sources"))
+	for i, passage := range passages {
+		sb.WriteString(fmt.Sprintf("\n[%d] %s:%d-%d", i+1, passage.Source, passage.StartLine, passage.EndLine))
+	}
+	return sb.String()
+}
+
+// userLocalizer возвращает локализатор для языка пользователя Telegram.
+// Если язык не поддерживается, используется язык из конфигурации
+func userLocalizer(user *tgbotapi.User) *i18n.Localizer {
+	if user != nil && i18n.Normalize(user.LanguageCode) != "" {
+		return i18n.New(user.LanguageCode)
+	}
+	return i18n.Default()
+}
+
+// previewText сокращает текст до одной строки для списков
+func previewText(text string) string {
+	const maxPreview = 60
+
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) > maxPreview {
+		return string(runes[:maxPreview]) + "..."
+	}
+	return text
+}
+
 // getSystemStatus возвращает текущий статус системы
-func getSystemStatus() string {
+func getSystemStatus(loc *i18n.Localizer) string {
 	metrics := logger.GetMetrics()
 
 	uptime := metrics.GetUptime()
-	uptimeStr := fmt.Sprintf("%d дней, %d часов, %d минут",
+	uptimeStr := loc.T("tg.uptime",
 		int(uptime.Hours())/24,
 		int(uptime.Hours())%24,
 		int(uptime.Minutes())%60)
@@ -315,14 +809,20 @@ func getSystemStatus() string {
 
 	// Формируем статус
 	status := fmt.Sprintf("SmolLM Sandbox v%s\n", VERSION)
-	status += fmt.Sprintf("Время работы: %s\n", uptimeStr)
-	status += fmt.Sprintf("Ошибок: %d\n", metricsMap["error_count"])
-	status += fmt.Sprintf("Выполнено кода: %d\n", metricsMap["executions"])
+	status += loc.T("tg.status_uptime", uptimeStr) + "\n"
+	status += loc.T("tg.status_errors", metricsMap["error_count"]) + "\n"
+	status += loc.T("tg.status_executions", metricsMap["executions"]) + "\n"
+	status += loc.T("tg.status_model", modelInstance.ServerState()) + "\n"
+	queue := modelInstance.QueueStats()
+	status += loc.T("tg.status_queue", queue.Running, queue.Parallel, queue.Queued) + "\n"
+	cacheHits, cacheMisses := modelInstance.Metrics().GetCacheStats(model.CACHE_RESPONSE)
+	prefixHits, prefixMisses := modelInstance.Metrics().GetCacheStats(model.CACHE_KV_PREFIX)
+	status += loc.T("tg.status_cache", cacheHits, cacheMisses, prefixHits, prefixMisses) + "\n"
 
 	// Добавляем информацию о системе
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
-	status += fmt.Sprintf("Использование памяти: %.2f МБ\n", float64(memStats.Alloc)/1024/1024)
+	status += loc.T("tg.status_memory", float64(memStats.Alloc)/1024/1024) + "\n"
 
 	return status
 }
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config представляет основную конфигурацию SmolLM Sandbox (configs/config.yaml)
+type Config struct {
+	Language string         `yaml:"language"` // Язык интерфейса CLI и бота (ru, en)
+	Model    ModelConfig    `yaml:"model"`
+	Logging  LoggingConfig  `yaml:"logging"`
+	Storage  StorageConfig  `yaml:"storage"`
+	CLI      CLIConfig      `yaml:"cli"`
+	Telegram TelegramConfig `yaml:"telegram"`
+	Server   ServerConfig   `yaml:"server"`
+	RAG      RAGConfig      `yaml:"rag"`
+	Profiles ProfilesConfig `yaml:"profiles"`
+}
+
+// ModelConfig содержит настройки модели
+type ModelConfig struct {
+	Name       string                  `yaml:"name"`
+	Path       string                  `yaml:"path"`
+	Backend    string                  `yaml:"backend"`    // auto, native, api, worker
+	Timeout    int                     `yaml:"timeout"`    // Максимальное время генерации ответа в секундах
+	Parameters model.GenerationOptions `yaml:"parameters"` // Параметры генерации по умолчанию
+	Thinking   ThinkingSettings        `yaml:"thinking"`
+	Queue      QueueSettings           `yaml:"queue"`
+	Cache      CacheSettings           `yaml:"cache"`
+}
+
+// CacheSettings содержит настройки кэша ответов на запросы с заданным seed
+type CacheSettings struct {
+	Enabled bool  `yaml:"enabled"`
+	MaxSize int64 `yaml:"max_size"` // Размер кэша во временной директории в байтах
+}
+
+// QueueSettings содержит настройки очереди запросов генерации
+type QueueSettings struct {
+	Parallel int `yaml:"parallel"` // Одновременных запросов; 0 - сколько выполняет способ генерации
+	MaxSize  int `yaml:"max_size"` // Максимальная длина очереди; 0 - без ограничения
+}
+
+// ThinkingSettings содержит настройки режима размышления
+type ThinkingSettings struct {
+	Enabled bool `yaml:"enabled"`
+	Seed    int  `yaml:"seed"`
+	MaxTime int  `yaml:"max_time"` // Максимальное время размышления в секундах
+}
+
+// LoggingConfig содержит настройки логирования
+type LoggingConfig struct {
+	Level   string `yaml:"level"`
+	File    string `yaml:"file"`
+	Console bool   `yaml:"console"`
+	Metrics bool   `yaml:"metrics"`
+}
+
+// StorageConfig содержит настройки хранилища
+type StorageConfig struct {
+	RootDir     string `yaml:"root_dir"`
+	SessionsDir string `yaml:"sessions_dir"`
+	ThoughtsDir string `yaml:"thoughts_dir"`
+	CodeDir     string `yaml:"code_dir"`
+	TempDir     string `yaml:"temp_dir"`
+	MaxSessions int    `yaml:"max_sessions"`
+	MaxFileSize int64  `yaml:"max_file_size"`
+}
+
+// CLIConfig содержит настройки интерфейса командной строки
+type CLIConfig struct {
+	HistoryFile    string `yaml:"history_file"`
+	DefaultMode    string `yaml:"default_mode"`
+	Prompt         string `yaml:"prompt"`
+	ThinkingPrompt string `yaml:"thinking_prompt"`
+}
+
+// ServerConfig содержит настройки HTTP сервера API в стиле OpenAI и gRPC сервиса
+type ServerConfig struct {
+	Addr     string   `yaml:"addr"`      // Адрес и порт сервера
+	GRPCAddr string   `yaml:"grpc_addr"` // Адрес gRPC сервиса; пусто - сервис отключен
+	APIKeys  []string `yaml:"api_keys"`  // Ключи доступа; пусто - только для адресов localhost
+	Sandbox  bool     `yaml:"sandbox"`   // Открыть ли эндпоинты песочницы
+}
+
+// TelegramConfig содержит настройки Telegram бота
+type TelegramConfig struct {
+	Enabled      bool    `yaml:"enabled"`
+	Token        string  `yaml:"token"`
+	AllowedUsers []int64 `yaml:"allowed_users"`
+	AdminUsers   []int64 `yaml:"admin_users"`
+}
+
+// RAGConfig содержит настройки поиска по локальной папке с документами
+type RAGConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	DocsDir       string   `yaml:"docs_dir"`       // Папка с документами для индексации
+	Extensions    []string `yaml:"extensions"`     // Расширения индексируемых файлов
+	ChunkSize     int      `yaml:"chunk_size"`     // Размер фрагмента в символах
+	ChunkOverlap  int      `yaml:"chunk_overlap"`  // Перекрытие соседних фрагментов в символах
+	TopK          int      `yaml:"top_k"`          // Количество фрагментов, добавляемых в контекст
+	EmbeddingsURL string   `yaml:"embeddings_url"` // Эндпоинт эмбеддингов; пусто - только BM25
+}
+
+// ProfilesConfig содержит профили (персоны) модели. Профили из директории
+// Dir (по одному YAML файлу на профиль) дополняют и переопределяют Items
+type ProfilesConfig struct {
+	Default string          `yaml:"default"` // Профиль новых сессий
+	Dir     string          `yaml:"dir"`
+	Items   []model.Profile `yaml:"items"`
+}
+
+// Default возвращает конфигурацию по умолчанию
+func Default() *Config {
+	return &Config{
+		Language: i18n.DEFAULT_LANG,
+		Model: ModelConfig{
+			Name:    "SmolLM2-135M-Instruct",
+			Path:    "/opt/smollm-models/SmolLM2-135M-Instruct",
+			Backend: model.BACKEND_AUTO,
+			Timeout: 120,
+			Parameters: model.GenerationOptions{
+				Temperature: model.Float(0.7),
+				TopP:        model.Float(0.9),
+				MaxTokens:   model.Int(2048),
+				TopK:        model.Int(40),
+			},
+			Thinking: ThinkingSettings{
+				Enabled: true,
+				Seed:    42,
+				MaxTime: 3600,
+			},
+			Cache: CacheSettings{
+				Enabled: true,
+				MaxSize: 67108864,
+			},
+		},
+		Logging: LoggingConfig{
+			Level:   "info",
+			File:    "logs/smollm.log",
+			Console: true,
+			Metrics: true,
+		},
+		Storage: StorageConfig{
+			RootDir:     "~/.smollm-sandbox",
+			SessionsDir: "sessions",
+			ThoughtsDir: "thoughts",
+			CodeDir:     "code",
+			TempDir:     "temp",
+			MaxSessions: 100,
+			MaxFileSize: 10 * 1024 * 1024,
+		},
+		CLI: CLIConfig{
+			HistoryFile:    "~/.smollm_history",
+			DefaultMode:    "interactive",
+			Prompt:         "smollm> ",
+			ThinkingPrompt: "thinking...",
+		},
+		Server: ServerConfig{
+			Addr:    "localhost:8080",
+			Sandbox: true,
+		},
+		RAG: RAGConfig{
+			Enabled:       false,
+			DocsDir:       "~/.smollm-sandbox/docs",
+			Extensions:    []string{".txt", ".md", ".go", ".py", ".js", ".c", ".cpp", ".h", ".sh", ".json", ".yaml", ".yml"},
+			ChunkSize:     800,
+			ChunkOverlap:  100,
+			TopK:          3,
+			EmbeddingsURL: "http://localhost:8000/v1/embeddings",
+		},
+		Profiles: ProfilesConfig{
+			Default: model.DEFAULT_PROFILE,
+			Dir:     "~/.smollm-sandbox/profiles",
+		},
+	}
+}
+
+// Load загружает конфигурацию из YAML файла поверх значений по умолчанию
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, i18n.WrapError(err, "config.read").WithKind(ErrConfigRead)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return cfg, i18n.WrapError(err, "config.parse").WithKind(ErrConfigParse)
+	}
+
+	return cfg, nil
+}
+
+// LoadProfiles возвращает профили из конфигурации и директории профилей.
+// Имя профиля из файла по умолчанию совпадает с именем файла
+func (c ProfilesConfig) LoadProfiles() ([]model.Profile, error) {
+	profiles := append([]model.Profile{}, c.Items...)
+	if c.Dir == "" {
+		return profiles, nil
+	}
+
+	dir := ExpandPath(c.Dir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return profiles, i18n.WrapError(err, "config.profiles_read").WithKind(ErrProfileRead)
+	}
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return profiles, i18n.WrapError(err, "config.profile_read", entry.Name()).WithKind(ErrProfileRead)
+		}
+
+		var profile model.Profile
+		if err := yaml.Unmarshal(data, &profile); err != nil {
+			return profiles, i18n.WrapError(err, "config.profile_parse", entry.Name()).WithKind(ErrProfileParse)
+		}
+		if profile.Name == "" {
+			profile.Name = strings.TrimSuffix(entry.Name(), ext)
+		}
+
+		// Профиль из файла заменяет одноименный профиль конфигурации
+		replaced := false
+		for i := range profiles {
+			if profiles[i].Name == profile.Name {
+				profiles[i] = profile
+				replaced = true
+			}
+		}
+		if !replaced {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles, nil
+}
+
+// ExpandPath раскрывает "~" в начале пути в домашнюю директорию пользователя
+func ExpandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
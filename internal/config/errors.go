@@ -0,0 +1,12 @@
+package config
+
+import "errors"
+
+// Виды ошибок конфигурации. При ErrConfigRead и ErrConfigParse Load
+// возвращает настройки по умолчанию
+var (
+	ErrConfigRead   = errors.New("config: read failed")
+	ErrConfigParse  = errors.New("config: parse failed")
+	ErrProfileRead  = errors.New("config: profile read failed")
+	ErrProfileParse = errors.New("config: profile parse failed")
+)
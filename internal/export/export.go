@@ -0,0 +1,95 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// Форматы экспорта
+const (
+	FORMAT_MARKDOWN = "markdown" // Читаемая стенограмма в Markdown
+	FORMAT_HTML     = "html"     // Самодостаточная HTML страница
+	FORMAT_CHATML   = "chatml"   // JSONL с текстом в шаблоне ChatML SmolLM2
+	FORMAT_SHAREGPT = "sharegpt" // JSONL в формате ShareGPT
+)
+
+// Conversation представляет диалог для экспорта: имя сессии и ее контекст.
+// Экспортируется только активная ветка диалога
+type Conversation struct {
+	Name    string
+	Context *model.Context
+}
+
+// Formats возвращает список поддерживаемых форматов
+func Formats() []string {
+	return []string{FORMAT_MARKDOWN, FORMAT_HTML, FORMAT_CHATML, FORMAT_SHAREGPT}
+}
+
+// ParseFormat приводит название формата к каноническому виду
+func ParseFormat(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "markdown", "md":
+		return FORMAT_MARKDOWN, nil
+	case "html", "htm":
+		return FORMAT_HTML, nil
+	case "chatml":
+		return FORMAT_CHATML, nil
+	case "sharegpt":
+		return FORMAT_SHAREGPT, nil
+	default:
+		return "", i18n.NewError("export.unknown_format", name, strings.Join(Formats(), ", "))
+	}
+}
+
+// Extension возвращает расширение файла для формата
+func Extension(format string) string {
+	switch format {
+	case FORMAT_MARKDOWN:
+		return ".md"
+	case FORMAT_HTML:
+		return ".html"
+	default:
+		return ".jsonl"
+	}
+}
+
+// Write записывает диалоги в указанном формате
+func Write(w io.Writer, conversations []Conversation, format string) error {
+	switch format {
+	case FORMAT_MARKDOWN:
+		return writeMarkdown(w, conversations)
+	case FORMAT_HTML:
+		return writeHTML(w, conversations)
+	case FORMAT_CHATML:
+		return writeChatML(w, conversations)
+	case FORMAT_SHAREGPT:
+		return writeShareGPT(w, conversations)
+	default:
+		return i18n.NewError("export.unknown_format", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// roleTitle возвращает название роли для стенограмм
+func roleTitle(role string) string {
+	switch role {
+	case "user":
+		return i18n.T("export.role_user")
+	case "assistant":
+		return i18n.T("export.role_assistant")
+	case "system":
+		return i18n.T("export.role_system")
+	default:
+		return role
+	}
+}
+
+// executionStatus возвращает краткое описание результата выполнения кода
+func executionStatus(record model.ExecutionRecord) string {
+	if record.Success {
+		return i18n.T("export.status_success", record.ExitCode)
+	}
+	return i18n.T("export.status_failure", record.ExitCode)
+}
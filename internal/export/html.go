@@ -0,0 +1,74 @@
+package export
+
+import (
+	"html/template"
+	"io"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// htmlConversation содержит данные одного диалога для HTML шаблона
+type htmlConversation struct {
+	Name     string
+	Context  *model.Context
+	Messages []model.Message
+}
+
+// htmlTemplate задает самодостаточную страницу без внешних ресурсов
+var htmlTemplate = template.Must(template.New("export").Funcs(template.FuncMap{
+	"roleTitle":       roleTitle,
+	"executionStatus": executionStatus,
+	"t":               i18n.T,
+	"lang":            func() string { return i18n.Default().Lang() },
+}).Parse(`<!DOCTYPE html>
+<html lang="{{lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{t "export.html_title"}}</title>
+<style>
+body { font-family: sans-serif; max-width: 860px; margin: 2em auto; padding: 0 1em; color: #222; }
+.meta { color: #777; font-size: 0.9em; }
+.message { border-radius: 6px; padding: 0.6em 1em; margin: 1em 0; }
+.user { background: #eef4ff; }
+.assistant { background: #f4f4f4; }
+.system { background: #fff8e1; font-style: italic; }
+.role { font-weight: bold; }
+.content { white-space: pre-wrap; }
+.execution { border-left: 3px solid #999; margin: 0.8em 0; padding-left: 0.8em; }
+.execution.failed { border-color: #c62828; }
+pre { background: #272822; color: #f8f8f2; padding: 0.6em; overflow-x: auto; }
+</style>
+</head>
+<body>
+{{range $i, $conv := .}}{{if $i}}<hr>{{end}}
+<h1>{{t "export.session_title" $conv.Name}}</h1>
+<p class="meta">ID: {{$conv.Context.SessionID}} &middot; {{t "export.created"}} {{$conv.Context.Metadata.CreatedAt.Format "2006-01-02 15:04"}} &middot; {{t "export.updated"}} {{$conv.Context.Metadata.UpdatedAt.Format "2006-01-02 15:04"}}</p>
+{{range $conv.Messages}}<div class="message {{.Role}}">
+<div><span class="role">{{roleTitle .Role}}</span> <span class="meta">{{.Timestamp.Format "2006-01-02 15:04:05"}}</span></div>
+<div class="content">{{.Content}}</div>
+{{range .Executions}}<div class="execution{{if not .Success}} failed{{end}}">
+<div>{{t "export.execution" .Language (executionStatus .)}}</div>
+{{if .Code}}<pre>{{.Code}}</pre>{{end}}
+{{if .Output}}<div>{{t "export.output"}}</div><pre>{{.Output}}</pre>{{end}}
+{{if .Error}}<div>{{t "export.errors"}}</div><pre>{{.Error}}</pre>{{end}}
+</div>
+{{end}}</div>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// writeHTML записывает диалоги в виде HTML страницы
+func writeHTML(w io.Writer, conversations []Conversation) error {
+	data := make([]htmlConversation, 0, len(conversations))
+	for _, conv := range conversations {
+		data = append(data, htmlConversation{
+			Name:     conv.Name,
+			Context:  conv.Context,
+			Messages: conv.Context.ActiveBranch(),
+		})
+	}
+
+	return htmlTemplate.Execute(w, data)
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"smollm-sandbox/internal/model"
+)
+
+// Разметка шаблона чата SmolLM2
+const (
+	CHATML_START = "<|im_start|>"
+	CHATML_END   = "<|im_end|>"
+)
+
+// ChatMLSample представляет строку JSONL в формате ChatML
+type ChatMLSample struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// ShareGPTSample представляет строку JSONL в формате ShareGPT
+type ShareGPTSample struct {
+	ID            string         `json:"id"`
+	Conversations []ShareGPTTurn `json:"conversations"`
+}
+
+// ShareGPTTurn представляет одну реплику в формате ShareGPT
+type ShareGPTTurn struct {
+	From  string `json:"from"` // "system", "human" или "gpt"
+	Value string `json:"value"`
+}
+
+// writeChatML записывает диалоги как JSONL с текстом в шаблоне ChatML
+func writeChatML(w io.Writer, conversations []Conversation) error {
+	return writeJSONL(w, conversations, func(conv Conversation, messages []model.Message) any {
+		var sb strings.Builder
+		for _, msg := range messages {
+			sb.WriteString(CHATML_START + msg.Role + "\n" + msg.Content + CHATML_END + "\n")
+		}
+		return ChatMLSample{ID: conv.Context.SessionID, Text: sb.String()}
+	})
+}
+
+// writeShareGPT записывает диалоги как JSONL в формате ShareGPT
+func writeShareGPT(w io.Writer, conversations []Conversation) error {
+	roles := map[string]string{"system": "system", "user": "human", "assistant": "gpt"}
+
+	return writeJSONL(w, conversations, func(conv Conversation, messages []model.Message) any {
+		sample := ShareGPTSample{ID: conv.Context.SessionID}
+		for _, msg := range messages {
+			sample.Conversations = append(sample.Conversations, ShareGPTTurn{
+				From:  roles[msg.Role],
+				Value: msg.Content,
+			})
+		}
+		return sample
+	})
+}
+
+// writeJSONL записывает по одной строке на диалог. Диалоги без ответов
+// ассистента пропускаются, так как для обучения они бесполезны
+func writeJSONL(w io.Writer, conversations []Conversation, sample func(Conversation, []model.Message) any) error {
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+
+	for _, conv := range conversations {
+		messages := trainingMessages(conv.Context)
+		if messages == nil {
+			continue
+		}
+		if err := encoder.Encode(sample(conv, messages)); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
+
+// trainingMessages возвращает сообщения активной ветки, обрезанные по
+// последнему ответу ассистента, или nil, если ответов нет
+func trainingMessages(ctx *model.Context) []model.Message {
+	branch := ctx.ActiveBranch()
+
+	last := -1
+	for i, msg := range branch {
+		if msg.Role == "assistant" {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil
+	}
+
+	return branch[:last+1]
+}
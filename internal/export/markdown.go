@@ -0,0 +1,68 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// writeMarkdown записывает диалоги в виде Markdown стенограммы
+func writeMarkdown(w io.Writer, conversations []Conversation) error {
+	out := bufio.NewWriter(w)
+
+	for i, conv := range conversations {
+		if i > 0 {
+			out.WriteString("\n---\n\n")
+		}
+
+		ctx := conv.Context
+		fmt.Fprintf(out, "# %s\n\n", i18n.T("export.session_title", conv.Name))
+		fmt.Fprintf(out, "- ID: `%s`\n", ctx.SessionID)
+		fmt.Fprintf(out, "- %s: %s\n", i18n.T("export.created"), ctx.Metadata.CreatedAt.Format("2006-01-02 15:04"))
+		fmt.Fprintf(out, "- %s: %s\n\n", i18n.T("export.updated"), ctx.Metadata.UpdatedAt.Format("2006-01-02 15:04"))
+
+		for _, msg := range ctx.ActiveBranch() {
+			fmt.Fprintf(out, "## %s\n\n", roleTitle(msg.Role))
+			fmt.Fprintf(out, "_%s_\n\n", msg.Timestamp.Format("2006-01-02 15:04:05"))
+
+			if msg.Role == "system" {
+				out.WriteString(quoteMarkdown(msg.Content) + "\n\n")
+			} else {
+				out.WriteString(strings.TrimRight(msg.Content, "\n") + "\n\n")
+			}
+
+			for _, record := range msg.Executions {
+				writeMarkdownExecution(out, record)
+			}
+		}
+	}
+
+	return out.Flush()
+}
+
+// writeMarkdownExecution записывает результат выполнения кода
+func writeMarkdownExecution(out *bufio.Writer, record model.ExecutionRecord) {
+	fmt.Fprintf(out, "**%s**\n\n", i18n.T("export.execution", record.Language, executionStatus(record)))
+	if record.Code != "" {
+		fmt.Fprintf(out, "```%s\n%s\n```\n\n", record.Language, strings.TrimRight(record.Code, "\n"))
+	}
+	if record.Output != "" {
+		fmt.Fprintf(out, "%s\n\n```text\n%s\n```\n\n", i18n.T("export.output"), strings.TrimRight(record.Output, "\n"))
+	}
+	if record.Error != "" {
+		fmt.Fprintf(out, "%s\n\n```text\n%s\n```\n\n", i18n.T("export.errors"), strings.TrimRight(record.Error, "\n"))
+	}
+}
+
+// quoteMarkdown оформляет текст как цитату
+func quoteMarkdown(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "> " + line
+	}
+	return strings.Join(lines, "\n")
+}
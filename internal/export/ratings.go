@@ -0,0 +1,44 @@
+package export
+
+import (
+	"smollm-sandbox/internal/feedback"
+)
+
+// SessionRatings возвращает среднюю оценку обратной связи по каждой сессии.
+// Учитываются только элементы, в метаданных которых указан session_id
+func SessionRatings(items []feedback.FeedbackItem) map[string]float64 {
+	sums := make(map[string]int)
+	counts := make(map[string]int)
+
+	for _, item := range items {
+		sessionID, ok := item.MetadataString(feedback.META_SESSION_ID)
+		if !ok {
+			continue
+		}
+		sums[sessionID] += item.Rating
+		counts[sessionID]++
+	}
+
+	ratings := make(map[string]float64, len(sums))
+	for sessionID, sum := range sums {
+		ratings[sessionID] = float64(sum) / float64(counts[sessionID])
+	}
+
+	return ratings
+}
+
+// FilterByRating оставляет диалоги со средней оценкой не ниже minRating.
+// Диалоги без оценок отбрасываются
+func FilterByRating(conversations []Conversation, items []feedback.FeedbackItem, minRating int) []Conversation {
+	ratings := SessionRatings(items)
+
+	var filtered []Conversation
+	for _, conv := range conversations {
+		rating, ok := ratings[conv.Context.SessionID]
+		if ok && rating >= float64(minRating) {
+			filtered = append(filtered, conv)
+		}
+	}
+
+	return filtered
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 )
 
@@ -32,6 +33,23 @@ type FeedbackItem struct {
 	Metadata  interface{}  `json:"metadata,omitempty"`
 }
 
+// Ключи метаданных, связывающие обратную связь с диалогом
+const (
+	META_SESSION_ID = "session_id"
+	META_MESSAGE_ID = "message_id"
+)
+
+// MetadataString возвращает строковое значение из метаданных элемента
+func (f FeedbackItem) MetadataString(key string) (string, bool) {
+	metadata, ok := f.Metadata.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	value, ok := metadata[key].(string)
+	return value, ok && value != ""
+}
+
 // Collector обеспечивает сбор и сохранение обратной связи
 type Collector struct {
 	logger      *logging.Logger
@@ -63,7 +81,7 @@ func (c *Collector) AddFeedback(feedbackType FeedbackType, content string, ratin
 
 	// Проверяем рейтинг
 	if rating < 1 || rating > 5 {
-		return "", fmt.Errorf("рейтинг должен быть от 1 до 5")
+		return "", i18n.NewError("feedback.invalid_rating").WithKind(ErrInvalidRating)
 	}
 
 	// Создаем ID для обратной связи
@@ -102,12 +120,12 @@ func (c *Collector) saveFeedback(item FeedbackItem) error {
 	// Сериализуем в JSON
 	data, err := json.MarshalIndent(item, "", "  ")
 	if err != nil {
-		return fmt.Errorf("ошибка сериализации в JSON: %v", err)
+		return i18n.WrapError(err, "feedback.encode")
 	}
 
 	// Записываем в файл
 	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("ошибка записи в файл: %v", err)
+		return i18n.WrapError(err, "feedback.write")
 	}
 
 	return nil
@@ -165,7 +183,7 @@ func (c *Collector) LoadFeedbackFromDisk() error {
 	// Читаем файлы из директории
 	files, err := os.ReadDir(c.feedbackDir)
 	if err != nil {
-		return fmt.Errorf("ошибка чтения директории: %v", err)
+		return i18n.WrapError(err, "feedback.dir_read")
 	}
 
 	// Обрабатываем каждый файл
@@ -0,0 +1,8 @@
+package feedback
+
+import "errors"
+
+// Виды ошибок обратной связи
+var (
+	ErrInvalidRating = errors.New("feedback: invalid rating")
+)
@@ -0,0 +1,503 @@
+package i18n
+
+// catalogEN содержит сообщения интерфейса на английском языке
+var catalogEN = map[string]string{
+	// Хранилище
+	"storage.backup_failed":             "failed to save backup copy",
+	"storage.cache_dir_create":          "failed to create response cache directory",
+	"storage.cache_encode":              "failed to encode response cache entry",
+	"storage.cache_read":                "failed to read response cache directory",
+	"storage.cache_write":               "failed to write response cache entry",
+	"storage.context_decode":            "failed to decode context",
+	"storage.context_encode":            "failed to encode context",
+	"storage.copy_dir_unsupported":      "copying directories is not supported",
+	"storage.dir_not_found":             "directory does not exist: %s",
+	"storage.invalid_session_name":      "invalid session name",
+	"storage.invalid_session_name_hint": "invalid session name (only letters, digits, hyphens and underscores are allowed)",
+	"storage.invalid_user_id":           "invalid user id: %s",
+	"storage.lock_acquire":              "failed to acquire lock",
+	"storage.lock_open":                 "failed to open lock file",
+	"storage.memory_decode":             "failed to parse user memory",
+	"storage.memory_empty_fact":         "empty fact",
+	"storage.memory_encode":             "failed to encode memory",
+	"storage.memory_fact_not_found":     "there is no fact number %d",
+	"storage.message_malformed":         "message %d is malformed",
+	"storage.migration_failed":          "failed to migrate session from version %d",
+	"storage.migration_missing":         "no session migration from version %d",
+	"storage.path_outside_root":         "path is outside the allowed directory",
+	"storage.session_conflict":          "session %s was changed by another process, load it again",
+	"storage.session_decode":            "failed to parse session",
+	"storage.session_encode":            "failed to encode session",
+	"storage.session_exists":            "session already exists: %s",
+	"storage.session_file_delete":       "failed to delete session file",
+	"storage.session_file_read":         "failed to read session file",
+	"storage.session_file_rename":       "failed to rename session file",
+	"storage.session_file_write":        "failed to write session file",
+	"storage.session_not_found":         "session not found: %s",
+	"storage.session_read":              "failed to read session",
+	"storage.session_too_new":           "session was saved by a newer format version (%d > %d)",
+	"storage.session_write":             "failed to write session",
+	"storage.sessions_dir_read":         "failed to read sessions directory",
+	"storage.user_dir_create":           "failed to create user directory",
+
+	// Песочница
+	"sandbox.cleanup_failed":          "cleanup errors: %s",
+	"sandbox.compile":                 "compilation error",
+	"sandbox.compile_error_c":         "C compilation error",
+	"sandbox.compile_error_cpp":       "C++ compilation error",
+	"sandbox.compile_error_go":        "Go compilation error",
+	"sandbox.compile_exit_code":       "compilation error (code %d): %s",
+	"sandbox.compile_failed":          "compiler exited with code %d",
+	"sandbox.compile_known_error":     "%s (code %d): %s",
+	"sandbox.compile_no_output":       "compilation produced no output file",
+	"sandbox.compile_timeout":         "compilation time limit exceeded (%d seconds)",
+	"sandbox.compiler_start":          "failed to start compiler",
+	"sandbox.dir_read":                "failed to read directory",
+	"sandbox.file_copy":               "failed to copy file",
+	"sandbox.file_not_found":          "file does not exist: %s",
+	"sandbox.file_remove":             "failed to remove %s",
+	"sandbox.file_too_large":          "file size limit exceeded: %d bytes (maximum %d)",
+	"sandbox.output_too_large":        "output size limit exceeded (%d bytes)",
+	"sandbox.process_start":           "failed to start process",
+	"sandbox.result_compile_error":    "Compilation error:",
+	"sandbox.result_compile_time":     "Compilation time: %v",
+	"sandbox.result_program_output":   "Program output:",
+	"sandbox.result_runtime_error":    "Execution error (code %d):",
+	"sandbox.result_success":          "Execution completed successfully in %v",
+	"sandbox.syntax_error_bash":       "Bash syntax error",
+	"sandbox.syntax_error_javascript": "JavaScript syntax error",
+	"sandbox.syntax_error_python":     "Python syntax error",
+	"sandbox.temp_file_write":         "failed to write temporary file",
+	"sandbox.timeout":                 "execution time limit exceeded (%d seconds)",
+	"sandbox.unsupported_file_type":   "unsupported file type: %s",
+	"sandbox.unsupported_language":    "unsupported language: %s",
+
+	// Модель
+	"model.api_status":               "API error (code %d): %s",
+	"model.branch_out_of_range":      "branch number must be between 1 and %d",
+	"model.complete_no_user":         "the last message must be from the user",
+	"model.complete_role":            "unknown message role: %s",
+	"model.error_apology":            "Sorry, an error occurred while processing your request. Please try again.",
+	"model.generation_range":         "parameter %s must be in range %s",
+	"model.generation_timeout":       "generation timed out",
+	"model.generation_unknown":       "unknown generation parameter: %s",
+	"model.generation_value":         "invalid value for parameter %s: %q",
+	"model.generation_value_missing": "parameter %s takes exactly one value",
+	"model.invalid_profile_name":     "invalid profile name: %q",
+	"model.local_failed":             "local generation failed",
+	"model.local_unavailable":        "local generation is unavailable",
+	"model.message_not_found":        "message not found: %s",
+	"model.message_out_of_range":     "message number must be between 1 and %d",
+	"model.native_failed":            "built-in engine generation failed",
+	"model.native_unavailable":       "built-in engine is unavailable",
+	"model.no_reply_to_regenerate":   "there is no assistant reply to regenerate",
+	"model.not_user_message":         "message %d is not a user message",
+	"model.profile_code_denied":      "profile %s does not allow code execution",
+	"model.profile_max_tokens":       "profile %s: max_tokens cannot be negative",
+	"model.profile_not_found":        "profile not found: %s",
+	"model.profile_temperature":      "profile %s: temperature must be between 0 and 2",
+	"model.profile_top_p":            "profile %s: top_p must be between 0 and 1",
+	"model.profile_unknown_tool":     "profile %s: unknown tool %s",
+	"model.queue_full":               "request queue is full (%d), try again later",
+	"model.reply_without_prompt":     "assistant reply is not linked to a user message",
+	"model.request_canceled":         "request canceled",
+	"model.request_create":           "failed to create HTTP request",
+	"model.request_encode":           "failed to encode request",
+	"model.request_failed":           "HTTP request failed",
+	"model.response_decode":          "failed to parse JSON",
+	"model.response_read":            "failed to read response",
+	"model.server_not_ready":         "model server is not ready (state: %s)",
+	"model.store_not_set":            "session store is not set",
+	"model.thought_journal_create":   "failed to create thought journal %s",
+	"model.thought_journal_end":      "## Thinking finished",
+	"model.thought_journal_error":    "Thinking mode error: %s",
+	"model.thought_journal_params":   "## Parameters",
+	"model.thought_journal_start":    "## Thinking started",
+	"model.thought_journal_title":    "# SmolLM2 Thoughts - %s",
+	"model.thought_record_decode":    "failed to parse thought record %s",
+	"model.thought_record_read":      "failed to read thought record %s",
+	"model.thought_record_write":     "failed to write thought record %s",
+
+	// Поиск
+	"search.empty_query":      "empty search query",
+	"search.feedback_session": ", session %s",
+	"search.feedback_title":   "%s, rating %d",
+	"search.index_decode":     "failed to parse the search index",
+	"search.index_encode":     "failed to encode the search index",
+	"search.index_read":       "failed to read index",
+	"search.index_version":    "unsupported search index version: %d",
+	"search.index_write":      "failed to write index",
+	"search.invalid_since":    "invalid time format: %s (examples: 12h, 7d, 2w, 2025-01-31)",
+	"search.thought_read":     "failed to read thought journal %s",
+	"search.thoughts_read":    "failed to read the thoughts directory",
+
+	// Экспорт
+	"export.created":        "Created",
+	"export.errors":         "Errors:",
+	"export.execution":      "Code execution (%s): %s",
+	"export.html_title":     "SmolLM Sandbox - conversation export",
+	"export.output":         "Output:",
+	"export.role_assistant": "Assistant",
+	"export.role_system":    "System prompt",
+	"export.role_user":      "User",
+	"export.session_title":  "Session: %s",
+	"export.status_failure": "failed, exit code %d",
+	"export.status_success": "success, exit code %d",
+	"export.unknown_format": "unknown export format: %s (available: %s)",
+	"export.updated":        "Updated",
+
+	// Обратная связь
+	"feedback.dir_read":       "directory read error",
+	"feedback.encode":         "JSON encoding error",
+	"feedback.invalid_rating": "rating must be between 1 and 5",
+	"feedback.write":          "file write error",
+
+	// CLI
+	"cli.batch_done":               "Batch processing finished: processed %d, skipped %d, failed %d",
+	"cli.batch_duplicate_id":       "line %d: duplicate id %s",
+	"cli.batch_flag_in":            "Input JSONL file with requests",
+	"cli.batch_flag_out":           "Output JSONL file with results",
+	"cli.batch_flag_profile":       "Model profile (persona) for all requests",
+	"cli.batch_flag_resume":        "Resume interrupted processing, skipping completed requests",
+	"cli.batch_flag_run_code":      "Run code blocks from replies in the sandbox",
+	"cli.batch_flag_seed":          "Seed for requests without their own seed; 0 picks a random one (the chosen seed is recorded in the result)",
+	"cli.batch_input_read_failed":  "Failed to read input file: %v",
+	"cli.batch_line":               "line %d",
+	"cli.batch_no_prompt":          "line %d: prompt is missing",
+	"cli.batch_output_open_failed": "Failed to open output file: %v",
+	"cli.batch_output_read_failed": "Failed to read output file: %v",
+	"cli.batch_profile_failed":     "Failed to select profile: %v",
+	"cli.batch_progress":           "[%d/%d] Processing request %s...",
+	"cli.batch_saved":              "Results were written to: %s",
+	"cli.batch_usage":              "Usage: smollm-cli batch --in prompts.jsonl --out results.jsonl [--resume] [--run-code] [--seed N]",
+	"cli.batch_write_failed":       "Failed to write result: %v",
+	"cli.branch_active":            "Branch %d is active",
+	"cli.branch_empty":             "The current branch has no messages",
+	"cli.branch_failed":            "Failed to switch branch",
+	"cli.branch_hint":              "Use /branch N to switch",
+	"cli.branch_line":              "%s %d. [%d msgs] %s",
+	"cli.code_unavailable":         "Code execution is unavailable",
+	"cli.command_parse_failed":     "Failed to parse command",
+	"cli.edit_empty":               "Message text cannot be empty",
+	"cli.edit_failed":              "Failed to edit message",
+	"cli.enter_code":               "Enter code, finish with %s",
+	"cli.enter_edit":               "Enter the new text of message %d, finish with %s",
+	"cli.enter_paste":              "Paste text, finish with %s",
+	"cli.error":                    "Error: %v",
+	"cli.example_batch":            "Batch processing",
+	"cli.example_input_file":       "Process a file",
+	"cli.example_input_text":       "Process text",
+	"cli.example_interactive":      "Start interactive mode",
+	"cli.example_json":             "Machine-readable JSON output",
+	"cli.example_lang":             "English interface",
+	"cli.example_profile":          "Session with the coder profile",
+	"cli.example_prompt":           "Write a simple Python script",
+	"cli.example_rag":              "Index the documents folder",
+	"cli.example_sessions":         "List saved sessions",
+	"cli.example_thought":          "Run thinking mode for 5 minutes",
+	"cli.example_thoughts_replay":  "Replay a thinking run and compare it with the original",
+	"cli.examples_header":          "Examples:",
+	"cli.execution_failed":         "Execution failed: %v",
+	"cli.export_done":              "Exported conversations: %d, file: %s",
+	"cli.export_failed":            "Export failed",
+	"cli.export_failed_with":       "Export failed: %v",
+	"cli.export_flag_all":          "Export all saved sessions",
+	"cli.export_flag_format":       "Format: %s",
+	"cli.export_flag_min_rating":   "Only sessions with an average feedback rating of at least this value (1-5)",
+	"cli.export_flag_out":          "Output file (stdout by default)",
+	"cli.export_json_needs_out":    "--out is required with --output json",
+	"cli.export_saved":             "Conversation exported to: %s",
+	"cli.export_session":           "session %s",
+	"cli.export_usage":             "Usage: smollm-cli export [flags] SESSION... | --all",
+	"cli.feedback_load_failed":     "failed to load feedback",
+	"cli.file_read_failed":         "Error: failed to read file",
+	"cli.flag_config":              "Path to the configuration file",
+	"cli.flag_input":               "Input text or file",
+	"cli.flag_interactive":         "Interactive mode",
+	"cli.flag_lang":                "Interface language: %s; defaults to the configuration",
+	"cli.flag_output":              "Output format: text or json",
+	"cli.flag_profile":             "Model profile (persona); defaults to the configuration",
+	"cli.flag_report_output":       "Summary report format: text or json",
+	"cli.flag_seed":                "Thinking mode seed for exact replay; 0 uses the configured seed",
+	"cli.flag_session":             "Session name (to save/load)",
+	"cli.flag_thought":             "Thinking mode (no user input)",
+	"cli.flag_thought_time":        "Thinking time in seconds",
+	"cli.flag_version":             "Print version and exit",
+	"cli.forget_failed":            "Failed to delete fact",
+	"cli.forget_not_number":        "Fact number must be a number: /memory forget N|all",
+	"cli.forgotten":                "Forgotten: %s",
+	"cli.generation_hint":          "Use /set NAME VALUE to change a parameter, /set stop \"\\n###\" ... for stop sequences; /set reset restores the defaults",
+	"cli.generation_reset":         "Session generation parameters reset",
+	"cli.generation_set":           "Parameter %s changed for the current session",
+	"cli.generation_set_failed":    "Failed to change parameter",
+	"cli.help_branch":              "Show conversation branches or switch to branch N",
+	"cli.help_code":                "Run a line of code (without code - multi-line input)",
+	"cli.help_edit":                "Edit message N and get a new reply (no arguments lists messages)",
+	"cli.help_exit":                "Exit the program",
+	"cli.help_export":              "Export the current conversation (markdown, html, chatml, sharegpt)",
+	"cli.help_help":                "Show this help",
+	"cli.help_hint":                "Type /help for the list of commands",
+	"cli.help_load":                "Load a saved session",
+	"cli.help_memory":              "Show or delete long-term memory facts",
+	"cli.help_paste":               "Multi-line request input until %s",
+	"cli.help_profile":             "Show profiles or switch the current session profile",
+	"cli.help_remember":            "Remember a fact about yourself for future sessions",
+	"cli.help_retry":               "Regenerate the last reply",
+	"cli.help_run":                 "Run a file in the sandbox",
+	"cli.help_save":                "Save the current session",
+	"cli.help_search":              "Search sessions, thoughts and feedback",
+	"cli.help_sessions":            "Manage saved sessions",
+	"cli.help_set":                 "Show generation parameters or change them for the current session",
+	"cli.help_title":               "Available commands:",
+	"cli.interactive_exit":         "Type 'exit' to quit",
+	"cli.interactive_hint":         "Type a message for the model or a command (/help lists commands)",
+	"cli.interactive_multiline":    "Multi-line input: start a line with ``` or use /paste (finish with %s)",
+	"cli.interactive_title":        "SmolLM Sandbox v%s interactive mode",
+	"cli.memory_by_model":          " (remembered by the model)",
+	"cli.memory_clear_failed":      "Failed to clear memory",
+	"cli.memory_cleared":           "Memory cleared",
+	"cli.memory_empty":             "Memory is empty. Add a fact: /remember text",
+	"cli.memory_open_failed":       "failed to open memory storage",
+	"cli.memory_read_failed":       "Failed to read memory: %v",
+	"cli.memory_title":             "What I remember about you:",
+	"cli.memory_unavailable":       "Memory is unavailable",
+	"cli.memory_unknown_action":    "Unknown action: %s (available: list, forget)",
+	"cli.migrate_dry_run_summary":  "Need updating: %d, failed: %d (files unchanged)",
+	"cli.migrate_flag_dry_run":     "Only show which sessions would be updated",
+	"cli.migrate_header":           "NAME\tVERSION\tSTATUS",
+	"cli.migrate_status_current":   "up to date",
+	"cli.migrate_status_done":      "updated, backup: %s",
+	"cli.migrate_status_error":     "error: %s",
+	"cli.migrate_status_pending":   "will be updated",
+	"cli.migrate_summary":          "Updated: %d, failed: %d",
+	"cli.model_unavailable":        "The model is unavailable: check the model files or the model server and try again.",
+	"cli.paste_interactive_only":   "The /paste command is only available in interactive mode",
+	"cli.processing":               "Processing the request... Please wait.",
+	"cli.processing_file":          "Processing file contents...",
+	"cli.processing_text":          "Processing text...",
+	"cli.profile_code_denied":      "Profile %s does not allow code execution",
+	"cli.profile_hint":             "Use /profile NAME to switch",
+	"cli.profile_select_failed":    "Failed to select profile",
+	"cli.profile_selected":         "Session profile: %s",
+	"cli.profiles_header":          "\tPROFILE\tTEMPERATURE\tLANGUAGE\tDESCRIPTION",
+	"cli.rag_method_embeddings":    "embeddings",
+	"cli.rag_no_passages":          "No matching passages found",
+	"cli.rag_report":               "Documents: %s — %d files, %d chunks (%d updated, %d removed), search: %s",
+	"cli.rag_sources":              "Sources:",
+	"cli.rag_unknown_action":       "unknown action: %s (available: ingest, query)",
+	"cli.rag_usage":                "Usage: smollm-cli rag [flags] ingest|query \"query\"",
+	"cli.raw_mode_failed":          "failed to switch the terminal to raw mode",
+	"cli.regenerating":             "Regenerating the reply... Please wait.",
+	"cli.remember_failed":          "Failed to save fact",
+	"cli.remembered":               "Remembered: %s",
+	"cli.remembered_inline":        "(Remembered: %s)",
+	"cli.response_failed":          "Sorry, an error occurred while processing the request. Please try again.",
+	"cli.retry_failed":             "Failed to regenerate the reply",
+	"cli.search_failed":            "Search failed: %v",
+	"cli.search_flag_limit":        "Maximum number of results",
+	"cli.search_flag_rebuild":      "Rebuild the index before searching",
+	"cli.search_flag_since":        "Only documents newer than this (12h, 7d, 2w or 2025-01-31)",
+	"cli.search_flag_type":         "Search only documents of type session, thought or feedback",
+	"cli.search_nothing":           "Nothing found",
+	"cli.search_rebuild_failed":    "Failed to build the index: %v",
+	"cli.search_rebuilding":        "Building the search index...",
+	"cli.search_unknown_type":      "Unknown document type: %s",
+	"cli.search_usage":             "Usage: smollm-cli search \"query\" [--type session|thought|feedback] [--since 7d] [--limit N] [--rebuild]",
+	"cli.session_deleted":          "Session deleted: %s",
+	"cli.session_detail_branches":  "Branches: %d (showing the active one)",
+	"cli.session_detail_created":   "Created:  %s",
+	"cli.session_detail_format":    "Format:   v%d",
+	"cli.session_detail_id":        "ID:       %s",
+	"cli.session_detail_messages":  "Messages: %d",
+	"cli.session_detail_name":      "Session:  %s",
+	"cli.session_detail_updated":   "Updated:  %s",
+	"cli.session_exported":         "Session '%s' exported to %s",
+	"cli.session_forked":           "Created a copy of session '%s': %s",
+	"cli.session_imported":         "Session imported as: %s",
+	"cli.session_load_failed":      "Failed to load session",
+	"cli.session_loaded":           "Session '%s' loaded successfully",
+	"cli.session_renamed":          "Session '%s' renamed to '%s'",
+	"cli.session_save_failed":      "Failed to save session",
+	"cli.session_saved":            "Session saved as: %s",
+	"cli.sessions_empty":           "No saved sessions",
+	"cli.sessions_header":          "NAME\tMESSAGES\tCREATED\tUPDATED",
+	"cli.sessions_unknown_action":  "unknown action: %s (available: list, show, delete, rename, export, import, fork, migrate)",
+	"cli.sessions_usage":           "Usage: smollm-cli sessions [flags] list|show|delete|rename|export|import|fork|migrate [arguments]",
+	"cli.shutdown":                 "Shutdown signal received. Releasing resources...",
+	"cli.thought_done":             "Thinking mode finished!",
+	"cli.thought_saved":            "Thoughts were written to: %s",
+	"cli.thought_seed":             "Seed: %d, replay record: %s",
+	"cli.thought_start":            "Starting thinking mode for %d seconds",
+	"cli.thought_target":           "Thoughts will be saved to: %s",
+	"cli.thought_wait":             "Starting thinking mode. This will take a while...",
+	"cli.thoughts_backend":         "Warning: the run used backend %s, replaying with %s; thoughts may differ",
+	"cli.thoughts_different":       "The replay differs from the original run",
+	"cli.thoughts_identical":       "The replay matches the original run",
+	"cli.thoughts_replaying":       "Replaying %d thoughts with seed %d...",
+	"cli.thoughts_step_differs":    "[%d] differs:",
+	"cli.thoughts_step_equal":      "[%d] identical",
+	"cli.thoughts_usage":           "Usage: smollm-cli thoughts [flags] replay FILE (.txt journal or .json record)",
+	"cli.unclosed_quote":           "unclosed quote %c",
+	"cli.unknown_command":          "Unknown command: %s",
+	"cli.unknown_output_format":    "unknown output format: %s (available: %s, %s)",
+	"cli.usage_branch":             "Specify a branch number: /branch N",
+	"cli.usage_code":               "Specify a language and code: /code [python|js|go] \"code to run\"",
+	"cli.usage_edit":               "Specify a message number: /edit N [text]",
+	"cli.usage_edit_text":          "Specify a number and new text: /edit N text",
+	"cli.usage_export":             "Specify a format: /export [%s] [file]",
+	"cli.usage_forget":             "Specify a fact number: /memory forget N|all",
+	"cli.usage_header":             "Usage:",
+	"cli.usage_load":               "Specify a session name: /load session_name",
+	"cli.usage_remember":           "Specify a fact: /remember text",
+	"cli.usage_run":                "Specify a file to run: /run filename",
+	"cli.usage_save":               "Specify a session name: /save session_name",
+	"cli.usage_search":             "Specify a query: /search query",
+	"cli.usage_sessions_delete":    "specify a session name: sessions delete NAME",
+	"cli.usage_sessions_export":    "specify a session name: sessions export NAME [FILE]",
+	"cli.usage_sessions_fork":      "specify the source and new sessions: sessions fork SOURCE TARGET",
+	"cli.usage_sessions_import":    "specify a file: sessions import FILE [NAME]",
+	"cli.usage_sessions_rename":    "specify the old and new names: sessions rename OLD NEW",
+	"cli.usage_sessions_show":      "specify a session name: sessions show NAME",
+	"cli.usage_title":              "SmolLM Sandbox - a sandbox for the SmolLM2 model",
+
+	// Telegram бот
+	"tg.branch_failed":   "Failed to switch branch: %s",
+	"tg.edit_failed":     "Failed to edit the message: %s",
+	"tg.export_failed":   "Failed to export the conversation.",
+	"tg.feedback_failed": "Failed to save feedback.",
+	"tg.feedback_saved":  "Thank you for the feedback! ID: %s",
+	"tg.flag_token":      "Telegram bot token",
+	"tg.help": `
+Bot commands:
+/start - Start a conversation
+/help - Show help
+/think [time] - Start thinking mode
+/retry - Regenerate the last reply
+/edit [N] [text] - Edit message N and get a new reply (no arguments lists messages)
+/branch [N] - Show conversation branches or switch to branch N
+/search [query] - Search this chat's conversation (admins search all data)
+/profile [name] - Show profiles or choose the model profile
+/memory [forget N|forget all] - Show or delete what the bot remembers about you
+/remember [text] - Remember a fact about yourself for future conversations
+/export [format] - Get a file with the conversation (markdown, html, chatml, sharegpt)
+/run - Run code (send the code in the next message)
+/status - Show bot status
+/feedback [rating] [comment] - Send feedback
+`,
+	"tg.invalid_rating":      "Rating must be between 1 and 5.",
+	"tg.memory_empty":        "I don't remember anything about you yet. Add a fact: /remember text",
+	"tg.memory_forget_hint":  "Delete a fact: /memory forget N",
+	"tg.memory_unavailable":  "Memory is unavailable: %s",
+	"tg.no_token":            "Telegram bot token is not set",
+	"tg.profile_failed":      "Failed to select profile: %s",
+	"tg.profile_hint":        "Use /profile name to switch",
+	"tg.profiles_title":      "Model profiles:",
+	"tg.queued":              "Your request is queued, position: %d",
+	"tg.retry_failed":        "Failed to regenerate the reply: %s",
+	"tg.run_prompt":          "Send me the code to run in the next message.",
+	"tg.session_unavailable": "Failed to load the chat conversation: %s",
+	"tg.start":               "Hi! I am the SmolLM bot v%s. Write me something and I will reply.",
+	"tg.status_cache":        "Response cache: %d hits, %d misses; KV prefix cache: %d hits, %d misses",
+	"tg.status_errors":       "Errors: %d",
+	"tg.status_executions":   "Code executions: %d",
+	"tg.status_memory":       "Memory usage: %.2f MB",
+	"tg.status_model":        "Model server: %s",
+	"tg.status_queue":        "Queue: %d of %d running, %d waiting",
+	"tg.status_uptime":       "Uptime: %s",
+	"tg.thinking":            "Starting thinking mode for %d seconds...",
+	"tg.thought_part":        "Part %d/%d:\n%s",
+	"tg.thought_read_failed": "Failed to read the thoughts file.",
+	"tg.unknown_command":     "Unknown command. Type /help for help.",
+	"tg.uptime":              "%d days, %d hours, %d minutes",
+	"tg.usage_edit":          "Usage: /edit N new text",
+	"tg.usage_forget":        "Usage: /memory forget N",
+	"tg.usage_memory":        "Usage: /memory [forget N|forget all]",
+	"tg.usage_remember":      "Usage: /remember a fact about yourself",
+	"tg.usage_search":        "Usage: /search query",
+
+	// Документы
+	"rag.docs_walk":     "failed to scan the documents folder %s",
+	"rag.embed_count":   "received %d embeddings instead of %d",
+	"rag.embed_decode":  "failed to parse the embeddings response",
+	"rag.embed_request": "embeddings request failed",
+	"rag.embed_status":  "embeddings server returned %d: %s",
+	"rag.index_decode":  "failed to parse the documents index",
+	"rag.index_encode":  "failed to encode the documents index",
+	"rag.index_read":    "failed to read document index",
+	"rag.index_version": "unsupported documents index version: %d",
+	"rag.index_write":   "failed to write document index",
+
+	// Сервер модели
+	"modelserver.closed":           "Python process is stopped",
+	"modelserver.dependency_check": "failed to check Python packages (%s)",
+	"modelserver.gave_up":          "Python process could not be restarted (attempts: %d)",
+	"modelserver.missing_packages": "missing Python packages: %s; install them with: %s -m pip install %s",
+	"modelserver.process_exited":   "Python process exited",
+	"modelserver.process_start":    "failed to start Python process",
+	"modelserver.python_not_found": "Python interpreter not found: %s",
+	"modelserver.script_write":     "failed to write Python script",
+	"modelserver.startup_timeout":  "Python process did not start within %v",
+	"modelserver.worker_failed":    "inference worker failed: %s",
+	"modelserver.worker_not_ready": "inference worker is not ready (state: %s)",
+	"modelserver.worker_write":     "failed to send request to inference worker",
+
+	// Встроенный движок
+	"llama.config_decode":            "invalid config.json",
+	"llama.config_read":              "failed to read model configuration in %s",
+	"llama.empty_prompt":             "empty prompt",
+	"llama.gguf_magic":               "file %s is not a GGUF file",
+	"llama.gguf_value":               "invalid value in GGUF header",
+	"llama.gguf_version":             "GGUF version %d is not supported",
+	"llama.invalid_config":           "invalid model parameters in config.json",
+	"llama.prompt_too_long":          "prompt of %d tokens does not fit the model context (%d)",
+	"llama.tensor_dtype":             "data type %s is not supported",
+	"llama.tensor_invalid":           "invalid tensor %s",
+	"llama.tensor_missing":           "tensor %s is missing from the weights",
+	"llama.tensor_offsets":           "tensor data bounds do not match its shape",
+	"llama.tensor_shape":             "tensor %s has shape %v, expected %v",
+	"llama.tokenizer_decode":         "invalid tokenizer.json",
+	"llama.tokenizer_read":           "failed to read tokenizer.json",
+	"llama.tokenizer_token":          "token %s is not in the vocabulary",
+	"llama.tokenizer_unsupported":    "tokenizer type %s is not supported, BPE is required",
+	"llama.unsupported_architecture": "model architecture %v is not supported, LlamaForCausalLM is required",
+	"llama.unsupported_rope_scaling": "RoPE scaling %s is not supported",
+	"llama.weights_corrupt":          "weights file %s is corrupted",
+	"llama.weights_not_found":        "safetensors model weights not found in %s",
+	"llama.weights_read":             "failed to read weights file %s",
+
+	// Конфигурация
+	"config.parse":         "failed to parse the configuration file",
+	"config.profile_parse": "failed to parse profile %s",
+	"config.profile_read":  "failed to read profile %s",
+	"config.profiles_read": "failed to read the profiles directory",
+	"config.read":          "failed to read the configuration file",
+
+	// gRPC сервис
+	"rpc.empty_api_key":     "empty API key in the key list",
+	"rpc.feedback_disabled": "feedback collection is disabled on the server",
+	"rpc.invalid_filename":  "a file name with an extension is required: %q",
+	"rpc.no_code":           "no code to execute",
+	"rpc.no_model":          "the service requires a model",
+	"rpc.no_prompt":         "generation in a session requires a prompt",
+	"rpc.sandbox_disabled":  "the sandbox is disabled on the server",
+	"rpc.session_messages":  "a session provides its own history: send only the prompt",
+	"rpc.temp_file":         "failed to store the file for execution",
+	"rpc.unauthorized":      "invalid or missing API key",
+
+	// API сервер
+	"server.flag_addr":             "API server address (defaults to the configuration)",
+	"server.flag_grpc_addr":        "gRPC service address (defaults to the configuration)",
+	"server.invalid_json":          "Invalid request JSON: %s",
+	"server.n_unsupported":         "Only n = 1 is supported",
+	"server.no_api_key":            "Server address %s is not loopback-only: set server.api_keys or %s",
+	"server.no_code":               "No code to execute",
+	"server.not_found":             "Endpoint not found: %s %s",
+	"server.streaming_unsupported": "Streaming is not supported",
+	"server.unauthorized":          "Invalid or missing API key",
+	"server.unsupported_content":   "Unsupported message part type: %s",
+
+	// Публичный пакет
+	"smollm.config_load":       "failed to load configuration %s",
+	"smollm.message_not_found": "session %s has no message %s",
+	"smollm.sandbox_disabled":  "the sandbox is disabled",
+}
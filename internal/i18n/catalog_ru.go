@@ -0,0 +1,503 @@
+package i18n
+
+// catalogRU содержит сообщения интерфейса на русском языке
+var catalogRU = map[string]string{
+	// Хранилище
+	"storage.backup_failed":             "ошибка сохранения резервной копии",
+	"storage.cache_dir_create":          "не удалось создать директорию кэша ответов",
+	"storage.cache_encode":              "не удалось закодировать запись кэша ответов",
+	"storage.cache_read":                "не удалось прочитать директорию кэша ответов",
+	"storage.cache_write":               "не удалось записать запись кэша ответов",
+	"storage.context_decode":            "ошибка десериализации контекста",
+	"storage.context_encode":            "ошибка сериализации контекста",
+	"storage.copy_dir_unsupported":      "копирование директорий не поддерживается",
+	"storage.dir_not_found":             "директория не существует: %s",
+	"storage.invalid_session_name":      "недопустимое имя сессии",
+	"storage.invalid_session_name_hint": "недопустимое имя сессии (разрешены только буквы, цифры, дефисы и подчеркивания)",
+	"storage.invalid_user_id":           "недопустимый идентификатор пользователя: %s",
+	"storage.lock_acquire":              "ошибка захвата блокировки",
+	"storage.lock_open":                 "ошибка открытия файла блокировки",
+	"storage.memory_decode":             "ошибка разбора памяти пользователя",
+	"storage.memory_empty_fact":         "пустой факт",
+	"storage.memory_encode":             "ошибка сериализации памяти",
+	"storage.memory_fact_not_found":     "факта с номером %d нет",
+	"storage.message_malformed":         "сообщение %d имеет неверный формат",
+	"storage.migration_failed":          "ошибка миграции сессии с версии %d",
+	"storage.migration_missing":         "нет миграции сессии с версии %d",
+	"storage.path_outside_root":         "путь находится за пределами разрешенной директории",
+	"storage.session_conflict":          "сессия %s изменена другим процессом, загрузите ее заново",
+	"storage.session_decode":            "ошибка разбора сессии",
+	"storage.session_encode":            "ошибка сериализации сессии",
+	"storage.session_exists":            "сессия уже существует: %s",
+	"storage.session_file_delete":       "ошибка удаления файла сессии",
+	"storage.session_file_read":         "ошибка чтения файла сессии",
+	"storage.session_file_rename":       "ошибка переименования файла сессии",
+	"storage.session_file_write":        "ошибка записи файла сессии",
+	"storage.session_not_found":         "сессия не найдена: %s",
+	"storage.session_read":              "ошибка чтения сессии",
+	"storage.session_too_new":           "сессия сохранена в более новой версии формата (%d > %d)",
+	"storage.session_write":             "ошибка записи сессии",
+	"storage.sessions_dir_read":         "ошибка чтения директории сессий",
+	"storage.user_dir_create":           "ошибка создания директории пользователя",
+
+	// Песочница
+	"sandbox.cleanup_failed":          "ошибки при очистке: %s",
+	"sandbox.compile":                 "ошибка компиляции",
+	"sandbox.compile_error_c":         "Ошибка компиляции C",
+	"sandbox.compile_error_cpp":       "Ошибка компиляции C++",
+	"sandbox.compile_error_go":        "Ошибка компиляции Go",
+	"sandbox.compile_exit_code":       "ошибка компиляции (код %d): %s",
+	"sandbox.compile_failed":          "компилятор завершился с кодом %d",
+	"sandbox.compile_known_error":     "%s (код %d): %s",
+	"sandbox.compile_no_output":       "компиляция не создала выходной файл",
+	"sandbox.compile_timeout":         "превышено время компиляции (%d секунд)",
+	"sandbox.compiler_start":          "ошибка запуска компилятора",
+	"sandbox.dir_read":                "ошибка чтения директории",
+	"sandbox.file_copy":               "ошибка копирования файла",
+	"sandbox.file_not_found":          "файл не существует: %s",
+	"sandbox.file_remove":             "не удалось удалить %s",
+	"sandbox.file_too_large":          "превышен максимальный размер файла: %d байт (максимум %d)",
+	"sandbox.output_too_large":        "превышен максимальный размер вывода (%d байт)",
+	"sandbox.process_start":           "ошибка запуска процесса",
+	"sandbox.result_compile_error":    "Ошибка компиляции:",
+	"sandbox.result_compile_time":     "Время компиляции: %v",
+	"sandbox.result_program_output":   "Вывод программы:",
+	"sandbox.result_runtime_error":    "Ошибка выполнения (код %d):",
+	"sandbox.result_success":          "Выполнение успешно завершено за %v",
+	"sandbox.syntax_error_bash":       "Синтаксическая ошибка Bash",
+	"sandbox.syntax_error_javascript": "Синтаксическая ошибка JavaScript",
+	"sandbox.syntax_error_python":     "Синтаксическая ошибка Python",
+	"sandbox.temp_file_write":         "ошибка записи во временный файл",
+	"sandbox.timeout":                 "превышено время выполнения (%d секунд)",
+	"sandbox.unsupported_file_type":   "неподдерживаемый тип файла: %s",
+	"sandbox.unsupported_language":    "неподдерживаемый язык: %s",
+
+	// Модель
+	"model.api_status":               "ошибка API (код %d): %s",
+	"model.branch_out_of_range":      "номер ветки должен быть от 1 до %d",
+	"model.complete_no_user":         "последнее сообщение диалога должно быть от пользователя",
+	"model.complete_role":            "неизвестная роль сообщения: %s",
+	"model.error_apology":            "Извините, произошла ошибка при обработке запроса. Пожалуйста, попробуйте еще раз.",
+	"model.generation_range":         "параметр %s должен быть в диапазоне %s",
+	"model.generation_timeout":       "время генерации истекло",
+	"model.generation_unknown":       "неизвестный параметр генерации: %s",
+	"model.generation_value":         "неверное значение параметра %s: %q",
+	"model.generation_value_missing": "для параметра %s нужно одно значение",
+	"model.invalid_profile_name":     "недопустимое имя профиля: %q",
+	"model.local_failed":             "ошибка локальной генерации",
+	"model.local_unavailable":        "локальная генерация недоступна",
+	"model.message_not_found":        "сообщение не найдено: %s",
+	"model.message_out_of_range":     "номер сообщения должен быть от 1 до %d",
+	"model.native_failed":            "ошибка генерации встроенного движка",
+	"model.native_unavailable":       "встроенный движок недоступен",
+	"model.no_reply_to_regenerate":   "нет ответа ассистента для повторной генерации",
+	"model.not_user_message":         "сообщение %d не является сообщением пользователя",
+	"model.profile_code_denied":      "профиль %s не разрешает выполнение кода",
+	"model.profile_max_tokens":       "профиль %s: max_tokens не может быть отрицательным",
+	"model.profile_not_found":        "профиль не найден: %s",
+	"model.profile_temperature":      "профиль %s: temperature должна быть от 0 до 2",
+	"model.profile_top_p":            "профиль %s: top_p должен быть от 0 до 1",
+	"model.profile_unknown_tool":     "профиль %s: неизвестный инструмент %s",
+	"model.queue_full":               "очередь запросов заполнена (%d), попробуйте позже",
+	"model.reply_without_prompt":     "ответ ассистента не связан с сообщением пользователя",
+	"model.request_canceled":         "запрос отменен",
+	"model.request_create":           "ошибка создания HTTP запроса",
+	"model.request_encode":           "ошибка сериализации запроса",
+	"model.request_failed":           "ошибка выполнения HTTP запроса",
+	"model.response_decode":          "ошибка разбора JSON",
+	"model.response_read":            "ошибка чтения ответа",
+	"model.server_not_ready":         "сервер модели не готов (состояние: %s)",
+	"model.store_not_set":            "хранилище сессий не задано",
+	"model.thought_journal_create":   "ошибка создания журнала размышлений %s",
+	"model.thought_journal_end":      "## Конец размышлений",
+	"model.thought_journal_error":    "Ошибка в режиме размышления: %s",
+	"model.thought_journal_params":   "## Параметры",
+	"model.thought_journal_start":    "## Начало размышлений",
+	"model.thought_journal_title":    "# Размышления SmolLM2 - %s",
+	"model.thought_record_decode":    "ошибка разбора записи размышления %s",
+	"model.thought_record_read":      "ошибка чтения записи размышления %s",
+	"model.thought_record_write":     "ошибка записи размышления %s",
+
+	// Поиск
+	"search.empty_query":      "пустой поисковый запрос",
+	"search.feedback_session": ", сессия %s",
+	"search.feedback_title":   "%s, оценка %d",
+	"search.index_decode":     "ошибка разбора индекса",
+	"search.index_encode":     "ошибка сериализации индекса",
+	"search.index_read":       "ошибка чтения индекса",
+	"search.index_version":    "неподдерживаемая версия индекса: %d",
+	"search.index_write":      "ошибка записи индекса",
+	"search.invalid_since":    "неверный формат времени: %s (примеры: 12h, 7d, 2w, 2025-01-31)",
+	"search.thought_read":     "ошибка чтения журнала размышлений %s",
+	"search.thoughts_read":    "ошибка чтения директории размышлений",
+
+	// Экспорт
+	"export.created":        "Создана",
+	"export.errors":         "Ошибки:",
+	"export.execution":      "Выполнение кода (%s): %s",
+	"export.html_title":     "SmolLM Sandbox - экспорт диалогов",
+	"export.output":         "Вывод:",
+	"export.role_assistant": "Ассистент",
+	"export.role_system":    "Системная инструкция",
+	"export.role_user":      "Пользователь",
+	"export.session_title":  "Сессия: %s",
+	"export.status_failure": "ошибка, код выхода %d",
+	"export.status_success": "успешно, код выхода %d",
+	"export.unknown_format": "неизвестный формат экспорта: %s (доступны %s)",
+	"export.updated":        "Изменена",
+
+	// Обратная связь
+	"feedback.dir_read":       "ошибка чтения директории",
+	"feedback.encode":         "ошибка сериализации в JSON",
+	"feedback.invalid_rating": "рейтинг должен быть от 1 до 5",
+	"feedback.write":          "ошибка записи в файл",
+
+	// CLI
+	"cli.batch_done":               "Пакетная обработка завершена: обработано %d, пропущено %d, с ошибками %d",
+	"cli.batch_duplicate_id":       "строка %d: повторяющийся id %s",
+	"cli.batch_flag_in":            "Входной JSONL файл с запросами",
+	"cli.batch_flag_out":           "Выходной JSONL файл с результатами",
+	"cli.batch_flag_profile":       "Профиль (персона) модели для всех запросов",
+	"cli.batch_flag_resume":        "Продолжить прерванную обработку, пропуская готовые запросы",
+	"cli.batch_flag_run_code":      "Выполнять блоки кода из ответов в песочнице",
+	"cli.batch_flag_seed":          "Seed для запросов без своего seed; 0 - случайный (выбранный seed записывается в результат)",
+	"cli.batch_input_read_failed":  "Ошибка чтения входного файла: %v",
+	"cli.batch_line":               "строка %d",
+	"cli.batch_no_prompt":          "строка %d: не указан prompt",
+	"cli.batch_output_open_failed": "Ошибка открытия выходного файла: %v",
+	"cli.batch_output_read_failed": "Ошибка чтения выходного файла: %v",
+	"cli.batch_profile_failed":     "Ошибка выбора профиля: %v",
+	"cli.batch_progress":           "[%d/%d] Обработка запроса %s...",
+	"cli.batch_saved":              "Результаты записаны в файл: %s",
+	"cli.batch_usage":              "Использование: smollm-cli batch --in prompts.jsonl --out results.jsonl [--resume] [--run-code] [--seed N]",
+	"cli.batch_write_failed":       "Ошибка записи результата: %v",
+	"cli.branch_active":            "Активна ветка %d",
+	"cli.branch_empty":             "В текущей ветке нет сообщений",
+	"cli.branch_failed":            "Ошибка переключения ветки",
+	"cli.branch_hint":              "Для переключения используйте /branch N",
+	"cli.branch_line":              "%s %d. [%d сообщ.] %s",
+	"cli.code_unavailable":         "Выполнение кода недоступно",
+	"cli.command_parse_failed":     "Ошибка разбора команды",
+	"cli.edit_empty":               "Текст сообщения не может быть пустым",
+	"cli.edit_failed":              "Ошибка редактирования",
+	"cli.enter_code":               "Введите код, для завершения - %s",
+	"cli.enter_edit":               "Введите новый текст сообщения %d, для завершения - %s",
+	"cli.enter_paste":              "Вставьте текст, для завершения - %s",
+	"cli.error":                    "Ошибка: %v",
+	"cli.example_batch":            "Пакетная обработка",
+	"cli.example_input_file":       "Обработка файла",
+	"cli.example_input_text":       "Обработка текста",
+	"cli.example_interactive":      "Запуск в интерактивном режиме",
+	"cli.example_json":             "Машиночитаемый вывод в формате JSON",
+	"cli.example_lang":             "Интерфейс на английском языке",
+	"cli.example_profile":          "Сессия с профилем программиста",
+	"cli.example_prompt":           "Напиши простой скрипт на Python",
+	"cli.example_rag":              "Индексация папки документов",
+	"cli.example_sessions":         "Список сохраненных сессий",
+	"cli.example_thought":          "Запуск режима размышления на 5 минут",
+	"cli.example_thoughts_replay":  "Повторить размышление и сравнить с исходным",
+	"cli.examples_header":          "Примеры:",
+	"cli.execution_failed":         "Ошибка выполнения: %v",
+	"cli.export_done":              "Экспортировано диалогов: %d, файл: %s",
+	"cli.export_failed":            "Ошибка экспорта",
+	"cli.export_failed_with":       "Ошибка экспорта: %v",
+	"cli.export_flag_all":          "Экспортировать все сохраненные сессии",
+	"cli.export_flag_format":       "Формат: %s",
+	"cli.export_flag_min_rating":   "Только сессии со средней оценкой обратной связи не ниже указанной (1-5)",
+	"cli.export_flag_out":          "Выходной файл (по умолчанию stdout)",
+	"cli.export_json_needs_out":    "В режиме --output json необходимо указать --out",
+	"cli.export_saved":             "Диалог экспортирован в файл: %s",
+	"cli.export_session":           "сессия %s",
+	"cli.export_usage":             "Использование: smollm-cli export [флаги] SESSION... | --all",
+	"cli.feedback_load_failed":     "ошибка загрузки обратной связи",
+	"cli.file_read_failed":         "Ошибка: не удалось прочитать файл",
+	"cli.flag_config":              "Путь к файлу конфигурации",
+	"cli.flag_input":               "Входной текст или файл",
+	"cli.flag_interactive":         "Интерактивный режим",
+	"cli.flag_lang":                "Язык интерфейса: %s; по умолчанию из конфигурации",
+	"cli.flag_output":              "Формат вывода: text или json",
+	"cli.flag_profile":             "Профиль (персона) модели; по умолчанию из конфигурации",
+	"cli.flag_report_output":       "Формат итогового отчета: text или json",
+	"cli.flag_seed":                "Seed режима размышления для точного повтора; 0 - seed из конфигурации",
+	"cli.flag_session":             "Имя сессии (для сохранения/загрузки)",
+	"cli.flag_thought":             "Режим размышления (без ввода пользователя)",
+	"cli.flag_thought_time":        "Время размышления в секундах",
+	"cli.flag_version":             "Вывести версию и выйти",
+	"cli.forget_failed":            "Ошибка удаления факта",
+	"cli.forget_not_number":        "Номер факта должен быть числом: /memory forget N|all",
+	"cli.forgotten":                "Забыто: %s",
+	"cli.generation_hint":          "Для изменения используйте /set NAME VALUE, для стоп-строк - /set stop \"\\n###\" ...; /set reset возвращает значения по умолчанию",
+	"cli.generation_reset":         "Параметры генерации сессии сброшены",
+	"cli.generation_set":           "Параметр %s изменен для текущей сессии",
+	"cli.generation_set_failed":    "Ошибка изменения параметра",
+	"cli.help_branch":              "Показать ветки диалога или переключиться на ветку N",
+	"cli.help_code":                "Выполнить строку кода (без кода - многострочный ввод)",
+	"cli.help_edit":                "Изменить сообщение N и получить новый ответ (без аргументов - список сообщений)",
+	"cli.help_exit":                "Выйти из программы",
+	"cli.help_export":              "Экспортировать текущий диалог (markdown, html, chatml, sharegpt)",
+	"cli.help_help":                "Показать эту справку",
+	"cli.help_hint":                "Введите /help для списка команд",
+	"cli.help_load":                "Загрузить сохраненную сессию",
+	"cli.help_memory":              "Показать или удалить факты долговременной памяти",
+	"cli.help_paste":               "Многострочный ввод запроса до %s",
+	"cli.help_profile":             "Показать профили или переключить профиль текущей сессии",
+	"cli.help_remember":            "Запомнить факт о себе для следующих сессий",
+	"cli.help_retry":               "Заново сгенерировать последний ответ",
+	"cli.help_run":                 "Запустить файл в песочнице",
+	"cli.help_save":                "Сохранить текущую сессию",
+	"cli.help_search":              "Поиск по сессиям, размышлениям и обратной связи",
+	"cli.help_sessions":            "Управление сохраненными сессиями",
+	"cli.help_set":                 "Показать параметры генерации или изменить их для текущей сессии",
+	"cli.help_title":               "Доступные команды:",
+	"cli.interactive_exit":         "Для выхода введите 'exit'",
+	"cli.interactive_hint":         "Введите текст для общения с нейросетью или команду (/help для списка команд)",
+	"cli.interactive_multiline":    "Многострочный ввод: начните строку с ``` или используйте /paste (завершение - %s)",
+	"cli.interactive_title":        "SmolLM Sandbox v%s интерактивный режим",
+	"cli.memory_by_model":          " (запомнено моделью)",
+	"cli.memory_clear_failed":      "Ошибка очистки памяти",
+	"cli.memory_cleared":           "Память очищена",
+	"cli.memory_empty":             "Память пуста. Добавить факт: /remember текст",
+	"cli.memory_open_failed":       "не удалось открыть хранилище памяти",
+	"cli.memory_read_failed":       "Ошибка чтения памяти: %v",
+	"cli.memory_title":             "Что я помню о вас:",
+	"cli.memory_unavailable":       "Память недоступна",
+	"cli.memory_unknown_action":    "Неизвестное действие: %s (доступны list, forget)",
+	"cli.migrate_dry_run_summary":  "Требуют обновления: %d, с ошибками: %d (файлы не изменены)",
+	"cli.migrate_flag_dry_run":     "Только показать, какие сессии будут обновлены",
+	"cli.migrate_header":           "ИМЯ\tВЕРСИЯ\tСТАТУС",
+	"cli.migrate_status_current":   "актуальна",
+	"cli.migrate_status_done":      "обновлена, копия: %s",
+	"cli.migrate_status_error":     "ошибка: %s",
+	"cli.migrate_status_pending":   "будет обновлена",
+	"cli.migrate_summary":          "Обновлено: %d, с ошибками: %d",
+	"cli.model_unavailable":        "Модель недоступна: проверьте файлы модели или сервер модели и повторите запрос.",
+	"cli.paste_interactive_only":   "Команда /paste доступна только в интерактивном режиме",
+	"cli.processing":               "Обработка запроса... Пожалуйста, подождите.",
+	"cli.processing_file":          "Обработка содержимого файла...",
+	"cli.processing_text":          "Обработка текста...",
+	"cli.profile_code_denied":      "Профиль %s не разрешает выполнение кода",
+	"cli.profile_hint":             "Для переключения используйте /profile NAME",
+	"cli.profile_select_failed":    "Ошибка выбора профиля",
+	"cli.profile_selected":         "Профиль сессии: %s",
+	"cli.profiles_header":          "\tПРОФИЛЬ\tTEMPERATURE\tЯЗЫК\tОПИСАНИЕ",
+	"cli.rag_method_embeddings":    "эмбеддинги",
+	"cli.rag_no_passages":          "Подходящих фрагментов не найдено",
+	"cli.rag_report":               "Документы: %s — файлов %d, фрагментов %d (обновлено %d, удалено %d), поиск: %s",
+	"cli.rag_sources":              "Источники:",
+	"cli.rag_unknown_action":       "неизвестное действие: %s (доступны ingest, query)",
+	"cli.rag_usage":                "Использование: smollm-cli rag [флаги] ingest|query \"запрос\"",
+	"cli.raw_mode_failed":          "ошибка перевода терминала в raw-режим",
+	"cli.regenerating":             "Повторная генерация ответа... Пожалуйста, подождите.",
+	"cli.remember_failed":          "Ошибка сохранения факта",
+	"cli.remembered":               "Запомнено: %s",
+	"cli.remembered_inline":        "(Запомнено: %s)",
+	"cli.response_failed":          "Извините, произошла ошибка при обработке запроса. Пожалуйста, попробуйте еще раз.",
+	"cli.retry_failed":             "Ошибка повторной генерации",
+	"cli.search_failed":            "Ошибка поиска: %v",
+	"cli.search_flag_limit":        "Максимальное количество результатов",
+	"cli.search_flag_rebuild":      "Перестроить индекс перед поиском",
+	"cli.search_flag_since":        "Только документы не старше указанного времени (12h, 7d, 2w или 2025-01-31)",
+	"cli.search_flag_type":         "Искать только в документах типа session, thought или feedback",
+	"cli.search_nothing":           "Ничего не найдено",
+	"cli.search_rebuild_failed":    "Ошибка построения индекса: %v",
+	"cli.search_rebuilding":        "Построение поискового индекса...",
+	"cli.search_unknown_type":      "Неизвестный тип документов: %s",
+	"cli.search_usage":             "Использование: smollm-cli search \"запрос\" [--type session|thought|feedback] [--since 7d] [--limit N] [--rebuild]",
+	"cli.session_deleted":          "Сессия удалена: %s",
+	"cli.session_detail_branches":  "Веток:     %d (показана активная)",
+	"cli.session_detail_created":   "Создана:   %s",
+	"cli.session_detail_format":    "Формат:    v%d",
+	"cli.session_detail_id":        "ID:        %s",
+	"cli.session_detail_messages":  "Сообщений: %d",
+	"cli.session_detail_name":      "Сессия:    %s",
+	"cli.session_detail_updated":   "Изменена:  %s",
+	"cli.session_exported":         "Сессия '%s' экспортирована в %s",
+	"cli.session_forked":           "Создана копия сессии '%s': %s",
+	"cli.session_imported":         "Сессия импортирована как: %s",
+	"cli.session_load_failed":      "Ошибка загрузки сессии",
+	"cli.session_loaded":           "Сессия '%s' успешно загружена",
+	"cli.session_renamed":          "Сессия '%s' переименована в '%s'",
+	"cli.session_save_failed":      "Ошибка сохранения сессии",
+	"cli.session_saved":            "Сессия сохранена как: %s",
+	"cli.sessions_empty":           "Сохраненных сессий нет",
+	"cli.sessions_header":          "ИМЯ\tСООБЩЕНИЙ\tСОЗДАНА\tИЗМЕНЕНА",
+	"cli.sessions_unknown_action":  "неизвестное действие: %s (доступны list, show, delete, rename, export, import, fork, migrate)",
+	"cli.sessions_usage":           "Использование: smollm-cli sessions [флаги] list|show|delete|rename|export|import|fork|migrate [аргументы]",
+	"cli.shutdown":                 "Получен сигнал завершения. Освобождение ресурсов...",
+	"cli.thought_done":             "Режим размышления завершен!",
+	"cli.thought_saved":            "Размышления записаны в файл: %s",
+	"cli.thought_seed":             "Seed: %d, запись для повтора: %s",
+	"cli.thought_start":            "Запуск режима размышления на %d секунд",
+	"cli.thought_target":           "Размышления будут сохранены в: %s",
+	"cli.thought_wait":             "Запуск режима размышления. Это займет некоторое время...",
+	"cli.thoughts_backend":         "Внимание: размышление выполнено способом %s, повтор выполняется способом %s; мысли могут отличаться",
+	"cli.thoughts_different":       "Повтор отличается от исходного размышления",
+	"cli.thoughts_identical":       "Повтор совпадает с исходным размышлением",
+	"cli.thoughts_replaying":       "Повтор %d мыслей с seed %d...",
+	"cli.thoughts_step_differs":    "[%d] отличается:",
+	"cli.thoughts_step_equal":      "[%d] совпадает",
+	"cli.thoughts_usage":           "Использование: smollm-cli thoughts [флаги] replay FILE (журнал .txt или запись .json)",
+	"cli.unclosed_quote":           "незакрытая кавычка %c",
+	"cli.unknown_command":          "Неизвестная команда: %s",
+	"cli.unknown_output_format":    "неизвестный формат вывода: %s (доступны %s, %s)",
+	"cli.usage_branch":             "Необходимо указать номер ветки: /branch N",
+	"cli.usage_code":               "Необходимо указать язык и код: /code [python|js|go] \"код для выполнения\"",
+	"cli.usage_edit":               "Необходимо указать номер сообщения: /edit N [текст]",
+	"cli.usage_edit_text":          "Необходимо указать номер и новый текст: /edit N текст",
+	"cli.usage_export":             "Необходимо указать формат: /export [%s] [файл]",
+	"cli.usage_forget":             "Необходимо указать номер факта: /memory forget N|all",
+	"cli.usage_header":             "Использование:",
+	"cli.usage_load":               "Необходимо указать имя сессии: /load session_name",
+	"cli.usage_remember":           "Необходимо указать факт: /remember текст",
+	"cli.usage_run":                "Необходимо указать исполняемый файл: /run filename",
+	"cli.usage_save":               "Необходимо указать имя сессии: /save session_name",
+	"cli.usage_search":             "Необходимо указать запрос: /search запрос",
+	"cli.usage_sessions_delete":    "необходимо указать имя сессии: sessions delete NAME",
+	"cli.usage_sessions_export":    "необходимо указать имя сессии: sessions export NAME [FILE]",
+	"cli.usage_sessions_fork":      "необходимо указать исходную и новую сессию: sessions fork SOURCE TARGET",
+	"cli.usage_sessions_import":    "необходимо указать файл: sessions import FILE [NAME]",
+	"cli.usage_sessions_rename":    "необходимо указать старое и новое имя: sessions rename OLD NEW",
+	"cli.usage_sessions_show":      "необходимо указать имя сессии: sessions show NAME",
+	"cli.usage_title":              "SmolLM Sandbox - песочница для нейросети SmolLM2",
+
+	// Telegram бот
+	"tg.branch_failed":   "Не удалось переключить ветку: %s",
+	"tg.edit_failed":     "Не удалось изменить сообщение: %s",
+	"tg.export_failed":   "Произошла ошибка при экспорте диалога.",
+	"tg.feedback_failed": "Произошла ошибка при сохранении обратной связи.",
+	"tg.feedback_saved":  "Спасибо за обратную связь! ID: %s",
+	"tg.flag_token":      "Токен Telegram бота",
+	"tg.help": `
+Команды бота:
+/start - Начать общение
+/help - Показать справку
+/think [время] - Запустить режим размышления
+/retry - Заново сгенерировать последний ответ
+/edit [N] [текст] - Изменить сообщение N и получить новый ответ (без аргументов - список сообщений)
+/branch [N] - Показать ветки диалога или переключиться на ветку N
+/search [запрос] - Поиск по диалогу этого чата (администраторам - по всем данным)
+/profile [имя] - Показать профили или выбрать профиль модели
+/memory [forget N|forget all] - Показать или удалить то, что бот помнит о вас
+/remember [текст] - Запомнить факт о себе для следующих разговоров
+/export [формат] - Получить файл с диалогом (markdown, html, chatml, sharegpt)
+/run - Выполнить код (отправь код в следующем сообщении)
+/status - Показать статус бота
+/feedback [оценка] [комментарий] - Отправить обратную связь
+`,
+	"tg.invalid_rating":      "Оценка должна быть от 1 до 5.",
+	"tg.memory_empty":        "Я пока ничего о вас не помню. Добавить факт: /remember текст",
+	"tg.memory_forget_hint":  "Удалить факт: /memory forget N",
+	"tg.memory_unavailable":  "Память недоступна: %s",
+	"tg.no_token":            "Не указан токен Telegram бота",
+	"tg.profile_failed":      "Не удалось выбрать профиль: %s",
+	"tg.profile_hint":        "Для переключения используйте /profile имя",
+	"tg.profiles_title":      "Профили модели:",
+	"tg.queued":              "Запрос в очереди, позиция: %d",
+	"tg.retry_failed":        "Не удалось повторить ответ: %s",
+	"tg.run_prompt":          "Отправь мне код для выполнения в следующем сообщении.",
+	"tg.session_unavailable": "Не удалось загрузить диалог чата: %s",
+	"tg.start":               "Привет! Я SmolLM бот v%s. Напиши мне что-нибудь, и я отвечу.",
+	"tg.status_cache":        "Кэш ответов: попаданий %d, промахов %d; KV кэш префикса: попаданий %d, промахов %d",
+	"tg.status_errors":       "Ошибок: %d",
+	"tg.status_executions":   "Выполнено кода: %d",
+	"tg.status_memory":       "Использование памяти: %.2f МБ",
+	"tg.status_model":        "Сервер модели: %s",
+	"tg.status_queue":        "Очередь: выполняется %d из %d, ожидает %d",
+	"tg.status_uptime":       "Время работы: %s",
+	"tg.thinking":            "Запускаю режим размышления на %d секунд...",
+	"tg.thought_part":        "Часть %d/%d:\n%s",
+	"tg.thought_read_failed": "Произошла ошибка при чтении файла размышлений.",
+	"tg.unknown_command":     "Неизвестная команда. Введите /help для справки.",
+	"tg.uptime":              "%d дней, %d часов, %d минут",
+	"tg.usage_edit":          "Использование: /edit N новый текст",
+	"tg.usage_forget":        "Использование: /memory forget N",
+	"tg.usage_memory":        "Использование: /memory [forget N|forget all]",
+	"tg.usage_remember":      "Использование: /remember факт о себе",
+	"tg.usage_search":        "Использование: /search запрос",
+
+	// Документы
+	"rag.docs_walk":     "ошибка обхода папки документов %s",
+	"rag.embed_count":   "получено %d эмбеддингов вместо %d",
+	"rag.embed_decode":  "ошибка разбора ответа эмбеддингов",
+	"rag.embed_request": "ошибка запроса эмбеддингов",
+	"rag.embed_status":  "сервер эмбеддингов вернул %d: %s",
+	"rag.index_decode":  "ошибка разбора индекса документов",
+	"rag.index_encode":  "ошибка сериализации индекса документов",
+	"rag.index_read":    "ошибка чтения индекса документов",
+	"rag.index_version": "неподдерживаемая версия индекса документов: %d",
+	"rag.index_write":   "ошибка записи индекса документов",
+
+	// Сервер модели
+	"modelserver.closed":           "процесс Python остановлен",
+	"modelserver.dependency_check": "не удалось проверить пакеты Python (%s)",
+	"modelserver.gave_up":          "процесс Python не удалось перезапустить (попыток: %d)",
+	"modelserver.missing_packages": "не установлены пакеты Python: %s; установите их командой: %s -m pip install %s",
+	"modelserver.process_exited":   "процесс Python завершился",
+	"modelserver.process_start":    "ошибка запуска процесса Python",
+	"modelserver.python_not_found": "интерпретатор Python не найден: %s",
+	"modelserver.script_write":     "ошибка записи скрипта Python",
+	"modelserver.startup_timeout":  "процесс Python не запустился за %v",
+	"modelserver.worker_failed":    "ошибка генерации в рабочем процессе: %s",
+	"modelserver.worker_not_ready": "рабочий процесс генерации не готов (состояние: %s)",
+	"modelserver.worker_write":     "ошибка отправки запроса рабочему процессу",
+
+	// Встроенный движок
+	"llama.config_decode":            "неверный формат config.json",
+	"llama.config_read":              "не удалось прочитать конфигурацию модели в %s",
+	"llama.empty_prompt":             "пустой промпт",
+	"llama.gguf_magic":               "файл %s не является файлом GGUF",
+	"llama.gguf_value":               "неверное значение в заголовке GGUF",
+	"llama.gguf_version":             "версия GGUF %d не поддерживается",
+	"llama.invalid_config":           "неверные параметры модели в config.json",
+	"llama.prompt_too_long":          "промпт из %d токенов не помещается в контекст модели (%d)",
+	"llama.tensor_dtype":             "тип данных %s не поддерживается",
+	"llama.tensor_invalid":           "неверный тензор %s",
+	"llama.tensor_missing":           "в весах нет тензора %s",
+	"llama.tensor_offsets":           "границы данных тензора не совпадают с его формой",
+	"llama.tensor_shape":             "тензор %s имеет форму %v, ожидалась %v",
+	"llama.tokenizer_decode":         "неверный формат tokenizer.json",
+	"llama.tokenizer_read":           "не удалось прочитать tokenizer.json",
+	"llama.tokenizer_token":          "в словаре нет токена %s",
+	"llama.tokenizer_unsupported":    "тип токенизатора %s не поддерживается, нужен BPE",
+	"llama.unsupported_architecture": "архитектура модели %v не поддерживается, нужна LlamaForCausalLM",
+	"llama.unsupported_rope_scaling": "масштабирование RoPE %s не поддерживается",
+	"llama.weights_corrupt":          "файл весов %s поврежден",
+	"llama.weights_not_found":        "веса модели в формате safetensors не найдены в %s",
+	"llama.weights_read":             "не удалось прочитать файл весов %s",
+
+	// Конфигурация
+	"config.parse":         "ошибка разбора файла конфигурации",
+	"config.profile_parse": "ошибка разбора профиля %s",
+	"config.profile_read":  "ошибка чтения профиля %s",
+	"config.profiles_read": "ошибка чтения директории профилей",
+	"config.read":          "ошибка чтения файла конфигурации",
+
+	// gRPC сервис
+	"rpc.empty_api_key":     "пустой ключ доступа в списке ключей",
+	"rpc.feedback_disabled": "сбор обратной связи отключен на сервере",
+	"rpc.invalid_filename":  "нужно имя файла с расширением: %q",
+	"rpc.no_code":           "не указан код для выполнения",
+	"rpc.no_model":          "для сервиса нужна модель",
+	"rpc.no_prompt":         "для генерации в сессии нужен prompt",
+	"rpc.sandbox_disabled":  "песочница отключена на сервере",
+	"rpc.session_messages":  "в сессии история берется из сессии: передайте только prompt",
+	"rpc.temp_file":         "не удалось сохранить файл для выполнения",
+	"rpc.unauthorized":      "неверный или отсутствующий ключ API",
+
+	// API сервер
+	"server.flag_addr":             "Адрес API сервера (по умолчанию из конфигурации)",
+	"server.flag_grpc_addr":        "Адрес gRPC сервиса (по умолчанию из конфигурации)",
+	"server.invalid_json":          "Некорректный JSON запроса: %s",
+	"server.n_unsupported":         "Поддерживается только n = 1",
+	"server.no_api_key":            "Сервер на адресе %s доступен не только локально: задайте server.api_keys или %s",
+	"server.no_code":               "Не указан код для выполнения",
+	"server.not_found":             "Эндпоинт не найден: %s %s",
+	"server.streaming_unsupported": "Потоковая выдача не поддерживается",
+	"server.unauthorized":          "Неверный или отсутствующий ключ API",
+	"server.unsupported_content":   "Неподдерживаемый тип части сообщения: %s",
+
+	// Публичный пакет
+	"smollm.config_load":       "не удалось загрузить конфигурацию %s",
+	"smollm.message_not_found": "в сессии %s нет сообщения %s",
+	"smollm.sandbox_disabled":  "песочница отключена",
+}
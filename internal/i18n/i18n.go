@@ -0,0 +1,195 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Поддерживаемые языки интерфейса
+const (
+	LANG_RU = "ru"
+	LANG_EN = "en"
+
+	// Язык по умолчанию; его каталог используется, если в другом нет сообщения
+	DEFAULT_LANG = LANG_RU
+)
+
+// catalogs содержит сообщения интерфейса по языкам. Значения - строки
+// формата fmt.Sprintf, ключи одинаковы во всех каталогах
+var catalogs = map[string]map[string]string{
+	LANG_RU: catalogRU,
+	LANG_EN: catalogEN,
+}
+
+// Localizer переводит сообщения интерфейса на выбранный язык
+type Localizer struct {
+	lang string
+}
+
+var (
+	defaultMu        sync.RWMutex
+	defaultLocalizer = &Localizer{lang: DEFAULT_LANG}
+)
+
+// New создает локализатор для языка lang. Неподдерживаемый язык заменяется языком по умолчанию
+func New(lang string) *Localizer {
+	if normalized := Normalize(lang); normalized != "" {
+		return &Localizer{lang: normalized}
+	}
+	return &Localizer{lang: DEFAULT_LANG}
+}
+
+// Normalize приводит код языка (ru, en-US, en_GB) к поддерживаемому языку.
+// Для неподдерживаемых языков возвращает пустую строку
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_."); i >= 0 {
+		code = code[:i]
+	}
+	if _, ok := catalogs[code]; ok {
+		return code
+	}
+	return ""
+}
+
+// Languages возвращает коды поддерживаемых языков
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Lang возвращает язык локализатора
+func (l *Localizer) Lang() string {
+	return l.lang
+}
+
+// T возвращает сообщение key на языке локализатора. Если сообщения нет
+// в каталоге языка, используется каталог по умолчанию, затем сам ключ
+func (l *Localizer) T(key string, args ...any) string {
+	format, ok := catalogs[l.lang][key]
+	if !ok {
+		format, ok = catalogs[DEFAULT_LANG][key]
+	}
+	if !ok {
+		format = key
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error возвращает текст ошибки на языке локализатора. Ошибки с кодом
+// (*Error) переводятся вместе с причинами, остальные выводятся как есть
+func (l *Localizer) Error(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var coded *Error
+	if !errors.As(err, &coded) {
+		return err.Error()
+	}
+
+	// Вложенные ошибки в аргументах переводятся тем же локализатором
+	args := make([]any, len(coded.Args))
+	for i, arg := range coded.Args {
+		if argErr, ok := arg.(error); ok {
+			arg = l.Error(argErr)
+		}
+		args[i] = arg
+	}
+
+	message := l.T(coded.Code, args...)
+	if coded.Err != nil {
+		message += ": " + l.Error(coded.Err)
+	}
+	return message
+}
+
+// SetLanguage задает язык локализатора по умолчанию для однопользовательских клиентов
+func SetLanguage(lang string) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+
+	defaultLocalizer = New(lang)
+}
+
+// Default возвращает локализатор по умолчанию
+func Default() *Localizer {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+
+	return defaultLocalizer
+}
+
+// T переводит сообщение на язык по умолчанию
+func T(key string, args ...any) string {
+	return Default().T(key, args...)
+}
+
+// LocalizeError переводит текст ошибки на язык по умолчанию
+func LocalizeError(err error) string {
+	return Default().Error(err)
+}
+
+// Code возвращает код сообщения первой ошибки с кодом в цепочке err или пустую строку
+func Code(err error) string {
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ""
+}
+
+// Error - ошибка с кодом сообщения каталога. Текст ошибки (Error())
+// формируется на языке по умолчанию, клиенты переводят ее через Localizer.Error
+type Error struct {
+	Code string // Ключ сообщения в каталоге
+	Args []any  // Аргументы сообщения
+	Err  error  // Причина; может быть nil
+	Kind error  // Вид ошибки (сигнальная ошибка пакета) для errors.Is; может быть nil
+}
+
+// NewError создает ошибку с кодом сообщения
+func NewError(code string, args ...any) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// WrapError создает ошибку с кодом сообщения и причиной err
+func WrapError(err error, code string, args ...any) *Error {
+	return &Error{Code: code, Args: args, Err: err}
+}
+
+// WithKind задает вид ошибки, по которому ее распознает errors.Is
+func (e *Error) WithKind(kind error) *Error {
+	e.Kind = kind
+	return e
+}
+
+// Error возвращает текст ошибки на языке по умолчанию
+func (e *Error) Error() string {
+	message := New(DEFAULT_LANG).T(e.Code, e.Args...)
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+// Unwrap возвращает причину ошибки
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is сообщает, относится ли ошибка к виду target
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && errors.Is(e.Kind, target)
+}
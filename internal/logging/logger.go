@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 )
@@ -31,19 +32,72 @@ type Logger struct {
 
 // LogConfig содержит настройки логирования
 type LogConfig struct {
-	Level         int    // Уровень логирования
-	EnableFile    bool   // Записывать ли логи в файл
-	FilePath      string // Путь к файлу логов
-	EnableConsole bool   // Выводить ли логи в консоль
+	Level         int       // Уровень логирования
+	EnableFile    bool      // Записывать ли логи в файл
+	FilePath      string    // Путь к файлу логов
+	EnableConsole bool      // Выводить ли логи в консоль
+	Console       io.Writer // Поток для вывода в консоль (по умолчанию os.Stdout)
 }
 
+var (
+	defaultConfig   *LogConfig
+	defaultConfigMu sync.Mutex
+)
+
 // DefaultLogConfig возвращает настройки по умолчанию
 func DefaultLogConfig() LogConfig {
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+
+	if defaultConfig != nil {
+		return *defaultConfig
+	}
+
 	return LogConfig{
 		Level:         INFO,
 		EnableFile:    true,
 		FilePath:      "logs/smollm.log",
 		EnableConsole: true,
+		Console:       os.Stdout,
+	}
+}
+
+// SetDefaultLogConfig задает настройки, которые будут использоваться всеми логгерами,
+// создаваемыми через NewLogger. Если файл логов недоступен, запись в файл отключается
+func SetDefaultLogConfig(config LogConfig) {
+	if config.EnableFile {
+		if err := os.MkdirAll(filepath.Dir(config.FilePath), 0755); err == nil {
+			file, err := os.OpenFile(config.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				log.Printf("Failed to open log file: %v", err)
+				config.EnableFile = false
+			} else {
+				file.Close()
+			}
+		} else {
+			log.Printf("Failed to create log directory: %v", err)
+			config.EnableFile = false
+		}
+	}
+
+	defaultConfigMu.Lock()
+	defer defaultConfigMu.Unlock()
+	defaultConfig = &config
+}
+
+// ParseLevel преобразует название уровня логирования в константу
+func ParseLevel(level string) int {
+	switch strings.ToLower(level) {
+	case "debug":
+		return DEBUG
+	case "warn", "warning":
+		return WARN
+	case "error":
+		return ERROR
+	case "fatal":
+		return FATAL
+	default:
+		return INFO
 	}
 }
 
@@ -54,7 +108,15 @@ func NewLogger() *Logger {
 
 // NewLoggerWithConfig создает новый экземпляр логгера с указанными настройками
 func NewLoggerWithConfig(config LogConfig) *Logger {
-	var output io.Writer = os.Stdout
+	console := config.Console
+	if console == nil {
+		console = os.Stdout
+	}
+
+	var output io.Writer = console
+	if !config.EnableConsole {
+		output = io.Discard
+	}
 	var fileOutput *os.File
 
 	// Если включена запись в файл
@@ -74,7 +136,7 @@ func NewLoggerWithConfig(config LogConfig) *Logger {
 
 			// Если нужно выводить и в консоль, и в файл
 			if config.EnableConsole {
-				output = io.MultiWriter(os.Stdout, file)
+				output = io.MultiWriter(console, file)
 			} else {
 				output = file
 			}
@@ -13,6 +13,8 @@ type Metrics struct {
 	ErrorCount  int            `json:"error_count"`
 	Executions  int            `json:"executions"`
 	ThoughtTime time.Duration  `json:"thought_time"`
+	CacheHits   map[string]int `json:"cache_hits"`   // Попадания по названиям кэшей
+	CacheMisses map[string]int `json:"cache_misses"` // Промахи по названиям кэшей
 	Custom      map[string]any `json:"custom"`
 	mu          sync.Mutex
 }
@@ -31,6 +33,8 @@ func NewMetrics() *Metrics {
 		ErrorCount:  0,
 		Executions:  0,
 		ThoughtTime: 0,
+		CacheHits:   make(map[string]int),
+		CacheMisses: make(map[string]int),
 		Custom:      make(map[string]any),
 	}
 }
@@ -67,6 +71,30 @@ func (m *Metrics) AddThoughtTime(duration time.Duration) {
 	m.ThoughtTime += duration
 }
 
+// IncrementCacheHit увеличивает счетчик попаданий в кэш cache
+func (m *Metrics) IncrementCacheHit(cache string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CacheHits[cache]++
+}
+
+// IncrementCacheMiss увеличивает счетчик промахов кэша cache
+func (m *Metrics) IncrementCacheMiss(cache string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CacheMisses[cache]++
+}
+
+// GetCacheStats возвращает число попаданий и промахов кэша cache
+func (m *Metrics) GetCacheStats(cache string) (hits, misses int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.CacheHits[cache], m.CacheMisses[cache]
+}
+
 // SetCustomMetric устанавливает пользовательскую метрику
 func (m *Metrics) SetCustomMetric(key string, value any) {
 	m.mu.Lock()
@@ -101,6 +129,8 @@ func (m *Metrics) ToJSON() ([]byte, error) {
 		"error_count":  m.ErrorCount,
 		"executions":   m.Executions,
 		"thought_time": m.ThoughtTime.String(),
+		"cache_hits":   m.CacheHits,
+		"cache_misses": m.CacheMisses,
 		"uptime":       time.Since(m.StartTime).String(),
 		"custom":       m.Custom,
 	}
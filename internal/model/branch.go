@@ -0,0 +1,173 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Branch описывает одну ветку диалога, заканчивающуюся листом дерева сообщений
+type Branch struct {
+	LeafID   string    // ID последнего сообщения ветки
+	Length   int       // Количество сообщений в ветке, включая системные
+	Preview  string    // Последнее сообщение пользователя в ветке
+	Active   bool      // Является ли ветка активной
+	Modified time.Time // Время последнего сообщения ветки
+}
+
+// addMessage добавляет сообщение как продолжение активной ветки
+func (c *Context) addMessage(role, content string) Message {
+	return c.addChild(c.ActiveLeaf, role, content)
+}
+
+// addChild добавляет сообщение с указанным родителем и делает его активным листом
+func (c *Context) addChild(parentID, role, content string) Message {
+	now := time.Now()
+	msg := Message{
+		ID:        fmt.Sprintf("m%d", len(c.Messages)+1),
+		ParentID:  parentID,
+		Role:      role,
+		Content:   content,
+		Timestamp: now,
+	}
+
+	c.Messages = append(c.Messages, msg)
+	c.ActiveLeaf = msg.ID
+	c.Metadata.UpdatedAt = now
+
+	return msg
+}
+
+// findMessage возвращает сообщение по идентификатору
+func (c *Context) findMessage(id string) (Message, bool) {
+	for _, msg := range c.Messages {
+		if msg.ID == id {
+			return msg, true
+		}
+	}
+	return Message{}, false
+}
+
+// VisibleMessages возвращает сообщения активной ветки без системных, в том
+// порядке и с той нумерацией, которые используют EditMessage и клиенты
+func (c *Context) VisibleMessages() []Message {
+	var visible []Message
+	for _, msg := range c.ActiveBranch() {
+		if msg.Role != "system" {
+			visible = append(visible, msg)
+		}
+	}
+	return visible
+}
+
+// ActiveBranch возвращает сообщения активной ветки от корня до листа
+func (c *Context) ActiveBranch() []Message {
+	return c.branchTo(c.ActiveLeaf)
+}
+
+// branchTo возвращает путь от корня дерева до сообщения leafID
+func (c *Context) branchTo(leafID string) []Message {
+	byID := make(map[string]Message, len(c.Messages))
+	for _, msg := range c.Messages {
+		byID[msg.ID] = msg
+	}
+
+	var branch []Message
+	for id := leafID; id != ""; {
+		msg, ok := byID[id]
+		if !ok {
+			break
+		}
+		branch = append(branch, msg)
+		id = msg.ParentID
+
+		// Защита от циклов в поврежденных файлах
+		if len(branch) > len(c.Messages) {
+			break
+		}
+	}
+
+	// Разворачиваем путь, чтобы он шел от корня к листу
+	for i, j := 0, len(branch)-1; i < j; i, j = i+1, j-1 {
+		branch[i], branch[j] = branch[j], branch[i]
+	}
+
+	return branch
+}
+
+// Branches возвращает все ветки диалога в порядке создания их листьев
+func (c *Context) Branches() []Branch {
+	hasChildren := make(map[string]bool, len(c.Messages))
+	for _, msg := range c.Messages {
+		if msg.ParentID != "" {
+			hasChildren[msg.ParentID] = true
+		}
+	}
+
+	var branches []Branch
+	for _, msg := range c.Messages {
+		if hasChildren[msg.ID] {
+			continue
+		}
+
+		path := c.branchTo(msg.ID)
+		branch := Branch{
+			LeafID:   msg.ID,
+			Length:   len(path),
+			Active:   msg.ID == c.ActiveLeaf,
+			Modified: msg.Timestamp,
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			if path[i].Role == "user" {
+				branch.Preview = path[i].Content
+				break
+			}
+		}
+		branches = append(branches, branch)
+	}
+
+	return branches
+}
+
+// SwitchBranch делает активной ветку, заканчивающуюся сообщением leafID
+func (c *Context) SwitchBranch(leafID string) error {
+	if _, ok := c.findMessage(leafID); !ok {
+		return i18n.NewError("model.message_not_found", leafID).WithKind(ErrMessageNotFound)
+	}
+
+	c.ActiveLeaf = leafID
+	c.Metadata.UpdatedAt = time.Now()
+	return nil
+}
+
+// PrepareRetry откатывает активную ветку к сообщению пользователя, на которое
+// был дан последний ответ ассистента. Старый ответ остается в соседней ветке
+func (c *Context) PrepareRetry() (Message, error) {
+	leaf, ok := c.findMessage(c.ActiveLeaf)
+	if !ok || leaf.Role != "assistant" {
+		return Message{}, i18n.NewError("model.no_reply_to_regenerate")
+	}
+
+	parent, ok := c.findMessage(leaf.ParentID)
+	if !ok || parent.Role != "user" {
+		return Message{}, i18n.NewError("model.reply_without_prompt")
+	}
+
+	c.ActiveLeaf = parent.ID
+	c.Metadata.UpdatedAt = time.Now()
+	return parent, nil
+}
+
+// EditMessage создает новую ветку, в которой сообщение активной ветки с номером
+// index (с единицы, без учета системных сообщений) заменено на content.
+// Последующие сообщения исходной ветки сохраняются в старой ветке
+func (c *Context) EditMessage(index int, content string) (Message, error) {
+	visible := c.VisibleMessages()
+	if index < 1 || index > len(visible) {
+		return Message{}, i18n.NewError("model.message_out_of_range", len(visible)).WithKind(ErrOutOfRange)
+	}
+
+	original := visible[index-1]
+	return c.addChild(original.ParentID, original.Role, content), nil
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// contents возвращает тексты сообщений по порядку
+func contents(messages []Message) []string {
+	result := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, msg.Content)
+	}
+	return result
+}
+
+// newDialog создает контекст с системной инструкцией и двумя обменами
+func newDialog() *Context {
+	c := NewContext()
+	c.SetSystemMessage("sys")
+	c.AddUserMessage("q1")
+	c.AddAssistantMessage("a1")
+	c.AddUserMessage("q2")
+	c.AddAssistantMessage("a2")
+	return c
+}
+
+func TestContextBranchOperations(t *testing.T) {
+	tests := []struct {
+		name         string
+		apply        func(t *testing.T, c *Context)
+		wantActive   []string
+		wantBranches int
+	}{
+		{
+			name:         "linear dialog",
+			apply:        func(t *testing.T, c *Context) {},
+			wantActive:   []string{"sys", "q1", "a1", "q2", "a2"},
+			wantBranches: 1,
+		},
+		{
+			name: "retry keeps old reply in sibling branch",
+			apply: func(t *testing.T, c *Context) {
+				prompt, err := c.PrepareRetry()
+				if err != nil || prompt.Content != "q2" {
+					t.Fatalf("PrepareRetry = %v, %v", prompt, err)
+				}
+				c.AddAssistantMessage("a2'")
+			},
+			wantActive:   []string{"sys", "q1", "a1", "q2", "a2'"},
+			wantBranches: 2,
+		},
+		{
+			name: "edit first message forks from root",
+			apply: func(t *testing.T, c *Context) {
+				if _, err := c.EditMessage(1, "q1'"); err != nil {
+					t.Fatal(err)
+				}
+				c.AddAssistantMessage("b1")
+			},
+			wantActive:   []string{"sys", "q1'", "b1"},
+			wantBranches: 2,
+		},
+		{
+			name: "switch back to original branch",
+			apply: func(t *testing.T, c *Context) {
+				original := c.ActiveLeaf
+				if _, err := c.EditMessage(3, "q2'"); err != nil {
+					t.Fatal(err)
+				}
+				if err := c.SwitchBranch(original); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantActive:   []string{"sys", "q1", "a1", "q2", "a2"},
+			wantBranches: 2,
+		},
+		{
+			name: "system message shared by all branches",
+			apply: func(t *testing.T, c *Context) {
+				if _, err := c.EditMessage(1, "x"); err != nil {
+					t.Fatal(err)
+				}
+				c.SetSystemMessage("sys'")
+			},
+			wantActive:   []string{"sys'", "x"},
+			wantBranches: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newDialog()
+			tt.apply(t, c)
+
+			if got := contents(c.ActiveBranch()); !reflect.DeepEqual(got, tt.wantActive) {
+				t.Errorf("ActiveBranch = %q, want %q", got, tt.wantActive)
+			}
+
+			branches := c.Branches()
+			if len(branches) != tt.wantBranches {
+				t.Fatalf("Branches = %d, want %d", len(branches), tt.wantBranches)
+			}
+			active := 0
+			for _, branch := range branches {
+				if branch.Active {
+					active++
+					if branch.LeafID != c.ActiveLeaf || branch.Length != len(tt.wantActive) {
+						t.Errorf("active branch = %+v, want leaf %s length %d", branch, c.ActiveLeaf, len(tt.wantActive))
+					}
+				}
+			}
+			if active != 1 {
+				t.Errorf("active branches = %d, want 1", active)
+			}
+		})
+	}
+}
+
+func TestContextBranchErrors(t *testing.T) {
+	c := newDialog()
+
+	if err := c.SwitchBranch("m99"); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("SwitchBranch(unknown) = %v, want ErrMessageNotFound", err)
+	}
+	for _, index := range []int{0, 5} {
+		if _, err := c.EditMessage(index, "x"); !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("EditMessage(%d) = %v, want ErrOutOfRange", index, err)
+		}
+	}
+
+	c.AddUserMessage("q3")
+	if _, err := c.PrepareRetry(); err == nil {
+		t.Error("PrepareRetry without reply succeeded")
+	}
+}
+
+func TestContextBranchCycle(t *testing.T) {
+	c := NewContext()
+	c.Messages = []Message{
+		{ID: "m1", ParentID: "m2", Role: "user"},
+		{ID: "m2", ParentID: "m1", Role: "assistant"},
+	}
+	c.ActiveLeaf = "m2"
+
+	if got := len(c.ActiveBranch()); got > len(c.Messages)+1 {
+		t.Errorf("ActiveBranch on cycle returned %d messages", got)
+	}
+}
+
+func TestNewContextUniqueSessionID(t *testing.T) {
+	// Сессии, созданные в одну секунду, не должны получать один идентификатор
+	seen := make(map[string]bool)
+	for range 100 {
+		id := NewContext().SessionID
+		if seen[id] {
+			t.Fatalf("duplicate session id %s", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"smollm-sandbox/internal/logging"
+)
+
+// Названия кэшей в счетчиках попаданий и промахов logging.Metrics
+const (
+	CACHE_RESPONSE  = "response"  // Кэш ответов на детерминированные запросы
+	CACHE_KV_PREFIX = "kv_prefix" // KV кэш общего префикса промпта сессии во встроенном движке
+)
+
+// ResponseCache хранит ответы модели на детерминированные запросы.
+// Реализация на файловой системе находится в пакете storage и передается
+// в SmolLM через SetResponseCache
+type ResponseCache interface {
+	// Get возвращает сохраненный ответ по ключу запроса
+	Get(key string) (*InferenceResponse, bool)
+	// Put сохраняет ответ на запрос с ключом key
+	Put(key string, response *InferenceResponse) error
+}
+
+// ResponseCacheKey возвращает ключ кэша ответа на запрос request: хэш
+// промпта, параметров генерации и seed вместе со способом генерации и
+// моделью, от которых зависит ответ
+func ResponseCacheKey(backend, modelPath string, request InferenceRequest) string {
+	data, _ := json.Marshal(struct {
+		Backend string           `json:"backend"`
+		Model   string           `json:"model"`
+		Request InferenceRequest `json:"request"`
+	}{backend, modelPath, request})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// SetResponseCache задает кэш ответов на запросы с заданным seed; nil - без кэша
+func (s *SmolLM) SetResponseCache(cache ResponseCache) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.cache = cache
+}
+
+// Metrics возвращает метрики модели, в том числе попадания и промахи кэшей
+func (s *SmolLM) Metrics() *logging.Metrics {
+	return s.logger.GetMetrics()
+}
+
+// infer выполняет запрос к модели. Ответ на детерминированный запрос
+// (cacheable) берется из кэша ответов cache и сохраняется в нем
+func (s *SmolLM) infer(ctx context.Context, cache ResponseCache, request InferenceRequest, cacheable bool) (*InferenceResponse, bool, error) {
+	metrics := s.logger.GetMetrics()
+
+	var key string
+	if cacheable && cache != nil {
+		key = ResponseCacheKey(s.inferencer.Backend(), s.modelPath, request)
+		if response, ok := cache.Get(key); ok {
+			metrics.IncrementCacheHit(CACHE_RESPONSE)
+			s.logger.Info("Response cache hit for prompt length: %d", len(request.Prompt))
+			if request.OnText != nil && response.Text != "" {
+				request.OnText(response.Text)
+			}
+			return response, true, nil
+		}
+		metrics.IncrementCacheMiss(CACHE_RESPONSE)
+	}
+
+	response, err := s.inferencer.GenerateRequest(ctx, request)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Префикс промпта переиспользует только встроенный движок
+	if request.Session != "" && s.inferencer.Backend() == BACKEND_NATIVE {
+		if response.CachedTokens > 0 {
+			metrics.IncrementCacheHit(CACHE_KV_PREFIX)
+		} else {
+			metrics.IncrementCacheMiss(CACHE_KV_PREFIX)
+		}
+	}
+
+	if key != "" {
+		if err := cache.Put(key, response); err != nil {
+			s.logger.Warn("Failed to cache response: %v", err)
+		}
+	}
+	return response, false, nil
+}
@@ -0,0 +1,38 @@
+package model
+
+import "testing"
+
+func TestResponseCacheKey(t *testing.T) {
+	base := InferenceRequest{Prompt: "prompt", MaxTokens: 16, Temperature: 0.7, TopK: 40, Seed: 42, StopTokens: []string{"\nUser:"}}
+	key := ResponseCacheKey(BACKEND_NATIVE, "model.gguf", base)
+
+	same := base
+	same.Session = "session"
+	same.OnText = func(string) {}
+	if got := ResponseCacheKey(BACKEND_NATIVE, "model.gguf", same); got != key {
+		t.Errorf("session and OnText changed the key")
+	}
+
+	tests := []struct {
+		name    string
+		backend string
+		model   string
+		mutate  func(r *InferenceRequest)
+	}{
+		{"backend", BACKEND_API, "model.gguf", func(r *InferenceRequest) {}},
+		{"model", BACKEND_NATIVE, "other.gguf", func(r *InferenceRequest) {}},
+		{"prompt", BACKEND_NATIVE, "model.gguf", func(r *InferenceRequest) { r.Prompt = "other" }},
+		{"seed", BACKEND_NATIVE, "model.gguf", func(r *InferenceRequest) { r.Seed = 43 }},
+		{"temperature", BACKEND_NATIVE, "model.gguf", func(r *InferenceRequest) { r.Temperature = 0 }},
+		{"max tokens", BACKEND_NATIVE, "model.gguf", func(r *InferenceRequest) { r.MaxTokens = 17 }},
+		{"stop tokens", BACKEND_NATIVE, "model.gguf", func(r *InferenceRequest) { r.StopTokens = nil }},
+	}
+
+	for _, tt := range tests {
+		request := base
+		tt.mutate(&request)
+		if got := ResponseCacheKey(tt.backend, tt.model, request); got == key {
+			t.Errorf("%s: key did not change", tt.name)
+		}
+	}
+}
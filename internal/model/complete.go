@@ -0,0 +1,301 @@
+package model
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Префикс сессии KV кэша для запросов Complete одного пользователя:
+// клиент присылает историю целиком, и ее начало совпадает между запросами
+const COMPLETION_SESSION_PREFIX = "complete:"
+
+// Complete генерирует ответ на диалог messages, не изменяя сессию: историю
+// хранит клиент, как в API в стиле OpenAI. Запрос не зависит от текущей
+// сессии модели: используются настройки модели и профиль opts.Profile (по
+// умолчанию - профиль default). Системные сообщения заменяют системную
+// инструкцию профиля, последнее сообщение должно быть от пользователя.
+// Запрос не ждет ходов диалога сессии. Память пользователя и теги
+// <remember> используются, только если память передана в opts.Memory
+func (s *SmolLM) Complete(messages []Message, opts ProcessOptions) (*ProcessResult, error) {
+	var system []string
+	var history []ContextEntry
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			system = append(system, msg.Content)
+		case "user", "assistant":
+			history = append(history, ContextEntry{Role: msg.Role, Content: msg.Content, Time: msg.Timestamp})
+		default:
+			return nil, i18n.NewError("model.complete_role", msg.Role).WithKind(ErrInvalidMessages)
+		}
+	}
+	if len(history) == 0 || history[len(history)-1].Role != "user" {
+		return nil, i18n.NewError("model.complete_no_user").WithKind(ErrInvalidMessages)
+	}
+	if len(history) > MAX_HISTORY_MESSAGES {
+		history = history[len(history)-MAX_HISTORY_MESSAGES:]
+	}
+
+	release, err := s.scheduler.Acquire(opts.context(), Job{
+		Priority: opts.Priority,
+		Owner:    opts.Owner,
+		OnQueued: opts.OnQueued,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(opts.context(), s.timeout)
+	defer cancel()
+
+	// Промпт собирается под мьютексом, генерация идет без него
+	s.mutex.Lock()
+	profileName := opts.Profile
+	if profileName == "" {
+		profileName = DEFAULT_PROFILE
+	}
+	profile, ok := s.profiles[profileName]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, i18n.NewError("model.profile_not_found", profileName).WithKind(ErrProfileNotFound)
+	}
+
+	systemMsg := profile.SystemPrompt
+	if len(system) > 0 {
+		systemMsg = strings.Join(system, "\n")
+	}
+	if opts.SystemPrompt != "" {
+		systemMsg = opts.SystemPrompt
+	}
+
+	var passages []Passage
+	if profile.Allows(TOOL_DOCUMENTS) {
+		passages = s.retrievePassages(ctx, history[len(history)-1].Content)
+	}
+
+	memory := opts.Memory
+	if !profile.Allows(TOOL_MEMORY) {
+		memory = nil
+	}
+	facts := s.memoryFacts(memory)
+
+	prompt := s.renderPrompt(profile, systemMsg, history, memory != nil, facts, passages)
+	generation := s.generation.Merge(profile.generation()).Merge(opts.Generation).withStop(opts.Stop)
+	cache := s.cache
+	s.mutex.Unlock()
+
+	deterministic := ValueOf(generation.Seed) != 0
+	if !deterministic {
+		generation.Seed = Int(rand.IntN(math.MaxInt32) + 1)
+	}
+
+	request := generation.request(prompt)
+	request.Session = COMPLETION_SESSION_PREFIX + opts.Owner
+	request.OnText = opts.OnText
+
+	start := time.Now()
+	inference, cached, err := s.infer(ctx, cache, request, deterministic)
+	latency := time.Since(start)
+	if err != nil {
+		err = s.timeoutError(ctx, err)
+		s.logger.Error("Completion error: %v", err)
+		return nil, err
+	}
+	text, remembered := s.applyRememberTags(memory, inference.Text)
+
+	return &ProcessResult{
+		Text:         text,
+		PromptTokens: inference.PromptTokens,
+		TokensUsed:   inference.TokensUsed,
+		Latency:      latency,
+		Citations:    passages,
+		Remembered:   remembered,
+		Cached:       cached,
+		Generation:   generation,
+	}, nil
+}
+
+// CompleteSession отвечает на input в сохраненной сессии name и сохраняет
+// запрос и ответ в ее активной ветке. Сессия не загружается в модель, поэтому
+// текущая сессия (CLI, бот) не меняется. Запросы в одной сессии выполняются
+// по очереди
+func (s *SmolLM) CompleteSession(name, input string, opts ProcessOptions) (*ProcessResult, error) {
+	s.mutex.Lock()
+	store := s.store
+	s.mutex.Unlock()
+
+	return s.CompleteSessionIn(store, name, input, opts)
+}
+
+// CompleteSessionIn работает как CompleteSession с сессией из хранилища
+// store вместо хранилища модели
+func (s *SmolLM) CompleteSessionIn(store SessionStore, name, input string, opts ProcessOptions) (*ProcessResult, error) {
+	return s.sessionTurn(store, name, opts, func(session *Context) error {
+		session.AddUserMessage(input)
+		return nil
+	})
+}
+
+// RetrySessionIn заново генерирует последний ответ ассистента в сохраненной
+// сессии name, как Retry для текущей сессии
+func (s *SmolLM) RetrySessionIn(store SessionStore, name string, opts ProcessOptions) (*ProcessResult, error) {
+	return s.sessionTurn(store, name, opts, func(session *Context) error {
+		_, err := session.PrepareRetry()
+		return err
+	})
+}
+
+// EditSessionIn заменяет сообщение пользователя с номером index в
+// сохраненной сессии name и генерирует на него новый ответ, как Edit для
+// текущей сессии
+func (s *SmolLM) EditSessionIn(store SessionStore, name string, index int, content string, opts ProcessOptions) (*ProcessResult, error) {
+	return s.sessionTurn(store, name, opts, func(session *Context) error {
+		branch := session.VisibleMessages()
+		if index >= 1 && index <= len(branch) && branch[index-1].Role != "user" {
+			return i18n.NewError("model.not_user_message", index)
+		}
+		_, err := session.EditMessage(index, content)
+		return err
+	})
+}
+
+// SwitchBranchIn делает активной ветку с номером index (с единицы) из
+// списка Branches сохраненной сессии name
+func (s *SmolLM) SwitchBranchIn(store SessionStore, name string, index int) error {
+	return s.updateSession(store, name, func(session *Context) error {
+		branches := session.Branches()
+		if index < 1 || index > len(branches) {
+			return i18n.NewError("model.branch_out_of_range", len(branches)).WithKind(ErrOutOfRange)
+		}
+		return session.SwitchBranch(branches[index-1].LeafID)
+	})
+}
+
+// UseProfileIn переключает сохраненную сессию name на профиль profile, как
+// UseProfile для текущей сессии. Профиль текущей сессии не меняется
+func (s *SmolLM) UseProfileIn(store SessionStore, name, profile string) error {
+	s.mutex.Lock()
+	selected, ok := s.profiles[profile]
+	s.mutex.Unlock()
+	if !ok {
+		return i18n.NewError("model.profile_not_found", profile).WithKind(ErrProfileNotFound)
+	}
+
+	return s.updateSession(store, name, func(session *Context) error {
+		session.SetSystemMessage(selected.SystemPrompt)
+		session.SetProperty(META_PROFILE, selected.Name)
+		return nil
+	})
+}
+
+// updateSession загружает сохраненную сессию name под ее блокировкой,
+// изменяет ее функцией update и сохраняет. При ошибке update сессия не
+// сохраняется
+func (s *SmolLM) updateSession(store SessionStore, name string, update func(session *Context) error) error {
+	if store == nil {
+		return i18n.NewError("model.store_not_set").WithKind(ErrStoreNotSet)
+	}
+
+	unlock := s.LockSession(name)
+	defer unlock()
+
+	session, err := store.LoadSession(name)
+	if err != nil {
+		return err
+	}
+	if err := update(session); err != nil {
+		return err
+	}
+	return store.SaveSession(name, session)
+}
+
+// sessionTurn выполняет ход диалога в сохраненной сессии name: prepare
+// подготавливает активную ветку, которая заканчивается сообщением
+// пользователя, ответ модели добавляется к ней. Сессия сохраняется только
+// при успешной генерации
+func (s *SmolLM) sessionTurn(store SessionStore, name string, opts ProcessOptions, prepare func(session *Context) error) (*ProcessResult, error) {
+	if store == nil {
+		return nil, i18n.NewError("model.store_not_set").WithKind(ErrStoreNotSet)
+	}
+
+	unlock := s.LockSession(name)
+	defer unlock()
+
+	session, err := store.LoadSession(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepare(session); err != nil {
+		return nil, err
+	}
+
+	// Профиль и параметры сессии действуют так же, как при работе с ней в
+	// CLI. Системная инструкция берется из истории сессии
+	if profile, ok := session.GetProperty(META_PROFILE); ok && profile != "" && opts.Profile == "" {
+		s.mutex.Lock()
+		_, defined := s.profiles[profile]
+		s.mutex.Unlock()
+
+		if defined {
+			opts.Profile = profile
+		} else {
+			s.logger.Warn("Session profile %s is not defined, using %s", profile, DEFAULT_PROFILE)
+		}
+	}
+	opts.Generation = session.Generation().Merge(opts.Generation)
+
+	result, err := s.Complete(session.ActiveBranch(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	session.AddAssistantMessage(result.Text)
+	if err := store.SaveSession(name, session); err != nil {
+		return nil, err
+	}
+
+	if reply, ok := session.GetLastAssistantMessage(); ok {
+		result.MessageID = reply.ID
+	}
+	return result, nil
+}
+
+// LockSession захватывает блокировку сохраненной сессии name и возвращает
+// функцию ее освобождения. Ее используют CompleteSession и клиенты, которые
+// изменяют сохраненные сессии в обход модели (создание, удаление)
+func (s *SmolLM) LockSession(name string) func() {
+	s.sessionMutex.Lock()
+	lock, ok := s.sessionLocks[name]
+	if !ok {
+		lock = &sessionLock{}
+		s.sessionLocks[name] = lock
+	}
+	lock.refs++
+	s.sessionMutex.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		// Блокировка удаляется, когда ее больше никто не держит и не ждет
+		s.sessionMutex.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(s.sessionLocks, name)
+		}
+		s.sessionMutex.Unlock()
+	}
+}
+
+// sessionLock - блокировка сохраненной сессии со счетчиком ссылок
+type sessionLock struct {
+	mu   sync.Mutex
+	refs int // Число держащих и ожидающих блокировку
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockSessionReleasesEntries(t *testing.T) {
+	s := &SmolLM{sessionLocks: make(map[string]*sessionLock)}
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := s.LockSession("chat")
+			unlock()
+		}()
+	}
+	wg.Wait()
+
+	unlock := s.LockSession("other")
+	if len(s.sessionLocks) != 1 {
+		t.Errorf("sessionLocks = %v, want only the held lock", s.sessionLocks)
+	}
+	unlock()
+	if len(s.sessionLocks) != 0 {
+		t.Errorf("sessionLocks = %v, want empty after release", s.sessionLocks)
+	}
+}
@@ -3,23 +3,49 @@ package model
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"reflect"
 	"time"
 )
 
-// Context представляет контекст сессии модели
+// CONTEXT_SCHEMA_VERSION - текущая версия формата сохраненной сессии.
+// При несовместимом изменении Message, ContextMeta или ContextState версию
+// нужно увеличить и зарегистрировать миграцию в пакете storage. Новые
+// необязательные поля с omitempty миграции не требуют
+const CONTEXT_SCHEMA_VERSION = 2
+
+// Context представляет контекст сессии модели. Сообщения образуют дерево:
+// каждое ссылается на родителя через ParentID, а ActiveLeaf указывает на
+// последнее сообщение текущей ветки диалога
 type Context struct {
-	SessionID string       `json:"session_id"`
-	Messages  []Message    `json:"messages"`
-	Metadata  ContextMeta  `json:"metadata"`
-	State     ContextState `json:"state"`
+	SchemaVersion int          `json:"schema_version"`
+	SessionID     string       `json:"session_id"`
+	Messages      []Message    `json:"messages"`    // Все сообщения всех веток в порядке создания
+	ActiveLeaf    string       `json:"active_leaf"` // ID последнего сообщения активной ветки
+	Metadata      ContextMeta  `json:"metadata"`
+	State         ContextState `json:"state"`
 }
 
 // Message представляет одно сообщение в контексте
 type Message struct {
-	Role      string    `json:"role"`      // "user", "assistant" или "system"
-	Content   string    `json:"content"`   // Содержимое сообщения
-	Timestamp time.Time `json:"timestamp"` // Время создания сообщения
+	ID        string    `json:"id"`                  // Уникальный в пределах контекста идентификатор
+	ParentID  string    `json:"parent_id,omitempty"` // Предыдущее сообщение ветки; пусто у корня
+	Role      string    `json:"role"`                // "user", "assistant" или "system"
+	Content   string    `json:"content"`             // Содержимое сообщения
+	Timestamp time.Time `json:"timestamp"`           // Время создания сообщения
+
+	Executions []ExecutionRecord `json:"executions,omitempty"` // Результаты выполнения кода из сообщения
+}
+
+// ExecutionRecord содержит результат выполнения кода в песочнице,
+// привязанный к сообщению, из которого код был взят
+type ExecutionRecord struct {
+	Language  string    `json:"language"`
+	Code      string    `json:"code"`
+	Success   bool      `json:"success"`
+	ExitCode  int       `json:"exit_code"`
+	Output    string    `json:"output"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // ContextMeta содержит метаданные контекста
@@ -36,14 +62,18 @@ type ContextState struct {
 	Temperature     float64 `json:"temperature"`      // Параметр temperature
 	TopP            float64 `json:"top_p"`            // Параметр top_p
 	ThinkingEnabled bool    `json:"thinking_enabled"` // Включен ли режим размышления
+
+	// Параметры генерации, переопределенные для сессии; nil - без переопределений
+	Generation *GenerationOptions `json:"generation,omitempty"`
 }
 
 // NewContext создает новый контекст
 func NewContext() *Context {
 	now := time.Now()
 	return &Context{
-		SessionID: fmt.Sprintf("session_%d", now.Unix()),
-		Messages:  []Message{},
+		SchemaVersion: CONTEXT_SCHEMA_VERSION,
+		SessionID:     fmt.Sprintf("session_%d", now.UnixNano()),
+		Messages:      []Message{},
 		Metadata: ContextMeta{
 			CreatedAt:  now,
 			UpdatedAt:  now,
@@ -59,34 +89,47 @@ func NewContext() *Context {
 	}
 }
 
-// AddUserMessage добавляет сообщение пользователя в контекст
+// AddUserMessage добавляет сообщение пользователя в активную ветку
 func (c *Context) AddUserMessage(content string) {
-	c.Messages = append(c.Messages, Message{
-		Role:      "user",
-		Content:   content,
-		Timestamp: time.Now(),
-	})
-	c.Metadata.UpdatedAt = time.Now()
+	c.addMessage("user", content)
 }
 
-// AddAssistantMessage добавляет сообщение ассистента в контекст
+// AddAssistantMessage добавляет сообщение ассистента в активную ветку
 func (c *Context) AddAssistantMessage(content string) {
-	c.Messages = append(c.Messages, Message{
-		Role:      "assistant",
-		Content:   content,
-		Timestamp: time.Now(),
-	})
-	c.Metadata.UpdatedAt = time.Now()
+	c.addMessage("assistant", content)
 }
 
-// AddSystemMessage добавляет системное сообщение в контекст
+// AddSystemMessage добавляет системное сообщение в активную ветку
 func (c *Context) AddSystemMessage(content string) {
-	c.Messages = append(c.Messages, Message{
-		Role:      "system",
-		Content:   content,
-		Timestamp: time.Now(),
-	})
-	c.Metadata.UpdatedAt = time.Now()
+	c.addMessage("system", content)
+}
+
+// SetSystemMessage заменяет текст системного сообщения в корне дерева, общего
+// для всех веток. В пустой контекст системное сообщение добавляется
+func (c *Context) SetSystemMessage(content string) {
+	for i := range c.Messages {
+		if c.Messages[i].Role == "system" && c.Messages[i].ParentID == "" {
+			c.Messages[i].Content = content
+			c.Metadata.UpdatedAt = time.Now()
+			return
+		}
+	}
+
+	if len(c.Messages) == 0 {
+		c.AddSystemMessage(content)
+	}
+}
+
+// AttachExecution привязывает результат выполнения кода к последнему
+// сообщению активной ветки
+func (c *Context) AttachExecution(record ExecutionRecord) {
+	for i := range c.Messages {
+		if c.Messages[i].ID == c.ActiveLeaf {
+			c.Messages[i].Executions = append(c.Messages[i].Executions, record)
+			c.Metadata.UpdatedAt = time.Now()
+			return
+		}
+	}
 }
 
 // SetProperty устанавливает свойство в метаданных
@@ -103,6 +146,8 @@ func (c *Context) GetProperty(key string) (string, bool) {
 
 // ToJSON сериализует контекст в JSON
 func (c *Context) ToJSON() ([]byte, error) {
+	// Контекст всегда сериализуется в текущем формате
+	c.SchemaVersion = CONTEXT_SCHEMA_VERSION
 	return json.Marshal(c)
 }
 
@@ -111,21 +156,23 @@ func (c *Context) FromJSON(data []byte) error {
 	return json.Unmarshal(data, c)
 }
 
-// GetLastUserMessage возвращает последнее сообщение пользователя
+// GetLastUserMessage возвращает последнее сообщение пользователя активной ветки
 func (c *Context) GetLastUserMessage() (Message, bool) {
-	for i := len(c.Messages) - 1; i >= 0; i-- {
-		if c.Messages[i].Role == "user" {
-			return c.Messages[i], true
+	branch := c.ActiveBranch()
+	for i := len(branch) - 1; i >= 0; i-- {
+		if branch[i].Role == "user" {
+			return branch[i], true
 		}
 	}
 	return Message{}, false
 }
 
-// GetLastAssistantMessage возвращает последнее сообщение ассистента
+// GetLastAssistantMessage возвращает последнее сообщение ассистента активной ветки
 func (c *Context) GetLastAssistantMessage() (Message, bool) {
-	for i := len(c.Messages) - 1; i >= 0; i-- {
-		if c.Messages[i].Role == "assistant" {
-			return c.Messages[i], true
+	branch := c.ActiveBranch()
+	for i := len(branch) - 1; i >= 0; i-- {
+		if branch[i].Role == "assistant" {
+			return branch[i], true
 		}
 	}
 	return Message{}, false
@@ -137,18 +184,46 @@ func (c *Context) SetMode(mode string) {
 	c.Metadata.UpdatedAt = time.Now()
 }
 
-// SetTemperature устанавливает параметр temperature
+// SetTemperature устанавливает параметр temperature сессии
 func (c *Context) SetTemperature(temperature float64) {
 	c.State.Temperature = temperature
+	c.sessionGeneration().Temperature = Float(temperature)
 	c.Metadata.UpdatedAt = time.Now()
 }
 
-// SetTopP устанавливает параметр top_p
+// SetTopP устанавливает параметр top_p сессии
 func (c *Context) SetTopP(topP float64) {
 	c.State.TopP = topP
+	c.sessionGeneration().TopP = Float(topP)
 	c.Metadata.UpdatedAt = time.Now()
 }
 
+// SetGeneration заменяет параметры генерации, переопределенные для сессии.
+// Пустые параметры снимают переопределения
+func (c *Context) SetGeneration(opts GenerationOptions) {
+	c.State.Generation = nil
+	if !reflect.ValueOf(opts).IsZero() {
+		c.State.Generation = &opts
+	}
+	c.Metadata.UpdatedAt = time.Now()
+}
+
+// Generation возвращает параметры генерации, переопределенные для сессии
+func (c *Context) Generation() GenerationOptions {
+	if c.State.Generation == nil {
+		return GenerationOptions{}
+	}
+	return *c.State.Generation
+}
+
+// sessionGeneration возвращает переопределения сессии, создавая их при необходимости
+func (c *Context) sessionGeneration() *GenerationOptions {
+	if c.State.Generation == nil {
+		c.State.Generation = &GenerationOptions{}
+	}
+	return c.State.Generation
+}
+
 // EnableThinking включает режим размышления
 func (c *Context) EnableThinking(enabled bool) {
 	c.State.ThinkingEnabled = enabled
@@ -159,7 +234,8 @@ func (c *Context) EnableThinking(enabled bool) {
 func (c *Context) GetSummary() map[string]any {
 	return map[string]any{
 		"session_id":       c.SessionID,
-		"message_count":    len(c.Messages),
+		"message_count":    len(c.ActiveBranch()),
+		"branch_count":     len(c.Branches()),
 		"created_at":       c.Metadata.CreatedAt,
 		"updated_at":       c.Metadata.UpdatedAt,
 		"mode":             c.State.Mode,
@@ -167,54 +243,3 @@ func (c *Context) GetSummary() map[string]any {
 		"thinking_enabled": c.State.ThinkingEnabled,
 	}
 }
-
-// Save сохраняет контекст в файл
-func (c *Context) Save(name string) error {
-	// Формируем имя файла
-	fileName := fmt.Sprintf("sessions/%s.json", name)
-
-	// Сериализуем контекст в JSON
-	data, err := c.ToJSON()
-	if err != nil {
-		return fmt.Errorf("ошибка сериализации контекста: %v", err)
-	}
-
-	// Создаем директорию если нужно
-	if err := os.MkdirAll("sessions", 0755); err != nil {
-		return fmt.Errorf("ошибка создания директории сессий: %v", err)
-	}
-
-	// Сохраняем файл
-	if err := os.WriteFile(fileName, data, 0644); err != nil {
-		return fmt.Errorf("ошибка записи файла сессии: %v", err)
-	}
-
-	return nil
-}
-
-// LoadContext загружает контекст из файла
-func LoadContext(name string) (*Context, error) {
-	// Формируем имя файла
-	fileName := fmt.Sprintf("sessions/%s.json", name)
-
-	// Проверяем существование файла
-	if _, err := os.Stat(fileName); os.IsNotExist(err) {
-		return nil, fmt.Errorf("сессия не найдена: %s", name)
-	}
-
-	// Читаем файл
-	data, err := os.ReadFile(fileName)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения файла сессии: %v", err)
-	}
-
-	// Создаем новый контекст
-	context := NewContext()
-
-	// Десериализуем данные
-	if err := context.FromJSON(data); err != nil {
-		return nil, fmt.Errorf("ошибка десериализации контекста: %v", err)
-	}
-
-	return context, nil
-}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"errors"
+	"net/http"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Виды ошибок модели. По ним клиенты выбирают ответ пользователю и код
+// статуса API
+var (
+	ErrModelUnavailable  = errors.New("model: model unavailable")
+	ErrGenerationFailed  = errors.New("model: generation failed")
+	ErrStoreNotSet       = errors.New("model: session store not set")
+	ErrProfileNotFound   = errors.New("model: profile not found")
+	ErrInvalidProfile    = errors.New("model: invalid profile")
+	ErrMessageNotFound   = errors.New("model: message not found")
+	ErrOutOfRange        = errors.New("model: index out of range")
+	ErrToolDenied        = errors.New("model: tool not allowed by profile")
+	ErrInvalidGeneration = errors.New("model: invalid generation options")
+	ErrQueueFull         = errors.New("model: request queue is full")
+	ErrCanceled          = errors.New("model: request canceled")
+	ErrGenerationTimeout = errors.New("model: generation timed out")
+	ErrInvalidMessages   = errors.New("model: invalid messages")
+)
+
+// APIError описывает ответ API модели с кодом статуса, отличным от 200.
+// Соответствует ErrGenerationFailed, а при недоступности сервера
+// (502, 503, 504) - также ErrModelUnavailable
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error возвращает текст ошибки на языке по умолчанию
+func (e *APIError) Error() string {
+	return e.Unwrap().Error()
+}
+
+// Unwrap возвращает ошибку с кодом сообщения для локализации
+func (e *APIError) Unwrap() error {
+	return i18n.NewError("model.api_status", e.StatusCode, e.Body)
+}
+
+// Is сообщает, относится ли ошибка к виду target
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrGenerationFailed:
+		return true
+	case ErrModelUnavailable:
+		return e.StatusCode == http.StatusBadGateway ||
+			e.StatusCode == http.StatusServiceUnavailable ||
+			e.StatusCode == http.StatusGatewayTimeout
+	}
+	return false
+}
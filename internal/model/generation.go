@@ -0,0 +1,238 @@
+package model
+
+import (
+	"slices"
+	"strconv"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Названия параметров генерации для команд клиентов (совпадают с ключами YAML)
+const (
+	GEN_TEMPERATURE        = "temperature"
+	GEN_TOP_P              = "top_p"
+	GEN_TOP_K              = "top_k"
+	GEN_MIN_P              = "min_p"
+	GEN_REPETITION_PENALTY = "repetition_penalty"
+	GEN_FREQUENCY_PENALTY  = "frequency_penalty"
+	GEN_PRESENCE_PENALTY   = "presence_penalty"
+	GEN_STOP               = "stop"
+	GEN_MAX_TOKENS         = "max_tokens"
+	GEN_SEED               = "seed"
+)
+
+// GenerationParameters - названия всех параметров генерации в порядке вывода
+var GenerationParameters = []string{
+	GEN_TEMPERATURE, GEN_TOP_P, GEN_TOP_K, GEN_MIN_P,
+	GEN_REPETITION_PENALTY, GEN_FREQUENCY_PENALTY, GEN_PRESENCE_PENALTY,
+	GEN_STOP, GEN_MAX_TOKENS, GEN_SEED,
+}
+
+// GenerationOptions содержит параметры сэмплирования. Поле nil означает
+// "не задано": берется значение уровня ниже (настройки модели, профиль,
+// сессия, запрос - от общего к частному). Заданный ноль тоже переопределяет
+// значение, например temperature 0 включает жадный выбор токена. У Stop не
+// задан только nil, пустой список отключает стоп-строки
+type GenerationOptions struct {
+	Temperature       *float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	TopP              *float64 `yaml:"top_p,omitempty" json:"top_p,omitempty"`
+	TopK              *int     `yaml:"top_k,omitempty" json:"top_k,omitempty"`
+	MinP              *float64 `yaml:"min_p,omitempty" json:"min_p,omitempty"`                           // Доля вероятности самого вероятного токена, ниже которой токены отбрасываются
+	RepetitionPenalty *float64 `yaml:"repetition_penalty,omitempty" json:"repetition_penalty,omitempty"` // Делитель логитов токенов промпта и ответа; 1 - без штрафа
+	FrequencyPenalty  *float64 `yaml:"frequency_penalty,omitempty" json:"frequency_penalty,omitempty"`   // Вычитается из логита за каждое повторение токена в ответе
+	PresencePenalty   *float64 `yaml:"presence_penalty,omitempty" json:"presence_penalty,omitempty"`     // Вычитается из логита токена, уже встречавшегося в ответе
+	Stop              []string `yaml:"stop" json:"stop"`                                                 // Генерация останавливается на первой из строк
+	MaxTokens         *int     `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`                 // Максимум сгенерированных токенов
+	Seed              *int     `yaml:"seed,omitempty" json:"seed,omitempty"`                             // Фиксированный seed; 0 - случайный
+}
+
+// Float возвращает заданное значение параметра генерации
+func Float(value float64) *float64 {
+	return &value
+}
+
+// Int возвращает заданное целое значение параметра генерации
+func Int(value int) *int {
+	return &value
+}
+
+// ValueOf возвращает значение параметра генерации или 0, если он не задан
+func ValueOf[T float64 | int](value *T) T {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// DefaultGenerationOptions возвращает параметры генерации по умолчанию.
+// Стоп-строка завершает ответ, когда модель начинает реплику пользователя
+func DefaultGenerationOptions() GenerationOptions {
+	return GenerationOptions{
+		Temperature: Float(TEMPERATURE),
+		TopP:        Float(TOP_P),
+		Stop:        []string{"\n" + USER_PREFIX},
+		MaxTokens:   Int(MAX_TOKENS / 2),
+	}
+}
+
+// Merge возвращает параметры, в которых заданные поля override заменяют
+// значения o
+func (o GenerationOptions) Merge(override GenerationOptions) GenerationOptions {
+	if override.Temperature != nil {
+		o.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		o.TopP = override.TopP
+	}
+	if override.TopK != nil {
+		o.TopK = override.TopK
+	}
+	if override.MinP != nil {
+		o.MinP = override.MinP
+	}
+	if override.RepetitionPenalty != nil {
+		o.RepetitionPenalty = override.RepetitionPenalty
+	}
+	if override.FrequencyPenalty != nil {
+		o.FrequencyPenalty = override.FrequencyPenalty
+	}
+	if override.PresencePenalty != nil {
+		o.PresencePenalty = override.PresencePenalty
+	}
+	if override.Stop != nil {
+		o.Stop = override.Stop
+	}
+	if override.MaxTokens != nil {
+		o.MaxTokens = override.MaxTokens
+	}
+	if override.Seed != nil {
+		o.Seed = override.Seed
+	}
+	return o
+}
+
+// withStop возвращает параметры, к стоп-строкам которых добавлены stop
+func (o GenerationOptions) withStop(stop []string) GenerationOptions {
+	if len(stop) > 0 {
+		o.Stop = append(slices.Clone(o.Stop), stop...)
+	}
+	return o
+}
+
+// Validate проверяет диапазоны заданных параметров
+func (o GenerationOptions) Validate() error {
+	switch {
+	case outside(o.Temperature, 0, 2):
+		return invalidGenerationOption(GEN_TEMPERATURE, "0..2")
+	case outside(o.TopP, 0, 1):
+		return invalidGenerationOption(GEN_TOP_P, "0..1")
+	case ValueOf(o.TopK) < 0:
+		return invalidGenerationOption(GEN_TOP_K, ">= 0")
+	case outside(o.MinP, 0, 1):
+		return invalidGenerationOption(GEN_MIN_P, "0..1")
+	case ValueOf(o.RepetitionPenalty) < 0:
+		return invalidGenerationOption(GEN_REPETITION_PENALTY, ">= 0")
+	case outside(o.FrequencyPenalty, -2, 2):
+		return invalidGenerationOption(GEN_FREQUENCY_PENALTY, "-2..2")
+	case outside(o.PresencePenalty, -2, 2):
+		return invalidGenerationOption(GEN_PRESENCE_PENALTY, "-2..2")
+	case ValueOf(o.MaxTokens) < 0:
+		return invalidGenerationOption(GEN_MAX_TOKENS, ">= 0")
+	}
+	return nil
+}
+
+// Set задает параметр name из текстовых значений команды клиента. Для stop
+// каждое значение - отдельная стоп-строка с escape-последовательностями Go
+// (\n, \t); без значений стоп-строки отключаются
+func (o *GenerationOptions) Set(name string, values []string) error {
+	if name == GEN_STOP {
+		stop := make([]string, 0, len(values))
+		for _, value := range values {
+			if unquoted, err := strconv.Unquote(`"` + value + `"`); err == nil {
+				value = unquoted
+			}
+			stop = append(stop, value)
+		}
+		o.Stop = stop
+		return nil
+	}
+
+	if len(values) != 1 {
+		return i18n.NewError("model.generation_value_missing", name).WithKind(ErrInvalidGeneration)
+	}
+	value := values[0]
+
+	var err error
+	switch name {
+	case GEN_TEMPERATURE:
+		o.Temperature, err = parseFloat(value)
+	case GEN_TOP_P:
+		o.TopP, err = parseFloat(value)
+	case GEN_TOP_K:
+		o.TopK, err = parseInt(value)
+	case GEN_MIN_P:
+		o.MinP, err = parseFloat(value)
+	case GEN_REPETITION_PENALTY:
+		o.RepetitionPenalty, err = parseFloat(value)
+	case GEN_FREQUENCY_PENALTY:
+		o.FrequencyPenalty, err = parseFloat(value)
+	case GEN_PRESENCE_PENALTY:
+		o.PresencePenalty, err = parseFloat(value)
+	case GEN_MAX_TOKENS:
+		o.MaxTokens, err = parseInt(value)
+	case GEN_SEED:
+		o.Seed, err = parseInt(value)
+	default:
+		return i18n.NewError("model.generation_unknown", name).WithKind(ErrInvalidGeneration)
+	}
+	if err != nil {
+		return i18n.WrapError(err, "model.generation_value", name, value).WithKind(ErrInvalidGeneration)
+	}
+	return o.Validate()
+}
+
+// request создает запрос к модели с этими параметрами
+func (o GenerationOptions) request(prompt string) InferenceRequest {
+	return InferenceRequest{
+		Prompt:            prompt,
+		MaxTokens:         ValueOf(o.MaxTokens),
+		Temperature:       ValueOf(o.Temperature),
+		TopP:              ValueOf(o.TopP),
+		TopK:              ValueOf(o.TopK),
+		MinP:              ValueOf(o.MinP),
+		RepetitionPenalty: ValueOf(o.RepetitionPenalty),
+		FrequencyPenalty:  ValueOf(o.FrequencyPenalty),
+		PresencePenalty:   ValueOf(o.PresencePenalty),
+		StopTokens:        o.Stop,
+		Seed:              ValueOf(o.Seed),
+	}
+}
+
+// outside проверяет, что заданное значение лежит вне диапазона [min, max]
+func outside(value *float64, min, max float64) bool {
+	return value != nil && (*value < min || *value > max)
+}
+
+// parseFloat разбирает дробное значение параметра
+func parseFloat(value string) (*float64, error) {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseInt разбирает целое значение параметра
+func parseInt(value string) (*int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// invalidGenerationOption создает ошибку значения параметра вне допустимого диапазона
+func invalidGenerationOption(name, limits string) error {
+	return i18n.NewError("model.generation_range", name, limits).WithKind(ErrInvalidGeneration)
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenerationOptionsMerge(t *testing.T) {
+	base := GenerationOptions{
+		Temperature:      Float(0.7),
+		TopK:             Int(40),
+		FrequencyPenalty: Float(0.5),
+		Stop:             []string{"\nUser:"},
+		Seed:             Int(42),
+	}
+
+	tests := []struct {
+		name     string
+		override GenerationOptions
+		want     GenerationOptions
+	}{
+		{"empty override keeps base", GenerationOptions{}, base},
+		{
+			"zero values override",
+			GenerationOptions{Temperature: Float(0), TopK: Int(0), FrequencyPenalty: Float(0), Seed: Int(0)},
+			GenerationOptions{Temperature: Float(0), TopK: Int(0), FrequencyPenalty: Float(0), Stop: base.Stop, Seed: Int(0)},
+		},
+		{
+			"empty stop list disables stop strings",
+			GenerationOptions{Stop: []string{}},
+			GenerationOptions{Temperature: Float(0.7), TopK: Int(40), FrequencyPenalty: Float(0.5), Stop: []string{}, Seed: Int(42)},
+		},
+		{
+			"unset fields fall through",
+			GenerationOptions{TopP: Float(0.9), MaxTokens: Int(10)},
+			GenerationOptions{Temperature: Float(0.7), TopP: Float(0.9), TopK: Int(40), FrequencyPenalty: Float(0.5), Stop: base.Stop, MaxTokens: Int(10), Seed: Int(42)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Merge(tt.override); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge = %s, want %s", formatGeneration(got), formatGeneration(tt.want))
+			}
+		})
+	}
+}
+
+func TestGenerationOptionsSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		check   func(GenerationOptions) bool
+		wantErr error
+	}{
+		{GEN_TEMPERATURE, []string{"0"}, func(o GenerationOptions) bool { return o.Temperature != nil && *o.Temperature == 0 }, nil},
+		{GEN_PRESENCE_PENALTY, []string{"-1.5"}, func(o GenerationOptions) bool { return ValueOf(o.PresencePenalty) == -1.5 }, nil},
+		{GEN_TOP_K, []string{"5"}, func(o GenerationOptions) bool { return ValueOf(o.TopK) == 5 }, nil},
+		{GEN_STOP, []string{`\n###`}, func(o GenerationOptions) bool { return reflect.DeepEqual(o.Stop, []string{"\n###"}) }, nil},
+		{GEN_STOP, nil, func(o GenerationOptions) bool { return o.Stop != nil && len(o.Stop) == 0 }, nil},
+		{GEN_TEMPERATURE, []string{"3"}, nil, ErrInvalidGeneration},
+		{GEN_TOP_K, []string{"many"}, nil, ErrInvalidGeneration},
+		{GEN_SEED, nil, nil, ErrInvalidGeneration},
+		{"beam_width", []string{"2"}, nil, ErrInvalidGeneration},
+	}
+
+	for _, tt := range tests {
+		var opts GenerationOptions
+		err := opts.Set(tt.name, tt.values)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Set(%s, %q) = %v, want %v", tt.name, tt.values, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !tt.check(opts) {
+			t.Errorf("Set(%s, %q) = %v, options %s", tt.name, tt.values, err, formatGeneration(opts))
+		}
+	}
+}
+
+func TestGenerationOptionsRequest(t *testing.T) {
+	request := GenerationOptions{Temperature: Float(0), TopK: Int(3)}.request("prompt")
+	if request.Temperature != 0 || request.TopK != 3 || request.TopP != 0 || request.Seed != 0 {
+		t.Errorf("request = %+v", request)
+	}
+}
+
+// formatGeneration выводит параметры для сообщений об ошибках
+func formatGeneration(o GenerationOptions) string {
+	data, _ := json.Marshal(o)
+	return string(data)
+}
@@ -3,27 +3,51 @@ package model
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model/llama"
+	"smollm-sandbox/internal/modelserver"
+)
+
+// Способы выполнения генерации (model.backend в конфигурации)
+const (
+	BACKEND_AUTO   = "auto"   // Встроенный движок, если в директории модели есть веса safetensors, иначе сервер модели
+	BACKEND_NATIVE = "native" // Встроенный движок на Go без внешних процессов
+	BACKEND_API    = "api"    // HTTP сервер модели на Python; при ошибке запуска - рабочий процесс
+	BACKEND_WORKER = "worker" // Рабочий процесс Python
+
+	API_CAPACITY = 2 // Одновременных запросов к серверу модели по умолчанию
 )
 
 // InferenceRequest представляет запрос к модели
 type InferenceRequest struct {
-	Prompt      string   `json:"prompt"`
-	MaxTokens   int      `json:"max_tokens"`
-	Temperature float64  `json:"temperature"`
-	TopP        float64  `json:"top_p"`
-	TopK        int      `json:"top_k,omitempty"`
-	StopTokens  []string `json:"stop_tokens,omitempty"`
-	Seed        int      `json:"seed,omitempty"`
+	Prompt            string   `json:"prompt"`
+	MaxTokens         int      `json:"max_tokens"`
+	Temperature       float64  `json:"temperature"`
+	TopP              float64  `json:"top_p"`
+	TopK              int      `json:"top_k,omitempty"`
+	MinP              float64  `json:"min_p,omitempty"`
+	RepetitionPenalty float64  `json:"repetition_penalty,omitempty"`
+	FrequencyPenalty  float64  `json:"frequency_penalty,omitempty"`
+	PresencePenalty   float64  `json:"presence_penalty,omitempty"`
+	StopTokens        []string `json:"stop_tokens,omitempty"`
+	Seed              int      `json:"seed,omitempty"`
+
+	// Сессия для повторного использования KV кэша общего префикса промпта
+	// встроенным движком. Не влияет на ответ и не передается серверу модели
+	Session string `json:"-"`
+
+	// Получает текст ответа по частям. Встроенный движок передает текст по
+	// мере генерации, остальные способы - весь ответ одной частью
+	OnText func(delta string) `json:"-"`
 }
 
 // InferenceResponse представляет ответ от модели
@@ -32,6 +56,7 @@ type InferenceResponse struct {
 	TokensUsed   int     `json:"tokens_used"`
 	GeneratedIn  float64 `json:"generated_in"`
 	PromptTokens int     `json:"prompt_tokens"`
+	CachedTokens int     `json:"cached_tokens,omitempty"` // Токены промпта из KV кэша сессии
 }
 
 // Inferencer обеспечивает инференс модели
@@ -40,282 +65,157 @@ type Inferencer struct {
 	modelPath  string
 	httpClient *http.Client
 	apiURL     string
-	useAPI     bool
-	modelCmd   *exec.Cmd
-	modelProc  *os.Process
+	backend    string               // Выбранный способ генерации (BACKEND_NATIVE, BACKEND_API или BACKEND_WORKER)
+	server     *modelserver.Manager // Сервер модели, используемый в режиме API
+
+	workerMu sync.Mutex
+	worker   *modelserver.Worker // Рабочий процесс локальной генерации; создается при первом запросе
+
+	engineMu  sync.Mutex
+	engine    *llama.Model // Встроенный движок; загружается при первом запросе
+	engineErr error        // Ошибка последней попытки загрузки встроенного движка
+
+	contextLength int // Длина контекста из метаданных модели; 0 - неизвестна
 }
 
-// NewInferencer создает новый экземпляр Inferencer
-func NewInferencer(modelPath string) *Inferencer {
+// NewInferencer создает новый экземпляр Inferencer. В режиме BACKEND_AUTO
+// встроенный движок выбирается, если в modelPath есть его файлы
+func NewInferencer(modelPath string, backend string) *Inferencer {
+	return NewInferencerWithServer(backend, modelserver.Config{ModelPath: modelPath})
+}
+
+// NewInferencerWithServer создает Inferencer с моделью server.ModelPath и
+// заданными настройками сервера модели. Если по адресу сервера уже отвечает
+// сервер модели, в режиме BACKEND_API используется он
+func NewInferencerWithServer(backend string, server modelserver.Config) *Inferencer {
 	logger := logging.NewLogger()
+	modelPath := server.ModelPath
 
 	// Проверяем существование модели
 	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
 		logger.Warn("Model path does not exist: %s", modelPath)
 	}
 
+	switch backend {
+	case BACKEND_NATIVE, BACKEND_API, BACKEND_WORKER:
+	case BACKEND_AUTO, "":
+		backend = BACKEND_API
+		if llama.Available(modelPath) {
+			backend = BACKEND_NATIVE
+		}
+	default:
+		logger.Warn("Unknown model backend %q, using %s", backend, BACKEND_API)
+		backend = BACKEND_API
+	}
+	logger.Info("Model backend: %s", backend)
+
 	httpClient := &http.Client{
 		Timeout: 60 * time.Second,
 	}
 
+	manager := modelserver.NewManager(server)
+
 	inf := &Inferencer{
 		logger:     logger,
 		modelPath:  modelPath,
 		httpClient: httpClient,
-		apiURL:     "http://localhost:8000/v1/generate", // Локальный API URL
-		useAPI:     true,                                // По умолчанию используем API
+		apiURL:     manager.URL() + "/v1/generate", // Локальный API URL
+		backend:    backend,
+		server:     manager,
 	}
 
-	// Запускаем API сервер модели, если он будет использоваться
-	if inf.useAPI {
-		err := inf.startModelServer()
-		if err != nil {
-			logger.Error("Failed to start model server: %v", err)
-			// Если не удалось запустить API, будем использовать прямой вызов Python
-			inf.useAPI = false
+	// Длина контекста читается из заголовка модели без загрузки весов
+	if backend == BACKEND_NATIVE {
+		if config, err := llama.ReadConfig(modelPath); err != nil {
+			logger.Warn("Failed to read model metadata: %v", err)
+		} else {
+			inf.contextLength = config.MaxPositions
+			logger.Info("Model context length: %d tokens", config.MaxPositions)
 		}
 	}
 
-	return inf
-}
-
-// startModelServer запускает локальный API сервер модели
-func (i *Inferencer) startModelServer() error {
-	i.logger.Info("Starting model API server...")
-
-	// Проверяем, запущен ли уже сервер
-	resp, err := http.Get(i.apiURL + "/health")
-	if err == nil && resp.StatusCode == http.StatusOK {
-		i.logger.Info("Model API server is already running")
-		return nil
-	}
-
-	// Создаем временную директорию для скрипта
-	scriptDir := "/tmp/smollm_api"
-	os.MkdirAll(scriptDir, 0755)
-
-	// Путь к скрипту
-	scriptPath := filepath.Join(scriptDir, "server.py")
-
-	// Создаем Python скрипт для запуска сервера
-	script := `
-import os
-import sys
-import json
-import time
-import traceback
-
-# Проверка и установка зависимостей
-def install_dependencies():
-    import subprocess
-    import pkg_resources
-
-    def package_installed(package):
-        try:
-            pkg_resources.get_distribution(package)
-            return True
-        except pkg_resources.DistributionNotFound:
-            return False
-
-    def install_package(package):
-        subprocess.check_call([sys.executable, "-m", "pip", "install", package])
-
-    # Список необходимых пакетов
-    required_packages = [
-        "torch",
-        "transformers", 
-        "fastapi", 
-        "uvicorn", 
-        "pydantic"
-    ]
-
-    for package in required_packages:
-        if not package_installed(package):
-            print(f"Устанавливаю {package}...")
-            install_package(package)
-
-# Установка зависимостей перед импортом
-install_dependencies()
-
-import torch
-from transformers import pipeline, AutoModelForCausalLM, AutoTokenizer
-from fastapi import FastAPI, HTTPException, Body
-from pydantic import BaseModel
-import uvicorn
-
-# Настройки безопасности и совместимости
-torch.set_default_dtype(torch.float32)
-
-# Определяем модель и токенизатор
-MODEL_PATH = os.environ.get("MODEL_PATH", "")
-if not MODEL_PATH:
-    print("MODEL_PATH не установлен")
-    sys.exit(1)
-
-print(f"Загрузка модели из {MODEL_PATH}")
-
-# Безопасная загрузка модели с обработкой ошибок
-try:
-    tokenizer = AutoTokenizer.from_pretrained(MODEL_PATH)
-    model = AutoModelForCausalLM.from_pretrained(
-        MODEL_PATH, 
-        torch_dtype=torch.float32,
-        device_map="auto"
-    )
-
-    generator = pipeline(
-        "text-generation",
-        model=model,
-        tokenizer=tokenizer
-    )
-except Exception as e:
-    print(f"Ошибка при загрузке модели: {e}")
-    traceback.print_exc()
-    sys.exit(1)
-
-# Создаем FastAPI приложение
-app = FastAPI(title="SmolLM2 API")
-
-class GenerateRequest(BaseModel):
-    prompt: str
-    max_tokens: int = 512
-    temperature: float = 0.7
-    top_p: float = 0.9
-    top_k: int = 40
-    stop_tokens: list = []
-    seed: int = None
-
-class GenerateResponse(BaseModel):
-    text: str
-    tokens_used: int
-    generated_in: float
-    prompt_tokens: int
-
-@app.get("/health")
-def health_check():
-    return {"status": "ok"}
-
-@app.post("/v1/generate")
-def generate(request: GenerateRequest = Body(...)):
-    start_time = time.time()
-    
-    # Устанавливаем seed если указан
-    if request.seed is not None:
-        torch.manual_seed(request.seed)
-    
-    # Вычисляем количество токенов в промпте
-    prompt_tokens = len(tokenizer.encode(request.prompt))
-    
-    # Генерируем ответ
-    try:
-        outputs = generator(
-            request.prompt,
-            max_new_tokens=request.max_tokens,
-            temperature=request.temperature,
-            top_p=request.top_p,
-            top_k=request.top_k,
-            do_sample=True,
-            pad_token_id=tokenizer.eos_token_id
-        )
-        
-        # Получаем сгенерированный текст
-        generated_text = outputs[0]["generated_text"]
-        
-        # Отрезаем промпт, чтобы получить только сгенерированный текст
-        if generated_text.startswith(request.prompt):
-            generated_text = generated_text[len(request.prompt):]
-        
-        # Если есть стоп-токены, обрезаем по ним
-        for stop_token in request.stop_tokens:
-            if stop_token in generated_text:
-                generated_text = generated_text.split(stop_token)[0]
-        
-        # Общее количество использованных токенов
-        total_tokens = len(tokenizer.encode(generated_text)) + prompt_tokens
-        
-        # Время генерации
-        generation_time = time.time() - start_time
-        
-        return GenerateResponse(
-            text=generated_text,
-            tokens_used=total_tokens,
-            generated_in=generation_time,
-            prompt_tokens=prompt_tokens
-        )
-    except Exception as e:
-        print(f"Ошибка генерации: {e}")
-        traceback.print_exc()
-        raise HTTPException(status_code=500, detail=str(e))
-
-if __name__ == "__main__":
-    uvicorn.run(app, host="localhost", port=8000)
-`
-
-	// Записываем скрипт в файл
-	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
-		return fmt.Errorf("ошибка создания скрипта сервера: %v", err)
+	// Запускаем API сервер модели. Если запустить его не удалось, генерация
+	// выполняется рабочим процессом, которому нужны только torch и transformers
+	if backend == BACKEND_API {
+		if err := manager.Start(); err != nil {
+			logger.Error("Failed to start model server, falling back to local worker: %s", i18n.LocalizeError(err))
+			manager.Close()
+			inf.backend = BACKEND_WORKER
+		}
 	}
 
-	// Создаем виртуальное окружение
-	venvPath := filepath.Join(scriptDir, "venv")
-	cmd := exec.Command("python3", "-m", "venv", venvPath)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ошибка создания виртуального окружения: %v", err)
-	}
+	return inf
+}
 
-	// Запускаем сервер в фоновом режиме
-	i.modelCmd = exec.Command(
-		filepath.Join(venvPath, "bin", "python"),
-		scriptPath,
-	)
+// ContextLength возвращает длину контекста модели в токенах из ее
+// метаданных или 0, если она неизвестна (сервер модели, рабочий процесс)
+func (i *Inferencer) ContextLength() int {
+	return i.contextLength
+}
 
-	// Устанавливаем окружение с путем к модели
-	i.modelCmd.Env = append(os.Environ(),
-		fmt.Sprintf("MODEL_PATH=%s", i.modelPath),
-		fmt.Sprintf("PYTHONPATH=%s", venvPath+"/lib/python3.11/site-packages"),
-	)
+// Backend возвращает выбранный способ генерации
+func (i *Inferencer) Backend() string {
+	return i.backend
+}
 
-	// Перенаправляем вывод в файл
-	logFile, err := os.Create(filepath.Join(scriptDir, "server.log"))
-	if err != nil {
-		return fmt.Errorf("ошибка создания файла логов: %v", err)
+// Capacity возвращает число запросов, которые способ генерации выполняет
+// одновременно. Встроенный движок и рабочий процесс выполняют запросы по
+// очереди, сервер модели обрабатывает их в пуле потоков
+func (i *Inferencer) Capacity() int {
+	if i.backend == BACKEND_API {
+		return API_CAPACITY
 	}
-	i.modelCmd.Stdout = logFile
-	i.modelCmd.Stderr = logFile
+	return 1
+}
 
-	// Запускаем процесс
-	if err := i.modelCmd.Start(); err != nil {
-		return fmt.Errorf("ошибка запуска сервера: %v", err)
+// ServerState возвращает состояние того, что выполняет генерацию: сервера
+// модели в режиме API, рабочего процесса или встроенного движка
+func (i *Inferencer) ServerState() modelserver.State {
+	switch i.backend {
+	case BACKEND_API:
+		return i.server.State()
+
+	case BACKEND_NATIVE:
+		i.engineMu.Lock()
+		defer i.engineMu.Unlock()
+
+		switch {
+		case i.engine != nil:
+			return modelserver.STATE_READY
+		case i.engineErr != nil:
+			return modelserver.STATE_FAILED
+		}
+		return modelserver.STATE_STOPPED
 	}
 
-	i.modelProc = i.modelCmd.Process
-	i.logger.Info("Model API server started, PID: %d", i.modelProc.Pid)
+	i.workerMu.Lock()
+	defer i.workerMu.Unlock()
 
-	// Ждем запуска сервера с расширенным таймаутом
-	for attempts := 0; attempts < 60; attempts++ {
-		resp, err := http.Get(i.apiURL + "/health")
-		if err == nil && resp.StatusCode == http.StatusOK {
-			i.logger.Info("Model API server is now running")
-			return nil
-		}
-		time.Sleep(2 * time.Second)
+	if i.worker == nil {
+		return modelserver.STATE_STOPPED
 	}
-
-	return fmt.Errorf("таймаут ожидания запуска сервера")
+	return i.worker.State()
 }
 
-// Close освобождает ресурсы и завершает процессы
+// Close освобождает ресурсы и останавливает сервер модели и рабочий процесс
 func (i *Inferencer) Close() {
-	// Если запущен API сервер, останавливаем его
-	if i.modelProc != nil {
-		i.logger.Info("Stopping model API server...")
-		i.modelProc.Kill()
+	i.server.Close()
+
+	i.workerMu.Lock()
+	defer i.workerMu.Unlock()
+
+	if i.worker != nil {
+		i.worker.Close()
 	}
 }
 
-// SetUseAPI устанавливает режим использования API
+// SetUseAPI переключает генерацию между сервером модели и рабочим процессом
 func (i *Inferencer) SetUseAPI(useAPI bool) {
-	i.useAPI = useAPI
+	if useAPI {
+		i.backend = BACKEND_API
+	} else {
+		i.backend = BACKEND_WORKER
+	}
 }
 
 // SetAPIURL устанавливает URL API
@@ -323,36 +223,42 @@ func (i *Inferencer) SetAPIURL(apiURL string) {
 	i.apiURL = apiURL
 }
 
-// Generate выполняет генерацию текста с помощью модели
-func (i *Inferencer) Generate(ctx context.Context, prompt string, maxTokens int, temperature float64, topP float64) (string, error) {
-	if i.useAPI {
-		return i.generateViaAPI(ctx, prompt, maxTokens, temperature, topP)
-	} else {
-		return i.generateLocally(ctx, prompt, maxTokens, temperature, topP)
+// GenerateRequest выполняет генерацию по полному запросу и возвращает ответ со статистикой
+func (i *Inferencer) GenerateRequest(ctx context.Context, request InferenceRequest) (*InferenceResponse, error) {
+	var response *InferenceResponse
+	var err error
+	switch i.backend {
+	case BACKEND_NATIVE:
+		return i.generateNatively(ctx, request)
+	case BACKEND_API:
+		response, err = i.generateViaAPI(ctx, request)
+	default:
+		response, err = i.generateLocally(ctx, request)
+	}
+
+	if err == nil && request.OnText != nil && response.Text != "" {
+		request.OnText(response.Text)
 	}
+	return response, err
 }
 
 // generateViaAPI выполняет генерацию через HTTP API
-func (i *Inferencer) generateViaAPI(ctx context.Context, prompt string, maxTokens int, temperature float64, topP float64) (string, error) {
-	// Создаем запрос
-	request := InferenceRequest{
-		Prompt:      prompt,
-		MaxTokens:   maxTokens,
-		Temperature: temperature,
-		TopP:        topP,
-		StopTokens:  []string{"\n\n"},
+func (i *Inferencer) generateViaAPI(ctx context.Context, request InferenceRequest) (*InferenceResponse, error) {
+	// Сервер, который еще загружается или перезапускается, не примет запрос
+	if strings.HasPrefix(i.apiURL, i.server.URL()) && !i.server.Ready() {
+		return nil, i18n.WrapError(i.server.Err(), "model.server_not_ready", i.server.State()).WithKind(ErrModelUnavailable)
 	}
 
 	// Сериализуем в JSON
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("ошибка сериализации запроса: %v", err)
+		return nil, i18n.WrapError(err, "model.request_encode")
 	}
 
 	// Создаем HTTP запрос
 	req, err := http.NewRequestWithContext(ctx, "POST", i.apiURL, strings.NewReader(string(jsonData)))
 	if err != nil {
-		return "", fmt.Errorf("ошибка создания HTTP запроса: %v", err)
+		return nil, i18n.WrapError(err, "model.request_create")
 	}
 
 	// Устанавливаем заголовки
@@ -362,218 +268,155 @@ func (i *Inferencer) generateViaAPI(ctx context.Context, prompt string, maxToken
 	start := time.Now()
 	resp, err := i.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("ошибка выполнения HTTP запроса: %v", err)
+		// Отмена запроса вызывающей стороной не означает недоступность модели
+		requestErr := i18n.WrapError(err, "model.request_failed")
+		if ctx.Err() == nil {
+			requestErr.WithKind(ErrModelUnavailable)
+		}
+		return nil, requestErr
 	}
 	defer resp.Body.Close()
 
 	// Проверяем статус
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("ошибка API: %s, код: %d", string(body), resp.StatusCode)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Читаем ответ
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("ошибка чтения ответа: %v", err)
+		return nil, i18n.WrapError(err, "model.response_read")
 	}
 
 	// Разбираем JSON
 	var response InferenceResponse
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("ошибка разбора JSON: %v", err)
+		return nil, i18n.WrapError(err, "model.response_decode").WithKind(ErrGenerationFailed)
 	}
 
 	elapsed := time.Since(start)
 	i.logger.Info("API inference completed in %v, tokens used: %d", elapsed, response.TokensUsed)
 
-	return response.Text, nil
+	return &response, nil
 }
 
-// generateLocally выполняет генерацию локально через Python
-func (i *Inferencer) generateLocally(ctx context.Context, prompt string, maxTokens int, temperature float64, topP float64) (string, error) {
-	i.logger.Info("Local inference for prompt length: %d", len(prompt))
-
-	// Создаем временную директорию для скрипта
-	scriptDir := "/tmp/smollm_inference"
-	os.MkdirAll(scriptDir, 0755)
-
-	// Путь к скрипту
-	scriptPath := filepath.Join(scriptDir, "inference.py")
-
-	// Экранируем специальные символы в промпте
-	safePrompt := strings.ReplaceAll(prompt, "\\", "\\\\")
-	safePrompt = strings.ReplaceAll(safePrompt, "\"", "\\\"")
-	safePrompt = strings.ReplaceAll(safePrompt, "\n", "\\n")
-
-	// Создаем Python скрипт для инференса
-	script := fmt.Sprintf(`
-import os
-import sys
-import json
-import time
-import traceback
-import subprocess
-import ensurepip
-
-def log(message):
-    timestamp = time.strftime("%%Y-%%m-%%d %%H:%%M:%%S")
-    print(f"[{timestamp}] {message}", flush=True)
-
-def install_dependencies():
-    log("Настройка окружения...")
-    
-    try:
-        # Принудительная установка pip
-        log("Обеспечение наличия pip...")
-        ensurepip.bootstrap()
-        
-        log("Установка зависимостей...")
-        subprocess.run([
-            sys.executable, "-m", "pip", 
-            "install", "-U", 
-            "--user", 
-            "pip", "torch", "transformers"
-        ], check=True, capture_output=True)
-
-    except Exception as e:
-        log(f"Ошибка установки: {e}")
-        # Расширенная диагностика
-        try:
-            import site
-            log(f"User site-packages: {site.getusersitepackages()}")
-        except:
-            log("Не удалось получить информацию о site-packages")
-        raise
-
-log("1. Начало подготовки окружения")
-
-try:
-    install_dependencies()
-
-    log("2. Импорт библиотек...")
-    import torch
-    from transformers import pipeline, AutoModelForCausalLM, AutoTokenizer
-
-    log("3. Загрузка модели...")
-    # Загрузка модели
-    model_path = %q
-    log(f"   Путь к модели: {model_path}")
-    
-    log("   Загрузка токенизатора...")
-    tokenizer = AutoTokenizer.from_pretrained(model_path)
-    
-    log("   Загрузка модели...")
-    model = AutoModelForCausalLM.from_pretrained(
-        model_path, 
-        torch_dtype=torch.float32
-    )
-
-    log("4. Создание генератора...")
-    # Создание генератора
-    generator = pipeline(
-        "text-generation",
-        model=model,
-        tokenizer=tokenizer
-    )
-
-    log("5. Генерация текста...")
-    # Генерация текста
-    prompt = %q
-    max_tokens = %d
-    temperature = %f
-
-    log(f"   Промпт: {prompt}")
-    log(f"   Макс. токенов: {max_tokens}")
-    log(f"   Температура: {temperature}")
-
-    outputs = generator(
-        prompt,
-        max_new_tokens=max_tokens,
-        temperature=temperature,
-        do_sample=True
-    )
-
-    # Получаем сгенерированный текст
-    generated_text = outputs[0]["generated_text"]
-
-    # Отрезаем промпт
-    if generated_text.startswith(prompt):
-        generated_text = generated_text[len(prompt):]
-
-    log("6. Текст сгенерирован успешно")
-
-    # Возвращаем результат
-    result = {
-        "text": generated_text,
-        "success": True
-    }
-    
-    print(json.dumps(result))
-
-except Exception as e:
-    log(f"ОШИБКА: {e}")
-    error_result = {
-        "text": "",
-        "success": False,
-        "error": str(e),
-        "traceback": traceback.format_exc()
-    }
-    print(json.dumps(error_result))
-
-log("7. Скрипт завершен")
-`, i.modelPath, safePrompt, maxTokens, temperature)
-
-	// Записываем скрипт в файл
-	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
-		return "", fmt.Errorf("ошибка создания скрипта: %v", err)
+// generateLocally выполняет генерацию в рабочем процессе Python без сервера
+// модели. Рабочий процесс запускается при первом запросе и загружает модель один раз
+func (i *Inferencer) generateLocally(ctx context.Context, request InferenceRequest) (*InferenceResponse, error) {
+	i.logger.Info("Local inference for prompt length: %d", len(request.Prompt))
+
+	worker, err := i.localWorker()
+	if err != nil {
+		return nil, i18n.WrapError(err, "model.local_unavailable").WithKind(ErrModelUnavailable)
 	}
 
-	// Создаем команду для выполнения скрипта
-	cmd := exec.CommandContext(ctx, "python3", scriptPath)
+	response, err := worker.Generate(ctx, modelserver.WorkerRequest{
+		Prompt:            request.Prompt,
+		MaxTokens:         request.MaxTokens,
+		Temperature:       request.Temperature,
+		TopP:              request.TopP,
+		TopK:              request.TopK,
+		MinP:              request.MinP,
+		RepetitionPenalty: request.RepetitionPenalty,
+		FrequencyPenalty:  request.FrequencyPenalty,
+		PresencePenalty:   request.PresencePenalty,
+		StopTokens:        request.StopTokens,
+		Seed:              request.Seed,
+	})
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		return nil, err
+	case errors.Is(err, modelserver.ErrRequestFailed):
+		return nil, i18n.WrapError(err, "model.local_failed").WithKind(ErrGenerationFailed)
+	default:
+		return nil, i18n.WrapError(err, "model.local_unavailable").WithKind(ErrModelUnavailable)
+	}
 
-	// Получаем вывод
-	output, err := cmd.Output()
-	if err != nil {
-		var stderr string
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			stderr = string(exitErr.Stderr)
+	return &InferenceResponse{
+		Text:         response.Text,
+		TokensUsed:   response.TokensUsed,
+		GeneratedIn:  response.GeneratedIn,
+		PromptTokens: response.PromptTokens,
+	}, nil
+}
+
+// localWorker возвращает рабочий процесс локальной генерации, запуская его
+// при первом вызове. Упавший процесс перезапускает его супервизор
+func (i *Inferencer) localWorker() (*modelserver.Worker, error) {
+	i.workerMu.Lock()
+	defer i.workerMu.Unlock()
+
+	if i.worker == nil {
+		i.worker = modelserver.NewWorker(modelserver.Config{ModelPath: i.modelPath})
+		if err := i.worker.Start(); err != nil {
+			return nil, err
 		}
-		return "", fmt.Errorf("ошибка выполнения скрипта: %v, stderr: %s", err, stderr)
 	}
+	return i.worker, nil
+}
 
-	// Парсим JSON-результат
-	var result struct {
-		Text    string `json:"text"`
-		Success bool   `json:"success"`
-		Error   string `json:"error,omitempty"`
+// generateNatively выполняет генерацию встроенным движком в текущем процессе
+func (i *Inferencer) generateNatively(ctx context.Context, request InferenceRequest) (*InferenceResponse, error) {
+	engine, err := i.nativeEngine()
+	if err != nil {
+		return nil, i18n.WrapError(err, "model.native_unavailable").WithKind(ErrModelUnavailable)
 	}
 
-	if err := json.Unmarshal(output, &result); err != nil {
-		return "", fmt.Errorf("ошибка парсинга вывода: %v, output: %s", err, string(output))
+	response, err := engine.Generate(ctx, llama.Request{
+		Prompt:    request.Prompt,
+		MaxTokens: request.MaxTokens,
+		SamplingParams: llama.SamplingParams{
+			Temperature:       request.Temperature,
+			TopP:              request.TopP,
+			TopK:              request.TopK,
+			MinP:              request.MinP,
+			RepetitionPenalty: request.RepetitionPenalty,
+			FrequencyPenalty:  request.FrequencyPenalty,
+			PresencePenalty:   request.PresencePenalty,
+		},
+		StopTokens: request.StopTokens,
+		Seed:       request.Seed,
+		Session:    request.Session,
+		OnText:     request.OnText,
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, i18n.WrapError(err, "model.native_failed").WithKind(ErrGenerationFailed)
 	}
 
-	if !result.Success {
-		return "", fmt.Errorf("ошибка генерации: %s", result.Error)
-	}
+	i.logger.Info("Native inference completed in %.2fs, tokens used: %d, cached: %d", response.GeneratedIn, response.TokensUsed, response.CachedTokens)
 
-	return result.Text, nil
+	return &InferenceResponse{
+		Text:         response.Text,
+		TokensUsed:   response.TokensUsed,
+		GeneratedIn:  response.GeneratedIn,
+		PromptTokens: response.PromptTokens,
+		CachedTokens: response.CachedTokens,
+	}, nil
 }
 
-// ThinkingGenerate генерирует текст в режиме размышления
-func (i *Inferencer) ThinkingGenerate(ctx context.Context, prompt string, maxTokens int) (string, error) {
-	// Для режима размышления используем фиксированные параметры
-	temperature := 0.9 // Более высокая температура для креативности
-	topP := 0.95
+// nativeEngine возвращает встроенный движок, загружая веса при первом
+// вызове. Ошибка загрузки не запоминается: следующий запрос повторит
+// загрузку, например после того, как файлы модели будут докачаны
+func (i *Inferencer) nativeEngine() (*llama.Model, error) {
+	i.engineMu.Lock()
+	defer i.engineMu.Unlock()
 
-	// Добавляем контекст размышления
-	thinkingPrompt := "Размышляю самостоятельно без участия пользователя: " + prompt
+	if i.engine != nil {
+		return i.engine, nil
+	}
 
-	// Генерируем текст
-	result, err := i.Generate(ctx, thinkingPrompt, maxTokens, temperature, topP)
+	i.logger.Info("Loading model weights from %s", i.modelPath)
+	engine, err := llama.Load(i.modelPath)
 	if err != nil {
-		return "", err
+		i.engineErr = err
+		i.logger.Error("Failed to load model: %v", err)
+		return nil, err
 	}
-
-	return result, nil
+	i.engine, i.engineErr = engine, nil
+	return engine, nil
 }
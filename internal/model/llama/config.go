@@ -0,0 +1,194 @@
+package llama
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Файлы модели в формате Hugging Face
+const (
+	CONFIG_FILE            = "config.json"
+	GENERATION_CONFIG_FILE = "generation_config.json"
+	TOKENIZER_FILE         = "tokenizer.json"
+	WEIGHTS_FILE           = "model.safetensors"
+	WEIGHTS_INDEX_FILE     = "model.safetensors.index.json"
+
+	ARCHITECTURE = "LlamaForCausalLM" // Единственная поддерживаемая архитектура
+)
+
+// Config - гиперпараметры модели из config.json или заголовка GGUF
+type Config struct {
+	Architectures     []string `json:"architectures"`
+	HiddenSize        int      `json:"hidden_size"`
+	IntermediateSize  int      `json:"intermediate_size"`
+	NumLayers         int      `json:"num_hidden_layers"`
+	NumHeads          int      `json:"num_attention_heads"`
+	NumKVHeads        int      `json:"num_key_value_heads"`
+	HeadDim           int      `json:"head_dim"`
+	VocabSize         int      `json:"vocab_size"`
+	MaxPositions      int      `json:"max_position_embeddings"`
+	RMSNormEps        float64  `json:"rms_norm_eps"`
+	RopeTheta         float64  `json:"rope_theta"`
+	TieWordEmbeddings bool     `json:"tie_word_embeddings"`
+	EOSTokenIDs       tokenIDs `json:"eos_token_id"`
+
+	RopeScaling *RopeScaling `json:"rope_scaling"`
+}
+
+// RopeScaling - масштабирование позиций RoPE. Поддерживается только линейное:
+// позиции делятся на Factor
+type RopeScaling struct {
+	Type     string  `json:"type"`
+	RopeType string  `json:"rope_type"` // Название поля в новых версиях transformers
+	Factor   float64 `json:"factor"`
+}
+
+// tokenIDs - идентификатор токена или список идентификаторов: в config.json
+// встречаются обе формы
+type tokenIDs []int
+
+// UnmarshalJSON принимает число, список чисел или null
+func (t *tokenIDs) UnmarshalJSON(data []byte) error {
+	var id int
+	if err := json.Unmarshal(data, &id); err == nil {
+		*t = tokenIDs{id}
+		return nil
+	}
+
+	var ids []int
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return err
+	}
+	*t = ids
+	return nil
+}
+
+// Available сообщает, есть ли файлы, нужные встроенному движку: файл GGUF
+// (modelPath или единственный .gguf в директории modelPath) либо
+// конфигурация, токенизатор и веса safetensors в директории modelPath
+func Available(modelPath string) bool {
+	if _, ok := ggufPath(modelPath); ok {
+		return true
+	}
+	for _, name := range []string{CONFIG_FILE, TOKENIZER_FILE} {
+		if _, err := os.Stat(filepath.Join(modelPath, name)); err != nil {
+			return false
+		}
+	}
+	for _, name := range []string{WEIGHTS_FILE, WEIGHTS_INDEX_FILE} {
+		if _, err := os.Stat(filepath.Join(modelPath, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadConfig читает гиперпараметры модели без загрузки весов: из заголовка
+// файла GGUF или из config.json
+func ReadConfig(modelPath string) (*Config, error) {
+	if path, ok := ggufPath(modelPath); ok {
+		file, err := readGGUF(path, false)
+		if err != nil {
+			return nil, err
+		}
+		return file.config()
+	}
+	return readHFConfig(modelPath)
+}
+
+// readHFConfig читает config.json модели. Идентификаторы конца генерации
+// дополняются значениями из generation_config.json
+func readHFConfig(modelPath string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Join(modelPath, CONFIG_FILE))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, i18n.WrapError(err, "llama.config_read", modelPath).WithKind(ErrModelNotFound)
+		}
+		return nil, i18n.WrapError(err, "llama.config_read", modelPath)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, i18n.WrapError(err, "llama.config_decode").WithKind(ErrUnsupportedModel)
+	}
+
+	if err := config.normalize(); err != nil {
+		return nil, err
+	}
+
+	// generation_config.json необязателен; его ошибки не мешают загрузке
+	var generation struct {
+		EOSTokenIDs tokenIDs `json:"eos_token_id"`
+	}
+	if data, err := os.ReadFile(filepath.Join(modelPath, GENERATION_CONFIG_FILE)); err == nil {
+		if json.Unmarshal(data, &generation) == nil {
+			for _, id := range generation.EOSTokenIDs {
+				if !slices.Contains(config.EOSTokenIDs, id) {
+					config.EOSTokenIDs = append(config.EOSTokenIDs, id)
+				}
+			}
+		}
+	}
+
+	return &config, nil
+}
+
+// normalize проверяет, что архитектура поддерживается, и заполняет
+// необязательные параметры значениями по умолчанию
+func (c *Config) normalize() error {
+	if !slices.Contains(c.Architectures, ARCHITECTURE) {
+		return i18n.NewError("llama.unsupported_architecture", c.Architectures).WithKind(ErrUnsupportedModel)
+	}
+
+	if c.HeadDim == 0 && c.NumHeads > 0 {
+		c.HeadDim = c.HiddenSize / c.NumHeads
+	}
+	if c.NumKVHeads == 0 {
+		c.NumKVHeads = c.NumHeads
+	}
+	if c.RopeTheta == 0 {
+		c.RopeTheta = 10000
+	}
+	if c.RMSNormEps == 0 {
+		c.RMSNormEps = 1e-6
+	}
+
+	if c.HiddenSize <= 0 || c.NumLayers <= 0 || c.NumHeads <= 0 ||
+		c.IntermediateSize <= 0 || c.VocabSize <= 0 || c.HeadDim%2 != 0 ||
+		c.NumHeads%c.NumKVHeads != 0 {
+		return i18n.NewError("llama.invalid_config").WithKind(ErrUnsupportedModel)
+	}
+
+	if scaling := c.RopeScaling; scaling != nil {
+		kind := scaling.Type
+		if kind == "" {
+			kind = scaling.RopeType
+		}
+		switch kind {
+		case "", "default", "none":
+			c.RopeScaling = nil
+		case "linear":
+			if scaling.Factor <= 0 {
+				return i18n.NewError("llama.invalid_config").WithKind(ErrUnsupportedModel)
+			}
+		default:
+			return i18n.NewError("llama.unsupported_rope_scaling", kind).WithKind(ErrUnsupportedModel)
+		}
+	}
+
+	return nil
+}
+
+// ropeScale возвращает делитель позиций RoPE
+func (c *Config) ropeScale() float64 {
+	if c.RopeScaling != nil {
+		return c.RopeScaling.Factor
+	}
+	return 1
+}
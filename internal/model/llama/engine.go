@@ -0,0 +1,304 @@
+package llama
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+)
+
+// Параметры генерации по умолчанию
+const (
+	DEFAULT_MAX_TOKENS = 512
+	PREFILL_CHUNK      = 64 // Токенов промпта за один проход; между проходами проверяется отмена
+	MAX_PREFIX_CACHES  = 4  // KV кэшей сессий для повторного использования префикса промпта
+)
+
+// Request - запрос генерации
+type Request struct {
+	Prompt    string
+	MaxTokens int // 0 - DEFAULT_MAX_TOKENS
+	SamplingParams
+	StopTokens []string // Генерация останавливается на первой из строк, сама строка отбрасывается
+	Seed       int      // 0 - случайный seed
+
+	// Сессия, KV кэш которой переиспользуется для общего префикса промпта
+	// следующего запроса; пусто - без повторного использования
+	Session string
+
+	// Получает текст ответа по частям по мере генерации; nil - без потоковой
+	// выдачи. Части в сумме дают Response.Text
+	OnText func(delta string)
+}
+
+// Response - результат генерации
+type Response struct {
+	Text         string
+	PromptTokens int
+	TokensUsed   int     // Токены промпта и сгенерированные токены
+	GeneratedIn  float64 // Время генерации в секундах
+	CachedTokens int     // Токены промпта, взятые из KV кэша сессии
+}
+
+// Model - модель SmolLM2 (архитектура Llama), выполняемая на CPU без внешних
+// процессов. Запросы выполняются по очереди: каждый занимает все ядра
+type Model struct {
+	logger      *logging.Logger
+	config      *Config
+	tokenizer   *Tokenizer
+	transformer *Transformer
+	eos         map[int]bool
+
+	mu       sync.Mutex
+	prefixes []*prefixCache // KV кэши сессий, последний использованный - в конце
+}
+
+// prefixCache - KV кэш последовательности сессии вместе с ее токенами
+type prefixCache struct {
+	session string
+	tokens  []int // Токены, ключи и значения которых находятся в cache
+	cache   *Cache
+}
+
+// Load загружает модель из файла GGUF (modelPath или единственный .gguf в
+// директории modelPath) либо из директории в формате Hugging Face
+// (config.json, tokenizer.json, model.safetensors)
+func Load(modelPath string) (*Model, error) {
+	logger := logging.NewLogger()
+	start := time.Now()
+
+	var (
+		config    *Config
+		tokenizer *Tokenizer
+		tensors   map[string]*Tensor
+		err       error
+	)
+	if path, ok := ggufPath(modelPath); ok {
+		config, tokenizer, tensors, err = loadGGUF(path)
+	} else {
+		config, tokenizer, tensors, err = loadHF(modelPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transformer, err := newTransformer(*config, tensors)
+	if err != nil {
+		return nil, err
+	}
+
+	eos := make(map[int]bool)
+	for _, id := range config.EOSTokenIDs {
+		eos[id] = true
+	}
+
+	logger.Info("Loaded model from %s in %v: %d layers, hidden size %d, vocabulary %d",
+		modelPath, time.Since(start).Round(time.Millisecond), config.NumLayers, config.HiddenSize, config.VocabSize)
+
+	return &Model{
+		logger:      logger,
+		config:      config,
+		tokenizer:   tokenizer,
+		transformer: transformer,
+		eos:         eos,
+	}, nil
+}
+
+// loadHF загружает модель из директории в формате Hugging Face
+func loadHF(modelPath string) (*Config, *Tokenizer, map[string]*Tensor, error) {
+	config, err := readHFConfig(modelPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tokenizer, err := LoadTokenizer(modelPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tensors, err := loadWeights(modelPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return config, tokenizer, tensors, nil
+}
+
+// Config возвращает гиперпараметры модели
+func (m *Model) Config() Config {
+	return *m.config
+}
+
+// Tokenizer возвращает токенизатор модели
+func (m *Model) Tokenizer() *Tokenizer {
+	return m.tokenizer
+}
+
+// Generate генерирует продолжение промпта. Отмена ctx прерывает генерацию
+// между токенами и возвращает ctx.Err()
+func (m *Model) Generate(ctx context.Context, request Request) (*Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := time.Now()
+
+	prompt := m.tokenizer.Encode(request.Prompt)
+	if len(prompt) == 0 {
+		return nil, i18n.NewError("llama.empty_prompt")
+	}
+	if m.config.MaxPositions > 0 && len(prompt) >= m.config.MaxPositions {
+		return nil, i18n.NewError("llama.prompt_too_long", len(prompt), m.config.MaxPositions).WithKind(ErrPromptTooLong)
+	}
+
+	maxTokens := request.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DEFAULT_MAX_TOKENS
+	}
+	if m.config.MaxPositions > 0 {
+		maxTokens = min(maxTokens, m.config.MaxPositions-len(prompt))
+	}
+
+	prefix := m.prefixCache(request.Session)
+	cache := prefix.cache
+	sampler := NewSampler(request.SamplingParams, uint64(request.Seed))
+	sampler.Prompt(prompt)
+
+	// Общий с прошлым запросом сессии префикс промпта уже в кэше. Последний
+	// токен промпта обрабатывается заново: нужны логиты после него
+	cached := min(commonPrefix(prefix.tokens, prompt), len(prompt)-1)
+	cache.truncate(cached)
+	prefix.tokens = append(prefix.tokens[:cached], prompt[cached:]...)
+
+	// При отмене в кэше остаются только обработанные токены
+	defer func() { prefix.tokens = prefix.tokens[:cache.Len()] }()
+
+	// Промпт обрабатывается пакетами; логиты нужны только после последнего
+	var logits []float32
+	for from := cached; from < len(prompt); from += PREFILL_CHUNK {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		logits = m.transformer.Forward(prompt[from:min(from+PREFILL_CHUNK, len(prompt))], cache)
+	}
+
+	var generated []int
+	text := ""
+	streamed := 0 // Длина уже переданной в OnText части текста
+	for len(generated) < maxTokens {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		token := sampler.Sample(logits)
+		if m.eos[token] {
+			break
+		}
+		sampler.Accept(token)
+		generated = append(generated, token)
+
+		text = m.tokenizer.Decode(generated, true)
+		if stop, found := cutStop(text, request.StopTokens); found {
+			text = stop
+			break
+		}
+		if request.OnText != nil {
+			if safe := streamable(text, request.StopTokens); safe > streamed {
+				request.OnText(text[streamed:safe])
+				streamed = safe
+			}
+		}
+
+		if len(generated) < maxTokens {
+			logits = m.transformer.Forward([]int{token}, cache)
+			prefix.tokens = append(prefix.tokens, token)
+		}
+	}
+
+	if request.OnText != nil && len(text) > streamed {
+		request.OnText(text[streamed:])
+	}
+
+	elapsed := time.Since(start)
+	m.logger.Debug("Generated %d tokens for %d prompt tokens (%d cached) in %v", len(generated), len(prompt), cached, elapsed)
+
+	return &Response{
+		Text:         text,
+		PromptTokens: len(prompt),
+		TokensUsed:   len(prompt) + len(generated),
+		GeneratedIn:  elapsed.Seconds(),
+		CachedTokens: cached,
+	}, nil
+}
+
+// prefixCache возвращает KV кэш сессии, создавая его при первом запросе.
+// Кэш запроса без сессии не сохраняется. Вызывается с захваченным мьютексом
+func (m *Model) prefixCache(session string) *prefixCache {
+	if session == "" {
+		return &prefixCache{cache: m.transformer.NewCache()}
+	}
+
+	for i, prefix := range m.prefixes {
+		if prefix.session == session {
+			m.prefixes = append(append(m.prefixes[:i], m.prefixes[i+1:]...), prefix)
+			return prefix
+		}
+	}
+
+	// Вытесняется кэш сессии, которая дольше всех не обращалась к модели
+	if len(m.prefixes) >= MAX_PREFIX_CACHES {
+		m.prefixes = m.prefixes[1:]
+	}
+	prefix := &prefixCache{session: session, cache: m.transformer.NewCache()}
+	m.prefixes = append(m.prefixes, prefix)
+	return prefix
+}
+
+// commonPrefix возвращает длину общего префикса последовательностей токенов
+func commonPrefix(a, b []int) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// streamable возвращает длину начала текста, которую можно передать
+// клиенту: без незавершенного символа UTF-8 в конце и без хвоста, с которого
+// может начаться стоп-строка
+func streamable(text string, stops []string) int {
+	safe := len(text)
+	for safe > 0 && strings.HasSuffix(text[:safe], string(utf8.RuneError)) {
+		safe -= len(string(utf8.RuneError))
+	}
+
+	for _, stop := range stops {
+		for n := min(len(stop)-1, safe); n > 0; n-- {
+			if strings.HasPrefix(stop, text[safe-n:safe]) {
+				safe -= n
+				break
+			}
+		}
+	}
+	return safe
+}
+
+// cutStop обрезает текст по первой найденной стоп-строке
+func cutStop(text string, stops []string) (string, bool) {
+	cut := -1
+	for _, stop := range stops {
+		if stop == "" {
+			continue
+		}
+		if i := strings.Index(text, stop); i >= 0 && (cut < 0 || i < cut) {
+			cut = i
+		}
+	}
+	if cut < 0 {
+		return text, false
+	}
+	return text[:cut], true
+}
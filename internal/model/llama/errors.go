@@ -0,0 +1,12 @@
+package llama
+
+import "errors"
+
+// Виды ошибок встроенного движка: загрузка весов и токенизатора, длина промпта
+var (
+	ErrModelNotFound    = errors.New("llama: model files not found")
+	ErrUnsupportedModel = errors.New("llama: unsupported model")
+	ErrInvalidWeights   = errors.New("llama: invalid weights")
+	ErrInvalidTokenizer = errors.New("llama: invalid tokenizer")
+	ErrPromptTooLong    = errors.New("llama: prompt exceeds context length")
+)
@@ -0,0 +1,555 @@
+package llama
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Параметры формата GGUF (файлы моделей llama.cpp)
+const (
+	GGUF_EXTENSION = ".gguf"
+	GGUF_MAGIC     = "GGUF"
+	GGUF_ALIGNMENT = 32 // Выравнивание данных тензоров, если general.alignment не задан
+	GGUF_ARCH      = "llama"
+
+	MAX_GGUF_STRING = 64 * 1024 * 1024 // Предел длины строки в заголовке
+	MAX_GGUF_ARRAY  = 64 * 1024 * 1024 // Предел числа элементов массива в заголовке
+)
+
+// Типы значений метаданных GGUF
+const (
+	ggufUint8 uint32 = iota
+	ggufInt8
+	ggufUint16
+	ggufInt16
+	ggufUint32
+	ggufInt32
+	ggufFloat32
+	ggufBool
+	ggufString
+	ggufArray
+	ggufUint64
+	ggufInt64
+	ggufFloat64
+)
+
+// Типы токенов в tokenizer.ggml.token_type
+const (
+	ggufTokenControl     = 3 // Служебный токен (<|im_start|>); пропускается при декодировании
+	ggufTokenUserDefined = 4 // Добавленный токен, распознаваемый в тексте целиком
+)
+
+// Предварительная токенизация llama.cpp (tokenizer.ggml.pre), выделяющая каждую цифру
+var ggufDigitPreTokenizers = []string{"smollm", "starcoder", "refact"}
+
+// ggufNames переводит имена тензоров llama.cpp в имена transformers
+var ggufNames = map[string]string{
+	"token_embd.weight":  "model.embed_tokens.weight",
+	"output_norm.weight": "model.norm.weight",
+	"output.weight":      "lm_head.weight",
+	"attn_norm.weight":   "input_layernorm.weight",
+	"attn_q.weight":      "self_attn.q_proj.weight",
+	"attn_k.weight":      "self_attn.k_proj.weight",
+	"attn_v.weight":      "self_attn.v_proj.weight",
+	"attn_output.weight": "self_attn.o_proj.weight",
+	"ffn_norm.weight":    "post_attention_layernorm.weight",
+	"ffn_gate.weight":    "mlp.gate_proj.weight",
+	"ffn_up.weight":      "mlp.up_proj.weight",
+	"ffn_down.weight":    "mlp.down_proj.weight",
+}
+
+// ggufFile - разобранный файл GGUF
+type ggufFile struct {
+	name     string
+	metadata map[string]any // Целые числа хранятся как int64, дробные - как float64
+	tensors  []ggufTensor
+	data     []byte // Данные тензоров; nil, если читался только заголовок
+}
+
+// ggufTensor - описание тензора в заголовке GGUF
+type ggufTensor struct {
+	name   string
+	dims   []int // Первым идет самое быстрое измерение (длина строки)
+	dtype  DType
+	offset uint64 // Смещение от начала данных тензоров
+}
+
+// ggufPath возвращает путь к файлу GGUF: сам modelPath, если это файл
+// .gguf, или первый по имени файл .gguf в директории modelPath
+func ggufPath(modelPath string) (string, bool) {
+	info, err := os.Stat(modelPath)
+	if err != nil {
+		return "", false
+	}
+	if !info.IsDir() {
+		return modelPath, strings.EqualFold(filepath.Ext(modelPath), GGUF_EXTENSION)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(modelPath, "*"+GGUF_EXTENSION))
+	if len(matches) == 0 {
+		return "", false
+	}
+	slices.Sort(matches)
+	return matches[0], true
+}
+
+// loadGGUF загружает конфигурацию, токенизатор и тензоры модели из файла GGUF.
+// Квантованные тензоры остаются в исходном формате и распаковываются при умножении
+func loadGGUF(path string) (*Config, *Tokenizer, map[string]*Tensor, error) {
+	file, err := readGGUF(path, true)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	config, err := file.config()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tokenizer, err := file.tokenizer()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tensors, err := file.weights(config)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return config, tokenizer, tensors, nil
+}
+
+// readGGUF читает заголовок файла GGUF и, если withData, данные тензоров
+func readGGUF(path string, withData bool) (*ggufFile, error) {
+	name := filepath.Base(path)
+	corrupt := func(err error) error {
+		return i18n.WrapError(err, "llama.weights_corrupt", name).WithKind(ErrInvalidWeights)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, i18n.WrapError(err, "llama.weights_read", name).WithKind(ErrModelNotFound)
+	}
+	defer f.Close()
+
+	r := &ggufReader{r: bufio.NewReaderSize(f, 1<<20)}
+
+	magic := make([]byte, 4)
+	r.read(magic)
+	if r.err == nil && string(magic) != GGUF_MAGIC {
+		return nil, i18n.NewError("llama.gguf_magic", name).WithKind(ErrInvalidWeights)
+	}
+	version := r.uint32()
+	if r.err == nil && version != 2 && version != 3 {
+		return nil, i18n.NewError("llama.gguf_version", version).WithKind(ErrUnsupportedModel)
+	}
+
+	tensorCount := r.uint64()
+	metadataCount := r.uint64()
+	if r.err != nil {
+		return nil, corrupt(r.err)
+	}
+	if tensorCount > MAX_GGUF_ARRAY || metadataCount > MAX_GGUF_ARRAY {
+		return nil, corrupt(nil)
+	}
+
+	// Счетчики заголовка не проверены, поэтому память под метаданные и
+	// тензоры выделяется по мере чтения, а не заранее: обрезанный файл с
+	// огромным счетчиком заканчивается ошибкой чтения
+	file := &ggufFile{name: name, metadata: make(map[string]any)}
+	for range metadataCount {
+		key := r.string()
+		file.metadata[key] = r.value(r.uint32())
+		if r.err != nil {
+			return nil, corrupt(r.err)
+		}
+	}
+
+	for range tensorCount {
+		var t ggufTensor
+		t.name = r.string()
+		dimCount := r.uint32()
+		if dimCount > 4 {
+			return nil, corrupt(nil)
+		}
+		t.dims = make([]int, dimCount)
+		for j := range t.dims {
+			t.dims[j] = int(r.uint64())
+		}
+		t.dtype = DType(r.uint32())
+		t.offset = r.uint64()
+		if r.err != nil {
+			return nil, corrupt(r.err)
+		}
+		file.tensors = append(file.tensors, t)
+	}
+
+	if !withData {
+		return file, nil
+	}
+
+	// Данные тензоров начинаются с выровненного смещения после заголовка
+	alignment := int64(GGUF_ALIGNMENT)
+	if value, ok := file.metadata["general.alignment"].(int64); ok && value > 0 {
+		alignment = value
+	}
+	padding := (alignment - r.offset%alignment) % alignment
+	r.read(make([]byte, padding))
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, i18n.WrapError(err, "llama.weights_read", name).WithKind(ErrInvalidWeights)
+	}
+	if r.err != nil || info.Size() < r.offset {
+		return nil, corrupt(r.err)
+	}
+	file.data = make([]byte, info.Size()-r.offset)
+	r.read(file.data)
+	if r.err != nil {
+		return nil, corrupt(r.err)
+	}
+
+	return file, nil
+}
+
+// config строит гиперпараметры модели по метаданным архитектуры llama
+func (f *ggufFile) config() (*Config, error) {
+	arch := f.metaString("general.architecture")
+	if arch != GGUF_ARCH {
+		return nil, i18n.NewError("llama.unsupported_architecture", []string{arch}).WithKind(ErrUnsupportedModel)
+	}
+	key := func(name string) string { return arch + "." + name }
+
+	config := &Config{
+		Architectures:    []string{ARCHITECTURE},
+		HiddenSize:       f.metaInt(key("embedding_length")),
+		IntermediateSize: f.metaInt(key("feed_forward_length")),
+		NumLayers:        f.metaInt(key("block_count")),
+		NumHeads:         f.metaInt(key("attention.head_count")),
+		NumKVHeads:       f.metaInt(key("attention.head_count_kv")),
+		HeadDim:          f.metaInt(key("attention.key_length")),
+		VocabSize:        f.metaInt(key("vocab_size")),
+		MaxPositions:     f.metaInt(key("context_length")),
+		RMSNormEps:       f.metaFloat(key("attention.layer_norm_rms_epsilon")),
+		RopeTheta:        f.metaFloat(key("rope.freq_base")),
+	}
+	if config.VocabSize == 0 {
+		if tokens, ok := f.metadata["tokenizer.ggml.tokens"].([]any); ok {
+			config.VocabSize = len(tokens)
+		}
+	}
+	if scaling := f.metaString(key("rope.scaling.type")); scaling != "" {
+		config.RopeScaling = &RopeScaling{Type: scaling, Factor: f.metaFloat(key("rope.scaling.factor"))}
+	}
+
+	// Связанные веса: отдельной выходной матрицы в файле нет
+	config.TieWordEmbeddings = !slices.ContainsFunc(f.tensors, func(t ggufTensor) bool {
+		return t.name == "output.weight"
+	})
+
+	for _, name := range []string{"tokenizer.ggml.eos_token_id", "tokenizer.ggml.eot_token_id"} {
+		if id, ok := f.metadata[name].(int64); ok && !slices.Contains(config.EOSTokenIDs, int(id)) {
+			config.EOSTokenIDs = append(config.EOSTokenIDs, int(id))
+		}
+	}
+
+	if err := config.normalize(); err != nil {
+		return nil, err
+	}
+
+	// Частичный RoPE (по части размерности головы) не поддерживается
+	if dims := f.metaInt(key("rope.dimension_count")); dims != 0 && dims != config.HeadDim {
+		return nil, i18n.NewError("llama.invalid_config").WithKind(ErrUnsupportedModel)
+	}
+
+	return config, nil
+}
+
+// tokenizer строит байтовый BPE токенизатор по метаданным tokenizer.ggml.*
+func (f *ggufFile) tokenizer() (*Tokenizer, error) {
+	if model := f.metaString("tokenizer.ggml.model"); model != "gpt2" {
+		return nil, i18n.NewError("llama.tokenizer_unsupported", model).WithKind(ErrInvalidTokenizer)
+	}
+
+	tokens := f.metaStrings("tokenizer.ggml.tokens")
+	merges := f.metaStrings("tokenizer.ggml.merges")
+	if len(tokens) == 0 {
+		return nil, i18n.NewError("llama.tokenizer_decode").WithKind(ErrInvalidTokenizer)
+	}
+
+	types, _ := f.metadata["tokenizer.ggml.token_type"].([]any)
+	vocab := make(map[string]int, len(tokens))
+	var added []addedToken
+	for id, token := range tokens {
+		var kind int64
+		if id < len(types) {
+			kind, _ = types[id].(int64)
+		}
+		switch kind {
+		case ggufTokenControl, ggufTokenUserDefined:
+			added = append(added, addedToken{id: id, content: token, special: kind == ggufTokenControl})
+		default:
+			vocab[token] = id
+		}
+	}
+
+	digits := slices.Contains(ggufDigitPreTokenizers, f.metaString("tokenizer.ggml.pre"))
+	t := newTokenizer(vocab, merges, added, false, digits)
+
+	if add, _ := f.metadata["tokenizer.ggml.add_bos_token"].(bool); add {
+		if id, ok := f.metadata["tokenizer.ggml.bos_token_id"].(int64); ok {
+			t.prefix = []int{int(id)}
+		}
+	}
+	if add, _ := f.metadata["tokenizer.ggml.add_eos_token"].(bool); add {
+		if id, ok := f.metadata["tokenizer.ggml.eos_token_id"].(int64); ok {
+			t.suffix = []int{int(id)}
+		}
+	}
+
+	return t, nil
+}
+
+// weights возвращает тензоры под именами transformers. Неквантованные тензоры
+// преобразуются в float32, строки Q и K переставляются обратно (см. unpermute)
+func (f *ggufFile) weights(config *Config) (map[string]*Tensor, error) {
+	tensors := make(map[string]*Tensor, len(f.tensors))
+
+	for _, info := range f.tensors {
+		name, ok := ggufNames[info.name]
+		if rest, found := strings.CutPrefix(info.name, "blk."); !ok && found {
+			layer, suffix, _ := strings.Cut(rest, ".")
+			if mapped, known := ggufNames[suffix]; known {
+				name, ok = "model.layers."+layer+"."+mapped, true
+			}
+		}
+		if !ok {
+			continue // Тензоры, не нужные прямому проходу (например, rope_freqs)
+		}
+
+		tensor, err := f.tensor(info)
+		if err != nil {
+			return nil, i18n.WrapError(err, "llama.tensor_invalid", info.name).WithKind(ErrInvalidWeights)
+		}
+
+		switch {
+		case strings.HasSuffix(name, "q_proj.weight"):
+			unpermute(tensor, config.NumHeads)
+		case strings.HasSuffix(name, "k_proj.weight"):
+			unpermute(tensor, config.NumKVHeads)
+		}
+		tensors[name] = tensor
+	}
+
+	return tensors, nil
+}
+
+// tensor возвращает данные тензора. Форма переводится в порядок PyTorch
+func (f *ggufFile) tensor(info ggufTensor) (*Tensor, error) {
+	dtype, ok := dtypes[info.dtype]
+	if !ok {
+		return nil, i18n.NewError("llama.tensor_dtype", info.dtype)
+	}
+
+	shape := slices.Clone(info.dims)
+	slices.Reverse(shape)
+	count := 1
+	for _, dim := range shape {
+		count *= dim
+	}
+	if len(shape) == 0 || shape[len(shape)-1]%dtype.blockSize != 0 {
+		return nil, i18n.NewError("llama.tensor_offsets")
+	}
+
+	size := uint64(count / dtype.blockSize * dtype.blockBytes)
+	if info.offset > uint64(len(f.data)) || size > uint64(len(f.data))-info.offset {
+		return nil, i18n.NewError("llama.tensor_offsets")
+	}
+	raw := f.data[info.offset : info.offset+size]
+
+	if info.dtype.quantized() {
+		return &Tensor{Shape: shape, DType: info.dtype, Raw: raw}, nil
+	}
+
+	values := make([]float32, count)
+	dequantize(info.dtype, values, raw)
+	return &Tensor{Shape: shape, Data: values}, nil
+}
+
+// unpermute отменяет перестановку строк Q и K, которую llama.cpp делает при
+// конвертации: в GGUF пары RoPE идут подряд (2i, 2i+1), а прямой проход, как
+// и transformers, поворачивает пары (i, i+HeadDim/2)
+func unpermute(t *Tensor, heads int) {
+	rows := t.Shape[0]
+	hd := rows / heads
+	half := hd / 2
+
+	// Строка h*hd + j*half + i берется из строки h*hd + 2i + j
+	source := func(r int) int {
+		h, rest := r/hd, r%hd
+		j, i := rest/half, rest%half
+		return h*hd + 2*i + j
+	}
+
+	if t.Raw != nil {
+		size := len(t.Raw) / rows
+		permuted := make([]byte, len(t.Raw))
+		for r := range rows {
+			copy(permuted[r*size:(r+1)*size], t.Raw[source(r)*size:(source(r)+1)*size])
+		}
+		t.Raw = permuted
+		return
+	}
+
+	cols := t.cols()
+	permuted := make([]float32, len(t.Data))
+	for r := range rows {
+		copy(permuted[r*cols:(r+1)*cols], t.Data[source(r)*cols:(source(r)+1)*cols])
+	}
+	t.Data = permuted
+}
+
+// metaString возвращает строковое значение метаданных или пустую строку
+func (f *ggufFile) metaString(key string) string {
+	value, _ := f.metadata[key].(string)
+	return value
+}
+
+// metaInt возвращает целое значение метаданных или 0
+func (f *ggufFile) metaInt(key string) int {
+	value, _ := f.metadata[key].(int64)
+	return int(value)
+}
+
+// metaFloat возвращает дробное значение метаданных или 0
+func (f *ggufFile) metaFloat(key string) float64 {
+	switch value := f.metadata[key].(type) {
+	case float64:
+		return value
+	case int64:
+		return float64(value)
+	}
+	return 0
+}
+
+// metaStrings возвращает массив строк из метаданных
+func (f *ggufFile) metaStrings(key string) []string {
+	values, _ := f.metadata[key].([]any)
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// ggufReader читает значения заголовка GGUF (little-endian) и считает
+// прочитанные байты. Первая ошибка сохраняется, последующие чтения ничего не делают
+type ggufReader struct {
+	r      *bufio.Reader
+	offset int64
+	err    error
+}
+
+func (r *ggufReader) read(buf []byte) {
+	if r.err != nil {
+		return
+	}
+	n, err := io.ReadFull(r.r, buf)
+	r.offset += int64(n)
+	r.err = err
+}
+
+func (r *ggufReader) uint32() uint32 {
+	var buf [4]byte
+	r.read(buf[:])
+	return binary.LittleEndian.Uint32(buf[:])
+}
+
+func (r *ggufReader) uint64() uint64 {
+	var buf [8]byte
+	r.read(buf[:])
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+func (r *ggufReader) string() string {
+	length := r.uint64()
+	if r.err != nil {
+		return ""
+	}
+	if length > MAX_GGUF_STRING {
+		r.err = i18n.NewError("llama.gguf_value")
+		return ""
+	}
+	buf := make([]byte, length)
+	r.read(buf)
+	return string(buf)
+}
+
+// value читает значение метаданных типа kind
+func (r *ggufReader) value(kind uint32) any {
+	var buf [8]byte
+	switch kind {
+	case ggufUint8, ggufInt8, ggufBool:
+		r.read(buf[:1])
+		switch kind {
+		case ggufInt8:
+			return int64(int8(buf[0]))
+		case ggufBool:
+			return buf[0] != 0
+		}
+		return int64(buf[0])
+	case ggufUint16, ggufInt16:
+		r.read(buf[:2])
+		value := binary.LittleEndian.Uint16(buf[:])
+		if kind == ggufInt16 {
+			return int64(int16(value))
+		}
+		return int64(value)
+	case ggufUint32, ggufInt32:
+		value := r.uint32()
+		if kind == ggufInt32 {
+			return int64(int32(value))
+		}
+		return int64(value)
+	case ggufUint64, ggufInt64:
+		return int64(r.uint64())
+	case ggufFloat32:
+		return float64(math.Float32frombits(r.uint32()))
+	case ggufFloat64:
+		return math.Float64frombits(r.uint64())
+	case ggufString:
+		return r.string()
+	case ggufArray:
+		elemKind := r.uint32()
+		count := r.uint64()
+		if r.err != nil {
+			return nil
+		}
+		if count > MAX_GGUF_ARRAY || elemKind == ggufArray {
+			r.err = i18n.NewError("llama.gguf_value")
+			return nil
+		}
+		var values []any
+		for range count {
+			value := r.value(elemKind)
+			if r.err != nil {
+				return nil
+			}
+			values = append(values, value)
+		}
+		return values
+	}
+
+	r.err = i18n.NewError("llama.gguf_value")
+	return nil
+}
@@ -0,0 +1,276 @@
+package llama
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// ggufBuilder собирает файл GGUF для тестов
+type ggufBuilder struct {
+	metadata bytes.Buffer
+	tensors  bytes.Buffer
+	data     bytes.Buffer
+	meta     int
+	count    int
+}
+
+func (b *ggufBuilder) key(w *bytes.Buffer, key string, kind uint32) {
+	writeGGUFString(w, key)
+	binary.Write(w, binary.LittleEndian, kind)
+	b.meta++
+}
+
+func (b *ggufBuilder) str(key, value string) {
+	b.key(&b.metadata, key, ggufString)
+	writeGGUFString(&b.metadata, value)
+}
+
+func (b *ggufBuilder) u32(key string, value uint32) {
+	b.key(&b.metadata, key, ggufUint32)
+	binary.Write(&b.metadata, binary.LittleEndian, value)
+}
+
+func (b *ggufBuilder) f32(key string, value float32) {
+	b.key(&b.metadata, key, ggufFloat32)
+	binary.Write(&b.metadata, binary.LittleEndian, value)
+}
+
+func (b *ggufBuilder) strings(key string, values ...string) {
+	b.key(&b.metadata, key, ggufArray)
+	binary.Write(&b.metadata, binary.LittleEndian, ggufString)
+	binary.Write(&b.metadata, binary.LittleEndian, uint64(len(values)))
+	for _, value := range values {
+		writeGGUFString(&b.metadata, value)
+	}
+}
+
+// tensor добавляет тензор; данные выравниваются по GGUF_ALIGNMENT
+func (b *ggufBuilder) tensor(name string, dtype DType, dims []int, raw []byte) {
+	for b.data.Len()%GGUF_ALIGNMENT != 0 {
+		b.data.WriteByte(0)
+	}
+	writeGGUFString(&b.tensors, name)
+	binary.Write(&b.tensors, binary.LittleEndian, uint32(len(dims)))
+	for _, dim := range dims {
+		binary.Write(&b.tensors, binary.LittleEndian, uint64(dim))
+	}
+	binary.Write(&b.tensors, binary.LittleEndian, uint32(dtype))
+	binary.Write(&b.tensors, binary.LittleEndian, uint64(b.data.Len()))
+	b.data.Write(raw)
+	b.count++
+}
+
+// write записывает файл в директорию теста и возвращает путь
+func (b *ggufBuilder) write(t *testing.T) string {
+	var out bytes.Buffer
+	out.WriteString(GGUF_MAGIC)
+	binary.Write(&out, binary.LittleEndian, uint32(3))
+	binary.Write(&out, binary.LittleEndian, uint64(b.count))
+	binary.Write(&out, binary.LittleEndian, uint64(b.meta))
+	out.Write(b.metadata.Bytes())
+	out.Write(b.tensors.Bytes())
+	for out.Len()%GGUF_ALIGNMENT != 0 {
+		out.WriteByte(0)
+	}
+	out.Write(b.data.Bytes())
+
+	path := filepath.Join(t.TempDir(), "model"+GGUF_EXTENSION)
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeGGUFString(w *bytes.Buffer, s string) {
+	binary.Write(w, binary.LittleEndian, uint64(len(s)))
+	w.WriteString(s)
+}
+
+// float32Bytes кодирует значения float32 в little-endian
+func float32Bytes(values ...float32) []byte {
+	raw := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(value))
+	}
+	return raw
+}
+
+// newTestGGUF возвращает сборщик минимальной модели llama
+func newTestGGUF() *ggufBuilder {
+	b := &ggufBuilder{}
+	b.str("general.architecture", GGUF_ARCH)
+	b.u32("llama.embedding_length", 8)
+	b.u32("llama.feed_forward_length", 16)
+	b.u32("llama.block_count", 1)
+	b.u32("llama.attention.head_count", 2)
+	b.u32("llama.attention.head_count_kv", 1)
+	b.u32("llama.context_length", 128)
+	b.f32("llama.attention.layer_norm_rms_epsilon", 1e-5)
+	b.f32("llama.rope.freq_base", 100000)
+	b.str("tokenizer.ggml.model", "gpt2")
+	b.strings("tokenizer.ggml.tokens", "a", "b", "ab", "<|im_end|>")
+	b.strings("tokenizer.ggml.merges", "a b")
+	b.u32("tokenizer.ggml.eos_token_id", 3)
+	return b
+}
+
+func TestReadGGUF(t *testing.T) {
+	b := newTestGGUF()
+	embeddings := make([]float32, 4*8)
+	for i := range embeddings {
+		embeddings[i] = float32(i)
+	}
+	b.tensor("token_embd.weight", DTYPE_F32, []int{8, 4}, float32Bytes(embeddings...))
+	q8 := make([]byte, 34)
+	q8[1] = 0x3c // d = 1
+	b.tensor("blk.0.ffn_down.weight", DTYPE_Q8_0, []int{32, 1}, q8)
+	b.tensor("rope_freqs.weight", DTYPE_F32, []int{2}, float32Bytes(1, 1))
+	path := b.write(t)
+
+	config, err := ReadConfig(path)
+	if err != nil {
+		t.Fatalf("ReadConfig: %v", err)
+	}
+	want := Config{
+		HiddenSize: 8, IntermediateSize: 16, NumLayers: 1, NumHeads: 2, NumKVHeads: 1,
+		HeadDim: 4, VocabSize: 4, MaxPositions: 128, RopeTheta: 100000, EOSTokenIDs: []int{3},
+		TieWordEmbeddings: true,
+	}
+	got := Config{
+		HiddenSize: config.HiddenSize, IntermediateSize: config.IntermediateSize, NumLayers: config.NumLayers,
+		NumHeads: config.NumHeads, NumKVHeads: config.NumKVHeads, HeadDim: config.HeadDim,
+		VocabSize: config.VocabSize, MaxPositions: config.MaxPositions, RopeTheta: config.RopeTheta,
+		EOSTokenIDs: config.EOSTokenIDs, TieWordEmbeddings: config.TieWordEmbeddings,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("config = %+v, want %+v", got, want)
+	}
+	if math.Abs(config.RMSNormEps-1e-5) > 1e-9 {
+		t.Errorf("RMSNormEps = %v, want 1e-5", config.RMSNormEps)
+	}
+
+	_, tokenizer, tensors, err := loadGGUF(path)
+	if err != nil {
+		t.Fatalf("loadGGUF: %v", err)
+	}
+	if tokenizer == nil {
+		t.Fatal("tokenizer is nil")
+	}
+	if len(tensors) != 2 {
+		t.Errorf("tensors = %d, want 2 (rope_freqs skipped)", len(tensors))
+	}
+
+	embed := tensors["model.embed_tokens.weight"]
+	if embed == nil || !reflect.DeepEqual(embed.Shape, []int{4, 8}) || !reflect.DeepEqual(embed.Data, embeddings) {
+		t.Errorf("embed_tokens = %+v", embed)
+	}
+	down := tensors["model.layers.0.mlp.down_proj.weight"]
+	if down == nil || down.DType != DTYPE_Q8_0 || !bytes.Equal(down.Raw, q8) || down.Data != nil {
+		t.Errorf("down_proj = %+v, want raw Q8_0", down)
+	}
+}
+
+func TestReadGGUFErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(raw []byte) []byte
+		kind   error
+	}{
+		{"bad magic", func(raw []byte) []byte { copy(raw, "GGUX"); return raw }, ErrInvalidWeights},
+		{"unsupported version", func(raw []byte) []byte { raw[4] = 9; return raw }, ErrUnsupportedModel},
+		{"truncated header", func(raw []byte) []byte { return raw[:40] }, ErrInvalidWeights},
+	}
+
+	source, err := os.ReadFile(newTestGGUF().write(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "broken"+GGUF_EXTENSION)
+			if err := os.WriteFile(path, tt.mutate(bytes.Clone(source)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := readGGUF(path, false); !errors.Is(err, tt.kind) {
+				t.Errorf("readGGUF = %v, want kind %v", err, tt.kind)
+			}
+		})
+	}
+
+	if _, err := readGGUF(filepath.Join(t.TempDir(), "missing.gguf"), false); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("readGGUF(missing) = %v, want ErrModelNotFound", err)
+	}
+}
+
+func TestReadGGUFHugeCounts(t *testing.T) {
+	b := newTestGGUF()
+	source, err := os.ReadFile(b.write(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const header = 24
+	tokens := bytes.Index(source, []byte("tokenizer.ggml.tokens")) + len("tokenizer.ggml.tokens") + 8
+
+	// Обрезанный файл объявляет огромное число элементов; память под них
+	// не должна выделяться заранее
+	tests := []struct {
+		name   string
+		offset int // Смещение счетчика
+		size   int // Длина обрезанного файла
+	}{
+		{"tensor count", 8, header + b.metadata.Len()},
+		{"metadata count", 16, header + 16},
+		{"array count", tokens, tokens + 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := bytes.Clone(source)
+			binary.LittleEndian.PutUint64(raw[tt.offset:], MAX_GGUF_ARRAY)
+			path := filepath.Join(t.TempDir(), "huge"+GGUF_EXTENSION)
+			if err := os.WriteFile(path, raw[:tt.size], 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, err := readGGUF(path, false)
+			runtime.ReadMemStats(&after)
+
+			if !errors.Is(err, ErrInvalidWeights) {
+				t.Errorf("readGGUF = %v, want ErrInvalidWeights", err)
+			}
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+				t.Errorf("readGGUF allocated %d bytes for a %d byte file", allocated, tt.size)
+			}
+		})
+	}
+}
+
+func TestGGUFTensorBounds(t *testing.T) {
+	file := &ggufFile{data: make([]byte, 64)}
+
+	tests := []struct {
+		name string
+		info ggufTensor
+	}{
+		{"unknown dtype", ggufTensor{dims: []int{4}, dtype: DType(99)}},
+		{"row not multiple of block", ggufTensor{dims: []int{16}, dtype: DTYPE_Q8_0}},
+		{"data out of range", ggufTensor{dims: []int{32}, dtype: DTYPE_F32}},
+		{"offset out of range", ggufTensor{dims: []int{4}, dtype: DTYPE_F32, offset: 100}},
+	}
+
+	for _, tt := range tests {
+		if _, err := file.tensor(tt.info); err == nil {
+			t.Errorf("%s: tensor succeeded", tt.name)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package llama
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Tensor - тензор весов. Shape записывается в порядке PyTorch: для матрицы
+// [строки, столбцы]. Веса хранятся в float32 (Data) или в исходном формате
+// (Raw), и тогда строки распаковываются при каждом умножении
+type Tensor struct {
+	Shape []int
+	Data  []float32
+	DType DType  // Тип данных Raw
+	Raw   []byte // Квантованные строки подряд; nil, если заполнено Data
+}
+
+// cols возвращает длину строки тензора
+func (t *Tensor) cols() int {
+	return t.Shape[len(t.Shape)-1]
+}
+
+// row возвращает строку r. Квантованная строка распаковывается в buf длиной cols
+func (t *Tensor) row(r int, buf []float32) []float32 {
+	cols := t.cols()
+	if t.Raw == nil {
+		return t.Data[r*cols : (r+1)*cols]
+	}
+
+	size := cols / dtypes[t.DType].blockSize * dtypes[t.DType].blockBytes
+	dequantize(t.DType, buf[:cols], t.Raw[r*size:(r+1)*size])
+	return buf[:cols]
+}
+
+// floats возвращает все значения тензора в float32
+func (t *Tensor) floats() []float32 {
+	if t.Raw == nil {
+		return t.Data
+	}
+
+	count := 1
+	for _, dim := range t.Shape {
+		count *= dim
+	}
+	values := make([]float32, count)
+	dequantize(t.DType, values, t.Raw)
+	return values
+}
+
+// layer - веса одного блока трансформера
+type layer struct {
+	attnNorm []float32 // input_layernorm
+	wq       *Tensor   // [NumHeads*HeadDim, HiddenSize]
+	wk       *Tensor   // [NumKVHeads*HeadDim, HiddenSize]
+	wv       *Tensor   // [NumKVHeads*HeadDim, HiddenSize]
+	wo       *Tensor   // [HiddenSize, NumHeads*HeadDim]
+	mlpNorm  []float32 // post_attention_layernorm
+	wGate    *Tensor   // [IntermediateSize, HiddenSize]
+	wUp      *Tensor   // [IntermediateSize, HiddenSize]
+	wDown    *Tensor   // [HiddenSize, IntermediateSize]
+}
+
+// Transformer - веса модели архитектуры Llama и прямой проход по ним.
+// Веса только читаются, поэтому один Transformer обслуживает несколько кэшей
+type Transformer struct {
+	config    Config
+	embedding *Tensor // [VocabSize, HiddenSize]
+	layers    []layer
+	norm      []float32
+	output    *Tensor   // [VocabSize, HiddenSize]; совпадает с embedding при связанных весах
+	invFreq   []float32 // Частоты RoPE для половины размерности головы
+}
+
+// Cache - KV кэш одной последовательности: ключи и значения всех
+// обработанных позиций по слоям
+type Cache struct {
+	keys   [][]float32 // [слой][позиция*NumKVHeads*HeadDim]
+	values [][]float32
+	length int // Количество обработанных позиций
+}
+
+// Len возвращает количество позиций в кэше
+func (c *Cache) Len() int {
+	return c.length
+}
+
+// truncate оставляет в кэше первые n позиций
+func (c *Cache) truncate(n int) {
+	if n >= c.length {
+		return
+	}
+	for li := range c.keys {
+		kvDim := len(c.keys[li]) / c.length
+		c.keys[li] = c.keys[li][:n*kvDim]
+		c.values[li] = c.values[li][:n*kvDim]
+	}
+	c.length = n
+}
+
+// newTransformer собирает модель из загруженных тензоров, проверяя их формы
+func newTransformer(config Config, tensors map[string]*Tensor) (*Transformer, error) {
+	d := config.HiddenSize
+	qDim := config.NumHeads * config.HeadDim
+	kvDim := config.NumKVHeads * config.HeadDim
+
+	get := func(name string, shape ...int) (*Tensor, error) {
+		tensor, ok := tensors[name]
+		if !ok {
+			return nil, i18n.NewError("llama.tensor_missing", name).WithKind(ErrInvalidWeights)
+		}
+		if !slices.Equal(tensor.Shape, shape) {
+			return nil, i18n.NewError("llama.tensor_shape", name, tensor.Shape, shape).WithKind(ErrInvalidWeights)
+		}
+		return tensor, nil
+	}
+	vector := func(name string, size int) ([]float32, error) {
+		tensor, err := get(name, size)
+		if err != nil {
+			return nil, err
+		}
+		return tensor.floats(), nil
+	}
+
+	t := &Transformer{config: config}
+
+	var err error
+	if t.embedding, err = get("model.embed_tokens.weight", config.VocabSize, d); err != nil {
+		return nil, err
+	}
+	if t.norm, err = vector("model.norm.weight", d); err != nil {
+		return nil, err
+	}
+	if _, ok := tensors["lm_head.weight"]; ok || !config.TieWordEmbeddings {
+		if t.output, err = get("lm_head.weight", config.VocabSize, d); err != nil {
+			return nil, err
+		}
+	} else {
+		t.output = t.embedding
+	}
+
+	t.layers = make([]layer, config.NumLayers)
+	for i := range t.layers {
+		prefix := fmt.Sprintf("model.layers.%d.", i)
+		l := &t.layers[i]
+		if l.attnNorm, err = vector(prefix+"input_layernorm.weight", d); err != nil {
+			return nil, err
+		}
+		if l.mlpNorm, err = vector(prefix+"post_attention_layernorm.weight", d); err != nil {
+			return nil, err
+		}
+		for _, w := range []struct {
+			dst  **Tensor
+			name string
+			rows int
+			cols int
+		}{
+			{&l.wq, "self_attn.q_proj.weight", qDim, d},
+			{&l.wk, "self_attn.k_proj.weight", kvDim, d},
+			{&l.wv, "self_attn.v_proj.weight", kvDim, d},
+			{&l.wo, "self_attn.o_proj.weight", d, qDim},
+			{&l.wGate, "mlp.gate_proj.weight", config.IntermediateSize, d},
+			{&l.wUp, "mlp.up_proj.weight", config.IntermediateSize, d},
+			{&l.wDown, "mlp.down_proj.weight", d, config.IntermediateSize},
+		} {
+			if *w.dst, err = get(prefix+w.name, w.rows, w.cols); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	half := config.HeadDim / 2
+	t.invFreq = make([]float32, half)
+	for i := range t.invFreq {
+		t.invFreq[i] = float32(1 / math.Pow(config.RopeTheta, float64(2*i)/float64(config.HeadDim)))
+	}
+
+	return t, nil
+}
+
+// NewCache создает пустой KV кэш для новой последовательности
+func (t *Transformer) NewCache() *Cache {
+	return &Cache{
+		keys:   make([][]float32, t.config.NumLayers),
+		values: make([][]float32, t.config.NumLayers),
+	}
+}
+
+// Forward пропускает токены через модель, дописывая их ключи и значения в
+// кэш, и возвращает логиты следующего токена после последнего из tokens.
+// Все токены обрабатываются одним пакетом: каждая матрица весов читается
+// один раз на пакет, а не на токен
+func (t *Transformer) Forward(tokens []int, cache *Cache) []float32 {
+	c := t.config
+	n := len(tokens)
+	d := c.HiddenSize
+	hd := c.HeadDim
+	qDim := c.NumHeads * hd
+	kvDim := c.NumKVHeads * hd
+	start := cache.length
+
+	x := make([]float32, n*d)
+	for i, token := range tokens {
+		copy(x[i*d:(i+1)*d], t.embedding.row(token, x[i*d:(i+1)*d]))
+	}
+
+	// Буферы переиспользуются всеми слоями
+	xb := make([]float32, n*d)
+	q := make([]float32, n*qDim)
+	k := make([]float32, n*kvDim)
+	v := make([]float32, n*kvDim)
+	att := make([]float32, n*qDim)
+	gate := make([]float32, n*c.IntermediateSize)
+	up := make([]float32, n*c.IntermediateSize)
+
+	// Углы RoPE зависят только от позиции и одинаковы для всех слоев и голов
+	cos := make([]float32, n*hd/2)
+	sin := make([]float32, n*hd/2)
+	for i := range n {
+		pos := float64(start+i) / c.ropeScale()
+		for j, freq := range t.invFreq {
+			angle := pos * float64(freq)
+			cos[i*hd/2+j] = float32(math.Cos(angle))
+			sin[i*hd/2+j] = float32(math.Sin(angle))
+		}
+	}
+
+	for li := range t.layers {
+		l := &t.layers[li]
+
+		// Внимание
+		rmsNorm(xb, x, l.attnNorm, n, c.RMSNormEps)
+		matmul(q, xb, l.wq, n)
+		matmul(k, xb, l.wk, n)
+		matmul(v, xb, l.wv, n)
+		rope(q, cos, sin, n, c.NumHeads, hd)
+		rope(k, cos, sin, n, c.NumKVHeads, hd)
+		cache.keys[li] = append(cache.keys[li], k...)
+		cache.values[li] = append(cache.values[li], v...)
+		t.attention(att, q, cache.keys[li], cache.values[li], n, start)
+		matmul(xb, att, l.wo, n)
+		add(x, xb)
+
+		// MLP: down(silu(gate(x)) * up(x))
+		rmsNorm(xb, x, l.mlpNorm, n, c.RMSNormEps)
+		matmul(gate, xb, l.wGate, n)
+		matmul(up, xb, l.wUp, n)
+		for i, g := range gate {
+			gate[i] = g / (1 + float32(math.Exp(float64(-g)))) * up[i]
+		}
+		matmul(xb, gate, l.wDown, n)
+		add(x, xb)
+	}
+	cache.length += n
+
+	// Логиты нужны только для последнего токена
+	last := x[(n-1)*d:]
+	rmsNorm(last, last, t.norm, 1, c.RMSNormEps)
+	logits := make([]float32, c.VocabSize)
+	matmul(logits, last, t.output, 1)
+
+	return logits
+}
+
+// attention вычисляет причинное внимание n новых токенов, начинающихся с
+// позиции start, ко всем позициям кэша. Несколько голов запросов делят
+// одну голову ключей и значений (GQA)
+func (t *Transformer) attention(out, q, keys, values []float32, n, start int) {
+	c := t.config
+	hd := c.HeadDim
+	qDim := c.NumHeads * hd
+	kvDim := c.NumKVHeads * hd
+	group := c.NumHeads / c.NumKVHeads
+	scale := float32(1 / math.Sqrt(float64(hd)))
+
+	parallel(n*c.NumHeads, func(from, to int) {
+		scores := make([]float32, start+n)
+		for task := from; task < to; task++ {
+			i, h := task/c.NumHeads, task%c.NumHeads
+			query := q[i*qDim+h*hd : i*qDim+(h+1)*hd]
+			kvOffset := (h / group) * hd
+			visible := start + i + 1
+
+			for p := range visible {
+				scores[p] = dot(query, keys[p*kvDim+kvOffset:p*kvDim+kvOffset+hd]) * scale
+			}
+			softmax(scores[:visible])
+
+			result := out[i*qDim+h*hd : i*qDim+(h+1)*hd]
+			clear(result)
+			for p := range visible {
+				weight := scores[p]
+				value := values[p*kvDim+kvOffset : p*kvDim+kvOffset+hd]
+				for j := range result {
+					result[j] += weight * value[j]
+				}
+			}
+		}
+	})
+}
+
+// rmsNorm нормирует n векторов x по среднеквадратичному и умножает на веса
+func rmsNorm(out, x, weight []float32, n int, eps float64) {
+	d := len(weight)
+	for i := range n {
+		row := x[i*d : (i+1)*d]
+		var sum float64
+		for _, value := range row {
+			sum += float64(value) * float64(value)
+		}
+		scale := float32(1 / math.Sqrt(sum/float64(d)+eps))
+		dst := out[i*d : (i+1)*d]
+		for j, value := range row {
+			dst[j] = value * scale * weight[j]
+		}
+	}
+}
+
+// rope поворачивает пары (j, j+HeadDim/2) каждой головы на угол позиции,
+// как rotate_half в реализации Llama из transformers
+func rope(x, cos, sin []float32, n, heads, hd int) {
+	half := hd / 2
+	for i := range n {
+		c := cos[i*half : (i+1)*half]
+		s := sin[i*half : (i+1)*half]
+		for h := range heads {
+			head := x[(i*heads+h)*hd : (i*heads+h+1)*hd]
+			for j := range half {
+				a, b := head[j], head[j+half]
+				head[j] = a*c[j] - b*s[j]
+				head[j+half] = b*c[j] + a*s[j]
+			}
+		}
+	}
+}
+
+// matmul вычисляет out[n, rows] = x[n, cols] * w[rows, cols]^T. Строки весов
+// распределяются между потоками; каждая строка распаковывается один раз и
+// умножается на все n векторов
+func matmul(out, x []float32, w *Tensor, n int) {
+	rows, cols := w.Shape[0], w.cols()
+	parallel(rows, func(from, to int) {
+		buf := make([]float32, cols)
+		for r := from; r < to; r++ {
+			row := w.row(r, buf)
+			for i := range n {
+				out[i*rows+r] = dot(row, x[i*cols:(i+1)*cols])
+			}
+		}
+	})
+}
+
+// dot - скалярное произведение векторов одной длины
+func dot(a, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// add прибавляет b к a поэлементно
+func add(a, b []float32) {
+	for i := range a {
+		a[i] += b[i]
+	}
+}
+
+// softmax заменяет значения их вероятностями
+func softmax(x []float32) {
+	maxValue := x[0]
+	for _, value := range x[1:] {
+		maxValue = max(maxValue, value)
+	}
+	var sum float32
+	for i, value := range x {
+		x[i] = float32(math.Exp(float64(value - maxValue)))
+		sum += x[i]
+	}
+	for i := range x {
+		x[i] /= sum
+	}
+}
+
+// parallel делит n задач на отрезки по числу процессоров и выполняет их одновременно
+func parallel(n int, fn func(from, to int)) {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	if workers <= 1 {
+		fn(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+	chunk := (n + workers - 1) / workers
+	for from := 0; from < n; from += chunk {
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			fn(from, to)
+		}(from, min(from+chunk, n))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,241 @@
+package llama
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// DType - тип данных тензора в нумерации GGML
+type DType uint32
+
+// Поддерживаемые типы данных. Квантованные типы хранят веса блоками по
+// blockSize значений с общими масштабами
+const (
+	DTYPE_F32  DType = 0
+	DTYPE_F16  DType = 1
+	DTYPE_Q4_0 DType = 2
+	DTYPE_Q4_1 DType = 3
+	DTYPE_Q5_0 DType = 6
+	DTYPE_Q5_1 DType = 7
+	DTYPE_Q8_0 DType = 8
+	DTYPE_Q4_K DType = 12
+	DTYPE_Q5_K DType = 13
+	DTYPE_Q6_K DType = 14
+	DTYPE_BF16 DType = 30
+)
+
+// dtypeInfo описывает формат блока типа данных
+type dtypeInfo struct {
+	name       string
+	blockSize  int                           // Значений в блоке
+	blockBytes int                           // Байт в блоке
+	dequant    func(dst []float32, b []byte) // Распаковка одного блока
+}
+
+var dtypes = map[DType]dtypeInfo{
+	DTYPE_F32:  {"F32", 1, 4, func(dst []float32, b []byte) { dst[0] = math.Float32frombits(binary.LittleEndian.Uint32(b)) }},
+	DTYPE_F16:  {"F16", 1, 2, func(dst []float32, b []byte) { dst[0] = halfToFloat32(binary.LittleEndian.Uint16(b)) }},
+	DTYPE_BF16: {"BF16", 1, 2, func(dst []float32, b []byte) { dst[0] = bfloat16ToFloat32(binary.LittleEndian.Uint16(b)) }},
+	DTYPE_Q4_0: {"Q4_0", 32, 18, dequantQ4_0},
+	DTYPE_Q4_1: {"Q4_1", 32, 20, dequantQ4_1},
+	DTYPE_Q5_0: {"Q5_0", 32, 22, dequantQ5_0},
+	DTYPE_Q5_1: {"Q5_1", 32, 24, dequantQ5_1},
+	DTYPE_Q8_0: {"Q8_0", 32, 34, dequantQ8_0},
+	DTYPE_Q4_K: {"Q4_K", 256, 144, dequantQ4_K},
+	DTYPE_Q5_K: {"Q5_K", 256, 176, dequantQ5_K},
+	DTYPE_Q6_K: {"Q6_K", 256, 210, dequantQ6_K},
+}
+
+// String возвращает название типа данных
+func (t DType) String() string {
+	if info, ok := dtypes[t]; ok {
+		return info.name
+	}
+	return "unknown"
+}
+
+// quantized сообщает, хранятся ли данные блоками, а не отдельными числами
+func (t DType) quantized() bool {
+	return dtypes[t].blockSize > 1
+}
+
+// dequantize распаковывает значения src типа dtype в dst. Длина dst кратна размеру блока
+func dequantize(dtype DType, dst []float32, src []byte) {
+	info := dtypes[dtype]
+	for i, j := 0, 0; i < len(dst); i, j = i+info.blockSize, j+info.blockBytes {
+		info.dequant(dst[i:i+info.blockSize], src[j:j+info.blockBytes])
+	}
+}
+
+// half читает число половинной точности
+func half(b []byte) float32 {
+	return halfToFloat32(binary.LittleEndian.Uint16(b))
+}
+
+// dequantQ4_0: d, 16 байт по два 4-битных значения со смещением 8
+func dequantQ4_0(dst []float32, b []byte) {
+	d := half(b)
+	qs := b[2:18]
+	for j := range 16 {
+		dst[j] = float32(int(qs[j]&0x0f)-8) * d
+		dst[j+16] = float32(int(qs[j]>>4)-8) * d
+	}
+}
+
+// dequantQ4_1: d, m и 16 байт по два 4-битных значения без знака
+func dequantQ4_1(dst []float32, b []byte) {
+	d, m := half(b), half(b[2:])
+	qs := b[4:20]
+	for j := range 16 {
+		dst[j] = float32(qs[j]&0x0f)*d + m
+		dst[j+16] = float32(qs[j]>>4)*d + m
+	}
+}
+
+// dequantQ5_0: d, старшие биты 32 значений и их младшие 4 бита; смещение 16
+func dequantQ5_0(dst []float32, b []byte) {
+	d := half(b)
+	qh := binary.LittleEndian.Uint32(b[2:])
+	qs := b[6:22]
+	for j := range 16 {
+		h0 := byte(qh>>j<<4) & 0x10
+		h1 := byte(qh>>(j+12)) & 0x10
+		dst[j] = float32(int(qs[j]&0x0f|h0)-16) * d
+		dst[j+16] = float32(int(qs[j]>>4|h1)-16) * d
+	}
+}
+
+// dequantQ5_1: как Q5_0, но со сдвигом m вместо смещения
+func dequantQ5_1(dst []float32, b []byte) {
+	d, m := half(b), half(b[2:])
+	qh := binary.LittleEndian.Uint32(b[4:])
+	qs := b[8:24]
+	for j := range 16 {
+		h0 := byte(qh>>j<<4) & 0x10
+		h1 := byte(qh>>(j+12)) & 0x10
+		dst[j] = float32(qs[j]&0x0f|h0)*d + m
+		dst[j+16] = float32(qs[j]>>4|h1)*d + m
+	}
+}
+
+// dequantQ8_0: d и 32 значения int8
+func dequantQ8_0(dst []float32, b []byte) {
+	d := half(b)
+	for j, q := range b[2:34] {
+		dst[j] = float32(int8(q)) * d
+	}
+}
+
+// scaleMinK4 извлекает 6-битные масштаб и сдвиг подблока j из 12 байт масштабов K-квантов
+func scaleMinK4(j int, q []byte) (float32, float32) {
+	if j < 4 {
+		return float32(q[j] & 63), float32(q[j+4] & 63)
+	}
+	scale := q[j+4]&0x0f | q[j-4]>>6<<4
+	minimum := q[j+4]>>4 | q[j]>>6<<4
+	return float32(scale), float32(minimum)
+}
+
+// dequantQ4_K: супер-блок из 8 подблоков по 32 значения с 6-битными масштабами и сдвигами
+func dequantQ4_K(dst []float32, b []byte) {
+	d, dmin := half(b), half(b[2:])
+	scales := b[4:16]
+	q := b[16:144]
+	for j, is := 0, 0; j < 256; j, is = j+64, is+2 {
+		sc1, m1 := scaleMinK4(is, scales)
+		sc2, m2 := scaleMinK4(is+1, scales)
+		d1, min1 := d*sc1, dmin*m1
+		d2, min2 := d*sc2, dmin*m2
+		for l := range 32 {
+			dst[j+l] = d1*float32(q[l]&0x0f) - min1
+			dst[j+l+32] = d2*float32(q[l]>>4) - min2
+		}
+		q = q[32:]
+	}
+}
+
+// dequantQ5_K: как Q4_K, с пятым битом каждого значения в отдельном массиве
+func dequantQ5_K(dst []float32, b []byte) {
+	d, dmin := half(b), half(b[2:])
+	scales := b[4:16]
+	qh := b[16:48]
+	ql := b[48:176]
+	var u1, u2 byte = 1, 2
+	for j, is := 0, 0; j < 256; j, is = j+64, is+2 {
+		sc1, m1 := scaleMinK4(is, scales)
+		sc2, m2 := scaleMinK4(is+1, scales)
+		d1, min1 := d*sc1, dmin*m1
+		d2, min2 := d*sc2, dmin*m2
+		for l := range 32 {
+			var h1, h2 float32
+			if qh[l]&u1 != 0 {
+				h1 = 16
+			}
+			if qh[l]&u2 != 0 {
+				h2 = 16
+			}
+			dst[j+l] = d1*(float32(ql[l]&0x0f)+h1) - min1
+			dst[j+l+32] = d2*(float32(ql[l]>>4)+h2) - min2
+		}
+		ql = ql[32:]
+		u1 <<= 2
+		u2 <<= 2
+	}
+}
+
+// dequantQ6_K: 16 подблоков по 16 значений с 8-битными масштабами; значения
+// собираются из младших 4 бит (ql) и старших 2 бит (qh) со смещением 32
+func dequantQ6_K(dst []float32, b []byte) {
+	ql := b[0:128]
+	qh := b[128:192]
+	scales := b[192:208]
+	d := half(b[208:])
+	for n := 0; n < 256; n += 128 {
+		for l := range 32 {
+			is := l / 16
+			q1 := int(ql[l]&0x0f|(qh[l]>>0&3)<<4) - 32
+			q2 := int(ql[l+32]&0x0f|(qh[l]>>2&3)<<4) - 32
+			q3 := int(ql[l]>>4|(qh[l]>>4&3)<<4) - 32
+			q4 := int(ql[l+32]>>4|(qh[l]>>6&3)<<4) - 32
+			dst[n+l] = d * float32(int8(scales[is])) * float32(q1)
+			dst[n+l+32] = d * float32(int8(scales[is+2])) * float32(q2)
+			dst[n+l+64] = d * float32(int8(scales[is+4])) * float32(q3)
+			dst[n+l+96] = d * float32(int8(scales[is+6])) * float32(q4)
+		}
+		ql = ql[64:]
+		qh = qh[32:]
+		scales = scales[8:]
+	}
+}
+
+// bfloat16ToFloat32 преобразует bfloat16 (старшие 16 бит float32) в float32
+func bfloat16ToFloat32(h uint16) float32 {
+	return math.Float32frombits(uint32(h) << 16)
+}
+
+// halfToFloat32 преобразует число половинной точности IEEE 754 в float32
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exponent := uint32(h>>10) & 0x1f
+	mantissa := uint32(h) & 0x3ff
+
+	switch exponent {
+	case 0:
+		if mantissa == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Денормализованное число: нормализуем мантиссу
+		exponent = 127 - 15 + 1
+		for mantissa&0x400 == 0 {
+			mantissa <<= 1
+			exponent--
+		}
+		mantissa &= 0x3ff
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | mantissa<<13)
+	default:
+		exponent += 127 - 15
+	}
+
+	return math.Float32frombits(sign | exponent<<23 | mantissa<<13)
+}
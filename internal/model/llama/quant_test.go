@@ -0,0 +1,150 @@
+package llama
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// Числа половинной точности для масштабов блоков
+const (
+	halfOne  uint16 = 0x3c00 // 1
+	halfHalf uint16 = 0x3800 // 0.5
+)
+
+// block собирает байты блока: масштабы в little-endian, затем fill
+// повторенный до size байт
+func block(size int, fill byte, halves ...uint16) []byte {
+	b := bytes.Repeat([]byte{fill}, size)
+	for i, h := range halves {
+		b[2*i] = byte(h)
+		b[2*i+1] = byte(h >> 8)
+	}
+	return b
+}
+
+// kScales возвращает 12 байт масштабов K-квантов, где у всех 8 подблоков
+// масштаб scale и сдвиг minimum (оба меньше 16)
+func kScales(scale, minimum byte) []byte {
+	q := make([]byte, 12)
+	for j := range 4 {
+		q[j] = scale
+		q[j+4] = minimum
+		q[j+8] = scale | minimum<<4
+	}
+	return q
+}
+
+// pattern возвращает n значений, чередуя группы по size одинаковых значений из values
+func pattern(n, size int, values ...float32) []float32 {
+	result := make([]float32, n)
+	for i := range result {
+		result[i] = values[i/size%len(values)]
+	}
+	return result
+}
+
+func TestDequantize(t *testing.T) {
+	q4k := block(144, 0x53, halfOne, halfOne)
+	copy(q4k[4:16], kScales(2, 1))
+
+	q5k := block(176, 0x53, halfOne, halfOne)
+	copy(q5k[4:16], kScales(2, 1))
+	for i := 16; i < 48; i++ {
+		q5k[i] = 0xff
+	}
+
+	q6k := block(210, 0x21)
+	for i := 128; i < 192; i++ {
+		q6k[i] = 0xff // Старшие биты: +48
+	}
+	for i := 192; i < 208; i++ {
+		q6k[i] = 1
+	}
+	copy(q6k[208:], block(2, 0, halfHalf))
+
+	q8 := block(34, 0xfd, halfHalf) // int8(-3)
+
+	tests := []struct {
+		name  string
+		dtype DType
+		src   []byte
+		want  []float32
+	}{
+		// Младшие 4 бита: 0xA - 8 = 2, старшие: 0x9 - 8 = 1
+		{"Q4_0", DTYPE_Q4_0, block(18, 0x9a, halfHalf), pattern(32, 16, 1, 0.5)},
+		{"Q4_1", DTYPE_Q4_1, block(20, 0x9a, halfHalf, halfOne), pattern(32, 16, 6, 5.5)},
+		// Без старших битов: 1 - 16 и 2 - 16
+		{"Q5_0", DTYPE_Q5_0, block(22, 0x21, halfOne, 0, 0), pattern(32, 16, -15, -14)},
+		{"Q5_1 high bits", DTYPE_Q5_1, block(24, 0x21, halfOne, halfOne, 0xffff, 0xffff), pattern(32, 16, 18, 19)},
+		{"Q8_0", DTYPE_Q8_0, q8, pattern(32, 32, -1.5)},
+		// Подблоки по 32: 2*3 - 1 и 2*5 - 1
+		{"Q4_K", DTYPE_Q4_K, q4k, pattern(256, 32, 5, 9)},
+		// С пятым битом: 2*(3+16) - 1 и 2*(5+16) - 1
+		{"Q5_K", DTYPE_Q5_K, q5k, pattern(256, 32, 37, 41)},
+		// (1+48-32)*0.5 для младших полубайтов, (2+48-32)*0.5 для старших
+		{"Q6_K", DTYPE_Q6_K, q6k, pattern(256, 64, 8.5, 9)},
+		{"F16", DTYPE_F16, []byte{0x00, 0xc0, 0x00, 0x3c}, []float32{-2, 1}},
+		{"BF16", DTYPE_BF16, []byte{0xc0, 0x3f}, []float32{1.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(tt.src) / dtypes[tt.dtype].blockBytes * dtypes[tt.dtype].blockSize; got != len(tt.want) {
+				t.Fatalf("test block holds %d values, want %d", got, len(tt.want))
+			}
+			dst := make([]float32, len(tt.want))
+			dequantize(tt.dtype, dst, tt.src)
+			for i := range dst {
+				if dst[i] != tt.want[i] {
+					t.Fatalf("value %d = %v, want %v", i, dst[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScaleMinK4(t *testing.T) {
+	q := []byte{0xc1, 0x02, 0x03, 0x04, 0x45, 0x06, 0x07, 0x08, 0x9a, 0x0b, 0x0c, 0x0d}
+
+	tests := []struct {
+		j          int
+		scale, min float32
+	}{
+		{0, 1, 5},                     // 0xc1 & 63, 0x45 & 63
+		{1, 2, 6},                     // 0x02, 0x06
+		{4, 0x0a | 3<<4, 0x09 | 1<<4}, // 0x9a и старшие биты q[0], q[4]
+		{5, 0x0b, 0x00},               // 0x0b
+	}
+
+	for _, tt := range tests {
+		scale, minimum := scaleMinK4(tt.j, q)
+		if scale != tt.scale || minimum != tt.min {
+			t.Errorf("scaleMinK4(%d) = %v, %v; want %v, %v", tt.j, scale, minimum, tt.scale, tt.min)
+		}
+	}
+}
+
+func TestHalfToFloat32(t *testing.T) {
+	tests := []struct {
+		h    uint16
+		want float32
+	}{
+		{0x0000, 0},
+		{0x3c00, 1},
+		{0xc000, -2},
+		{0x7bff, 65504},
+		{0x0001, float32(math.Ldexp(1, -24))},
+		{0x0400, float32(math.Ldexp(1, -14))},
+		{0x7c00, float32(math.Inf(1))},
+	}
+
+	for _, tt := range tests {
+		if got := halfToFloat32(tt.h); got != tt.want {
+			t.Errorf("halfToFloat32(%#04x) = %v, want %v", tt.h, got, tt.want)
+		}
+	}
+	if got := halfToFloat32(0x7e00); !math.IsNaN(float64(got)) {
+		t.Errorf("halfToFloat32(NaN) = %v", got)
+	}
+}
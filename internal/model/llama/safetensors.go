@@ -0,0 +1,145 @@
+package llama
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// MAX_HEADER_SIZE ограничивает JSON заголовок файла safetensors
+const MAX_HEADER_SIZE = 100 * 1024 * 1024
+
+// safetensorsTypes - типы данных safetensors, которые преобразуются в float32
+var safetensorsTypes = map[string]DType{
+	"F32":  DTYPE_F32,
+	"F16":  DTYPE_F16,
+	"BF16": DTYPE_BF16,
+}
+
+// tensorInfo - описание тензора в заголовке safetensors
+type tensorInfo struct {
+	DType   string   `json:"dtype"`
+	Shape   []int    `json:"shape"`
+	Offsets [2]int64 `json:"data_offsets"`
+}
+
+// loadWeights загружает все тензоры модели: из model.safetensors или из
+// файлов, перечисленных в model.safetensors.index.json
+func loadWeights(modelPath string) (map[string]*Tensor, error) {
+	single := filepath.Join(modelPath, WEIGHTS_FILE)
+	if _, err := os.Stat(single); err == nil {
+		return loadSafetensors(single, nil)
+	}
+
+	data, err := os.ReadFile(filepath.Join(modelPath, WEIGHTS_INDEX_FILE))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, i18n.WrapError(err, "llama.weights_not_found", modelPath).WithKind(ErrModelNotFound)
+		}
+		return nil, i18n.WrapError(err, "llama.weights_read", WEIGHTS_INDEX_FILE).WithKind(ErrInvalidWeights)
+	}
+
+	var index struct {
+		WeightMap map[string]string `json:"weight_map"`
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, i18n.WrapError(err, "llama.weights_read", WEIGHTS_INDEX_FILE).WithKind(ErrInvalidWeights)
+	}
+
+	var files []string
+	for _, file := range index.WeightMap {
+		if !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+
+	tensors := make(map[string]*Tensor)
+	for _, file := range files {
+		if _, err := loadSafetensors(filepath.Join(modelPath, filepath.Base(file)), tensors); err != nil {
+			return nil, err
+		}
+	}
+	return tensors, nil
+}
+
+// loadSafetensors читает файл safetensors и добавляет его тензоры в tensors
+// (новый словарь, если tensors равен nil). Поддерживаются F32, F16 и BF16
+func loadSafetensors(path string, tensors map[string]*Tensor) (map[string]*Tensor, error) {
+	if tensors == nil {
+		tensors = make(map[string]*Tensor)
+	}
+	name := filepath.Base(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.WrapError(err, "llama.weights_read", name).WithKind(ErrInvalidWeights)
+	}
+
+	// Файл начинается с длины заголовка (uint64 little-endian) и JSON заголовка
+	if len(data) < 8 {
+		return nil, i18n.NewError("llama.weights_corrupt", name).WithKind(ErrInvalidWeights)
+	}
+	headerSize := binary.LittleEndian.Uint64(data)
+	if headerSize > MAX_HEADER_SIZE || 8+headerSize > uint64(len(data)) {
+		return nil, i18n.NewError("llama.weights_corrupt", name).WithKind(ErrInvalidWeights)
+	}
+
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(data[8:8+headerSize], &header); err != nil {
+		return nil, i18n.WrapError(err, "llama.weights_corrupt", name).WithKind(ErrInvalidWeights)
+	}
+	payload := data[8+headerSize:]
+
+	for tensorName, raw := range header {
+		if tensorName == "__metadata__" {
+			continue
+		}
+
+		var info tensorInfo
+		if err := json.Unmarshal(raw, &info); err != nil {
+			return nil, i18n.WrapError(err, "llama.weights_corrupt", name).WithKind(ErrInvalidWeights)
+		}
+
+		tensor, err := decodeTensor(info, payload)
+		if err != nil {
+			return nil, i18n.WrapError(err, "llama.tensor_invalid", tensorName).WithKind(ErrInvalidWeights)
+		}
+		tensors[tensorName] = tensor
+	}
+
+	return tensors, nil
+}
+
+// decodeTensor преобразует данные тензора в float32
+func decodeTensor(info tensorInfo, payload []byte) (*Tensor, error) {
+	count := 1
+	for _, dim := range info.Shape {
+		count *= dim
+	}
+
+	start, end := info.Offsets[0], info.Offsets[1]
+	if start < 0 || end < start || end > int64(len(payload)) {
+		return nil, i18n.NewError("llama.tensor_offsets")
+	}
+	raw := payload[start:end]
+
+	dtype, ok := safetensorsTypes[info.DType]
+	if !ok {
+		return nil, i18n.NewError("llama.tensor_dtype", info.DType)
+	}
+	if len(raw) != count*dtypes[dtype].blockBytes {
+		return nil, i18n.NewError("llama.tensor_offsets")
+	}
+
+	values := make([]float32, count)
+	dequantize(dtype, values, raw)
+
+	return &Tensor{Shape: info.Shape, Data: values}, nil
+}
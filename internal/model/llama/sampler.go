@@ -0,0 +1,193 @@
+package llama
+
+import (
+	"container/heap"
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// SamplingParams - параметры выбора токена
+type SamplingParams struct {
+	Temperature       float64 // 0 - жадный выбор самого вероятного токена
+	TopK              int     // 0 - без ограничения
+	TopP              float64 // 0 или 1 - без ограничения
+	MinP              float64 // Отбрасываются токены с вероятностью меньше MinP от наибольшей; 0 - без ограничения
+	RepetitionPenalty float64 // Штраф токенов промпта и ответа, как в transformers; 0 или 1 - без штрафа
+	FrequencyPenalty  float64 // Вычитается из логита за каждое появление токена в ответе
+	PresencePenalty   float64 // Вычитается из логита токена, уже появившегося в ответе
+}
+
+// Sampler выбирает следующий токен по логитам: штрафы за повторы, затем
+// температура, top-k, top-p и min-p, в том же порядке, что и generate в
+// transformers
+type Sampler struct {
+	SamplingParams
+
+	rng       *rand.Rand
+	seen      map[int]bool // Токены промпта и ответа для RepetitionPenalty
+	generated map[int]int  // Число появлений токенов в ответе
+}
+
+// candidate - токен-кандидат с логитом или вероятностью
+type candidate struct {
+	id    int
+	score float32
+}
+
+// NewSampler создает сэмплер. Одинаковый ненулевой seed дает одинаковую
+// последовательность токенов; при seed 0 используется случайный
+func NewSampler(params SamplingParams, seed uint64) *Sampler {
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &Sampler{
+		SamplingParams: params,
+		rng:            rand.New(rand.NewPCG(seed, seed)),
+		seen:           make(map[int]bool),
+		generated:      make(map[int]int),
+	}
+}
+
+// Prompt запоминает токены промпта для штрафа за повторы
+func (s *Sampler) Prompt(tokens []int) {
+	for _, token := range tokens {
+		s.seen[token] = true
+	}
+}
+
+// Accept запоминает выбранный токен ответа
+func (s *Sampler) Accept(token int) {
+	s.seen[token] = true
+	s.generated[token]++
+}
+
+// Sample возвращает идентификатор выбранного токена. Штрафы за повторы
+// применяются к logits на месте
+func (s *Sampler) Sample(logits []float32) int {
+	s.penalize(logits)
+
+	if s.Temperature <= 0 {
+		best := 0
+		for i, logit := range logits {
+			if logit > logits[best] {
+				best = i
+			}
+		}
+		return best
+	}
+
+	candidates := topCandidates(logits, s.TopK)
+
+	// Вероятности с учетом температуры; кандидаты отсортированы по убыванию
+	temperature := float32(s.Temperature)
+	maxLogit := candidates[0].score
+	var sum float64
+	for i := range candidates {
+		p := math.Exp(float64((candidates[i].score - maxLogit) / temperature))
+		candidates[i].score = float32(p)
+		sum += p
+	}
+
+	// Top-p: наименьший набор самых вероятных токенов с суммой не меньше TopP
+	if s.TopP > 0 && s.TopP < 1 {
+		var cumulative float64
+		for i := range candidates {
+			cumulative += float64(candidates[i].score) / sum
+			if cumulative >= s.TopP {
+				candidates = candidates[:i+1]
+				break
+			}
+		}
+		sum = 0
+		for _, c := range candidates {
+			sum += float64(c.score)
+		}
+	}
+
+	// Min-p: вероятности не нормированы, у первого кандидата она равна 1
+	if s.MinP > 0 {
+		keep := 1
+		for keep < len(candidates) && float64(candidates[keep].score) >= s.MinP {
+			keep++
+		}
+		candidates = candidates[:keep]
+		sum = 0
+		for _, c := range candidates {
+			sum += float64(c.score)
+		}
+	}
+
+	target := s.rng.Float64() * sum
+	for _, c := range candidates {
+		target -= float64(c.score)
+		if target < 0 {
+			return c.id
+		}
+	}
+	return candidates[len(candidates)-1].id
+}
+
+// penalize применяет штрафы за повторы: RepetitionPenalty делит
+// положительные логиты и умножает отрицательные, частотный штраф и штраф
+// присутствия вычитаются, как в API OpenAI
+func (s *Sampler) penalize(logits []float32) {
+	if s.RepetitionPenalty > 0 && s.RepetitionPenalty != 1 {
+		penalty := float32(s.RepetitionPenalty)
+		for token := range s.seen {
+			if logits[token] > 0 {
+				logits[token] /= penalty
+			} else {
+				logits[token] *= penalty
+			}
+		}
+	}
+
+	if s.FrequencyPenalty != 0 || s.PresencePenalty != 0 {
+		for token, count := range s.generated {
+			logits[token] -= float32(float64(count)*s.FrequencyPenalty + s.PresencePenalty)
+		}
+	}
+}
+
+// topCandidates возвращает k токенов с наибольшими логитами (все при k <= 0)
+// в порядке убывания логита
+func topCandidates(logits []float32, k int) []candidate {
+	if k <= 0 || k >= len(logits) {
+		candidates := make([]candidate, len(logits))
+		for i, logit := range logits {
+			candidates[i] = candidate{id: i, score: logit}
+		}
+		sort.Slice(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+		return candidates
+	}
+
+	// Минимальная куча из k лучших кандидатов
+	h := make(candidateHeap, 0, k)
+	for i, logit := range logits {
+		if len(h) < k {
+			heap.Push(&h, candidate{id: i, score: logit})
+		} else if logit > h[0].score {
+			h[0] = candidate{id: i, score: logit}
+			heap.Fix(&h, 0)
+		}
+	}
+
+	candidates := []candidate(h)
+	sort.Slice(candidates, func(a, b int) bool { return candidates[a].score > candidates[b].score })
+	return candidates
+}
+
+// candidateHeap - минимальная куча кандидатов по score
+type candidateHeap []candidate
+
+func (h candidateHeap) Len() int           { return len(h) }
+func (h candidateHeap) Less(a, b int) bool { return h[a].score < h[b].score }
+func (h candidateHeap) Swap(a, b int)      { h[a], h[b] = h[b], h[a] }
+func (h *candidateHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *candidateHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return last
+}
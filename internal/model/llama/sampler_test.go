@@ -0,0 +1,90 @@
+package llama
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSamplerSample(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    SamplingParams
+		logits    []float32
+		prompt    []int
+		generated []int
+		want      int
+	}{
+		{"greedy", SamplingParams{}, []float32{0.1, 3, 2}, nil, nil, 1},
+		{"top-k 1", SamplingParams{Temperature: 1, TopK: 1}, []float32{0.1, 3, 2.9}, nil, nil, 1},
+		{"top-p keeps most likely", SamplingParams{Temperature: 1, TopP: 0.5}, []float32{0, 10, 0}, nil, nil, 1},
+		{"min-p drops unlikely", SamplingParams{Temperature: 1, MinP: 0.5}, []float32{5, 0, 0}, nil, nil, 0},
+		{"repetition penalty on prompt", SamplingParams{RepetitionPenalty: 2}, []float32{2, 1.5}, []int{0}, nil, 1},
+		{"repetition penalty on negative logit", SamplingParams{RepetitionPenalty: 2}, []float32{-1, -1.5}, []int{0}, nil, 1},
+		{"frequency penalty", SamplingParams{FrequencyPenalty: 0.5}, []float32{2, 1.5}, nil, []int{0, 0}, 1},
+		{"presence penalty", SamplingParams{PresencePenalty: 1}, []float32{2, 1.5}, nil, []int{0}, 1},
+		{"penalties ignore prompt", SamplingParams{PresencePenalty: 1}, []float32{2, 1.5}, []int{0}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Результат не должен зависеть от seed
+			for seed := uint64(1); seed <= 20; seed++ {
+				s := NewSampler(tt.params, seed)
+				s.Prompt(tt.prompt)
+				for _, token := range tt.generated {
+					s.Accept(token)
+				}
+				logits := append([]float32(nil), tt.logits...)
+				if got := s.Sample(logits); got != tt.want {
+					t.Fatalf("seed %d: Sample = %d, want %d", seed, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSamplerSeed(t *testing.T) {
+	params := SamplingParams{Temperature: 1}
+	logits := []float32{1, 1, 1, 1, 1, 1, 1, 1}
+
+	sequence := func(seed uint64) []int {
+		s := NewSampler(params, seed)
+		tokens := make([]int, 32)
+		for i := range tokens {
+			tokens[i] = s.Sample(append([]float32(nil), logits...))
+		}
+		return tokens
+	}
+
+	if a, b := sequence(42), sequence(42); !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed gave different tokens: %v vs %v", a, b)
+	}
+	if a, b := sequence(42), sequence(43); reflect.DeepEqual(a, b) {
+		t.Errorf("different seeds gave the same tokens: %v", a)
+	}
+}
+
+func TestTopCandidates(t *testing.T) {
+	logits := []float32{0.5, 3, -1, 2, 2.5}
+
+	tests := []struct {
+		k    int
+		want []int
+	}{
+		{0, []int{1, 4, 3, 0, 2}},
+		{2, []int{1, 4}},
+		{3, []int{1, 4, 3}},
+		{10, []int{1, 4, 3, 0, 2}},
+	}
+
+	for _, tt := range tests {
+		candidates := topCandidates(logits, tt.k)
+		ids := make([]int, len(candidates))
+		for i, c := range candidates {
+			ids[i] = c.id
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("topCandidates(k=%d) = %v, want %v", tt.k, ids, tt.want)
+		}
+	}
+}
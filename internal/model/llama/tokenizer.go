@@ -0,0 +1,474 @@
+package llama
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Tokenizer - байтовый BPE токенизатор в формате tokenizer.json (GPT-2,
+// Llama 3, SmolLM2). Текст делится по специальным токенам, затем на слова
+// регулярным выражением GPT-2, а слова кодируются слияниями BPE
+type Tokenizer struct {
+	vocab        map[string]int
+	tokens       []string       // Строки токенов по идентификаторам
+	ranks        map[string]int // Приоритет слияния пары "a b"; меньше - раньше
+	ignoreMerges bool           // Слово из словаря кодируется целиком
+	special      map[int]bool   // Добавленные специальные токены
+	added        []string       // Добавленные токены, от длинных к коротким
+	digits       bool           // Цифры выделяются в отдельные слова
+	prefix       []int          // Токены, добавляемые перед текстом
+	suffix       []int          // Токены, добавляемые после текста
+
+	cacheMu sync.Mutex
+	cache   map[string][]int // Результаты BPE по словам
+}
+
+// tokenizerFile - используемая часть tokenizer.json
+type tokenizerFile struct {
+	AddedTokens []struct {
+		ID      int    `json:"id"`
+		Content string `json:"content"`
+		Special bool   `json:"special"`
+	} `json:"added_tokens"`
+	PreTokenizer  json.RawMessage `json:"pre_tokenizer"`
+	PostProcessor json.RawMessage `json:"post_processor"`
+	Model         struct {
+		Type         string          `json:"type"`
+		Vocab        map[string]int  `json:"vocab"`
+		Merges       json.RawMessage `json:"merges"`
+		IgnoreMerges bool            `json:"ignore_merges"`
+	} `json:"model"`
+}
+
+// preTokenizer - описание предварительной токенизации; Sequence содержит вложенные
+type preTokenizer struct {
+	Type             string         `json:"type"`
+	IndividualDigits bool           `json:"individual_digits"`
+	PreTokenizers    []preTokenizer `json:"pretokenizers"`
+}
+
+// byteEncoder и byteDecoder - взаимно однозначное отображение байтов в
+// печатаемые символы, которым байтовый BPE записывает токены
+var byteEncoder, byteDecoder = byteTables()
+
+// LoadTokenizer загружает tokenizer.json из директории модели
+func LoadTokenizer(modelPath string) (*Tokenizer, error) {
+	data, err := os.ReadFile(filepath.Join(modelPath, TOKENIZER_FILE))
+	if err != nil {
+		return nil, i18n.WrapError(err, "llama.tokenizer_read").WithKind(ErrModelNotFound)
+	}
+
+	var file tokenizerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, i18n.WrapError(err, "llama.tokenizer_decode").WithKind(ErrInvalidTokenizer)
+	}
+	if file.Model.Type != "BPE" {
+		return nil, i18n.NewError("llama.tokenizer_unsupported", file.Model.Type).WithKind(ErrInvalidTokenizer)
+	}
+
+	// Слияния записываются строками "a b" или, в новых файлах, парами ["a", "b"]
+	var rawMerges []json.RawMessage
+	if err := json.Unmarshal(file.Model.Merges, &rawMerges); err != nil {
+		return nil, i18n.WrapError(err, "llama.tokenizer_decode").WithKind(ErrInvalidTokenizer)
+	}
+	merges := make([]string, len(rawMerges))
+	for i, raw := range rawMerges {
+		if err := json.Unmarshal(raw, &merges[i]); err != nil {
+			var pair []string
+			if err := json.Unmarshal(raw, &pair); err != nil || len(pair) != 2 {
+				return nil, i18n.NewError("llama.tokenizer_decode").WithKind(ErrInvalidTokenizer)
+			}
+			merges[i] = pair[0] + " " + pair[1]
+		}
+	}
+
+	added := make([]addedToken, len(file.AddedTokens))
+	for i, token := range file.AddedTokens {
+		added[i] = addedToken{id: token.ID, content: token.Content, special: token.Special}
+	}
+
+	digits := false
+	if len(file.PreTokenizer) > 0 && string(file.PreTokenizer) != "null" {
+		var pre preTokenizer
+		if err := json.Unmarshal(file.PreTokenizer, &pre); err != nil {
+			return nil, i18n.WrapError(err, "llama.tokenizer_decode").WithKind(ErrInvalidTokenizer)
+		}
+		digits = pre.splitsDigits()
+	}
+
+	t := newTokenizer(file.Model.Vocab, merges, added, file.Model.IgnoreMerges, digits)
+	if err := t.loadTemplate(file.PostProcessor); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// addedToken - токен, который распознается в тексте целиком до разбора на слова
+type addedToken struct {
+	id      int
+	content string
+	special bool // Пропускается при декодировании
+}
+
+// newTokenizer создает токенизатор по словарю, слияниям в виде "a b" в
+// порядке приоритета и добавленным токенам
+func newTokenizer(vocab map[string]int, merges []string, added []addedToken, ignoreMerges, digits bool) *Tokenizer {
+	t := &Tokenizer{
+		vocab:        vocab,
+		ranks:        make(map[string]int, len(merges)),
+		ignoreMerges: ignoreMerges,
+		special:      make(map[int]bool),
+		digits:       digits,
+		cache:        make(map[string][]int),
+	}
+
+	for rank, merge := range merges {
+		if _, ok := t.ranks[merge]; !ok {
+			t.ranks[merge] = rank
+		}
+	}
+
+	for _, token := range added {
+		t.vocab[token.content] = token.id
+		t.added = append(t.added, token.content)
+		if token.special {
+			t.special[token.id] = true
+		}
+	}
+	sort.Slice(t.added, func(a, b int) bool { return len(t.added[a]) > len(t.added[b]) })
+
+	size := 0
+	for _, id := range t.vocab {
+		size = max(size, id+1)
+	}
+	t.tokens = make([]string, size)
+	for token, id := range t.vocab {
+		t.tokens[id] = token
+	}
+
+	return t
+}
+
+// splitsDigits сообщает, выделяет ли предварительная токенизация каждую цифру
+func (p preTokenizer) splitsDigits() bool {
+	if p.Type == "Digits" && p.IndividualDigits {
+		return true
+	}
+	for _, nested := range p.PreTokenizers {
+		if nested.splitsDigits() {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTemplate читает специальные токены, которые постобработка TemplateProcessing
+// добавляет вокруг текста (например, BOS). Другие виды постобработки не меняют токены
+func (t *Tokenizer) loadTemplate(raw json.RawMessage) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var processor struct {
+		Type   string `json:"type"`
+		Single []struct {
+			SpecialToken *struct {
+				ID string `json:"id"`
+			} `json:"SpecialToken"`
+			Sequence *struct{} `json:"Sequence"`
+		} `json:"single"`
+	}
+	if err := json.Unmarshal(raw, &processor); err != nil {
+		return i18n.WrapError(err, "llama.tokenizer_decode").WithKind(ErrInvalidTokenizer)
+	}
+	if processor.Type != "TemplateProcessing" {
+		return nil
+	}
+
+	seenText := false
+	for _, item := range processor.Single {
+		switch {
+		case item.Sequence != nil:
+			seenText = true
+		case item.SpecialToken != nil:
+			id, ok := t.vocab[item.SpecialToken.ID]
+			if !ok {
+				return i18n.NewError("llama.tokenizer_token", item.SpecialToken.ID).WithKind(ErrInvalidTokenizer)
+			}
+			if seenText {
+				t.suffix = append(t.suffix, id)
+			} else {
+				t.prefix = append(t.prefix, id)
+			}
+		}
+	}
+	return nil
+}
+
+// TokenID возвращает идентификатор токена по его строке
+func (t *Tokenizer) TokenID(token string) (int, bool) {
+	id, ok := t.vocab[token]
+	return id, ok
+}
+
+// VocabSize возвращает размер словаря с добавленными токенами
+func (t *Tokenizer) VocabSize() int {
+	return len(t.tokens)
+}
+
+// Encode преобразует текст в токены. Специальные токены, записанные в тексте
+// (например, <|im_start|>), кодируются одним токеном
+func (t *Tokenizer) Encode(text string) []int {
+	ids := append([]int(nil), t.prefix...)
+
+	for len(text) > 0 {
+		// Ближайший добавленный токен; при равенстве позиций - самый длинный
+		next, match := len(text), ""
+		for _, token := range t.added {
+			if i := strings.Index(text[:min(len(text), next+len(token))], token); i >= 0 && i < next {
+				next, match = i, token
+			}
+		}
+
+		for _, word := range t.splitWords(text[:next]) {
+			ids = append(ids, t.encodeWord(word)...)
+		}
+		if match == "" {
+			break
+		}
+		ids = append(ids, t.vocab[match])
+		text = text[next+len(match):]
+	}
+
+	return append(ids, t.suffix...)
+}
+
+// Decode преобразует токены в текст. Специальные токены пропускаются, если skipSpecial
+func (t *Tokenizer) Decode(ids []int, skipSpecial bool) string {
+	var encoded strings.Builder
+	var result []byte
+
+	flush := func() {
+		for _, r := range encoded.String() {
+			if b, ok := byteDecoder[r]; ok {
+				result = append(result, b)
+			} else {
+				result = append(result, string(r)...)
+			}
+		}
+		encoded.Reset()
+	}
+
+	for _, id := range ids {
+		if id < 0 || id >= len(t.tokens) {
+			continue
+		}
+		if t.special[id] {
+			flush()
+			if !skipSpecial {
+				result = append(result, t.tokens[id]...)
+			}
+			continue
+		}
+		encoded.WriteString(t.tokens[id])
+	}
+	flush()
+
+	return strings.ToValidUTF8(string(result), "�")
+}
+
+// splitWords делит текст на слова как регулярное выражение GPT-2
+//
+//	's|'t|'re|'ve|'m|'ll|'d| ?\p{L}+| ?\p{N}+| ?[^\s\p{L}\p{N}]+|\s+(?!\S)|\s+
+//
+// Пакет regexp не поддерживает (?!\S), поэтому разбор написан вручную.
+// Если токенизатор выделяет цифры, каждая цифра отделяется до разбора
+func (t *Tokenizer) splitWords(text string) []string {
+	runes := []rune(text)
+	var words []string
+
+	split := func(part []rune) {
+		for i := 0; i < len(part); {
+			end := gpt2Word(part, i)
+			words = append(words, string(part[i:end]))
+			i = end
+		}
+	}
+
+	if !t.digits {
+		split(runes)
+		return words
+	}
+
+	start := 0
+	for i, r := range runes {
+		if unicode.IsNumber(r) {
+			split(runes[start:i])
+			words = append(words, string(r))
+			start = i + 1
+		}
+	}
+	split(runes[start:])
+
+	return words
+}
+
+// gpt2Word возвращает конец слова, начинающегося с позиции i
+func gpt2Word(runes []rune, i int) int {
+	n := len(runes)
+
+	// Английские сокращения: 's, 't, 're, 've, 'm, 'll, 'd
+	if runes[i] == '\'' && i+1 < n {
+		switch runes[i+1] {
+		case 's', 't', 'm', 'd':
+			return i + 2
+		case 'r', 'v', 'l':
+			if i+2 < n && (runes[i+1] == 'r' && runes[i+2] == 'e' ||
+				runes[i+1] == 'v' && runes[i+2] == 'e' ||
+				runes[i+1] == 'l' && runes[i+2] == 'l') {
+				return i + 3
+			}
+		}
+	}
+
+	// Буквы, цифры или прочие символы с необязательным пробелом впереди
+	start := i
+	if runes[i] == ' ' && i+1 < n && !unicode.IsSpace(runes[i+1]) {
+		start = i + 1
+	}
+	if !unicode.IsSpace(runes[start]) {
+		class := runeClass(runes[start])
+		end := start + 1
+		for end < n && runeClass(runes[end]) == class {
+			end++
+		}
+		return end
+	}
+
+	// Пробельные символы: \s+(?!\S) оставляет последний пробел следующему слову
+	end := i + 1
+	for end < n && unicode.IsSpace(runes[end]) {
+		end++
+	}
+	if end < n && end-i > 1 {
+		return end - 1
+	}
+	return end
+}
+
+// Классы символов для разбора на слова
+const (
+	classLetter = iota
+	classNumber
+	classOther
+	classSpace
+)
+
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsLetter(r):
+		return classLetter
+	case unicode.IsNumber(r):
+		return classNumber
+	case unicode.IsSpace(r):
+		return classSpace
+	default:
+		return classOther
+	}
+}
+
+// encodeWord кодирует слово слияниями BPE
+func (t *Tokenizer) encodeWord(word string) []int {
+	t.cacheMu.Lock()
+	cached, ok := t.cache[word]
+	t.cacheMu.Unlock()
+	if ok {
+		return cached
+	}
+
+	var encoded strings.Builder
+	for _, b := range []byte(word) {
+		encoded.WriteRune(byteEncoder[b])
+	}
+
+	var ids []int
+	if id, ok := t.vocab[encoded.String()]; ok && t.ignoreMerges {
+		ids = []int{id}
+	} else {
+		for _, symbol := range t.merge(encoded.String()) {
+			if id, ok := t.vocab[symbol]; ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	t.cacheMu.Lock()
+	t.cache[word] = ids
+	t.cacheMu.Unlock()
+
+	return ids
+}
+
+// merge применяет слияния BPE к символам слова, начиная с пары наивысшего приоритета
+func (t *Tokenizer) merge(word string) []string {
+	var symbols []string
+	for _, r := range word {
+		symbols = append(symbols, string(r))
+	}
+
+	for len(symbols) > 1 {
+		best, bestRank := -1, len(t.ranks)
+		for i := 0; i+1 < len(symbols); i++ {
+			if rank, ok := t.ranks[symbols[i]+" "+symbols[i+1]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		// Сливаем все вхождения выбранной пары слева направо
+		first, second := symbols[best], symbols[best+1]
+		merged := symbols[:0:0]
+		for i := 0; i < len(symbols); i++ {
+			if i+1 < len(symbols) && symbols[i] == first && symbols[i+1] == second {
+				merged = append(merged, first+second)
+				i++
+			} else {
+				merged = append(merged, symbols[i])
+			}
+		}
+		symbols = merged
+	}
+
+	return symbols
+}
+
+// byteTables строит таблицы байтового BPE: печатаемые байты отображаются в
+// себя, остальные - в символы начиная с U+0100
+func byteTables() (map[byte]rune, map[rune]byte) {
+	encoder := make(map[byte]rune, 256)
+	decoder := make(map[rune]byte, 256)
+
+	next := rune(256)
+	for b := 0; b < 256; b++ {
+		r := rune(b)
+		printable := b >= '!' && b <= '~' || b >= 0xa1 && b <= 0xac || b >= 0xae && b <= 0xff
+		if !printable {
+			r = next
+			next++
+		}
+		encoder[byte(b)] = r
+		decoder[r] = byte(b)
+	}
+
+	return encoder, decoder
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Источники фактов долговременной памяти
+const (
+	MEMORY_SOURCE_USER  = "user"  // Добавлен командой /remember
+	MEMORY_SOURCE_MODEL = "model" // Записан моделью через тег <remember>
+)
+
+// MemoryFact представляет факт о пользователе, сохраняемый между сессиями
+type MemoryFact struct {
+	Text      string    `json:"text"`
+	Source    string    `json:"source"` // user или model
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MemoryStore хранит долговременную память одного пользователя.
+// Реализация находится в пакете storage
+type MemoryStore interface {
+	Facts() ([]MemoryFact, error)
+	Remember(text, source string) (MemoryFact, error)
+}
+
+// rememberTagPattern находит теги <remember>факт</remember> в ответе модели
+var rememberTagPattern = regexp.MustCompile(`(?s)<remember>(.*?)</remember>`)
+
+// extractRememberTags возвращает факты из тегов <remember> и текст ответа без них
+func extractRememberTags(text string) (string, []string) {
+	var facts []string
+	for _, match := range rememberTagPattern.FindAllStringSubmatch(text, -1) {
+		if fact := strings.TrimSpace(match[1]); fact != "" {
+			facts = append(facts, fact)
+		}
+	}
+	if len(facts) == 0 {
+		return text, nil
+	}
+
+	return strings.TrimSpace(rememberTagPattern.ReplaceAllString(text, "")), facts
+}
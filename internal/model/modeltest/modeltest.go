@@ -0,0 +1,119 @@
+// Package modeltest запускает поддельный сервер модели для тестов пакетов,
+// которые работают с SmolLM, но не должны загружать настоящую модель
+package modeltest
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/modelserver"
+)
+
+// Ответ сервера по умолчанию
+const (
+	REPLY         = "Hello from the test model"
+	PROMPT_TOKENS = 10
+	REPLY_TOKENS  = 5
+)
+
+// Reply возвращает ответ на запрос и код статуса HTTP
+type Reply func(request model.InferenceRequest) (*model.InferenceResponse, int)
+
+// Server - сервер модели, который отвечает на /health и /v1/generate без модели
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	reply    Reply
+	requests []model.InferenceRequest
+}
+
+// NewServer запускает сервер модели, который отвечает REPLY на любой
+// запрос. Сервер останавливается по завершении теста
+func NewServer(t testing.TB) *Server {
+	s := &Server{reply: func(model.InferenceRequest) (*model.InferenceResponse, int) {
+		return &model.InferenceResponse{
+			Text:         REPLY,
+			PromptTokens: PROMPT_TOKENS,
+			TokensUsed:   PROMPT_TOKENS + REPLY_TOKENS,
+		}, http.StatusOK
+	}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+modelserver.HEALTH_PATH, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /v1/generate", s.handleGenerate)
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// SetReply задает ответ сервера на следующие запросы
+func (s *Server) SetReply(reply Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reply = reply
+}
+
+// Requests возвращает полученные сервером запросы генерации
+func (s *Server) Requests() []model.InferenceRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]model.InferenceRequest(nil), s.requests...)
+}
+
+// Config возвращает настройки сервера модели, указывающие на этот сервер
+func (s *Server) Config() modelserver.Config {
+	host, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return modelserver.Config{Host: host, Port: portNumber}
+}
+
+// Options возвращает настройки SmolLM, генерирующей через этот сервер
+func (s *Server) Options(t testing.TB) model.Options {
+	return model.Options{
+		Path:    t.TempDir(),
+		Backend: model.BACKEND_API,
+		Server:  s.Config(),
+	}
+}
+
+// NewModel создает SmolLM, генерирующую через новый сервер модели. Модель
+// закрывается по завершении теста
+func NewModel(t testing.TB) (*model.SmolLM, *Server) {
+	server := NewServer(t)
+	m := model.NewSmolLMWithOptions(server.Options(t))
+	t.Cleanup(m.Close)
+	return m, server
+}
+
+func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
+	var request model.InferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, request)
+	reply := s.reply
+	s.mu.Unlock()
+
+	response, status := reply(request)
+	if status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,192 @@
+package model
+
+import (
+	"sort"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+const (
+	// Имя профиля по умолчанию
+	DEFAULT_PROFILE = "default"
+
+	// Префикс запросов режима размышления по умолчанию
+	DEFAULT_THINKING_PROMPT = "Размышляю самостоятельно без участия пользователя: "
+
+	// Свойство метаданных сессии с именем профиля
+	META_PROFILE = "profile"
+)
+
+// Инструменты, доступ к которым ограничивается профилем
+const (
+	TOOL_CODE      = "code"      // Выполнение кода в песочнице
+	TOOL_MEMORY    = "memory"    // Долговременная память пользователя
+	TOOL_DOCUMENTS = "documents" // Поиск по локальным документам (RAG)
+)
+
+// languageNames содержит названия языков ответа для системной инструкции
+var languageNames = map[string]string{
+	"ru": "русском",
+	"en": "английском",
+}
+
+// Profile описывает персону модели: системную инструкцию, параметры
+// генерации, доступные инструменты и язык ответов. Незаданные поля
+// берутся из профиля по умолчанию
+type Profile struct {
+	Name           string   `yaml:"name" json:"name"`
+	Description    string   `yaml:"description" json:"description,omitempty"`
+	SystemPrompt   string   `yaml:"system_prompt" json:"system_prompt"`
+	ThinkingPrompt string   `yaml:"thinking_prompt" json:"thinking_prompt,omitempty"` // Префикс запросов режима размышления
+	Temperature    float64  `yaml:"temperature" json:"temperature"`
+	TopP           float64  `yaml:"top_p" json:"top_p"`
+	MaxTokens      int      `yaml:"max_tokens" json:"max_tokens"`
+	Language       string   `yaml:"language" json:"language,omitempty"` // Код языка ответов (ru, en); пусто - язык пользователя
+	Tools          []string `yaml:"tools" json:"tools"`                 // Разрешенные инструменты; nil - все
+}
+
+// DefaultProfile возвращает встроенный профиль по умолчанию
+func DefaultProfile() Profile {
+	return Profile{
+		Name:           DEFAULT_PROFILE,
+		Description:    "Универсальный ассистент",
+		SystemPrompt:   DEFAULT_SYSTEM_PROMPT,
+		ThinkingPrompt: DEFAULT_THINKING_PROMPT,
+		Temperature:    TEMPERATURE,
+		TopP:           TOP_P,
+		MaxTokens:      MAX_TOKENS / 2,
+	}
+}
+
+// Allows проверяет, разрешен ли профилю инструмент
+func (p Profile) Allows(tool string) bool {
+	if p.Tools == nil {
+		return true
+	}
+
+	for _, allowed := range p.Tools {
+		if allowed == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет корректность профиля
+func (p Profile) Validate() error {
+	if !isValidProfileName(p.Name) {
+		return i18n.NewError("model.invalid_profile_name", p.Name).WithKind(ErrInvalidProfile)
+	}
+	if p.Temperature < 0 || p.Temperature > 2 {
+		return i18n.NewError("model.profile_temperature", p.Name).WithKind(ErrInvalidProfile)
+	}
+	if p.TopP < 0 || p.TopP > 1 {
+		return i18n.NewError("model.profile_top_p", p.Name).WithKind(ErrInvalidProfile)
+	}
+	if p.MaxTokens < 0 {
+		return i18n.NewError("model.profile_max_tokens", p.Name).WithKind(ErrInvalidProfile)
+	}
+	for _, tool := range p.Tools {
+		switch tool {
+		case TOOL_CODE, TOOL_MEMORY, TOOL_DOCUMENTS:
+		default:
+			return i18n.NewError("model.profile_unknown_tool", p.Name, tool).WithKind(ErrInvalidProfile)
+		}
+	}
+	return nil
+}
+
+// withDefaults заполняет незаданные поля значениями из base
+func (p Profile) withDefaults(base Profile) Profile {
+	if p.SystemPrompt == "" {
+		p.SystemPrompt = base.SystemPrompt
+	}
+	if p.ThinkingPrompt == "" {
+		p.ThinkingPrompt = base.ThinkingPrompt
+	}
+	if p.Temperature == 0 {
+		p.Temperature = base.Temperature
+	}
+	if p.TopP == 0 {
+		p.TopP = base.TopP
+	}
+	if p.MaxTokens == 0 {
+		p.MaxTokens = base.MaxTokens
+	}
+	return p
+}
+
+// generation возвращает параметры генерации, заданные профилем. Нулевые
+// значения профиля не заданы и берутся из параметров модели
+func (p Profile) generation() GenerationOptions {
+	var opts GenerationOptions
+	if p.Temperature != 0 {
+		opts.Temperature = Float(p.Temperature)
+	}
+	if p.TopP != 0 {
+		opts.TopP = Float(p.TopP)
+	}
+	if p.MaxTokens != 0 {
+		opts.MaxTokens = Int(p.MaxTokens)
+	}
+	return opts
+}
+
+// withGeneration заменяет параметры генерации профиля заданными полями opts
+func (p Profile) withGeneration(opts GenerationOptions) Profile {
+	if opts.Temperature != nil {
+		p.Temperature = *opts.Temperature
+	}
+	if opts.TopP != nil {
+		p.TopP = *opts.TopP
+	}
+	if opts.MaxTokens != nil {
+		p.MaxTokens = *opts.MaxTokens
+	}
+	return p
+}
+
+// languageInstruction возвращает указание о языке ответа для системной инструкции
+func (p Profile) languageInstruction() string {
+	if p.Language == "" {
+		return ""
+	}
+
+	name, ok := languageNames[p.Language]
+	if !ok {
+		name = "языке " + p.Language
+	} else {
+		name += " языке"
+	}
+	return "Всегда отвечай на " + name + "."
+}
+
+// sortedProfiles возвращает профили реестра в порядке имен
+func sortedProfiles(profiles map[string]Profile) []Profile {
+	result := make([]Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		result = append(result, profile)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// isValidProfileName проверяет допустимость имени профиля
+func isValidProfileName(name string) bool {
+	if name == "" || len(name) > 32 {
+		return false
+	}
+
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') ||
+			(char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') ||
+			char == '-' || char == '_') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,18 @@
+package model
+
+import "context"
+
+// Passage представляет фрагмент документа, найденный для запроса
+type Passage struct {
+	Source    string  `json:"source"`     // Путь к файлу относительно папки документов
+	StartLine int     `json:"start_line"` // Первая строка фрагмента (с единицы)
+	EndLine   int     `json:"end_line"`   // Последняя строка фрагмента
+	Text      string  `json:"text"`
+	Score     float64 `json:"score"`
+}
+
+// Retriever подбирает фрагменты документов, относящиеся к запросу пользователя.
+// Реализация находится в пакете rag и передается в SmolLM через SetRetriever
+type Retriever interface {
+	Retrieve(ctx context.Context, query string) ([]Passage, error)
+}
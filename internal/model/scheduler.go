@@ -0,0 +1,279 @@
+package model
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Priority - приоритет запроса генерации. Запрос с меньшим значением
+// выполняется раньше
+type Priority int
+
+const (
+	PRIORITY_INTERACTIVE Priority = iota // Ответы пользователям в диалоге
+	PRIORITY_BATCH                       // Пакетная обработка
+	PRIORITY_THINKING                    // Режим размышления
+)
+
+// Префикс ключа заданий, которые изменяют диалог сессии: ходы одной сессии
+// выполняются по очереди, ходы разных сессий - одновременно
+const SESSION_KEY_PREFIX = "session:"
+
+// SessionKey возвращает ключ заданий, изменяющих сессию id
+func SessionKey(id string) string {
+	return SESSION_KEY_PREFIX + id
+}
+
+// Job описывает запрос на выполнение генерации в планировщике
+type Job struct {
+	Priority Priority
+
+	// Пользователь, от имени которого выполняется запрос. Ожидающие запросы
+	// разных пользователей с одинаковым приоритетом обслуживаются по очереди
+	Owner string
+
+	// Запросы с одинаковым непустым ключом не выполняются одновременно
+	Key string
+
+	// Вызывается при постановке запроса в очередь и при изменении его
+	// позиции (с единицы) в горутине, ожидающей в Acquire
+	OnQueued func(position int)
+}
+
+// SchedulerStats содержит состояние планировщика
+type SchedulerStats struct {
+	Parallel int `json:"parallel"` // Число одновременно выполняемых запросов
+	Running  int `json:"running"`
+	Queued   int `json:"queued"`
+}
+
+// Scheduler распределяет слоты генерации между запросами: не больше
+// parallel запросов выполняются одновременно, остальные ждут в очереди по
+// приоритету, а внутри приоритета - по очереди пользователей
+type Scheduler struct {
+	mu       sync.Mutex
+	parallel int
+	maxQueue int // Максимальная длина очереди; 0 - без ограничения
+	running  int
+	keys     map[string]bool   // Ключи выполняемых запросов
+	queue    []*waiter         // Ожидающие запросы в порядке обслуживания
+	served   map[string]uint64 // Номер последнего обслуживания пользователя (см. prune)
+	turn     uint64            // Счетчик обслуживаний
+	seq      uint64            // Счетчик постановок в очередь
+}
+
+// waiter - запрос, ожидающий слота
+type waiter struct {
+	job      Job
+	seq      uint64
+	position int
+	started  bool
+	ready    chan struct{} // Закрывается при выделении слота
+	moved    chan struct{} // Сигнал об изменении позиции
+}
+
+// NewScheduler создает планировщик на parallel одновременных запросов
+// с очередью не длиннее maxQueue (0 - без ограничения)
+func NewScheduler(parallel, maxQueue int) *Scheduler {
+	if parallel < 1 {
+		parallel = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+
+	return &Scheduler{
+		parallel: parallel,
+		maxQueue: maxQueue,
+		keys:     make(map[string]bool),
+		served:   make(map[string]uint64),
+	}
+}
+
+// Acquire ждет слота для запроса job и возвращает функцию его освобождения.
+// Отмена ctx убирает запрос из очереди и возвращает ошибку вида ErrCanceled
+func (s *Scheduler) Acquire(ctx context.Context, job Job) (func(), error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s.mu.Lock()
+	if err := ctx.Err(); err != nil {
+		s.mu.Unlock()
+		return nil, i18n.WrapError(err, "model.request_canceled").WithKind(ErrCanceled)
+	}
+	if s.maxQueue > 0 && len(s.queue) >= s.maxQueue {
+		s.mu.Unlock()
+		return nil, i18n.NewError("model.queue_full", s.maxQueue).WithKind(ErrQueueFull)
+	}
+
+	s.seq++
+	w := &waiter{
+		job:   job,
+		seq:   s.seq,
+		ready: make(chan struct{}),
+		moved: make(chan struct{}, 1),
+	}
+	s.queue = append(s.queue, w)
+	s.dispatch()
+	s.mu.Unlock()
+
+	for {
+		select {
+		case <-w.ready:
+			return s.releaser(w), nil
+
+		case <-w.moved:
+			s.mu.Lock()
+			position, started := w.position, w.started
+			s.mu.Unlock()
+
+			if !started && job.OnQueued != nil {
+				job.OnQueued(position)
+			}
+
+		case <-ctx.Done():
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			// Слот мог быть выделен одновременно с отменой
+			if w.started {
+				s.finish(w)
+			} else {
+				s.remove(w)
+			}
+			s.dispatch()
+			return nil, i18n.WrapError(ctx.Err(), "model.request_canceled").WithKind(ErrCanceled)
+		}
+	}
+}
+
+// Stats возвращает текущее состояние планировщика
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return SchedulerStats{
+		Parallel: s.parallel,
+		Running:  s.running,
+		Queued:   len(s.queue),
+	}
+}
+
+// releaser возвращает функцию освобождения слота запроса w. Повторные
+// вызовы функции ничего не делают
+func (s *Scheduler) releaser(w *waiter) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.finish(w)
+			s.dispatch()
+		})
+	}
+}
+
+// dispatch выделяет свободные слоты ожидающим запросам и сообщает
+// оставшимся их новые позиции. Вызывается с захваченным мьютексом
+func (s *Scheduler) dispatch() {
+	for s.running < s.parallel {
+		s.sortQueue()
+
+		// Запрос, чей ключ занят, пропускает вперед следующие за ним
+		next := -1
+		for i, w := range s.queue {
+			if w.job.Key == "" || !s.keys[w.job.Key] {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		s.start(s.queue[next])
+		s.queue = append(s.queue[:next], s.queue[next+1:]...)
+	}
+
+	s.sortQueue()
+	s.prune()
+	for i, w := range s.queue {
+		if w.position == i+1 {
+			continue
+		}
+		w.position = i + 1
+		select {
+		case w.moved <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// sortQueue упорядочивает очередь: по приоритету, затем первым идет
+// пользователь, который дольше не обслуживался, затем по времени постановки
+func (s *Scheduler) sortQueue() {
+	sort.SliceStable(s.queue, func(i, j int) bool {
+		a, b := s.queue[i], s.queue[j]
+		if a.job.Priority != b.job.Priority {
+			return a.job.Priority < b.job.Priority
+		}
+		if servedA, servedB := s.served[a.job.Owner], s.served[b.job.Owner]; servedA != servedB {
+			return servedA < servedB
+		}
+		return a.seq < b.seq
+	})
+}
+
+// prune удаляет номера обслуживания пользователей, обслуженных раньше всех
+// ожидающих: для порядка очереди такой номер равносилен его отсутствию, а
+// без удаления таблица росла бы с каждым новым пользователем. Вызывается с
+// захваченным мьютексом
+func (s *Scheduler) prune() {
+	var floor uint64 = math.MaxUint64
+	for _, w := range s.queue {
+		floor = min(floor, s.served[w.job.Owner])
+	}
+
+	for owner, served := range s.served {
+		if served < floor {
+			delete(s.served, owner)
+		}
+	}
+}
+
+// start выделяет слот запросу w
+func (s *Scheduler) start(w *waiter) {
+	s.running++
+	if w.job.Key != "" {
+		s.keys[w.job.Key] = true
+	}
+	s.turn++
+	s.served[w.job.Owner] = s.turn
+
+	w.started = true
+	close(w.ready)
+}
+
+// finish освобождает слот запроса w
+func (s *Scheduler) finish(w *waiter) {
+	s.running--
+	if w.job.Key != "" {
+		delete(s.keys, w.job.Key)
+	}
+}
+
+// remove убирает ожидающий запрос w из очереди
+func (s *Scheduler) remove(w *waiter) {
+	for i, queued := range s.queue {
+		if queued == w {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
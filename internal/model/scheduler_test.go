@@ -0,0 +1,225 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitQueued ждет, пока в очереди планировщика окажется n запросов
+func waitQueued(t *testing.T, s *Scheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Stats().Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", s.Stats().Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// acquire занимает слот планировщика или завершает тест
+func acquire(t *testing.T, s *Scheduler, job Job) func() {
+	t.Helper()
+	release, err := s.Acquire(context.Background(), job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return release
+}
+
+func TestSchedulerOrder(t *testing.T) {
+	type queued struct {
+		name string
+		job  Job
+	}
+
+	tests := []struct {
+		name  string
+		queue []queued
+		want  []string
+	}{
+		{
+			name: "priority",
+			queue: []queued{
+				{"thinking", Job{Priority: PRIORITY_THINKING}},
+				{"batch", Job{Priority: PRIORITY_BATCH}},
+				{"interactive", Job{Priority: PRIORITY_INTERACTIVE}},
+			},
+			want: []string{"interactive", "batch", "thinking"},
+		},
+		{
+			name: "owners take turns",
+			queue: []queued{
+				{"a1", Job{Owner: "a"}},
+				{"a2", Job{Owner: "a"}},
+				{"a3", Job{Owner: "a"}},
+				{"b1", Job{Owner: "b"}},
+				{"c1", Job{Owner: "c"}},
+			},
+			want: []string{"a1", "b1", "c1", "a2", "a3"},
+		},
+		{
+			name: "priority before fairness",
+			queue: []queued{
+				{"a1", Job{Owner: "a", Priority: PRIORITY_BATCH}},
+				{"b1", Job{Owner: "b", Priority: PRIORITY_BATCH}},
+				{"a2", Job{Owner: "a"}},
+				{"a3", Job{Owner: "a"}},
+			},
+			want: []string{"a2", "a3", "b1", "a1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScheduler(1, 0)
+			blocker := acquire(t, s, Job{Owner: "blocker"})
+
+			var mu sync.Mutex
+			var order []string
+			var wg sync.WaitGroup
+			for i, q := range tt.queue {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					release := acquire(t, s, q.job)
+					mu.Lock()
+					order = append(order, q.name)
+					mu.Unlock()
+					release()
+				}()
+				waitQueued(t, s, i+1)
+			}
+
+			blocker()
+			wg.Wait()
+
+			if !reflect.DeepEqual(order, tt.want) {
+				t.Errorf("order = %v, want %v", order, tt.want)
+			}
+			if len(s.served) != 0 {
+				t.Errorf("served = %v, want empty after the queue drained", s.served)
+			}
+		})
+	}
+}
+
+func TestSchedulerKey(t *testing.T) {
+	s := NewScheduler(2, 0)
+	first := acquire(t, s, Job{Key: SessionKey("a")})
+
+	started := make(chan struct{})
+	go func() {
+		release := acquire(t, s, Job{Key: SessionKey("a")})
+		close(started)
+		release()
+	}()
+	waitQueued(t, s, 1)
+
+	// Запросы без ключа и другой сессии занимают свободный слот раньше
+	// запроса с занятым ключом
+	other := acquire(t, s, Job{})
+	other()
+	other = acquire(t, s, Job{Key: SessionKey("b")})
+	other()
+
+	select {
+	case <-started:
+		t.Fatal("job with a busy key started")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	first()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not start after its key was released")
+	}
+}
+
+func TestSchedulerCancelWhileQueued(t *testing.T) {
+	s := NewScheduler(1, 0)
+	blocker := acquire(t, s, Job{})
+	defer blocker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		_, err := s.Acquire(ctx, Job{Owner: "a"})
+		result <- err
+	}()
+	waitQueued(t, s, 1)
+
+	cancel()
+	if err := <-result; !errors.Is(err, ErrCanceled) {
+		t.Errorf("Acquire = %v, want ErrCanceled", err)
+	}
+	if stats := s.Stats(); stats.Queued != 0 || stats.Running != 1 {
+		t.Errorf("stats = %+v, want 1 running and empty queue", stats)
+	}
+
+	// Уже отмененный контекст не ставит запрос в очередь
+	if _, err := s.Acquire(ctx, Job{}); !errors.Is(err, ErrCanceled) {
+		t.Errorf("Acquire(canceled) = %v, want ErrCanceled", err)
+	}
+}
+
+func TestSchedulerQueueFull(t *testing.T) {
+	s := NewScheduler(1, 1)
+	blocker := acquire(t, s, Job{})
+
+	done := make(chan struct{})
+	go func() {
+		acquire(t, s, Job{})()
+		close(done)
+	}()
+	waitQueued(t, s, 1)
+
+	if _, err := s.Acquire(context.Background(), Job{}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Acquire = %v, want ErrQueueFull", err)
+	}
+
+	blocker()
+	<-done
+}
+
+func TestSchedulerOnQueued(t *testing.T) {
+	s := NewScheduler(1, 0)
+	blocker := acquire(t, s, Job{})
+
+	positions := make(chan int, 10)
+	done := make(chan struct{})
+	go func() {
+		release := acquire(t, s, Job{Priority: PRIORITY_BATCH, OnQueued: func(position int) { positions <- position }})
+		release()
+		close(done)
+	}()
+	waitQueued(t, s, 1)
+	if got := <-positions; got != 1 {
+		t.Fatalf("first position = %d, want 1", got)
+	}
+
+	// Запрос с более высоким приоритетом сдвигает ожидающий назад
+	go func() {
+		acquire(t, s, Job{Priority: PRIORITY_INTERACTIVE})()
+	}()
+	waitQueued(t, s, 2)
+	if got := <-positions; got != 2 {
+		t.Fatalf("position after interactive job = %d, want 2", got)
+	}
+
+	blocker()
+	<-done
+	select {
+	case got := <-positions:
+		if got != 1 {
+			t.Errorf("position after interactive job started = %d, want 1", got)
+		}
+	default:
+		// Позиция могла не успеть смениться до выделения слота
+	}
+}
@@ -1,36 +1,61 @@
 package model
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"math"
+	"math/rand/v2"
 	"sync"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/modelserver"
 )
 
 const (
 	// Константы для работы с моделью
 	MODEL_PATH    = "/opt/smollm-models/SmolLM2-135M-Instruct"
-	MAX_TOKENS    = 2048
+	MAX_TOKENS    = 2048 // Размер контекста, если метаданные модели недоступны
 	TEMPERATURE   = 0.7
 	TOP_P         = 0.9
 	THINKING_SEED = 42 // Seed для режима размышления
+
+	GENERATION_TIMEOUT = 2 * time.Minute // Время генерации ответа по умолчанию
+
+	MAX_HISTORY_MESSAGES = 10 // Сообщений диалога в промпте модели
+
+	// Префиксы реплик в промпте модели
+	USER_PREFIX      = "Человек:"
+	ASSISTANT_PREFIX = "Ассистент:"
+
+	// Системная инструкция по умолчанию для новых сессий
+	DEFAULT_SYSTEM_PROMPT = "Ты SmolLM2, маленькая, но умная языковая модель. Ты можешь писать код, объяснять понятия и размышлять на разные темы."
 )
 
 // SmolLM представляет интерфейс для работы с моделью SmolLM2
 type SmolLM struct {
-	logger      *logging.Logger
-	modelPath   string
-	contextSize int
-	temperature float64
-	topP        float64
-	history     []ContextEntry
-	mutex       sync.Mutex
-	inferencer  *Inferencer // Интерфейс для инференса модели
-	context     *Context    // Управление контекстом
+	logger       *logging.Logger
+	modelPath    string
+	contextSize  int
+	history      []ContextEntry
+	mutex        sync.Mutex
+	inferencer   *Inferencer        // Интерфейс для инференса модели
+	context      *Context           // Управление контекстом
+	store        SessionStore       // Хранилище сессий
+	retriever    Retriever          // Поиск по локальным документам; nil - без RAG
+	memory       MemoryStore        // Долговременная память пользователя; nil - без памяти
+	cache        ResponseCache      // Кэш ответов на запросы с заданным seed; nil - без кэша
+	profile      Profile            // Профиль текущей сессии
+	profiles     map[string]Profile // Доступные профили по именам
+	generation   GenerationOptions  // Параметры генерации из настроек модели
+	thinkingSeed int                // Seed режима размышления по умолчанию
+	timeout      time.Duration      // Максимальное время генерации ответа
+	scheduler    *Scheduler         // Очередь запросов генерации
+
+	sessionMutex sync.Mutex
+	sessionLocks map[string]*sessionLock // Блокировки сохраненных сессий (LockSession)
 }
 
 // ContextEntry представляет одну запись в истории контекста
@@ -40,36 +65,163 @@ type ContextEntry struct {
 	Time    time.Time
 }
 
-// NewSmolLM создает новый экземпляр SmolLM
+// ProcessOptions содержит параметры обработки отдельного запроса
+type ProcessOptions struct {
+	SystemPrompt string // Переопределение системной инструкции
+
+	// Профиль запроса Complete; пусто - профиль по умолчанию. Ходы диалога
+	// используют профиль текущей сессии
+	Profile string
+
+	// Параметры генерации этого запроса поверх параметров сессии
+	Generation GenerationOptions
+
+	// Стоп-строки в дополнение к стоп-строкам модели, профиля и сессии.
+	// Generation.Stop, наоборот, заменяет их
+	Stop []string
+
+	// Память пользователя для этого запроса вместо заданной через SetMemory.
+	// Нужна клиентам, которые обслуживают нескольких пользователей
+	Memory MemoryStore
+
+	// Отмена запроса клиентом, в том числе пока он ждет в очереди; nil - без отмены
+	Context context.Context
+
+	Priority Priority           // Приоритет в очереди; по умолчанию PRIORITY_INTERACTIVE
+	Owner    string             // Пользователь для очередности в очереди
+	OnQueued func(position int) // Сообщает позицию запроса в очереди
+
+	// Получает ответ модели по частям по мере генерации, до обработки тегов
+	// <remember>; nil - без потоковой выдачи
+	OnText func(delta string)
+}
+
+// ProcessResult содержит ответ модели и статистику генерации
+type ProcessResult struct {
+	Text         string
+	PromptTokens int
+	TokensUsed   int
+	Latency      time.Duration
+	Citations    []Passage    // Фрагменты документов, добавленные в контекст
+	Remembered   []MemoryFact // Факты, которые модель сохранила в память
+	Cached       bool         // Ответ взят из кэша ответов
+	MessageID    string       // Сообщение ответа в сохраненной сессии (CompleteSession)
+
+	// Параметры, с которыми выполнена генерация, включая выбранный seed:
+	// с ними запрос можно повторить
+	Generation GenerationOptions
+}
+
+// Options содержит настройки модели из конфигурации
+type Options struct {
+	Path       string            // Директория модели; пусто - MODEL_PATH
+	Backend    string            // Способ генерации (BACKEND_*); пусто - BACKEND_AUTO
+	Generation GenerationOptions // Параметры генерации поверх DefaultGenerationOptions
+
+	ThinkingSeed int // Seed режима размышления; 0 - THINKING_SEED
+
+	Timeout time.Duration // Максимальное время генерации ответа; 0 - GENERATION_TIMEOUT
+
+	Parallel  int // Одновременных запросов генерации; 0 - сколько выполняет способ генерации
+	QueueSize int // Максимальная длина очереди запросов; 0 - без ограничения
+
+	// Сервер модели в режиме BACKEND_API; ModelPath заменяется на Path
+	Server modelserver.Config
+}
+
+// NewSmolLM создает новый экземпляр SmolLM с моделью из MODEL_PATH
 func NewSmolLM() *SmolLM {
+	return NewSmolLMWithOptions(Options{})
+}
+
+// NewSmolLMWithOptions создает новый экземпляр SmolLM с заданными настройками модели
+func NewSmolLMWithOptions(opts Options) *SmolLM {
+	if opts.Path == "" {
+		opts.Path = MODEL_PATH
+	}
+	if opts.Backend == "" {
+		opts.Backend = BACKEND_AUTO
+	}
+	if opts.ThinkingSeed == 0 {
+		opts.ThinkingSeed = THINKING_SEED
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = GENERATION_TIMEOUT
+	}
+
 	logger := logging.NewLogger()
 	logger.Info("Initializing SmolLM2 model")
 
+	generation := DefaultGenerationOptions().Merge(opts.Generation)
+	if err := generation.Validate(); err != nil {
+		logger.Warn("Invalid generation parameters, using defaults: %v", err)
+		generation = DefaultGenerationOptions()
+	}
+	profile := DefaultProfile().withGeneration(generation)
+
 	// Создаем контекст
 	ctx := NewContext()
-	ctx.AddSystemMessage("Ты SmolLM2, маленькая, но умная языковая модель. Ты можешь писать код, объяснять понятия и размышлять на разные темы.")
+	ctx.AddSystemMessage(profile.SystemPrompt)
+	ctx.SetProperty(META_PROFILE, profile.Name)
 
 	// Создаем объект для инференса
-	inferencer := NewInferencer(MODEL_PATH)
+	opts.Server.ModelPath = opts.Path
+	inferencer := NewInferencerWithServer(opts.Backend, opts.Server)
+
+	// Размер контекста берется из метаданных модели, если они доступны
+	contextSize := MAX_TOKENS
+	if length := inferencer.ContextLength(); length > 0 {
+		contextSize = length
+	}
+
+	parallel := opts.Parallel
+	if parallel <= 0 {
+		parallel = inferencer.Capacity()
+	}
+	logger.Info("Inference queue: %d parallel requests", parallel)
 
 	return &SmolLM{
-		logger:      logger,
-		modelPath:   MODEL_PATH,
-		contextSize: MAX_TOKENS,
-		temperature: TEMPERATURE,
-		topP:        TOP_P,
-		history:     make([]ContextEntry, 0),
-		inferencer:  inferencer,
-		context:     ctx,
+		logger:       logger,
+		modelPath:    opts.Path,
+		contextSize:  contextSize,
+		history:      make([]ContextEntry, 0),
+		inferencer:   inferencer,
+		context:      ctx,
+		profile:      profile,
+		profiles:     map[string]Profile{profile.Name: profile},
+		generation:   generation,
+		thinkingSeed: opts.ThinkingSeed,
+		timeout:      opts.Timeout,
+		scheduler:    NewScheduler(parallel, opts.QueueSize),
+		sessionLocks: make(map[string]*sessionLock),
 	}
 }
 
 // Process обрабатывает ввод пользователя и возвращает ответ модели
 func (s *SmolLM) Process(input string) string {
-	s.mutex.Lock()
+	result, err := s.ProcessWithOptions(input, ProcessOptions{})
+	if err != nil {
+		return i18n.T("model.error_apology")
+	}
+
+	return result.Text
+}
+
+// ProcessWithOptions обрабатывает ввод пользователя с параметрами запроса
+// и возвращает ответ модели вместе со статистикой генерации. При ошибке
+// модели вместе с ошибкой возвращается текст извинения, добавленный в историю;
+// отмененный запрос возвращает только ошибку и не меняет историю.
+// Запрос ждет своей очереди; пока он в очереди, ввод не попадает в историю
+func (s *SmolLM) ProcessWithOptions(input string, opts ProcessOptions) (*ProcessResult, error) {
+	release, err := s.schedule(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 	defer s.mutex.Unlock()
 
 	// Добавляем ввод пользователя в историю и контекст
+	t := s.beginTurn()
 	s.history = append(s.history, ContextEntry{
 		Role:    "user",
 		Content: input,
@@ -77,98 +229,417 @@ func (s *SmolLM) Process(input string) string {
 	})
 	s.context.AddUserMessage(input)
 
-	// Подготовка контекста для модели
-	contextStr := s.prepareContext()
+	return s.generate(opts, t)
+}
 
-	// Вызываем модель с контекстом
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-	defer cancel()
+// Retry заново генерирует последний ответ ассистента. Предыдущий ответ
+// сохраняется в соседней ветке, к нему можно вернуться через SwitchBranch
+func (s *SmolLM) Retry(opts ProcessOptions) (*ProcessResult, error) {
+	release, err := s.schedule(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	defer s.mutex.Unlock()
+
+	t := s.beginTurn()
+	if _, err := s.context.PrepareRetry(); err != nil {
+		return nil, err
+	}
+	s.rebuildHistory()
+
+	return s.generate(opts, t)
+}
 
-	response, err := s.inferencer.Generate(ctx, contextStr, MAX_TOKENS/2, s.temperature, s.topP)
+// Edit заменяет сообщение пользователя с номером index (с единицы, без учета
+// системных сообщений) в новой ветке и генерирует на него новый ответ
+func (s *SmolLM) Edit(index int, content string, opts ProcessOptions) (*ProcessResult, error) {
+	release, err := s.schedule(opts)
 	if err != nil {
-		s.logger.Error("Inference error: %v", err)
-		response = "Извините, произошла ошибка при обработке запроса. Пожалуйста, попробуйте еще раз."
+		return nil, err
 	}
+	defer release()
+	defer s.mutex.Unlock()
 
-	// Добавляем ответ в историю и контекст
-	s.history = append(s.history, ContextEntry{
-		Role:    "assistant",
-		Content: response,
-		Time:    time.Now(),
-	})
-	s.context.AddAssistantMessage(response)
+	branch := s.visibleMessages()
+	if index >= 1 && index <= len(branch) && branch[index-1].Role != "user" {
+		return nil, i18n.NewError("model.not_user_message", index)
+	}
 
-	// Обрезаем историю, если она слишком длинная
-	s.truncateHistory()
+	t := s.beginTurn()
+	if _, err := s.context.EditMessage(index, content); err != nil {
+		return nil, err
+	}
+	s.rebuildHistory()
 
-	return response
+	return s.generate(opts, t)
 }
 
-// Think запускает режим размышления без входных данных пользователя
-func (s *SmolLM) Think(seconds int, outputFile string) {
+// RecordExecution сохраняет результат выполнения кода в последнем сообщении
+// активной ветки, чтобы он попал в экспорт диалога
+func (s *SmolLM) RecordExecution(record ExecutionRecord) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.logger.Info("Starting thinking mode for %d seconds", seconds)
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	s.context.AttachExecution(record)
+}
 
-	// Создаем файл для записи размышлений
-	file, err := os.Create(outputFile)
-	if err != nil {
-		s.logger.Error("Failed to create output file: %v", err)
-		return
+// Branches возвращает ветки диалога текущей сессии
+func (s *SmolLM) Branches() []Branch {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.context.Branches()
+}
+
+// SwitchBranch делает активной ветку с номером index (с единицы) из списка Branches
+func (s *SmolLM) SwitchBranch(index int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	branches := s.context.Branches()
+	if index < 1 || index > len(branches) {
+		return i18n.NewError("model.branch_out_of_range", len(branches)).WithKind(ErrOutOfRange)
 	}
-	defer file.Close()
 
-	writer := bufio.NewWriter(file)
-	defer writer.Flush()
+	if err := s.context.SwitchBranch(branches[index-1].LeafID); err != nil {
+		return err
+	}
+	s.rebuildHistory()
+	return nil
+}
+
+// Messages возвращает сообщения активной ветки без системных, в том порядке
+// и с той нумерацией, которые используют Edit и команды клиентов
+func (s *SmolLM) Messages() []Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.visibleMessages()
+}
 
-	// Запись заголовка
-	timestamp := time.Now().Format(time.RFC3339)
-	writer.WriteString(fmt.Sprintf("# SmolLM2 Thoughts - %s\n\n", timestamp))
+// schedule ставит ход диалога текущей сессии в очередь генерации и ждет
+// слота. Ходы одной сессии выполняются по очереди, так как изменяют ее
+// контекст, ходы разных сессий - одновременно. Возвращается с захваченным
+// мьютексом; если, пока запрос ждал, текущая сессия сменилась, запрос
+// встает в очередь заново с ключом новой сессии
+func (s *SmolLM) schedule(opts ProcessOptions) (func(), error) {
+	for {
+		s.mutex.Lock()
+		id := s.context.SessionID
+		s.mutex.Unlock()
+
+		release, err := s.scheduler.Acquire(opts.context(), Job{
+			Priority: opts.Priority,
+			Owner:    opts.Owner,
+			Key:      SessionKey(id),
+			OnQueued: opts.OnQueued,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		s.mutex.Lock()
+		if s.context.SessionID == id {
+			return release, nil
+		}
+		s.mutex.Unlock()
+		release()
+	}
+}
+
+// timeoutError заменяет ошибку генерации, прерванной по истечении времени
+// ctx, ошибкой вида ErrGenerationTimeout
+func (s *SmolLM) timeoutError(ctx context.Context, err error) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return i18n.WrapError(err, "model.generation_timeout").WithKind(ErrGenerationTimeout)
+}
+
+// context возвращает контекст отмены запроса
+func (opts ProcessOptions) context() context.Context {
+	if opts.Context != nil {
+		return opts.Context
+	}
+	return context.Background()
+}
+
+// QueueStats возвращает состояние очереди запросов генерации
+func (s *SmolLM) QueueStats() SchedulerStats {
+	return s.scheduler.Stats()
+}
+
+// turn - состояние сессии перед ходом диалога, к которому он откатывается
+// при отмене запроса. Сообщения контекста только добавляются, поэтому
+// достаточно их числа и активного листа
+type turn struct {
+	session  *Context
+	messages int
+	leaf     string
+}
+
+// beginTurn запоминает состояние текущей сессии. Вызывается с захваченным мьютексом
+func (s *SmolLM) beginTurn() turn {
+	return turn{session: s.context, messages: len(s.context.Messages), leaf: s.context.ActiveLeaf}
+}
+
+// rollback отменяет изменения сессии, сделанные ходом t. Вызывается с
+// захваченным мьютексом
+func (s *SmolLM) rollback(t turn) {
+	t.session.Messages = t.session.Messages[:t.messages]
+	t.session.ActiveLeaf = t.leaf
+	if s.context == t.session {
+		s.rebuildHistory()
+	}
+}
 
-	// Создаем контекст с таймаутом
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(seconds)*time.Second)
+// generate вызывает модель для активной ветки и добавляет ответ в контекст.
+// Вызывается с захваченным мьютексом и слотом генерации. Промпт собирается
+// под мьютексом, на время генерации мьютекс освобождается, а ответ
+// добавляется к тому сообщению и той сессии, для которых он генерировался
+func (s *SmolLM) generate(opts ProcessOptions, t turn) (*ProcessResult, error) {
+	ctx, cancel := context.WithTimeout(opts.context(), s.timeout)
 	defer cancel()
 
-	// Начальный затравочный текст для размышлений
-	prompt := "Размышляю самостоятельно без участия пользователя. Могу думать о программировании, философии, науке и других интересных темах. Начну со свободного потока мыслей."
+	// Подбираем фрагменты документов к последнему сообщению пользователя
+	var passages []Passage
+	if query, ok := s.context.GetLastUserMessage(); ok && s.profile.Allows(TOOL_DOCUMENTS) {
+		passages = s.retrievePassages(ctx, query.Content)
+	}
 
-	writer.WriteString("## Начало размышлений\n\n")
+	memory := s.memory
+	if opts.Memory != nil {
+		memory = opts.Memory
+	}
+	if !s.profile.Allows(TOOL_MEMORY) {
+		memory = nil
+	}
+	facts := s.memoryFacts(memory)
 
-	// Запрашиваем первую мысль
-	thought, err := s.inferencer.ThinkingGenerate(ctx, prompt, 200)
+	// Подготовка контекста для модели
+	contextStr := s.prepareContext(opts.SystemPrompt, memory != nil, facts, passages)
+
+	// Случайный seed выбирается здесь, чтобы его можно было вернуть в результате.
+	// Ответ на запрос с заданным seed повторяем и может быть взят из кэша
+	generation := s.sessionGeneration().Merge(opts.Generation).withStop(opts.Stop)
+	deterministic := ValueOf(generation.Seed) != 0
+	if !deterministic {
+		generation.Seed = Int(rand.IntN(math.MaxInt32) + 1)
+	}
+
+	// Вызываем модель с контекстом
+	request := generation.request(contextStr)
+	request.Session = s.context.SessionID
+	request.OnText = opts.OnText
+	session, parent, cache := s.context, s.context.ActiveLeaf, s.cache
+
+	s.mutex.Unlock()
+	start := time.Now()
+	inference, cached, err := s.infer(ctx, cache, request, deterministic)
+	latency := time.Since(start)
+
+	var response string
+	var remembered []MemoryFact
+	if err == nil {
+		response, remembered = s.applyRememberTags(memory, inference.Text)
+	}
+	s.mutex.Lock()
+
+	// Отмененный запрос не оставляет следов в истории. Истекшее время
+	// генерации - ошибка модели: пользователь получает извинение
+	if err != nil {
+		err = s.timeoutError(ctx, err)
+	}
+	if err != nil && !errors.Is(err, ErrGenerationTimeout) && (errors.Is(err, ErrCanceled) || ctx.Err() != nil) {
+		s.logger.Info("Generation canceled: %v", err)
+		s.rollback(t)
+		return nil, err
+	}
 	if err != nil {
-		s.logger.Error("Error in thinking mode: %v", err)
-		writer.WriteString("Ошибка в режиме размышления: " + err.Error())
-		return
+		s.logger.Error("Inference error: %v", err)
+		response = i18n.T("model.error_apology")
 	}
 
-	writer.WriteString(thought + "\n\n")
+	// Добавляем ответ в контекст и историю
+	session.addChild(parent, "assistant", response)
+	if s.context == session {
+		s.rebuildHistory()
+	}
 
-	// Цикл размышлений
-	for {
-		select {
-		case <-ctx.Done():
-			// Время вышло
-			writer.WriteString("\n## Конец размышлений\n")
-			s.logger.Info("Thinking mode completed, output saved to %s", outputFile)
-			return
-		default:
-			// Продолжаем размышления, используя предыдущую мысль как контекст
-			nextThought, err := s.inferencer.ThinkingGenerate(ctx, "Продолжаю размышлять о "+thought, 200)
-			if err != nil {
-				s.logger.Error("Error in thinking mode: %v", err)
-				writer.WriteString("\nОшибка в режиме размышления: " + err.Error() + "\n")
-				continue
-			}
+	// При ошибке модели возвращаем и ошибку, и добавленный в контекст ответ
+	if err != nil {
+		return &ProcessResult{Text: response, Latency: latency, Generation: generation}, err
+	}
+
+	return &ProcessResult{
+		Text:         response,
+		PromptTokens: inference.PromptTokens,
+		TokensUsed:   inference.TokensUsed,
+		Latency:      latency,
+		Citations:    passages,
+		Remembered:   remembered,
+		Cached:       cached,
+		Generation:   generation,
+	}, nil
+}
+
+// SetSessionStore задает хранилище, в котором сохраняются сессии
+func (s *SmolLM) SetSessionStore(store SessionStore) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.store = store
+}
+
+// SetRetriever подключает поиск по локальным документам (RAG)
+func (s *SmolLM) SetRetriever(retriever Retriever) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.retriever = retriever
+}
+
+// SetMemory подключает долговременную память пользователя. Факты из нее
+// добавляются в системную инструкцию каждого запроса
+func (s *SmolLM) SetMemory(memory MemoryStore) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-			writer.WriteString(nextThought + "\n\n")
-			thought = nextThought
+	s.memory = memory
+}
+
+// SetProfiles задает доступные профили. Незаданные поля профилей берутся из
+// профиля по умолчанию, который можно переопределить профилем с именем default
+func (s *SmolLM) SetProfiles(profiles []Profile) error {
+	registry := make(map[string]Profile)
+
+	base := DefaultProfile().withGeneration(s.generation)
+	for _, profile := range profiles {
+		if profile.Name == DEFAULT_PROFILE {
+			base = profile.withDefaults(base)
+		}
+	}
+	registry[DEFAULT_PROFILE] = base
 
-			// Небольшая пауза между размышлениями
-			time.Sleep(2 * time.Second)
+	for _, profile := range profiles {
+		if err := profile.Validate(); err != nil {
+			return err
 		}
+		if profile.Name != DEFAULT_PROFILE {
+			registry[profile.Name] = profile.withDefaults(base)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.profiles = registry
+	if profile, ok := registry[s.profile.Name]; ok {
+		s.applyProfile(profile)
 	}
+	return nil
+}
+
+// Profiles возвращает доступные профили в порядке имен
+func (s *SmolLM) Profiles() []Profile {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return sortedProfiles(s.profiles)
+}
+
+// Profile возвращает профиль текущей сессии
+func (s *SmolLM) Profile() Profile {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.profile
+}
+
+// UseProfile переключает текущую сессию на профиль name. Системная
+// инструкция сессии заменяется инструкцией профиля, имя профиля
+// сохраняется в метаданных сессии
+func (s *SmolLM) UseProfile(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	profile, ok := s.profiles[name]
+	if !ok {
+		return i18n.NewError("model.profile_not_found", name).WithKind(ErrProfileNotFound)
+	}
+
+	s.applyProfile(profile)
+	s.logger.Info("Switched to profile %s", name)
+	return nil
+}
+
+// NewSessionContext создает контекст новой сессии с профилем name (пусто -
+// профиль текущей сессии), не переключая текущую сессию
+func (s *SmolLM) NewSessionContext(name string) (*Context, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	profile := s.profile
+	if name != "" {
+		var ok bool
+		if profile, ok = s.profiles[name]; !ok {
+			return nil, i18n.NewError("model.profile_not_found", name).WithKind(ErrProfileNotFound)
+		}
+	}
+
+	ctx := NewContext()
+	ctx.AddSystemMessage(profile.SystemPrompt)
+	ctx.SetProperty(META_PROFILE, profile.Name)
+	return ctx, nil
+}
+
+// GenerationOptions возвращает параметры генерации текущей сессии: настройки
+// модели, профиль и переопределения сессии
+func (s *SmolLM) GenerationOptions() GenerationOptions {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.sessionGeneration()
+}
+
+// SetGenerationOption переопределяет параметр генерации name для текущей
+// сессии. Переопределения сохраняются вместе с сессией
+func (s *SmolLM) SetGenerationOption(name string, values []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	overrides := s.context.Generation()
+	if err := overrides.Set(name, values); err != nil {
+		return err
+	}
+	s.context.SetGeneration(overrides)
+	return nil
+}
+
+// ResetGenerationOptions снимает переопределения параметров генерации сессии
+func (s *SmolLM) ResetGenerationOptions() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.context.SetGeneration(GenerationOptions{})
+}
+
+// sessionGeneration возвращает параметры генерации сессии. Вызывается с
+// захваченным мьютексом
+func (s *SmolLM) sessionGeneration() GenerationOptions {
+	return s.generation.Merge(s.profile.generation()).Merge(s.context.Generation())
+}
+
+// applyProfile делает профиль текущим для сессии. Вызывается с захваченным мьютексом
+func (s *SmolLM) applyProfile(profile Profile) {
+	s.profile = profile
+	s.context.SetSystemMessage(profile.SystemPrompt)
+	s.context.SetProperty(META_PROFILE, profile.Name)
+	s.rebuildHistory()
 }
 
 // SaveSession сохраняет текущую сессию (историю контекста)
@@ -176,8 +647,12 @@ func (s *SmolLM) SaveSession(sessionName string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if s.store == nil {
+		return i18n.NewError("model.store_not_set").WithKind(ErrStoreNotSet)
+	}
+
 	// Сохраняем контекст
-	return s.context.Save(sessionName)
+	return s.store.SaveSession(sessionName, s.context)
 }
 
 // LoadSession загружает сессию
@@ -185,52 +660,165 @@ func (s *SmolLM) LoadSession(sessionName string) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if s.store == nil {
+		return i18n.NewError("model.store_not_set").WithKind(ErrStoreNotSet)
+	}
+
 	// Загружаем контекст
-	newContext, err := LoadContext(sessionName)
+	newContext, err := s.store.LoadSession(sessionName)
 	if err != nil {
 		return err
 	}
 
-	s.context = newContext
+	s.setContext(newContext)
+
+	s.logger.Info("Session loaded: %s", sessionName)
+	return nil
+}
+
+// GetContext возвращает текущий контекст сессии
+func (s *SmolLM) GetContext() *Context {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.context
+}
+
+// SetContext заменяет текущий контекст сессии, например загруженный из хранилища
+func (s *SmolLM) SetContext(ctx *Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.setContext(ctx)
+}
+
+// setContext заменяет контекст, восстанавливает профиль сессии и
+// перестраивает внутреннюю историю. Вызывается с захваченным мьютексом
+func (s *SmolLM) setContext(ctx *Context) {
+	s.context = ctx
+
+	// Сессии без профиля созданы до появления профилей и используют профиль по умолчанию
+	name, ok := ctx.GetProperty(META_PROFILE)
+	if !ok || name == "" {
+		name = DEFAULT_PROFILE
+	}
+	if profile, ok := s.profiles[name]; ok {
+		s.profile = profile
+	} else {
+		s.logger.Warn("Session profile %s is not defined, keeping %s", name, s.profile.Name)
+	}
+
+	s.rebuildHistory()
+}
 
-	// Обновляем внутреннюю историю
+// rebuildHistory заполняет внутреннюю историю сообщениями активной ветки.
+// Вызывается с захваченным мьютексом
+func (s *SmolLM) rebuildHistory() {
 	s.history = []ContextEntry{}
-	for _, msg := range s.context.Messages {
+	for _, msg := range s.context.ActiveBranch() {
 		s.history = append(s.history, ContextEntry{
 			Role:    msg.Role,
 			Content: msg.Content,
 			Time:    msg.Timestamp,
 		})
 	}
+	s.truncateHistory()
+}
 
-	s.logger.Info("Session loaded: %s", sessionName)
-	return nil
+// ResetSession начинает новую сессию с системной инструкцией текущего профиля
+func (s *SmolLM) ResetSession() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.context = NewContext()
+	s.context.AddSystemMessage(s.profile.SystemPrompt)
+	s.context.SetProperty(META_PROFILE, s.profile.Name)
+	s.history = []ContextEntry{}
+}
+
+// ContextSize возвращает размер контекста модели в токенах
+func (s *SmolLM) ContextSize() int {
+	return s.contextSize
+}
+
+// Backend возвращает способ генерации (BACKEND_NATIVE, BACKEND_API или BACKEND_WORKER)
+func (s *SmolLM) Backend() string {
+	return s.inferencer.Backend()
+}
+
+// ServerState возвращает состояние сервера модели, рабочего процесса или встроенного движка
+func (s *SmolLM) ServerState() modelserver.State {
+	return s.inferencer.ServerState()
 }
 
 // Close освобождает ресурсы
 func (s *SmolLM) Close() {
+	metrics := s.logger.GetMetrics()
+	cacheHits, cacheMisses := metrics.GetCacheStats(CACHE_RESPONSE)
+	prefixHits, prefixMisses := metrics.GetCacheStats(CACHE_KV_PREFIX)
+	s.logger.Info("Response cache: %d hits, %d misses; KV prefix cache: %d hits, %d misses",
+		cacheHits, cacheMisses, prefixHits, prefixMisses)
+
 	s.inferencer.Close()
 }
 
 // Вспомогательные методы
 
-// prepareContext готовит контекст для модели на основе истории
-func (s *SmolLM) prepareContext() string {
+// prepareContext готовит контекст для модели на основе истории.
+// Непустой systemOverride заменяет системное сообщение сессии. При
+// подключенной памяти к системной инструкции добавляются известные факты
+// о пользователе, найденные фрагменты документов добавляются перед
+// диалогом с номерами для ссылок
+func (s *SmolLM) prepareContext(systemOverride string, withMemory bool, facts []MemoryFact, passages []Passage) string {
+	systemMsg, found := s.getSystemMessage()
+	if !found {
+		systemMsg = s.profile.SystemPrompt
+	}
+	if systemOverride != "" {
+		systemMsg = systemOverride
+	}
+
+	return s.renderPrompt(s.profile, systemMsg, s.history, withMemory, facts, passages)
+}
+
+// renderPrompt собирает промпт модели из системной инструкции, памяти,
+// справочных материалов и истории диалога. Язык ответов задает profile
+func (s *SmolLM) renderPrompt(profile Profile, systemMsg string, history []ContextEntry, withMemory bool, facts []MemoryFact, passages []Passage) string {
 	var contextStr string
 
 	// Добавляем системное сообщение
-	systemMsg, found := s.getSystemMessage()
-	if found {
-		contextStr += "Системная инструкция: " + systemMsg + "\n\n"
+	if instruction := profile.languageInstruction(); instruction != "" {
+		systemMsg += " " + instruction
+	}
+	contextStr += "Системная инструкция: " + systemMsg + "\n\n"
+
+	// Добавляем долговременную память
+	if withMemory {
+		if len(facts) > 0 {
+			contextStr += "Что известно о пользователе:\n"
+			for _, fact := range facts {
+				contextStr += "- " + fact.Text + "\n"
+			}
+		}
+		contextStr += "Чтобы запомнить новый важный факт о пользователе, напиши его в теге <remember>факт</remember>.\n\n"
+	}
+
+	// Добавляем справочные материалы
+	if len(passages) > 0 {
+		contextStr += "Справочные материалы (ссылайся на них как [1], [2] и т.д.):\n"
+		for i, passage := range passages {
+			contextStr += fmt.Sprintf("[%d] %s:%d-%d\n%s\n", i+1, passage.Source, passage.StartLine, passage.EndLine, passage.Text)
+		}
+		contextStr += "\n"
 	}
 
 	// Добавляем историю диалога
-	for _, entry := range s.history {
+	for _, entry := range history {
 		prefix := ""
 		if entry.Role == "user" {
-			prefix = "Человек: "
+			prefix = USER_PREFIX + " "
 		} else if entry.Role == "assistant" {
-			prefix = "Ассистент: "
+			prefix = ASSISTANT_PREFIX + " "
 		} else {
 			continue // Пропускаем системные сообщения
 		}
@@ -238,14 +826,72 @@ func (s *SmolLM) prepareContext() string {
 	}
 
 	// Добавляем префикс для ответа
-	contextStr += "Ассистент: "
+	contextStr += ASSISTANT_PREFIX + " "
 
 	return contextStr
 }
 
-// getSystemMessage возвращает системное сообщение из контекста
+// retrievePassages ищет фрагменты документов для сообщения пользователя
+// query. Ошибки поиска не прерывают генерацию
+func (s *SmolLM) retrievePassages(ctx context.Context, query string) []Passage {
+	if s.retriever == nil {
+		return nil
+	}
+
+	passages, err := s.retriever.Retrieve(ctx, query)
+	if err != nil {
+		s.logger.Warn("Document retrieval failed: %v", err)
+		return nil
+	}
+
+	return passages
+}
+
+// memoryFacts загружает факты из памяти. Ошибки чтения не прерывают генерацию
+func (s *SmolLM) memoryFacts(memory MemoryStore) []MemoryFact {
+	if memory == nil {
+		return nil
+	}
+
+	facts, err := memory.Facts()
+	if err != nil {
+		s.logger.Warn("Failed to load user memory: %v", err)
+		return nil
+	}
+
+	return facts
+}
+
+// applyRememberTags сохраняет в память факты из тегов <remember> ответа
+// и возвращает ответ без этих тегов
+func (s *SmolLM) applyRememberTags(memory MemoryStore, text string) (string, []MemoryFact) {
+	if memory == nil {
+		return text, nil
+	}
+
+	cleaned, texts := extractRememberTags(text)
+
+	var remembered []MemoryFact
+	for _, factText := range texts {
+		fact, err := memory.Remember(factText, MEMORY_SOURCE_MODEL)
+		if err != nil {
+			s.logger.Warn("Failed to remember fact: %v", err)
+			continue
+		}
+		remembered = append(remembered, fact)
+	}
+
+	return cleaned, remembered
+}
+
+// visibleMessages возвращает сообщения активной ветки без системных
+func (s *SmolLM) visibleMessages() []Message {
+	return s.context.VisibleMessages()
+}
+
+// getSystemMessage возвращает системное сообщение активной ветки
 func (s *SmolLM) getSystemMessage() (string, bool) {
-	for _, msg := range s.context.Messages {
+	for _, msg := range s.context.ActiveBranch() {
 		if msg.Role == "system" {
 			return msg.Content, true
 		}
@@ -255,11 +901,8 @@ func (s *SmolLM) getSystemMessage() (string, bool) {
 
 // truncateHistory обрезает историю, чтобы она не превышала максимальный размер
 func (s *SmolLM) truncateHistory() {
-	// Максимальное количество сообщений в истории
-	maxMessages := 10
-
-	// Оставляем только последние maxMessages сообщений
-	if len(s.history) > maxMessages {
-		s.history = s.history[len(s.history)-maxMessages:]
+	// Оставляем только последние MAX_HISTORY_MESSAGES сообщений
+	if len(s.history) > MAX_HISTORY_MESSAGES {
+		s.history = s.history[len(s.history)-MAX_HISTORY_MESSAGES:]
 	}
 }
@@ -0,0 +1,190 @@
+package model_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/model/modeltest"
+	"smollm-sandbox/internal/storage"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	os.Exit(m.Run())
+}
+
+// newModel создает SmolLM с сервером модели, ответ которого задает reply
+func newModel(t *testing.T, timeout time.Duration, reply modeltest.Reply) (*model.SmolLM, *modeltest.Server) {
+	server := modeltest.NewServer(t)
+	server.SetReply(reply)
+
+	opts := server.Options(t)
+	opts.Timeout = timeout
+	opts.Parallel = 2
+	m := model.NewSmolLMWithOptions(opts)
+	t.Cleanup(m.Close)
+	return m, server
+}
+
+// slowReply отвечает через delay
+func slowReply(delay time.Duration) modeltest.Reply {
+	return func(model.InferenceRequest) (*model.InferenceResponse, int) {
+		time.Sleep(delay)
+		return &model.InferenceResponse{Text: modeltest.REPLY}, http.StatusOK
+	}
+}
+
+func TestProcessTimeoutKeepsTurn(t *testing.T) {
+	m, _ := newModel(t, 50*time.Millisecond, slowReply(500*time.Millisecond))
+
+	result, err := m.ProcessWithOptions("hi", model.ProcessOptions{})
+	if !errors.Is(err, model.ErrGenerationTimeout) {
+		t.Fatalf("err = %v, want ErrGenerationTimeout", err)
+	}
+	if result == nil || result.Text != i18n.T("model.error_apology") {
+		t.Fatalf("result = %+v, want the apology", result)
+	}
+
+	messages := m.Messages()
+	if len(messages) != 2 || messages[0].Content != "hi" || messages[1].Role != "assistant" {
+		t.Errorf("messages = %+v, want the turn with the apology", messages)
+	}
+}
+
+func TestProcessCancelRollsBack(t *testing.T) {
+	m, _ := newModel(t, time.Minute, slowReply(500*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	if _, err := m.ProcessWithOptions("hi", model.ProcessOptions{Context: ctx}); err == nil {
+		t.Fatal("canceled request returned no error")
+	}
+	if messages := m.Messages(); len(messages) != 0 {
+		t.Errorf("messages = %+v, want none after cancel", messages)
+	}
+}
+
+func TestProcessSessionsRunConcurrently(t *testing.T) {
+	unblock := make(chan struct{})
+	m, server := newModel(t, time.Minute, func(request model.InferenceRequest) (*model.InferenceResponse, int) {
+		if strings.Contains(request.Prompt, "first") {
+			<-unblock
+		}
+		return &model.InferenceResponse{Text: modeltest.REPLY}, http.StatusOK
+	})
+
+	first := m.GetContext()
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.ProcessWithOptions("first", model.ProcessOptions{})
+		done <- err
+	}()
+	for len(server.Requests()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Ход другой сессии не ждет хода первой
+	second := model.NewContext()
+	second.SessionID = "second"
+	m.SetContext(second)
+
+	finished := make(chan error, 1)
+	go func() {
+		_, err := m.ProcessWithOptions("second", model.ProcessOptions{})
+		finished <- err
+	}()
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Fatalf("second session: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("turn of another session waited for the first one")
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Fatalf("first session: %v", err)
+	}
+	if branch := first.ActiveBranch(); len(branch) == 0 || branch[len(branch)-1].Content != modeltest.REPLY {
+		t.Errorf("first session branch = %+v, want the reply", branch)
+	}
+	if branch := second.ActiveBranch(); len(branch) == 0 || branch[len(branch)-1].Content != modeltest.REPLY {
+		t.Errorf("second session branch = %+v, want the reply", branch)
+	}
+}
+
+func TestCompleteIgnoresCurrentSession(t *testing.T) {
+	m, server := newModel(t, time.Minute, slowReply(0))
+	if err := m.SetProfiles([]model.Profile{{Name: "coder", SystemPrompt: "You write code.", Temperature: 0.1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Профиль и параметры текущей сессии CLI не влияют на запросы Complete
+	if err := m.UseProfile("coder"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SetGenerationOption("top_p", []string{"0.5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := []model.Message{{Role: "user", Content: "hi"}}
+	if _, err := m.Complete(messages, model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Complete(messages, model.ProcessOptions{Profile: "coder"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Complete(messages, model.ProcessOptions{Profile: "missing"}); !errors.Is(err, model.ErrProfileNotFound) {
+		t.Errorf("unknown profile: err = %v, want ErrProfileNotFound", err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if r := requests[0]; r.Temperature != model.TEMPERATURE || r.TopP != model.TOP_P || !strings.Contains(r.Prompt, model.DEFAULT_SYSTEM_PROMPT) {
+		t.Errorf("default request = temperature %v, top_p %v, prompt %q", r.Temperature, r.TopP, r.Prompt)
+	}
+	if r := requests[1]; r.Temperature != 0.1 || r.TopP != model.TOP_P || !strings.Contains(r.Prompt, "You write code.") {
+		t.Errorf("coder request = temperature %v, top_p %v, prompt %q", r.Temperature, r.TopP, r.Prompt)
+	}
+}
+
+func TestCompleteSessionUsesStoredProfile(t *testing.T) {
+	m, server := newModel(t, time.Minute, slowReply(0))
+	if err := m.SetProfiles([]model.Profile{{Name: "coder", SystemPrompt: "You write code.", Temperature: 0.1}}); err != nil {
+		t.Fatal(err)
+	}
+	sessions := storage.NewSessionManager(storage.NewFileSystem(t.TempDir()), "sessions")
+
+	session, err := m.NewSessionContext("coder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sessions.SaveSession("chat", session); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.CompleteSessionIn(sessions, "chat", "hi", model.ProcessOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].Temperature != 0.1 {
+		t.Errorf("requests = %+v, want the coder profile temperature", requests)
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+// SessionStore описывает хранилище сессий. Реализация на файловой системе
+// находится в пакете storage и передается в SmolLM через SetSessionStore
+type SessionStore interface {
+	// SaveSession сохраняет контекст под указанным именем
+	SaveSession(name string, context *Context) error
+	// LoadSession загружает контекст сессии по имени
+	LoadSession(name string) (*Context, error)
+	// DeleteSession удаляет сессию
+	DeleteSession(name string) error
+	// SessionExists проверяет, существует ли сессия
+	SessionExists(name string) bool
+}
@@ -0,0 +1,256 @@
+package model
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Параметры режима размышления
+const (
+	THINKING_TEMPERATURE = 0.9 // Более высокая температура для креативности
+	THINKING_TOP_P       = 0.95
+	THINKING_MAX_TOKENS  = 200
+	THINKING_PAUSE       = 2 * time.Second // Пауза между мыслями в ограниченном по времени режиме
+
+	// Затравки размышления; к ним добавляется префикс размышления профиля
+	THINKING_START    = "Размышляю самостоятельно без участия пользователя. Могу думать о программировании, философии, науке и других интересных темах. Начну со свободного потока мыслей."
+	THINKING_CONTINUE = "Продолжаю размышлять о "
+
+	// Расширение файла записи размышления рядом с журналом
+	THOUGHT_RECORD_EXTENSION = ".json"
+)
+
+// ThinkOptions содержит параметры запуска размышления
+type ThinkOptions struct {
+	Seconds int // Ограничение по времени; 0 - без ограничения, нужен Steps
+	Steps   int // Число мыслей; 0 - до истечения Seconds
+	Seed    int // Seed первой мысли, мысль i генерируется с Seed+i; 0 - seed из настроек или случайный
+
+	// Отмена размышления клиентом; nil - без отмены
+	Context context.Context
+
+	Owner string // Пользователь для очередности в очереди генерации
+}
+
+// ThoughtRun - запись размышления, по которой его можно повторить: с тем же
+// способом генерации и той же моделью повтор дает те же мысли
+type ThoughtRun struct {
+	Started    time.Time         `json:"started"`
+	Backend    string            `json:"backend"`
+	ModelPath  string            `json:"model_path"`
+	Profile    string            `json:"profile"`
+	Prompt     string            `json:"prompt"` // Префикс размышления профиля
+	Seed       int               `json:"seed"`
+	Generation GenerationOptions `json:"generation"` // Параметры генерации без seed
+	Seconds    int               `json:"seconds,omitempty"`
+	Thoughts   []string          `json:"thoughts"`
+}
+
+// ThoughtRecordPath возвращает путь записи размышления для файла журнала
+func ThoughtRecordPath(journal string) string {
+	return strings.TrimSuffix(journal, filepath.Ext(journal)) + THOUGHT_RECORD_EXTENSION
+}
+
+// LoadThoughtRun читает запись размышления. Вместо записи можно указать
+// файл журнала, запись тогда ищется рядом с ним
+func LoadThoughtRun(path string) (*ThoughtRun, error) {
+	path = ThoughtRecordPath(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.WrapError(err, "model.thought_record_read", path)
+	}
+
+	var run ThoughtRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, i18n.WrapError(err, "model.thought_record_decode", path)
+	}
+	return &run, nil
+}
+
+// Save сохраняет запись размышления
+func (r *ThoughtRun) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return i18n.WrapError(err, "model.thought_record_write", path)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return i18n.WrapError(err, "model.thought_record_write", path)
+	}
+	return nil
+}
+
+// Think запускает режим размышления без входных данных пользователя на
+// seconds секунд с seed из настроек
+func (s *SmolLM) Think(seconds int, outputFile string) {
+	if _, err := s.ThinkWithOptions(ThinkOptions{Seconds: seconds}, outputFile); err != nil {
+		s.logger.Error("Error in thinking mode: %v", err)
+	}
+}
+
+// ThinkWithOptions запускает режим размышления. Мысли записываются в журнал
+// outputFile, а запись для повтора - рядом с ним (ThoughtRecordPath).
+// Каждая мысль ждет очереди с приоритетом PRIORITY_THINKING и не задерживает
+// ответы пользователям дольше одной мысли
+func (s *SmolLM) ThinkWithOptions(opts ThinkOptions, outputFile string) (*ThoughtRun, error) {
+	s.mutex.Lock()
+	// В запись попадает seed, с которым генерировались мысли: без seed
+	// в параметрах и настройках он выбирается здесь, иначе повтор невозможен
+	seed := opts.Seed
+	if seed == 0 {
+		seed = s.thinkingSeed
+	}
+	if seed == 0 {
+		seed = rand.IntN(math.MaxInt32) + 1
+	}
+
+	generation := s.sessionGeneration().Merge(GenerationOptions{
+		Temperature: Float(THINKING_TEMPERATURE),
+		TopP:        Float(THINKING_TOP_P),
+		MaxTokens:   Int(THINKING_MAX_TOKENS),
+	})
+	generation.Seed = nil
+
+	run := &ThoughtRun{
+		Started:    time.Now(),
+		Backend:    s.inferencer.Backend(),
+		ModelPath:  s.modelPath,
+		Profile:    s.profile.Name,
+		Prompt:     s.profile.ThinkingPrompt,
+		Seed:       seed,
+		Generation: generation,
+		Seconds:    opts.Seconds,
+	}
+	s.mutex.Unlock()
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s.logger.Info("Starting thinking mode for %d seconds, %d steps, seed %d", opts.Seconds, opts.Steps, seed)
+	return run, s.think(ctx, opts.Owner, run, opts.Steps, outputFile)
+}
+
+// ReplayThoughts повторяет размышление из записи run: те же префикс, seed,
+// параметры генерации и число мыслей, без ограничения по времени
+func (s *SmolLM) ReplayThoughts(run *ThoughtRun, outputFile string) (*ThoughtRun, error) {
+	replay := &ThoughtRun{
+		Started:    time.Now(),
+		Backend:    s.inferencer.Backend(),
+		ModelPath:  s.modelPath,
+		Profile:    run.Profile,
+		Prompt:     run.Prompt,
+		Seed:       run.Seed,
+		Generation: run.Generation,
+	}
+
+	s.logger.Info("Replaying %d thoughts with seed %d", len(run.Thoughts), run.Seed)
+	return replay, s.think(context.Background(), "", replay, len(run.Thoughts), outputFile)
+}
+
+// think генерирует мысли по параметрам run и записывает их в журнал и запись
+// размышления. При ограничении по времени ошибка генерации записывается в
+// журнал, а мысль генерируется заново; при заданном числе мыслей ошибка
+// прерывает размышление. Отмена ctx завершает размышление без ошибки
+func (s *SmolLM) think(ctx context.Context, owner string, run *ThoughtRun, steps int, outputFile string) error {
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return i18n.WrapError(err, "model.thought_journal_create", outputFile)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	defer writer.Flush()
+
+	// Заголовок с параметрами начинается с # и не индексируется поиском
+	generation, _ := json.Marshal(run.Generation)
+	writer.WriteString(i18n.T("model.thought_journal_title", run.Started.Format(time.RFC3339)) + "\n\n")
+	writer.WriteString(i18n.T("model.thought_journal_params") + fmt.Sprintf("\n- backend: %s\n- seed: %d\n- generation: %s\n\n", run.Backend, run.Seed, generation))
+	writer.WriteString(i18n.T("model.thought_journal_start") + "\n\n")
+
+	if run.Seconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(run.Seconds)*time.Second)
+		defer cancel()
+	}
+
+	var runErr error
+	for steps == 0 || len(run.Thoughts) < steps {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Первая мысль начинается с затравки, следующие продолжают предыдущую
+		prompt := run.Prompt + THINKING_START
+		if n := len(run.Thoughts); n > 0 {
+			prompt = run.Prompt + THINKING_CONTINUE + run.Thoughts[n-1]
+		}
+
+		generation := run.Generation
+		generation.Seed = Int(run.Seed + len(run.Thoughts))
+
+		response, err := s.thought(ctx, owner, generation.request(prompt))
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			s.logger.Error("Error in thinking mode: %v", err)
+			writer.WriteString(i18n.T("model.thought_journal_error", i18n.LocalizeError(err)) + "\n\n")
+			if steps > 0 {
+				runErr = err
+				break
+			}
+			pause(ctx, THINKING_PAUSE)
+			continue
+		}
+
+		run.Thoughts = append(run.Thoughts, response.Text)
+		writer.WriteString(response.Text + "\n\n")
+
+		// Небольшая пауза между размышлениями
+		if run.Seconds > 0 {
+			pause(ctx, THINKING_PAUSE)
+		}
+	}
+
+	writer.WriteString(i18n.T("model.thought_journal_end") + "\n")
+	s.logger.Info("Thinking mode completed with %d thoughts, output saved to %s", len(run.Thoughts), outputFile)
+
+	if err := run.Save(ThoughtRecordPath(outputFile)); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// pause ждет duration или отмены ctx
+func pause(ctx context.Context, duration time.Duration) {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// thought генерирует одну мысль, дождавшись слота в очереди генерации
+func (s *SmolLM) thought(ctx context.Context, owner string, request InferenceRequest) (*InferenceResponse, error) {
+	release, err := s.scheduler.Acquire(ctx, Job{Priority: PRIORITY_THINKING, Owner: owner})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.inferencer.GenerateRequest(ctx, request)
+}
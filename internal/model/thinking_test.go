@@ -0,0 +1,30 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPauseCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	pause(ctx, time.Minute)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("pause ignored cancellation, waited %v", elapsed)
+	}
+}
+
+func TestThoughtRecordPath(t *testing.T) {
+	tests := []struct{ journal, want string }{
+		{"thoughts/run.txt", "thoughts/run" + THOUGHT_RECORD_EXTENSION},
+		{"thoughts/run", "thoughts/run" + THOUGHT_RECORD_EXTENSION},
+	}
+	for _, tt := range tests {
+		if got := ThoughtRecordPath(tt.journal); got != tt.want {
+			t.Errorf("ThoughtRecordPath(%q) = %q, want %q", tt.journal, got, tt.want)
+		}
+	}
+}
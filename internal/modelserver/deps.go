@@ -0,0 +1,44 @@
+package modelserver
+
+import (
+	"os/exec"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Пакеты Python, необходимые серверу модели
+var SERVER_PACKAGES = []string{"torch", "transformers", "fastapi", "uvicorn", "pydantic"}
+
+// findMissingScript выводит по строке на каждый модуль из argv, который не
+// удается найти. Модули не импортируются: загрузка torch заняла бы секунды
+const findMissingScript = `
+import importlib.util, sys
+for name in sys.argv[1:]:
+    if importlib.util.find_spec(name) is None:
+        print(name)
+`
+
+// CheckDependencies проверяет, что интерпретатор python доступен и в нем
+// установлены пакеты packages. Пакеты не устанавливаются автоматически:
+// ошибка содержит команду для их установки
+func CheckDependencies(python string, packages []string) error {
+	path, err := exec.LookPath(python)
+	if err != nil {
+		return i18n.WrapError(err, "modelserver.python_not_found", python).WithKind(ErrPythonNotFound)
+	}
+
+	args := append([]string{"-c", findMissingScript}, packages...)
+	output, err := exec.Command(path, args...).Output()
+	if err != nil {
+		return i18n.WrapError(err, "modelserver.dependency_check", python).WithKind(ErrPythonNotFound)
+	}
+
+	missing := strings.Fields(string(output))
+	if len(missing) > 0 {
+		return i18n.NewError("modelserver.missing_packages",
+			strings.Join(missing, ", "), python, strings.Join(missing, " ")).WithKind(ErrMissingDependencies)
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package modelserver
+
+import "errors"
+
+// Виды ошибок процесса сервера модели: запуск, готовность и запросы
+var (
+	ErrPythonNotFound      = errors.New("modelserver: python interpreter not found")
+	ErrMissingDependencies = errors.New("modelserver: missing python packages")
+	ErrStartupTimeout      = errors.New("modelserver: startup timeout")
+	ErrServerFailed        = errors.New("modelserver: server failed")
+	ErrClosed              = errors.New("modelserver: process closed")
+	ErrNotReady            = errors.New("modelserver: process not ready")
+	ErrRequestFailed       = errors.New("modelserver: request failed")
+)
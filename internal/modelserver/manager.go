@@ -0,0 +1,250 @@
+package modelserver
+
+import (
+	_ "embed"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+)
+
+// Параметры сервера модели по умолчанию
+const (
+	DEFAULT_PYTHON = "python3"
+	DEFAULT_HOST   = "localhost"
+	DEFAULT_PORT   = 8000
+	SCRIPT_NAME    = "server.py"
+	HEALTH_PATH    = "/health"
+
+	STARTUP_TIMEOUT = 2 * time.Minute  // Время на загрузку модели при каждом запуске
+	HEALTH_INTERVAL = 2 * time.Second  // Период опроса /health во время запуска
+	STOP_TIMEOUT    = 10 * time.Second // Ожидание после SIGTERM перед SIGKILL
+	BACKOFF_MIN     = time.Second      // Пауза перед первым перезапуском
+	BACKOFF_MAX     = time.Minute      // Предел удвоения паузы между перезапусками
+	STABLE_UPTIME   = 5 * time.Minute  // После такой работы счетчик перезапусков сбрасывается
+	MAX_RESTARTS    = 5                // Перезапусков подряд, после которых супервизор сдается
+)
+
+// serverScript - HTTP сервер модели на FastAPI, распаковываемый в рабочую директорию
+//
+//go:embed server.py
+var serverScript []byte
+
+// State - состояние процесса Python (сервера модели или рабочего процесса)
+type State int
+
+// Состояния процесса Python
+const (
+	STATE_STOPPED    State = iota // Процесс не запущен или остановлен Close
+	STATE_STARTING                // Процесс запущен, модель загружается
+	STATE_READY                   // Процесс готов принимать запросы
+	STATE_RESTARTING              // Процесс завершился, ожидается перезапуск
+	STATE_FAILED                  // Запуск невозможен или перезапуски исчерпаны
+)
+
+var stateNames = map[State]string{
+	STATE_STOPPED:    "stopped",
+	STATE_STARTING:   "starting",
+	STATE_READY:      "ready",
+	STATE_RESTARTING: "restarting",
+	STATE_FAILED:     "failed",
+}
+
+// String возвращает название состояния
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Config содержит настройки процессов Python: сервера модели и рабочего
+// процесса. Нулевые поля заменяются значениями по умолчанию
+type Config struct {
+	ModelPath      string        // Путь к модели, передается процессу в MODEL_PATH
+	Python         string        // Интерпретатор Python
+	Host           string        // Адрес, на котором слушает сервер
+	Port           int           // Порт сервера
+	WorkDir        string        // Директория, куда распаковываются скрипты
+	StartupTimeout time.Duration // Время на загрузку модели при каждом запуске
+	MaxRestarts    int           // Перезапусков подряд до перехода в STATE_FAILED
+}
+
+// withDefaults заполняет незаданные поля настроек значениями по умолчанию
+func (c Config) withDefaults() Config {
+	if c.Python == "" {
+		c.Python = DEFAULT_PYTHON
+	}
+	if c.Host == "" {
+		c.Host = DEFAULT_HOST
+	}
+	if c.Port == 0 {
+		c.Port = DEFAULT_PORT
+	}
+	if c.WorkDir == "" {
+		c.WorkDir = filepath.Join(os.TempDir(), "smollm_api")
+	}
+	if c.StartupTimeout == 0 {
+		c.StartupTimeout = STARTUP_TIMEOUT
+	}
+	if c.MaxRestarts == 0 {
+		c.MaxRestarts = MAX_RESTARTS
+	}
+	return c
+}
+
+// env возвращает окружение процесса Python
+func (c Config) env() []string {
+	return append(os.Environ(),
+		"MODEL_PATH="+c.ModelPath,
+		"SMOLLM_HOST="+c.Host,
+		"SMOLLM_PORT="+strconv.Itoa(c.Port),
+		"PYTHONUNBUFFERED=1",
+	)
+}
+
+// writeScript распаковывает скрипт в рабочую директорию и возвращает путь к нему
+func (c Config) writeScript(name string, script []byte) (string, error) {
+	if err := os.MkdirAll(c.WorkDir, 0755); err != nil {
+		return "", i18n.WrapError(err, "modelserver.script_write").WithKind(ErrServerFailed)
+	}
+
+	scriptPath := filepath.Join(c.WorkDir, name)
+	if err := os.WriteFile(scriptPath, script, 0644); err != nil {
+		return "", i18n.WrapError(err, "modelserver.script_write").WithKind(ErrServerFailed)
+	}
+
+	return scriptPath, nil
+}
+
+// Manager запускает HTTP сервер модели, перезапускает его при падении и
+// останавливает при закрытии. Если по адресу сервера уже отвечает другой
+// процесс, менеджер использует его и не управляет им
+type Manager struct {
+	*supervisor
+	config     Config
+	client     *http.Client
+	scriptPath string
+	external   bool // Сервер запущен не менеджером
+}
+
+// NewManager создает менеджер сервера модели. Сервер запускается методом Start
+func NewManager(config Config) *Manager {
+	m := &Manager{
+		config: config.withDefaults(),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+	m.supervisor = newSupervisor(logging.NewLogger(), "Model server", m.config.MaxRestarts, m.run)
+	return m
+}
+
+// URL возвращает базовый адрес сервера модели
+func (m *Manager) URL() string {
+	return "http://" + net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+}
+
+// Start запускает сервер модели под надзором и ждет результата первого
+// запуска. При ошибке первого запуска супервизор продолжает перезапускать
+// сервер в фоне, пока не исчерпает MaxRestarts
+func (m *Manager) Start() error {
+	if m.healthy() {
+		m.logger.Info("Model server is already running at %s", m.URL())
+		m.external = true
+		m.setState(STATE_READY, nil)
+		return nil
+	}
+
+	m.logger.Info("Starting model server at %s", m.URL())
+
+	if err := CheckDependencies(m.config.Python, SERVER_PACKAGES); err != nil {
+		return m.fail(err)
+	}
+
+	scriptPath, err := m.config.writeScript(SCRIPT_NAME, serverScript)
+	if err != nil {
+		return m.fail(err)
+	}
+	m.scriptPath = scriptPath
+
+	return m.start()
+}
+
+// Close останавливает сервер, запущенный менеджером (SIGTERM, затем SIGKILL
+// через STOP_TIMEOUT), и ждет завершения процесса. Сервер, запущенный не
+// менеджером, продолжает работать
+func (m *Manager) Close() {
+	m.close()
+}
+
+// run запускает процесс сервера и ждет его завершения. Возвращает причину
+// завершения; при вызове Close останавливает процесс и возвращает nil
+func (m *Manager) run() error {
+	stdout := &logWriter{logger: m.logger, prefix: "model-server stdout"}
+	stderr := &logWriter{logger: m.logger, prefix: "model-server stderr"}
+	defer stdout.Flush()
+	defer stderr.Flush()
+
+	cmd := exec.Command(m.config.Python, m.scriptPath)
+	cmd.Dir = m.config.WorkDir
+	cmd.Env = m.config.env()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true, // Группа процессов останавливается целиком
+	}
+
+	if err := cmd.Start(); err != nil {
+		return i18n.WrapError(err, "modelserver.process_start").WithKind(ErrServerFailed)
+	}
+	m.logger.Info("Model server process started, PID: %d", cmd.Process.Pid)
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	ticker := time.NewTicker(HEALTH_INTERVAL)
+	defer ticker.Stop()
+	deadline := time.After(m.config.StartupTimeout)
+
+	for {
+		select {
+		case err := <-exited:
+			return exitError(err)
+
+		case <-ticker.C:
+			if m.State() == STATE_STARTING && m.healthy() {
+				m.logger.Info("Model server is ready")
+				m.setState(STATE_READY, nil)
+				deadline = nil
+			}
+
+		case <-deadline:
+			m.logger.Error("Model server did not become ready in %v", m.config.StartupTimeout)
+			m.terminate(cmd, exited)
+			return i18n.NewError("modelserver.startup_timeout", m.config.StartupTimeout).WithKind(ErrStartupTimeout)
+
+		case <-m.stop:
+			m.terminate(cmd, exited)
+			return nil
+		}
+	}
+}
+
+// healthy проверяет, отвечает ли сервер на /health
+func (m *Manager) healthy() bool {
+	resp, err := m.client.Get(m.URL() + HEALTH_PATH)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
@@ -0,0 +1,189 @@
+# HTTP сервер модели SmolLM2. Запускается и перезапускается менеджером
+# modelserver; зависимости (torch, transformers, fastapi, uvicorn, pydantic)
+# проверяются менеджером до запуска и здесь не устанавливаются
+import os
+import sys
+import time
+import traceback
+
+import torch
+from transformers import (pipeline, AutoModelForCausalLM, AutoTokenizer, LogitsProcessor, LogitsProcessorList,
+                          StoppingCriteria, StoppingCriteriaList)
+from fastapi import FastAPI, HTTPException, Body
+from pydantic import BaseModel
+import uvicorn
+
+# Настройки безопасности и совместимости
+torch.set_default_dtype(torch.float32)
+
+# Определяем модель и токенизатор
+MODEL_PATH = os.environ.get("MODEL_PATH", "")
+if not MODEL_PATH:
+    print("MODEL_PATH не установлен", file=sys.stderr)
+    sys.exit(1)
+
+print(f"Загрузка модели из {MODEL_PATH}")
+
+# Безопасная загрузка модели с обработкой ошибок
+try:
+    tokenizer = AutoTokenizer.from_pretrained(MODEL_PATH)
+    model = AutoModelForCausalLM.from_pretrained(
+        MODEL_PATH,
+        torch_dtype=torch.float32,
+        device_map="auto"
+    )
+
+    generator = pipeline(
+        "text-generation",
+        model=model,
+        tokenizer=tokenizer
+    )
+except Exception as e:
+    print(f"Ошибка при загрузке модели: {e}")
+    traceback.print_exc()
+    sys.exit(1)
+
+class StopStringsCriteria(StoppingCriteria):
+    """Останавливает генерацию, когда в ответе появилась одна из стоп-строк"""
+
+    def __init__(self, stop_strings, prompt_tokens):
+        self.stop_strings = stop_strings
+        self.prompt_tokens = prompt_tokens
+
+    def __call__(self, input_ids, scores, **kwargs):
+        text = tokenizer.decode(input_ids[0][self.prompt_tokens:], skip_special_tokens=True)
+        return any(stop in text for stop in self.stop_strings)
+
+class PenaltyProcessor(LogitsProcessor):
+    """Частотный штраф и штраф присутствия по токенам ответа, как в API OpenAI"""
+
+    def __init__(self, frequency, presence, prompt_tokens):
+        self.frequency = frequency
+        self.presence = presence
+        self.prompt_tokens = prompt_tokens
+
+    def __call__(self, input_ids, scores):
+        for row, ids in enumerate(input_ids):
+            generated = ids[self.prompt_tokens:]
+            if len(generated) == 0:
+                continue
+            counts = torch.bincount(generated, minlength=scores.shape[-1])[:scores.shape[-1]].to(scores.dtype)
+            scores[row] -= counts * self.frequency + (counts > 0).to(scores.dtype) * self.presence
+        return scores
+
+# Создаем FastAPI приложение
+app = FastAPI(title="SmolLM2 API")
+
+class GenerateRequest(BaseModel):
+    prompt: str
+    max_tokens: int = 512
+    temperature: float = 0.7
+    top_p: float = 0.9
+    top_k: int = 40
+    min_p: float = 0.0
+    repetition_penalty: float = 0.0
+    frequency_penalty: float = 0.0
+    presence_penalty: float = 0.0
+    stop_tokens: list = []
+    seed: int = None
+
+class GenerateResponse(BaseModel):
+    text: str
+    tokens_used: int
+    generated_in: float
+    prompt_tokens: int
+
+@app.get("/health")
+def health_check():
+    return {"status": "ok"}
+
+@app.post("/v1/generate")
+def generate(request: GenerateRequest = Body(...)):
+    start_time = time.time()
+
+    # Устанавливаем seed если указан
+    if request.seed is not None:
+        torch.manual_seed(request.seed)
+
+    # Вычисляем количество токенов в промпте
+    prompt_tokens = len(tokenizer.encode(request.prompt))
+
+    # Необязательные параметры передаются, только если заданы
+    stop_strings = [stop for stop in request.stop_tokens if stop]
+    kwargs = {}
+    if stop_strings:
+        kwargs["stopping_criteria"] = StoppingCriteriaList([StopStringsCriteria(stop_strings, prompt_tokens)])
+    if request.min_p:
+        kwargs["min_p"] = request.min_p
+    if request.repetition_penalty:
+        kwargs["repetition_penalty"] = request.repetition_penalty
+    if request.frequency_penalty or request.presence_penalty:
+        kwargs["logits_processor"] = LogitsProcessorList([PenaltyProcessor(
+            request.frequency_penalty, request.presence_penalty, prompt_tokens)])
+
+    # Генерируем ответ
+    try:
+        outputs = generator(
+            request.prompt,
+            max_new_tokens=request.max_tokens,
+            temperature=request.temperature,
+            top_p=request.top_p,
+            top_k=request.top_k,
+            do_sample=True,
+            pad_token_id=tokenizer.eos_token_id,
+            **kwargs
+        )
+
+        # Получаем сгенерированный текст
+        generated_text = outputs[0]["generated_text"]
+
+        # Отрезаем промпт, чтобы получить только сгенерированный текст
+        if generated_text.startswith(request.prompt):
+            generated_text = generated_text[len(request.prompt):]
+
+        # Обрезаем по первой стоп-строке
+        for stop_token in stop_strings:
+            if stop_token in generated_text:
+                generated_text = generated_text.split(stop_token)[0]
+
+        # Общее количество использованных токенов
+        total_tokens = len(tokenizer.encode(generated_text)) + prompt_tokens
+
+        # Время генерации
+        generation_time = time.time() - start_time
+
+        return GenerateResponse(
+            text=generated_text,
+            tokens_used=total_tokens,
+            generated_in=generation_time,
+            prompt_tokens=prompt_tokens
+        )
+    except Exception as e:
+        print(f"Ошибка генерации: {e}")
+        traceback.print_exc()
+        raise HTTPException(status_code=500, detail=str(e))
+
+class EmbeddingsRequest(BaseModel):
+    input: list
+
+@app.post("/v1/embeddings")
+def embeddings(request: EmbeddingsRequest = Body(...)):
+    # Эмбеддинг текста - усредненное последнее скрытое состояние модели
+    try:
+        vectors = []
+        for text in request.input:
+            encoded = tokenizer(text, return_tensors="pt", truncation=True, max_length=512).to(model.device)
+            with torch.no_grad():
+                output = model(**encoded, output_hidden_states=True)
+            hidden = output.hidden_states[-1][0]
+            vectors.append(hidden.mean(dim=0).tolist())
+        return {"embeddings": vectors}
+    except Exception as e:
+        print(f"Ошибка вычисления эмбеддингов: {e}")
+        traceback.print_exc()
+        raise HTTPException(status_code=500, detail=str(e))
+
+if __name__ == "__main__":
+    host = os.environ.get("SMOLLM_HOST", "localhost")
+    port = int(os.environ.get("SMOLLM_PORT", "8000"))
+    uvicorn.run(app, host=host, port=port)
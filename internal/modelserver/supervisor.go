@@ -0,0 +1,256 @@
+package modelserver
+
+import (
+	"bytes"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+)
+
+// supervisor отслеживает состояние процесса Python и перезапускает его после
+// падения с удваивающейся паузой. Один запуск процесса выполняет функция run:
+// она переводит супервизор в STATE_READY, когда процесс готов, и возвращает
+// причину завершения процесса (nil после остановки через close)
+type supervisor struct {
+	logger      *logging.Logger
+	name        string // Название процесса для логов
+	maxRestarts int
+	run         func() error
+
+	mu      sync.Mutex
+	state   State
+	err     error         // Причина последнего сбоя
+	changed chan struct{} // Закрывается при каждой смене состояния
+	closed  bool
+	stop    chan struct{} // Закрывается close; nil, пока цикл не запущен
+	done    chan struct{} // Закрывается при выходе цикла
+}
+
+// newSupervisor создает супервизор процесса; процесс запускается методом start
+func newSupervisor(logger *logging.Logger, name string, maxRestarts int, run func() error) *supervisor {
+	return &supervisor{
+		logger:      logger,
+		name:        name,
+		maxRestarts: maxRestarts,
+		run:         run,
+		changed:     make(chan struct{}),
+	}
+}
+
+// State возвращает текущее состояние процесса
+func (s *supervisor) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state
+}
+
+// Ready сообщает, готов ли процесс принимать запросы
+func (s *supervisor) Ready() bool {
+	return s.State() == STATE_READY
+}
+
+// Err возвращает причину последнего сбоя процесса или nil
+func (s *supervisor) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// start запускает цикл надзора и ждет результата первого запуска. При
+// ошибке первого запуска цикл продолжает перезапускать процесс в фоне
+func (s *supervisor) start() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return i18n.NewError("modelserver.closed").WithKind(ErrClosed)
+	}
+	if s.stop != nil {
+		s.mu.Unlock()
+		return s.waitStarted()
+	}
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	// Состояние меняется до запуска цикла, чтобы waitStarted не принял
+	// начальное STATE_STOPPED за остановку
+	s.setState(STATE_STARTING, nil)
+	go s.loop()
+
+	return s.waitStarted()
+}
+
+// close останавливает цикл надзора и ждет завершения процесса
+func (s *supervisor) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	stop, done := s.stop, s.done
+	s.mu.Unlock()
+
+	if stop != nil {
+		s.logger.Info("%s: stopping...", s.name)
+		close(stop)
+		<-done
+	}
+	s.setState(STATE_STOPPED, nil)
+}
+
+// fail переводит супервизор в STATE_FAILED без запуска процесса
+func (s *supervisor) fail(err error) error {
+	s.setState(STATE_FAILED, err)
+	return err
+}
+
+// waitStarted ждет, пока процесс станет готов или первый запуск завершится ошибкой
+func (s *supervisor) waitStarted() error {
+	for {
+		s.mu.Lock()
+		state, err, changed := s.state, s.err, s.changed
+		s.mu.Unlock()
+
+		switch state {
+		case STATE_READY:
+			return nil
+		case STATE_RESTARTING, STATE_FAILED:
+			return err
+		case STATE_STOPPED:
+			return i18n.NewError("modelserver.closed").WithKind(ErrClosed)
+		}
+		<-changed
+	}
+}
+
+// setState меняет состояние процесса и будит ожидающих. Ошибка сохраняется
+// до следующего сбоя или перехода в STATE_READY
+func (s *supervisor) setState(state State, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = state
+	if err != nil || state == STATE_READY {
+		s.err = err
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// stopping сообщает, вызван ли close
+func (s *supervisor) stopping() bool {
+	select {
+	case <-s.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// loop запускает процесс и перезапускает его после падения, пока не будет вызван close
+func (s *supervisor) loop() {
+	defer close(s.done)
+
+	backoff := BACKOFF_MIN
+	restarts := 0
+	for {
+		started := time.Now()
+		s.setState(STATE_STARTING, nil)
+		err := s.run()
+		if s.stopping() {
+			return
+		}
+
+		// Долгая работа без сбоев означает, что предыдущие падения не связаны с текущим
+		if time.Since(started) >= STABLE_UPTIME {
+			restarts = 0
+			backoff = BACKOFF_MIN
+		}
+
+		if restarts >= s.maxRestarts {
+			s.logger.Error("%s failed %d times in a row, giving up: %v", s.name, restarts+1, err)
+			s.setState(STATE_FAILED, i18n.WrapError(err, "modelserver.gave_up", restarts+1).WithKind(ErrServerFailed))
+			return
+		}
+
+		restarts++
+		s.logger.Warn("%s stopped: %v; restarting in %v (attempt %d of %d)",
+			s.name, err, backoff, restarts, s.maxRestarts)
+		s.setState(STATE_RESTARTING, err)
+
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, BACKOFF_MAX)
+	}
+}
+
+// terminate останавливает группу процессов cmd: SIGTERM, затем SIGKILL,
+// если процесс не завершился за STOP_TIMEOUT. Возвращает результат Wait из exited
+func (s *supervisor) terminate(cmd *exec.Cmd, exited <-chan error) error {
+	pgid := -cmd.Process.Pid
+	syscall.Kill(pgid, syscall.SIGTERM)
+
+	select {
+	case err := <-exited:
+		return err
+	case <-time.After(STOP_TIMEOUT):
+		s.logger.Warn("%s did not stop in %v, killing it", s.name, STOP_TIMEOUT)
+		syscall.Kill(pgid, syscall.SIGKILL)
+		return <-exited
+	}
+}
+
+// exitError возвращает ошибку завершения процесса по результату Wait
+func exitError(err error) error {
+	if err != nil {
+		return i18n.WrapError(err, "modelserver.process_exited").WithKind(ErrServerFailed)
+	}
+	return i18n.NewError("modelserver.process_exited").WithKind(ErrServerFailed)
+}
+
+// logWriter построчно передает вывод процесса в логгер
+type logWriter struct {
+	logger *logging.Logger
+	prefix string
+	buf    []byte
+}
+
+// Write записывает полные строки в лог, остаток сохраняет до следующей записи
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush записывает в лог незавершенную строку
+func (w *logWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *logWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	w.logger.Info("%s: %s", w.prefix, line)
+}
@@ -0,0 +1,314 @@
+package modelserver
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+)
+
+// Параметры рабочего процесса
+const (
+	WORKER_SCRIPT_NAME = "worker.py"
+	MAX_MESSAGE_SIZE   = 16 * 1024 * 1024 // Максимальная длина строки ответа рабочего процесса
+)
+
+// Пакеты Python, необходимые рабочему процессу
+var WORKER_PACKAGES = []string{"torch", "transformers"}
+
+// workerScript - рабочий процесс локальной генерации, распаковываемый в рабочую директорию
+//
+//go:embed worker.py
+var workerScript []byte
+
+// WorkerRequest - запрос генерации к рабочему процессу
+type WorkerRequest struct {
+	Prompt            string   `json:"prompt"`
+	MaxTokens         int      `json:"max_tokens"`
+	Temperature       float64  `json:"temperature"`
+	TopP              float64  `json:"top_p,omitempty"`
+	TopK              int      `json:"top_k,omitempty"`
+	MinP              float64  `json:"min_p,omitempty"`
+	RepetitionPenalty float64  `json:"repetition_penalty,omitempty"`
+	FrequencyPenalty  float64  `json:"frequency_penalty,omitempty"`
+	PresencePenalty   float64  `json:"presence_penalty,omitempty"`
+	StopTokens        []string `json:"stop_tokens,omitempty"`
+	Seed              int      `json:"seed,omitempty"`
+}
+
+// WorkerResponse - результат генерации рабочего процесса
+type WorkerResponse struct {
+	Text         string  `json:"text"`
+	PromptTokens int     `json:"prompt_tokens"`
+	TokensUsed   int     `json:"tokens_used"`
+	GeneratedIn  float64 `json:"generated_in"`
+}
+
+// workerMessage - строка протокола рабочего процесса (запрос или ответ)
+type workerMessage struct {
+	Type string `json:"type"` // generate, cancel, ready, result
+	ID   uint64 `json:"id,omitempty"`
+	*WorkerRequest
+	*WorkerResponse
+	Error     string `json:"error,omitempty"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+
+	failure error // Сбой процесса, завершивший запрос без ответа
+}
+
+// Worker - долгоживущий процесс Python, который загружает модель один раз
+// и обслуживает запросы генерации по протоколу NDJSON через stdin/stdout.
+// Запросы выполняются по очереди; при падении процесс перезапускается
+type Worker struct {
+	*supervisor
+	config     Config
+	scriptPath string
+
+	reqMu   sync.Mutex
+	stdin   io.Writer                     // nil, пока процесс не запущен
+	pending map[uint64]chan workerMessage // Ожидающие ответа запросы по ID
+	nextID  uint64
+}
+
+// NewWorker создает рабочий процесс. Поля Host и Port настроек не используются.
+// Процесс запускается методом Start
+func NewWorker(config Config) *Worker {
+	w := &Worker{
+		config:  config.withDefaults(),
+		pending: make(map[uint64]chan workerMessage),
+	}
+	w.supervisor = newSupervisor(logging.NewLogger(), "Inference worker", w.config.MaxRestarts, w.run)
+	return w
+}
+
+// Start проверяет зависимости, запускает рабочий процесс под надзором и
+// ждет загрузки модели
+func (w *Worker) Start() error {
+	if err := CheckDependencies(w.config.Python, WORKER_PACKAGES); err != nil {
+		return w.fail(err)
+	}
+
+	scriptPath, err := w.config.writeScript(WORKER_SCRIPT_NAME, workerScript)
+	if err != nil {
+		return w.fail(err)
+	}
+	w.scriptPath = scriptPath
+
+	return w.start()
+}
+
+// Close останавливает рабочий процесс: закрывает его stdin, затем SIGTERM и SIGKILL
+func (w *Worker) Close() {
+	w.close()
+}
+
+// Generate отправляет запрос рабочему процессу и ждет ответа. Отмена ctx
+// прерывает генерацию в рабочем процессе и возвращает ctx.Err()
+func (w *Worker) Generate(ctx context.Context, request WorkerRequest) (*WorkerResponse, error) {
+	if !w.Ready() {
+		return nil, i18n.WrapError(w.Err(), "modelserver.worker_not_ready", w.State()).WithKind(ErrNotReady)
+	}
+
+	w.reqMu.Lock()
+	if w.stdin == nil {
+		w.reqMu.Unlock()
+		return nil, i18n.NewError("modelserver.worker_not_ready", w.State()).WithKind(ErrNotReady)
+	}
+	w.nextID++
+	id := w.nextID
+	reply := make(chan workerMessage, 1)
+	w.pending[id] = reply
+	err := w.send(workerMessage{Type: "generate", ID: id, WorkerRequest: &request})
+	w.reqMu.Unlock()
+
+	if err != nil {
+		w.forget(id)
+		return nil, i18n.WrapError(err, "modelserver.worker_write").WithKind(ErrNotReady)
+	}
+
+	select {
+	case message := <-reply:
+		if message.failure != nil {
+			return nil, message.failure
+		}
+		if message.Error != "" {
+			return nil, i18n.NewError("modelserver.worker_failed", message.Error).WithKind(ErrRequestFailed)
+		}
+		if message.Cancelled {
+			return nil, context.Canceled
+		}
+		if message.WorkerResponse == nil {
+			return &WorkerResponse{}, nil
+		}
+		return message.WorkerResponse, nil
+
+	case <-ctx.Done():
+		// Рабочий процесс прерывает генерацию и пришлет ответ, который уже никто не ждет
+		w.forget(id)
+		w.reqMu.Lock()
+		if w.stdin != nil {
+			w.send(workerMessage{Type: "cancel", ID: id})
+		}
+		w.reqMu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// send записывает сообщение в stdin рабочего процесса. Вызывается под reqMu
+func (w *Worker) send(message workerMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = w.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// forget удаляет ожидающий запрос
+func (w *Worker) forget(id uint64) {
+	w.reqMu.Lock()
+	defer w.reqMu.Unlock()
+
+	delete(w.pending, id)
+}
+
+// deliver передает ответ ожидающему запросу
+func (w *Worker) deliver(message workerMessage) {
+	w.reqMu.Lock()
+	reply, ok := w.pending[message.ID]
+	delete(w.pending, message.ID)
+	w.reqMu.Unlock()
+
+	if ok {
+		reply <- message
+	}
+}
+
+// failPending завершает ошибкой все ожидающие запросы после падения процесса
+func (w *Worker) failPending(err error) {
+	w.reqMu.Lock()
+	defer w.reqMu.Unlock()
+
+	for id, reply := range w.pending {
+		reply <- workerMessage{ID: id, failure: err}
+		delete(w.pending, id)
+	}
+}
+
+// run запускает рабочий процесс и ждет его завершения. Возвращает причину
+// завершения; при вызове Close останавливает процесс и возвращает nil
+func (w *Worker) run() error {
+	stderr := &logWriter{logger: w.logger, prefix: "inference-worker"}
+	defer stderr.Flush()
+
+	cmd := exec.Command(w.config.Python, w.scriptPath)
+	cmd.Dir = w.config.WorkDir
+	cmd.Env = w.config.env()
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true, // Группа процессов останавливается целиком
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return i18n.WrapError(err, "modelserver.process_start").WithKind(ErrServerFailed)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return i18n.WrapError(err, "modelserver.process_start").WithKind(ErrServerFailed)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return i18n.WrapError(err, "modelserver.process_start").WithKind(ErrServerFailed)
+	}
+	w.logger.Info("Inference worker process started, PID: %d", cmd.Process.Pid)
+
+	// Ответы читаются до закрытия stdout, после чего процесс ожидается:
+	// Wait нельзя вызывать, пока чтение из StdoutPipe не завершено
+	ready := make(chan struct{})
+	exited := make(chan error, 1)
+	go func() {
+		w.readMessages(stdout, ready)
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.After(w.config.StartupTimeout)
+	var result error
+	for result == nil {
+		select {
+		case err := <-exited:
+			result = exitError(err)
+
+		case <-ready:
+			w.reqMu.Lock()
+			w.stdin = stdin
+			w.reqMu.Unlock()
+			w.logger.Info("Inference worker is ready")
+			w.setState(STATE_READY, nil)
+			ready, deadline = nil, nil
+
+		case <-deadline:
+			w.logger.Error("Inference worker did not become ready in %v", w.config.StartupTimeout)
+			w.terminate(cmd, exited)
+			result = i18n.NewError("modelserver.startup_timeout", w.config.StartupTimeout).WithKind(ErrStartupTimeout)
+
+		case <-w.stop:
+			// Новых запросов не будет; процесс останавливается сигналами, так
+			// как может быть занят генерацией
+			stdin.Close()
+			w.terminate(cmd, exited)
+			result = i18n.NewError("modelserver.closed").WithKind(ErrClosed)
+		}
+	}
+
+	w.reqMu.Lock()
+	w.stdin = nil
+	w.reqMu.Unlock()
+	w.failPending(result)
+
+	if w.stopping() {
+		return nil
+	}
+	return result
+}
+
+// readMessages читает ответы рабочего процесса из stdout до его закрытия
+func (w *Worker) readMessages(stdout io.Reader, ready chan<- struct{}) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), MAX_MESSAGE_SIZE)
+
+	announced := false
+
+	for scanner.Scan() {
+		var message workerMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			w.logger.Info("inference-worker: %s", scanner.Text())
+			continue
+		}
+
+		switch message.Type {
+		case "ready":
+			if !announced {
+				announced = true
+				close(ready)
+			}
+		case "result":
+			w.deliver(message)
+		default:
+			w.logger.Warn("Unknown inference worker message type: %s", message.Type)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		w.logger.Error("Failed to read inference worker output: %v", err)
+		// Остаток вывода вычитывается, чтобы процесс не заблокировался на записи
+		io.Copy(io.Discard, stdout)
+	}
+}
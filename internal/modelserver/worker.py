@@ -0,0 +1,192 @@
+# Рабочий процесс локальной генерации. Загружает модель один раз и
+# обслуживает запросы по протоколу JSON построчно (NDJSON):
+#   stdin:  {"type": "generate", "id": 1, "prompt": "...", "max_tokens": 128, ...}
+#           {"type": "cancel", "id": 1}
+#   stdout: {"type": "ready"} после загрузки модели
+#           {"type": "result", "id": 1, "text": "...", ...} на каждый запрос
+# Ход работы выводится в stderr. Зависимости (torch, transformers)
+# проверяются менеджером до запуска и здесь не устанавливаются
+import json
+import os
+import queue
+import sys
+import threading
+import time
+import traceback
+
+import torch
+from transformers import (AutoModelForCausalLM, AutoTokenizer, LogitsProcessor, LogitsProcessorList,
+                          StoppingCriteria, StoppingCriteriaList)
+
+out_lock = threading.Lock()
+cancel_lock = threading.Lock()
+cancelled = set()
+requests = queue.Queue()
+
+
+def send(message):
+    with out_lock:
+        sys.stdout.write(json.dumps(message, ensure_ascii=False) + "\n")
+        sys.stdout.flush()
+
+
+def log(message):
+    print(message, file=sys.stderr, flush=True)
+
+
+def is_cancelled(request_id):
+    with cancel_lock:
+        return request_id in cancelled
+
+
+class CancelCriteria(StoppingCriteria):
+    """Прерывает генерацию, если запрос отменен вызывающей стороной"""
+
+    def __init__(self, request_id):
+        self.request_id = request_id
+
+    def __call__(self, input_ids, scores, **kwargs):
+        return is_cancelled(self.request_id)
+
+
+class StopStringsCriteria(StoppingCriteria):
+    """Останавливает генерацию, когда в ответе появилась одна из стоп-строк"""
+
+    def __init__(self, stop_strings, prompt_tokens):
+        self.stop_strings = stop_strings
+        self.prompt_tokens = prompt_tokens
+
+    def __call__(self, input_ids, scores, **kwargs):
+        text = tokenizer.decode(input_ids[0][self.prompt_tokens:], skip_special_tokens=True)
+        return any(stop in text for stop in self.stop_strings)
+
+
+class PenaltyProcessor(LogitsProcessor):
+    """Частотный штраф и штраф присутствия по токенам ответа, как в API OpenAI"""
+
+    def __init__(self, frequency, presence, prompt_tokens):
+        self.frequency = frequency
+        self.presence = presence
+        self.prompt_tokens = prompt_tokens
+
+    def __call__(self, input_ids, scores):
+        for row, ids in enumerate(input_ids):
+            generated = ids[self.prompt_tokens:]
+            if len(generated) == 0:
+                continue
+            counts = torch.bincount(generated, minlength=scores.shape[-1])[:scores.shape[-1]].to(scores.dtype)
+            scores[row] -= counts * self.frequency + (counts > 0).to(scores.dtype) * self.presence
+        return scores
+
+
+def reader():
+    """Читает запросы из stdin; отмена обрабатывается сразу, генерация - по очереди"""
+    for line in sys.stdin:
+        line = line.strip()
+        if not line:
+            continue
+        try:
+            message = json.loads(line)
+        except ValueError:
+            log(f"Неверный запрос: {line[:200]}")
+            continue
+
+        if message.get("type") == "cancel":
+            with cancel_lock:
+                cancelled.add(message.get("id"))
+        else:
+            requests.put(message)
+
+    # stdin закрыт - процесс завершается после текущего запроса
+    requests.put(None)
+
+
+def generate(request):
+    prompt = request["prompt"]
+    if request.get("seed"):
+        torch.manual_seed(request["seed"])
+
+    encoded = tokenizer(prompt, return_tensors="pt").to(model.device)
+    prompt_tokens = encoded["input_ids"].shape[1]
+
+    stop_strings = [stop for stop in request.get("stop_tokens") or [] if stop]
+    criteria = [CancelCriteria(request["id"])]
+    if stop_strings:
+        criteria.append(StopStringsCriteria(stop_strings, prompt_tokens))
+
+    kwargs = {
+        "max_new_tokens": request.get("max_tokens") or 512,
+        "do_sample": True,
+        "pad_token_id": tokenizer.eos_token_id,
+        "stopping_criteria": StoppingCriteriaList(criteria),
+    }
+    if request.get("temperature"):
+        kwargs["temperature"] = request["temperature"]
+    if request.get("top_p"):
+        kwargs["top_p"] = request["top_p"]
+    if request.get("top_k"):
+        kwargs["top_k"] = request["top_k"]
+    if request.get("min_p"):
+        kwargs["min_p"] = request["min_p"]
+    if request.get("repetition_penalty"):
+        kwargs["repetition_penalty"] = request["repetition_penalty"]
+    if request.get("frequency_penalty") or request.get("presence_penalty"):
+        kwargs["logits_processor"] = LogitsProcessorList([PenaltyProcessor(
+            request.get("frequency_penalty") or 0, request.get("presence_penalty") or 0, prompt_tokens)])
+
+    with torch.no_grad():
+        output = model.generate(**encoded, **kwargs)
+
+    new_tokens = output[0][prompt_tokens:]
+    text = tokenizer.decode(new_tokens, skip_special_tokens=True)
+
+    # Обрезаем по первой стоп-строке
+    for stop_token in stop_strings:
+        if stop_token in text:
+            text = text.split(stop_token)[0]
+
+    return {
+        "text": text,
+        "prompt_tokens": prompt_tokens,
+        "tokens_used": prompt_tokens + len(new_tokens),
+    }
+
+
+MODEL_PATH = os.environ.get("MODEL_PATH", "")
+if not MODEL_PATH:
+    log("MODEL_PATH не установлен")
+    sys.exit(1)
+
+log(f"Загрузка модели из {MODEL_PATH}")
+torch.set_default_dtype(torch.float32)
+tokenizer = AutoTokenizer.from_pretrained(MODEL_PATH)
+model = AutoModelForCausalLM.from_pretrained(MODEL_PATH, torch_dtype=torch.float32)
+model.eval()
+
+threading.Thread(target=reader, daemon=True).start()
+send({"type": "ready"})
+log("Модель загружена, рабочий процесс готов")
+
+while True:
+    request = requests.get()
+    if request is None:
+        break
+
+    request_id = request.get("id")
+    result = {"type": "result", "id": request_id}
+    start_time = time.time()
+
+    if not is_cancelled(request_id):
+        try:
+            result.update(generate(request))
+        except Exception as e:
+            traceback.print_exc()
+            result["error"] = str(e)
+
+    if is_cancelled(request_id):
+        result["cancelled"] = True
+        with cancel_lock:
+            cancelled.discard(request_id)
+
+    result["generated_in"] = time.time() - start_time
+    send(result)
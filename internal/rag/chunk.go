@@ -0,0 +1,76 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Chunk представляет фрагмент документа с номерами строк для цитирования
+type Chunk struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`     // Путь относительно папки документов
+	StartLine int       `json:"start_line"` // С единицы
+	EndLine   int       `json:"end_line"`
+	Text      string    `json:"text"`
+	Embedding []float32 `json:"embedding,omitempty"`
+}
+
+// splitChunks разбивает текст на фрагменты по границам строк. Фрагмент
+// содержит не больше size символов (кроме случая одной длинной строки),
+// соседние фрагменты перекрываются примерно на overlap символов
+func splitChunks(source, text string, size, overlap int) []Chunk {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var chunks []Chunk
+	start := 0
+	for start < len(lines) {
+		// Набираем строки, пока фрагмент не превысит размер
+		end := start
+		length := 0
+		for end < len(lines) {
+			lineLength := len([]rune(lines[end])) + 1
+			if end > start && length+lineLength > size {
+				break
+			}
+			length += lineLength
+			end++
+		}
+
+		// Пустые строки по краям не входят в диапазон цитирования
+		first, last := start, end
+		for first < last && strings.TrimSpace(lines[first]) == "" {
+			first++
+		}
+		for last > first && strings.TrimSpace(lines[last-1]) == "" {
+			last--
+		}
+
+		if first < last {
+			chunkText := strings.TrimSpace(strings.Join(lines[first:last], "\n"))
+			chunks = append(chunks, Chunk{
+				ID:        fmt.Sprintf("%s#%d", source, len(chunks)+1),
+				Source:    source,
+				StartLine: first + 1,
+				EndLine:   last,
+				Text:      chunkText,
+			})
+		}
+
+		if end >= len(lines) {
+			break
+		}
+
+		// Следующий фрагмент начинается с последних строк текущего
+		next := end
+		for back := 0; next-1 > start; {
+			back += len([]rune(lines[next-1])) + 1
+			if back > overlap {
+				break
+			}
+			next--
+		}
+		start = next
+	}
+
+	return chunks
+}
@@ -0,0 +1,98 @@
+package rag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Embedder вычисляет векторные представления текстов
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// HTTPEmbedder получает эмбеддинги от сервера инференса (POST /v1/embeddings)
+type HTTPEmbedder struct {
+	url        string
+	httpClient *http.Client
+}
+
+// embeddingsRequest - тело запроса к эндпоинту эмбеддингов
+type embeddingsRequest struct {
+	Input []string `json:"input"`
+}
+
+// embeddingsResponse - ответ эндпоинта эмбеддингов
+type embeddingsResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// NewHTTPEmbedder создает клиента эндпоинта эмбеддингов
+func NewHTTPEmbedder(url string) *HTTPEmbedder {
+	return &HTTPEmbedder{
+		url: url,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// Embed отправляет тексты на сервер и возвращает их эмбеддинги
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(embeddingsRequest{Input: texts})
+	if err != nil {
+		return nil, i18n.WrapError(err, "rag.embed_request").WithKind(ErrEmbeddings)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, i18n.WrapError(err, "rag.embed_request").WithKind(ErrEmbeddings)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, i18n.WrapError(err, "rag.embed_request").WithKind(ErrEmbeddings)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return nil, i18n.NewError("rag.embed_status", resp.StatusCode, string(data)).WithKind(ErrEmbeddings)
+	}
+
+	var result embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, i18n.WrapError(err, "rag.embed_decode").WithKind(ErrEmbeddings)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, i18n.NewError("rag.embed_count", len(result.Embeddings), len(texts)).WithKind(ErrEmbeddings)
+	}
+
+	return result.Embeddings, nil
+}
+
+// cosine возвращает косинусное сходство векторов
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
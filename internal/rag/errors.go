@@ -0,0 +1,11 @@
+package rag
+
+import "errors"
+
+// Виды ошибок RAG. Модель при них отвечает без фрагментов документов
+var (
+	ErrCorruptIndex    = errors.New("rag: corrupt index")
+	ErrIndexIO         = errors.New("rag: index read or write failed")
+	ErrDocsUnavailable = errors.New("rag: documents unavailable")
+	ErrEmbeddings      = errors.New("rag: embeddings request failed")
+)
@@ -0,0 +1,471 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"smollm-sandbox/internal/config"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/search"
+	"smollm-sandbox/internal/storage"
+)
+
+const (
+	// Версия формата файла индекса; индекс другой версии перестраивается
+	RAG_INDEX_VERSION = 1
+
+	// Файлы индекса в директории RAG_DIR корня хранилища
+	RAG_DIR       = "rag"
+	RAG_FILE      = "index.json"
+	RAG_LOCK_FILE = ".lock"
+
+	// Файлы больше этого размера не индексируются
+	MAX_DOCUMENT_SIZE = 1024 * 1024
+
+	// Количество фрагментов в одном запросе к серверу эмбеддингов
+	EMBED_BATCH_SIZE = 16
+
+	// Фрагменты с меньшим косинусным сходством считаются нерелевантными
+	MIN_SIMILARITY = 0.1
+)
+
+// fileEntry описывает проиндексированный файл
+type fileEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Chunks  []Chunk   `json:"chunks"`
+}
+
+// indexData - содержимое файла индекса
+type indexData struct {
+	Version      int                   `json:"version"`
+	ChunkSize    int                   `json:"chunk_size"`
+	ChunkOverlap int                   `json:"chunk_overlap"`
+	Files        map[string]*fileEntry `json:"files"` // Относительный путь -> файл
+}
+
+// IngestReport содержит итоги индексации папки документов
+type IngestReport struct {
+	DocsDir  string `json:"docs_dir"`
+	Files    int    `json:"files"`    // Всего файлов в индексе
+	Updated  int    `json:"updated"`  // Новых и измененных файлов
+	Removed  int    `json:"removed"`  // Удаленных из индекса файлов
+	Chunks   int    `json:"chunks"`   // Всего фрагментов в индексе
+	Embedded bool   `json:"embedded"` // Есть ли эмбеддинги у всех фрагментов
+}
+
+// Index хранит фрагменты документов и подбирает их для запросов. При наличии
+// эндпоинта эмбеддингов используется косинусное сходство, иначе BM25
+type Index struct {
+	logger   *logging.Logger
+	fs       *storage.FileSystem
+	cfg      config.RAGConfig
+	docsDir  string
+	dir      string
+	embedder Embedder
+
+	mu       sync.Mutex
+	cached   *indexData
+	cachedAt time.Time
+}
+
+// NewIndex создает индекс документов в директории RAG_DIR хранилища
+func NewIndex(fs *storage.FileSystem, cfg config.RAGConfig) *Index {
+	logger := logging.NewLogger()
+
+	dir := filepath.Join(fs.GetRootDir(), RAG_DIR)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Error("Failed to create RAG directory: %v", err)
+	}
+
+	ix := &Index{
+		logger:  logger,
+		fs:      fs,
+		cfg:     cfg,
+		docsDir: config.ExpandPath(cfg.DocsDir),
+		dir:     dir,
+	}
+	if cfg.EmbeddingsURL != "" {
+		ix.embedder = NewHTTPEmbedder(cfg.EmbeddingsURL)
+	}
+
+	return ix
+}
+
+// DocsDir возвращает папку с документами
+func (ix *Index) DocsDir() string {
+	return ix.docsDir
+}
+
+// Ingest индексирует новые и измененные файлы папки документов и удаляет
+// из индекса отсутствующие. Неизмененные файлы повторно не обрабатываются
+func (ix *Index) Ingest(ctx context.Context) (*IngestReport, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	lock, err := storage.LockFile(filepath.Join(ix.dir, RAG_LOCK_FILE))
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	data, err := ix.load()
+	if err != nil {
+		ix.logger.Warn("RAG index is unreadable, starting a new one: %v", err)
+		data = ix.newIndexData()
+	}
+	// При смене параметров разбиения все файлы разбиваются заново
+	if data.ChunkSize != ix.cfg.ChunkSize || data.ChunkOverlap != ix.cfg.ChunkOverlap {
+		data = ix.newIndexData()
+	}
+
+	report := &IngestReport{DocsDir: ix.docsDir}
+	seen := make(map[string]bool)
+
+	err = filepath.WalkDir(ix.docsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			// Скрытые директории (.git и т.п.) пропускаем
+			if path != ix.docsDir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !ix.acceptFile(entry.Name()) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.Size() > MAX_DOCUMENT_SIZE {
+			return nil
+		}
+
+		rel, err := filepath.Rel(ix.docsDir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+
+		existing, ok := data.Files[rel]
+		if ok && existing.Size == info.Size() && existing.ModTime.Equal(info.ModTime()) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			ix.logger.Warn("Failed to read document %s: %v", rel, err)
+			return nil
+		}
+
+		data.Files[rel] = &fileEntry{
+			ModTime: info.ModTime(),
+			Size:    info.Size(),
+			Chunks:  splitChunks(rel, string(content), ix.cfg.ChunkSize, ix.cfg.ChunkOverlap),
+		}
+		report.Updated++
+		return nil
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, i18n.WrapError(err, "rag.docs_walk", ix.docsDir).WithKind(ErrDocsUnavailable)
+	}
+
+	for rel := range data.Files {
+		if !seen[rel] {
+			delete(data.Files, rel)
+			report.Removed++
+		}
+	}
+
+	embedded, embeddedNow := ix.embedMissing(ctx, data)
+	report.Embedded = embedded
+
+	for _, file := range data.Files {
+		report.Files++
+		report.Chunks += len(file.Chunks)
+	}
+
+	if report.Updated == 0 && report.Removed == 0 && embeddedNow == 0 {
+		return report, nil
+	}
+	if err := ix.save(data); err != nil {
+		return nil, err
+	}
+
+	ix.logger.Info("RAG index updated: %d files, %d chunks (%d updated, %d removed)",
+		report.Files, report.Chunks, report.Updated, report.Removed)
+
+	return report, nil
+}
+
+// embedMissing вычисляет эмбеддинги фрагментов, у которых их нет. Возвращает
+// признак наличия эмбеддингов у всех фрагментов и число вычисленных
+func (ix *Index) embedMissing(ctx context.Context, data *indexData) (bool, int) {
+	if ix.embedder == nil {
+		return false, 0
+	}
+
+	var pending []*Chunk
+	for _, file := range data.Files {
+		for i := range file.Chunks {
+			if len(file.Chunks[i].Embedding) == 0 {
+				pending = append(pending, &file.Chunks[i])
+			}
+		}
+	}
+
+	embedded := 0
+	for start := 0; start < len(pending); start += EMBED_BATCH_SIZE {
+		end := min(start+EMBED_BATCH_SIZE, len(pending))
+
+		texts := make([]string, 0, end-start)
+		for _, chunk := range pending[start:end] {
+			texts = append(texts, chunk.Text)
+		}
+
+		embeddings, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			// Без эмбеддингов поиск работает через BM25
+			ix.logger.Warn("Embeddings are unavailable, falling back to BM25: %v", err)
+			return false, embedded
+		}
+		for i, chunk := range pending[start:end] {
+			chunk.Embedding = embeddings[i]
+		}
+		embedded += end - start
+	}
+
+	return true, embedded
+}
+
+// Retrieve возвращает до TopK фрагментов, наиболее подходящих к запросу
+func (ix *Index) Retrieve(ctx context.Context, query string) ([]model.Passage, error) {
+	data, err := ix.current()
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []*Chunk
+	embedded := true
+	for _, file := range data.Files {
+		for i := range file.Chunks {
+			chunks = append(chunks, &file.Chunks[i])
+			if len(file.Chunks[i].Embedding) == 0 {
+				embedded = false
+			}
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	var scores []float64
+	if embedded && ix.embedder != nil {
+		vectors, err := ix.embedder.Embed(ctx, []string{query})
+		if err == nil {
+			scores = make([]float64, len(chunks))
+			for i, chunk := range chunks {
+				if similarity := cosine(vectors[0], chunk.Embedding); similarity >= MIN_SIMILARITY {
+					scores[i] = similarity
+				}
+			}
+		} else {
+			ix.logger.Warn("Failed to embed query, falling back to BM25: %v", err)
+		}
+	}
+	if scores == nil {
+		scores = bm25Scores(chunks, query)
+	}
+
+	order := make([]int, 0, len(chunks))
+	for i := range chunks {
+		if scores[i] > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if scores[order[a]] != scores[order[b]] {
+			return scores[order[a]] > scores[order[b]]
+		}
+		return chunks[order[a]].ID < chunks[order[b]].ID
+	})
+
+	topK := ix.cfg.TopK
+	if topK <= 0 || topK > len(order) {
+		topK = len(order)
+	}
+
+	passages := make([]model.Passage, 0, topK)
+	for _, i := range order[:topK] {
+		passages = append(passages, model.Passage{
+			Source:    chunks[i].Source,
+			StartLine: chunks[i].StartLine,
+			EndLine:   chunks[i].EndLine,
+			Text:      chunks[i].Text,
+			Score:     scores[i],
+		})
+	}
+
+	return passages, nil
+}
+
+// bm25Scores ранжирует фрагменты по BM25 без использования модели
+func bm25Scores(chunks []*Chunk, query string) []float64 {
+	terms := search.Tokenize(query)
+	scores := make([]float64, len(chunks))
+	if len(terms) == 0 {
+		return scores
+	}
+
+	freqs := make([]map[string]int, len(chunks))
+	lengths := make([]int, len(chunks))
+	docFreq := make(map[string]int)
+	totalLength := 0
+	for i, chunk := range chunks {
+		chunkTerms := search.Tokenize(chunk.Text)
+		lengths[i] = len(chunkTerms)
+		totalLength += lengths[i]
+
+		freqs[i] = make(map[string]int)
+		for _, term := range chunkTerms {
+			if freqs[i][term] == 0 {
+				docFreq[term]++
+			}
+			freqs[i][term]++
+		}
+	}
+	avgLength := float64(totalLength) / float64(len(chunks))
+	if avgLength == 0 {
+		return scores
+	}
+
+	n := float64(len(chunks))
+	for _, term := range terms {
+		df := float64(docFreq[term])
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for i := range chunks {
+			freq := float64(freqs[i][term])
+			if freq == 0 {
+				continue
+			}
+			norm := 1 - search.BM25_B + search.BM25_B*float64(lengths[i])/avgLength
+			scores[i] += idf * freq * (search.BM25_K1 + 1) / (freq + search.BM25_K1*norm)
+		}
+	}
+
+	return scores
+}
+
+// current возвращает индекс, перечитывая файл только после его изменения
+func (ix *Index) current() (*indexData, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	info, err := os.Stat(ix.path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return ix.newIndexData(), nil
+	}
+	if err != nil {
+		return nil, i18n.WrapError(err, "rag.index_read").WithKind(ErrIndexIO)
+	}
+	if ix.cached != nil && info.ModTime().Equal(ix.cachedAt) {
+		return ix.cached, nil
+	}
+
+	// Файл индекса заменяется атомарно, поэтому читаем его без блокировки
+	data, err := ix.load()
+	if err != nil {
+		return nil, err
+	}
+	ix.cached = data
+	ix.cachedAt = info.ModTime()
+
+	return data, nil
+}
+
+// acceptFile проверяет, подходит ли расширение файла для индексации
+func (ix *Index) acceptFile(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, allowed := range ix.cfg.Extensions {
+		if strings.ToLower(allowed) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// load читает файл индекса. Отсутствующий индекс считается пустым
+func (ix *Index) load() (*indexData, error) {
+	raw, err := ix.fs.ReadFile(ix.path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return ix.newIndexData(), nil
+	}
+	if err != nil {
+		return nil, i18n.WrapError(err, "rag.index_read").WithKind(ErrIndexIO)
+	}
+
+	data := ix.newIndexData()
+	if err := json.Unmarshal(raw, data); err != nil {
+		return nil, i18n.WrapError(err, "rag.index_decode").WithKind(ErrCorruptIndex)
+	}
+	if data.Version != RAG_INDEX_VERSION {
+		return nil, i18n.NewError("rag.index_version", data.Version).WithKind(ErrCorruptIndex)
+	}
+
+	return data, nil
+}
+
+// save атомарно записывает файл индекса
+func (ix *Index) save(data *indexData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return i18n.WrapError(err, "rag.index_encode").WithKind(ErrIndexIO)
+	}
+
+	if err := ix.fs.WriteFileAtomic(ix.path(), raw); err != nil {
+		return i18n.WrapError(err, "rag.index_write").WithKind(ErrIndexIO)
+	}
+	return nil
+}
+
+// path возвращает путь к файлу индекса
+func (ix *Index) path() string {
+	return filepath.Join(ix.dir, RAG_FILE)
+}
+
+// newIndexData создает пустой индекс с текущими параметрами разбиения
+func (ix *Index) newIndexData() *indexData {
+	return &indexData{
+		Version:      RAG_INDEX_VERSION,
+		ChunkSize:    ix.cfg.ChunkSize,
+		ChunkOverlap: ix.cfg.ChunkOverlap,
+		Files:        make(map[string]*fileEntry),
+	}
+}
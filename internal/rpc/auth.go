@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyContextKey - ключ номера ключа доступа запроса в контексте
+type apiKeyContextKey struct{}
+
+// unaryInterceptor пропускает одиночные вызовы с верным ключом доступа
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor пропускает потоковые вызовы с верным ключом доступа
+func (s *Server) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticate проверяет ключ из метаданных authorization: Bearer <ключ> и
+// сохраняет его номер в контексте. Без настроенных ключей проверка отключена
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if len(s.apiKeys) == 0 {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		key, ok := strings.CutPrefix(value, "Bearer ")
+		if !ok {
+			continue
+		}
+		for i, candidate := range s.apiKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+				return context.WithValue(ctx, apiKeyContextKey{}, i), nil
+			}
+		}
+	}
+
+	s.logger.Warn("Unauthorized gRPC call to %s", method)
+	return nil, status.Error(codes.Unauthenticated, i18n.T("rpc.unauthorized"))
+}
+
+// requestOwner возвращает пользователя запроса для очереди генерации:
+// номер ключа доступа и пользователя, переданного клиентом
+func requestOwner(ctx context.Context, user string) string {
+	owner := "grpc"
+	if index, ok := ctx.Value(apiKeyContextKey{}).(int); ok {
+		owner = fmt.Sprintf("grpc:key%d", index+1)
+	}
+	if user != "" {
+		owner += ":" + user
+	}
+	return owner
+}
+
+// authenticatedStream подменяет контекст потока контекстом с ключом доступа
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+	"smollm-sandbox/pkg/api/smollmv1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// generationFromProto преобразует параметры генерации запроса. Незаданные
+// параметры оставляют параметры сессии и модели
+func generationFromProto(opts *smollmv1.GenerationOptions) model.GenerationOptions {
+	if opts == nil {
+		return model.GenerationOptions{}
+	}
+
+	return model.GenerationOptions{
+		Temperature:       opts.Temperature,
+		TopP:              opts.TopP,
+		TopK:              convertInt[int](opts.TopK),
+		MinP:              opts.MinP,
+		RepetitionPenalty: opts.RepetitionPenalty,
+		FrequencyPenalty:  opts.FrequencyPenalty,
+		PresencePenalty:   opts.PresencePenalty,
+		Stop:              opts.Stop,
+		MaxTokens:         convertInt[int](opts.MaxTokens),
+		Seed:              convertInt[int](opts.Seed),
+	}
+}
+
+// generationToProto преобразует параметры генерации для ответа
+func generationToProto(opts model.GenerationOptions) *smollmv1.GenerationOptions {
+	return &smollmv1.GenerationOptions{
+		Temperature:       opts.Temperature,
+		TopP:              opts.TopP,
+		TopK:              convertInt[int32](opts.TopK),
+		MinP:              opts.MinP,
+		RepetitionPenalty: opts.RepetitionPenalty,
+		FrequencyPenalty:  opts.FrequencyPenalty,
+		PresencePenalty:   opts.PresencePenalty,
+		Stop:              opts.Stop,
+		MaxTokens:         convertInt[int32](opts.MaxTokens),
+		Seed:              convertInt[int64](opts.Seed),
+	}
+}
+
+// convertInt преобразует необязательное целое в другой целый тип
+func convertInt[To, From int | int32 | int64](value *From) *To {
+	if value == nil {
+		return nil
+	}
+	converted := To(*value)
+	return &converted
+}
+
+// resultToProto преобразует результат генерации
+func resultToProto(result *model.ProcessResult) *smollmv1.GenerateResult {
+	response := &smollmv1.GenerateResult{
+		Text:             result.Text,
+		PromptTokens:     int32(result.PromptTokens),
+		CompletionTokens: int32(result.TokensUsed - result.PromptTokens),
+		LatencyMs:        result.Latency.Milliseconds(),
+		Cached:           result.Cached,
+		Generation:       generationToProto(result.Generation),
+		MessageId:        result.MessageID,
+	}
+	for _, passage := range result.Citations {
+		response.Citations = append(response.Citations, &smollmv1.Passage{
+			Source:    passage.Source,
+			StartLine: int32(passage.StartLine),
+			EndLine:   int32(passage.EndLine),
+			Text:      passage.Text,
+			Score:     passage.Score,
+		})
+	}
+	return response
+}
+
+// messagesFromProto преобразует сообщения запроса
+func messagesFromProto(messages []*smollmv1.Message) []model.Message {
+	result := make([]model.Message, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, model.Message{
+			ID:        msg.Id,
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Timestamp: msg.Timestamp.AsTime(),
+		})
+	}
+	return result
+}
+
+// messagesToProto преобразует сообщения сессии
+func messagesToProto(messages []model.Message) []*smollmv1.Message {
+	result := make([]*smollmv1.Message, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, &smollmv1.Message{
+			Id:        msg.ID,
+			Role:      msg.Role,
+			Content:   msg.Content,
+			Timestamp: timestamppb.New(msg.Timestamp),
+		})
+	}
+	return result
+}
+
+// sessionToProto описывает сессию name по ее контексту
+func sessionToProto(name string, session *model.Context, withMessages bool) *smollmv1.Session {
+	response := &smollmv1.Session{
+		Name:         name,
+		MessageCount: int32(len(session.ActiveBranch())),
+		CreatedAt:    timestamppb.New(session.Metadata.CreatedAt),
+		UpdatedAt:    timestamppb.New(session.Metadata.UpdatedAt),
+	}
+	response.Profile, _ = session.GetProperty(model.META_PROFILE)
+	if withMessages {
+		response.Messages = messagesToProto(session.ActiveBranch())
+	}
+	return response
+}
+
+// sessionMetaToProto описывает сессию по метаданным хранилища
+func sessionMetaToProto(meta storage.SessionMeta) *smollmv1.Session {
+	return &smollmv1.Session{
+		Name:         meta.Name,
+		MessageCount: int32(meta.MessageCount),
+		CreatedAt:    timestamppb.New(meta.CreatedAt),
+		UpdatedAt:    timestamppb.New(meta.UpdatedAt),
+	}
+}
+
+// executeResultToProto преобразует результат выполнения кода. Причина
+// неудачи передается текстом на языке сервера
+func executeResultToProto(result *sandbox.ExecuteResult, language string) *smollmv1.ExecuteResult {
+	response := &smollmv1.ExecuteResult{
+		Success:       result.Success,
+		ExitCode:      int32(result.ExitCode),
+		Output:        result.Output,
+		Error:         result.Error,
+		Compiled:      result.Compiled,
+		CompileTimeMs: result.CompileTime.Milliseconds(),
+		ExecuteTimeMs: result.ExecuteTime.Milliseconds(),
+		Language:      language,
+	}
+	if result.Failure != nil {
+		response.Failure = i18n.LocalizeError(result.Failure)
+	}
+	return response
+}
+
+// feedbackType преобразует тип обратной связи; неуказанный тип - оценка ответа модели
+func feedbackType(kind smollmv1.FeedbackType) feedback.FeedbackType {
+	switch kind {
+	case smollmv1.FeedbackType_FEEDBACK_TYPE_CODE_EXECUTION:
+		return feedback.CodeExecution
+	case smollmv1.FeedbackType_FEEDBACK_TYPE_SYSTEM_ERROR:
+		return feedback.SystemError
+	case smollmv1.FeedbackType_FEEDBACK_TYPE_THINKING:
+		return feedback.Thinking
+	}
+	return feedback.ModelOutput
+}
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Домен ErrorInfo в деталях статуса; Reason содержит код сообщения ошибки
+const ERROR_DOMAIN = "smollm-sandbox"
+
+// Виды ошибок сервиса. errorCode сопоставляет их и ошибки других пакетов
+// с кодами gRPC
+var (
+	ErrInvalidRequest = errors.New("rpc: invalid request")
+	ErrInvalidOptions = errors.New("rpc: invalid options")
+	ErrUnavailable    = errors.New("rpc: service unavailable")
+)
+
+// statusError преобразует ошибку пакетов приложения в статус gRPC. Текст
+// статуса - ошибка на языке сервера, код сообщения передается в ErrorInfo
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	st := status.New(errorCode(err), i18n.LocalizeError(err))
+	if code := i18n.Code(err); code != "" {
+		if detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{Reason: code, Domain: ERROR_DOMAIN}); detailsErr == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// errorCode возвращает код статуса gRPC для вида ошибки err
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, ErrInvalidRequest),
+		errors.Is(err, model.ErrInvalidMessages),
+		errors.Is(err, model.ErrInvalidGeneration),
+		errors.Is(err, sandbox.ErrUnsupportedLanguage),
+		errors.Is(err, sandbox.ErrUnsupportedFileType),
+		errors.Is(err, sandbox.ErrFileTooLarge),
+		errors.Is(err, storage.ErrInvalidSessionName),
+		errors.Is(err, feedback.ErrInvalidRating):
+		return codes.InvalidArgument
+	case errors.Is(err, storage.ErrSessionNotFound),
+		errors.Is(err, model.ErrProfileNotFound):
+		return codes.NotFound
+	case errors.Is(err, storage.ErrSessionExists):
+		return codes.AlreadyExists
+	case errors.Is(err, storage.ErrSessionConflict):
+		return codes.Aborted
+	case errors.Is(err, storage.ErrCorruptSession),
+		errors.Is(err, storage.ErrUnsupportedVersion),
+		errors.Is(err, model.ErrStoreNotSet):
+		return codes.FailedPrecondition
+	case errors.Is(err, model.ErrQueueFull):
+		return codes.ResourceExhausted
+	case errors.Is(err, model.ErrGenerationTimeout):
+		return codes.DeadlineExceeded
+	case errors.Is(err, model.ErrCanceled),
+		errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, model.ErrModelUnavailable),
+		errors.Is(err, ErrUnavailable):
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
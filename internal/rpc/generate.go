@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/pkg/api/smollmv1"
+)
+
+// Generate генерирует ответ модели и передает в поток позиции в очереди,
+// фрагменты текста и итоговый результат. Ответ в сессии сохраняется вместе
+// с запросом; сессия, загруженная в модель (CLI, бот), при этом не меняется
+func (s *Server) Generate(req *smollmv1.GenerateRequest, stream smollmv1.SmolLM_GenerateServer) error {
+	ctx := stream.Context()
+
+	opts := model.ProcessOptions{
+		Context:      ctx,
+		Owner:        requestOwner(ctx, req.Owner),
+		SystemPrompt: req.SystemPrompt,
+		Generation:   generationFromProto(req.Generation),
+		OnQueued: func(position int) {
+			stream.Send(&smollmv1.GenerateResponse{
+				Event: &smollmv1.GenerateResponse_Queued{Queued: &smollmv1.Queued{Position: int32(position)}},
+			})
+		},
+		OnText: func(delta string) {
+			stream.Send(&smollmv1.GenerateResponse{
+				Event: &smollmv1.GenerateResponse_Delta{Delta: delta},
+			})
+		},
+	}
+	if err := opts.Generation.Validate(); err != nil {
+		return statusError(err)
+	}
+
+	var result *smollmv1.GenerateResult
+	var err error
+	if req.Session != "" {
+		result, err = s.generateInSession(req, opts)
+	} else {
+		result, err = s.generate(req, opts)
+	}
+	if err != nil {
+		return statusError(err)
+	}
+
+	return stream.Send(&smollmv1.GenerateResponse{
+		Event: &smollmv1.GenerateResponse_Result{Result: result},
+	})
+}
+
+// generate отвечает на диалог из запроса без сохранения
+func (s *Server) generate(req *smollmv1.GenerateRequest, opts model.ProcessOptions) (*smollmv1.GenerateResult, error) {
+	messages := messagesFromProto(req.Messages)
+	if req.Prompt != "" {
+		messages = append(messages, model.Message{Role: "user", Content: req.Prompt, Timestamp: time.Now()})
+	}
+
+	result, err := s.model.Complete(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resultToProto(result), nil
+}
+
+// generateInSession отвечает на prompt в сохраненной сессии
+func (s *Server) generateInSession(req *smollmv1.GenerateRequest, opts model.ProcessOptions) (*smollmv1.GenerateResult, error) {
+	if req.Prompt == "" {
+		return nil, i18n.NewError("rpc.no_prompt").WithKind(ErrInvalidRequest)
+	}
+	if len(req.Messages) > 0 {
+		return nil, i18n.NewError("rpc.session_messages").WithKind(ErrInvalidRequest)
+	}
+
+	if s.sessions == nil {
+		return nil, i18n.NewError("model.store_not_set").WithKind(model.ErrStoreNotSet)
+	}
+
+	result, err := s.model.CompleteSessionIn(s.sessions, req.Session, req.Prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+	return resultToProto(result), nil
+}
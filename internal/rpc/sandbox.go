@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/pkg/api/smollmv1"
+)
+
+// ExecuteCode выполняет фрагмент кода в песочнице. Ошибка компиляции или
+// выполнения программы - успешный ответ с success = false
+func (s *Server) ExecuteCode(ctx context.Context, req *smollmv1.ExecuteCodeRequest) (*smollmv1.ExecuteResult, error) {
+	if err := s.requireSandbox(); err != nil {
+		return nil, err
+	}
+	if req.Code == "" {
+		return nil, statusError(i18n.NewError("rpc.no_code").WithKind(ErrInvalidRequest))
+	}
+
+	s.sandboxMutex.Lock()
+	result, err := s.sandbox.RunCode(req.Code, req.Language)
+	s.sandboxMutex.Unlock()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return executeResultToProto(result, req.Language), nil
+}
+
+// ExecuteFile выполняет переданный файл в песочнице. Файл записывается во
+// временную директорию под своим именем: по расширению выбирается язык, а
+// компилятор Go требует имя с .go. Пути из запроса не используются
+func (s *Server) ExecuteFile(ctx context.Context, req *smollmv1.ExecuteFileRequest) (*smollmv1.ExecuteResult, error) {
+	if err := s.requireSandbox(); err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(req.Filename)
+	if name == "." || name == string(filepath.Separator) || filepath.Ext(name) == "" {
+		return nil, statusError(i18n.NewError("rpc.invalid_filename", req.Filename).WithKind(ErrInvalidRequest))
+	}
+
+	dir, err := os.MkdirTemp("", "smollm-rpc-")
+	if err != nil {
+		return nil, statusError(i18n.WrapError(err, "rpc.temp_file"))
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, req.Content, 0644); err != nil {
+		return nil, statusError(i18n.WrapError(err, "rpc.temp_file"))
+	}
+
+	s.sandboxMutex.Lock()
+	result, err := s.sandbox.RunFile(path)
+	s.sandboxMutex.Unlock()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return executeResultToProto(result, strings.TrimPrefix(filepath.Ext(name), ".")), nil
+}
+
+// requireSandbox возвращает ошибку, если песочница отключена
+func (s *Server) requireSandbox() error {
+	if s.sandbox == nil {
+		return statusError(i18n.NewError("rpc.sandbox_disabled").WithKind(ErrUnavailable))
+	}
+	return nil
+}
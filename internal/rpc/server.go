@@ -0,0 +1,97 @@
+package rpc
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+	"smollm-sandbox/pkg/api/smollmv1"
+
+	"google.golang.org/grpc"
+)
+
+// Options содержит зависимости сервиса. Модель обязательна, операции с
+// другой отсутствующей зависимостью (nil) возвращают ошибку
+type Options struct {
+	Model    *model.SmolLM
+	Sandbox  *sandbox.Environment
+	Sessions *storage.SessionManager
+	Feedback *feedback.Collector
+	APIKeys  []string // Ключи доступа; пустой список отключает проверку
+}
+
+// Server реализует gRPC сервис smollm.v1.SmolLM поверх модели, песочницы,
+// хранилища сессий и сборщика обратной связи
+type Server struct {
+	smollmv1.UnimplementedSmolLMServer
+
+	logger   *logging.Logger
+	model    *model.SmolLM
+	sandbox  *sandbox.Environment
+	sessions *storage.SessionManager
+	feedback *feedback.Collector
+	apiKeys  []string
+
+	sandboxMutex sync.Mutex // Песочница использует общую рабочую директорию
+}
+
+// NewServer создает сервис с зависимостями opts. Модель обязательна;
+// хранилище сессий передается ей при каждом вызове, поэтому хранилище самой
+// модели не меняется
+func NewServer(opts Options) (*Server, error) {
+	if opts.Model == nil {
+		return nil, i18n.NewError("rpc.no_model").WithKind(ErrInvalidOptions)
+	}
+	if slices.Contains(opts.APIKeys, "") {
+		return nil, i18n.NewError("rpc.empty_api_key").WithKind(ErrInvalidOptions)
+	}
+
+	return &Server{
+		logger:   logging.NewLogger(),
+		model:    opts.Model,
+		sandbox:  opts.Sandbox,
+		sessions: opts.Sessions,
+		feedback: opts.Feedback,
+		apiKeys:  slices.Clone(opts.APIKeys),
+	}, nil
+}
+
+// NewGRPCServer создает gRPC сервер с проверкой ключей доступа и
+// зарегистрированным сервисом
+func (s *Server) NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+
+	server := grpc.NewServer(opts...)
+	smollmv1.RegisterSmolLMServer(server, s)
+	return server
+}
+
+// SubmitFeedback сохраняет оценку, связанную с сессией и сообщением, если они указаны
+func (s *Server) SubmitFeedback(ctx context.Context, req *smollmv1.SubmitFeedbackRequest) (*smollmv1.SubmitFeedbackResponse, error) {
+	if s.feedback == nil {
+		return nil, statusError(i18n.NewError("rpc.feedback_disabled").WithKind(ErrUnavailable))
+	}
+
+	metadata := map[string]interface{}{}
+	if req.Session != "" {
+		metadata[feedback.META_SESSION_ID] = req.Session
+	}
+	if req.MessageId != "" {
+		metadata[feedback.META_MESSAGE_ID] = req.MessageId
+	}
+
+	id, err := s.feedback.AddFeedback(feedbackType(req.Type), req.Content, int(req.Rating), req.Comment, metadata)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &smollmv1.SubmitFeedbackResponse{Id: id}, nil
+}
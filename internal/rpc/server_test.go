@@ -0,0 +1,431 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/model/modeltest"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+	"smollm-sandbox/pkg/api/smollmv1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Ключ доступа тестового сервиса
+const TEST_API_KEY = "secret"
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	os.Exit(m.Run())
+}
+
+// testService - сервис на bufconn с моделью на поддельном сервере модели
+type testService struct {
+	client   smollmv1.SmolLMClient
+	model    *model.SmolLM
+	backend  *modeltest.Server
+	sessions *storage.SessionManager
+	feedback *feedback.Collector
+}
+
+// newTestService запускает сервис с сессиями и обратной связью. configure
+// меняет зависимости перед созданием сервиса
+func newTestService(t *testing.T, configure func(opts *Options)) *testService {
+	t.Helper()
+	root := t.TempDir()
+	m, backend := modeltest.NewModel(t)
+
+	opts := Options{
+		Model:    m,
+		Sessions: storage.NewSessionManager(storage.NewFileSystem(root), "sessions"),
+		Feedback: feedback.NewCollector(filepath.Join(root, "feedback")),
+	}
+	if configure != nil {
+		configure(&opts)
+	}
+
+	server, err := NewServer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := server.NewGRPCServer()
+	listener := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &testService{
+		client:   smollmv1.NewSmolLMClient(conn),
+		model:    m,
+		backend:  backend,
+		sessions: opts.Sessions,
+		feedback: opts.Feedback,
+	}
+}
+
+// testContext возвращает контекст вызова с таймаутом теста
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// generate вызывает Generate и собирает фрагменты текста и результат
+func (s *testService) generate(ctx context.Context, req *smollmv1.GenerateRequest) (string, *smollmv1.GenerateResult, error) {
+	stream, err := s.client.Generate(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var text strings.Builder
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return text.String(), nil, nil
+		}
+		if err != nil {
+			return text.String(), nil, err
+		}
+		switch event := response.Event.(type) {
+		case *smollmv1.GenerateResponse_Delta:
+			text.WriteString(event.Delta)
+		case *smollmv1.GenerateResponse_Result:
+			return text.String(), event.Result, nil
+		}
+	}
+}
+
+// checkStatus проверяет код статуса ошибки и, если reason не пуст, код
+// сообщения в ErrorInfo
+func checkStatus(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != code {
+		t.Fatalf("error = %v, want code %s", err, code)
+	}
+	if reason == "" {
+		return
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == reason && info.Domain == ERROR_DOMAIN {
+			return
+		}
+	}
+	t.Errorf("status details = %v, want reason %s", st.Details(), reason)
+}
+
+func TestNewServer(t *testing.T) {
+	m, _ := modeltest.NewModel(t)
+
+	if _, err := NewServer(Options{}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("NewServer without model = %v, want ErrInvalidOptions", err)
+	}
+	if _, err := NewServer(Options{Model: m, APIKeys: []string{"key", ""}}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("NewServer with empty key = %v, want ErrInvalidOptions", err)
+	}
+
+	sessions := storage.NewSessionManager(storage.NewFileSystem(t.TempDir()), "sessions")
+	if _, err := NewServer(Options{Model: m, Sessions: sessions}); err != nil {
+		t.Fatal(err)
+	}
+	// Хранилище сервиса не подключается к модели
+	if _, err := m.CompleteSession("any", "Hi", model.ProcessOptions{}); !errors.Is(err, model.ErrStoreNotSet) {
+		t.Errorf("model CompleteSession = %v, want ErrStoreNotSet", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := testContext(t)
+
+	text, result, err := s.generate(ctx, &smollmv1.GenerateRequest{
+		Messages:   []*smollmv1.Message{{Role: "system", Content: "Be brief"}},
+		Prompt:     "Hi",
+		Generation: &smollmv1.GenerationOptions{Temperature: new(float64)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != modeltest.REPLY || result.GetText() != modeltest.REPLY {
+		t.Errorf("deltas = %q, result = %q", text, result.GetText())
+	}
+	if result.PromptTokens != modeltest.PROMPT_TOKENS || result.CompletionTokens != modeltest.REPLY_TOKENS {
+		t.Errorf("tokens = %d, %d", result.PromptTokens, result.CompletionTokens)
+	}
+	if result.Generation.GetTemperature() != 0 || result.Generation.GetSeed() == 0 {
+		t.Errorf("generation = %v, want temperature 0 and the seed used", result.Generation)
+	}
+
+	requests := s.backend.Requests()
+	if len(requests) != 1 || !strings.Contains(requests[0].Prompt, "Be brief") || !strings.Contains(requests[0].Prompt, "Hi") {
+		t.Errorf("model requests = %+v", requests)
+	}
+
+	_, _, err = s.generate(ctx, &smollmv1.GenerateRequest{Messages: []*smollmv1.Message{{Role: "assistant", Content: "Hi"}}})
+	checkStatus(t, err, codes.InvalidArgument, "model.complete_no_user")
+
+	s.backend.SetReply(func(model.InferenceRequest) (*model.InferenceResponse, int) {
+		return nil, 503
+	})
+	_, _, err = s.generate(ctx, &smollmv1.GenerateRequest{Prompt: "Hi"})
+	checkStatus(t, err, codes.Unavailable, "model.api_status")
+}
+
+func TestGenerateInSessionAndFeedback(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := testContext(t)
+
+	if _, err := s.client.CreateSession(ctx, &smollmv1.CreateSessionRequest{Name: "chat"}); err != nil {
+		t.Fatal(err)
+	}
+	_, result, err := s.generate(ctx, &smollmv1.GenerateRequest{Session: "chat", Prompt: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MessageId == "" {
+		t.Fatal("result has no message id")
+	}
+
+	session, err := s.client.GetSession(ctx, &smollmv1.GetSessionRequest{Name: "chat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := session.Messages
+	if len(messages) < 2 || messages[len(messages)-2].Content != "Hi" || messages[len(messages)-1].Content != modeltest.REPLY {
+		t.Fatalf("session messages = %v", messages)
+	}
+	if last := messages[len(messages)-1]; last.Id != result.MessageId {
+		t.Errorf("reply id = %s, result message id = %s", last.Id, result.MessageId)
+	}
+
+	_, _, err = s.generate(ctx, &smollmv1.GenerateRequest{Session: "chat"})
+	checkStatus(t, err, codes.InvalidArgument, "rpc.no_prompt")
+	_, _, err = s.generate(ctx, &smollmv1.GenerateRequest{Session: "missing", Prompt: "Hi"})
+	checkStatus(t, err, codes.NotFound, "")
+
+	response, err := s.client.SubmitFeedback(ctx, &smollmv1.SubmitFeedbackRequest{
+		Rating:    5,
+		Content:   result.Text,
+		Session:   "chat",
+		MessageId: result.MessageId,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, ok := s.feedback.GetFeedback(response.Id)
+	if !ok || item.Type != feedback.ModelOutput || item.Rating != 5 {
+		t.Fatalf("feedback = %+v, %v", item, ok)
+	}
+	if id, _ := item.MetadataString(feedback.META_MESSAGE_ID); id != result.MessageId {
+		t.Errorf("feedback message id = %q, want %q", id, result.MessageId)
+	}
+
+	_, err = s.client.SubmitFeedback(ctx, &smollmv1.SubmitFeedbackRequest{Rating: 9})
+	checkStatus(t, err, codes.InvalidArgument, "")
+}
+
+func TestSessions(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := testContext(t)
+
+	created, err := s.client.CreateSession(ctx, &smollmv1.CreateSessionRequest{Name: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Name != "first" || created.Profile == "" {
+		t.Errorf("created = %v", created)
+	}
+	_, err = s.client.CreateSession(ctx, &smollmv1.CreateSessionRequest{Name: "first"})
+	checkStatus(t, err, codes.AlreadyExists, "storage.session_exists")
+	_, err = s.client.CreateSession(ctx, &smollmv1.CreateSessionRequest{Name: "second", Profile: "missing"})
+	checkStatus(t, err, codes.NotFound, "")
+
+	renamed, err := s.client.RenameSession(ctx, &smollmv1.RenameSessionRequest{Name: "first", NewName: "renamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Name != "renamed" {
+		t.Errorf("renamed = %v", renamed)
+	}
+	_, err = s.client.GetSession(ctx, &smollmv1.GetSessionRequest{Name: "first"})
+	checkStatus(t, err, codes.NotFound, "")
+
+	list, err := s.client.ListSessions(ctx, &smollmv1.ListSessionsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Sessions) != 1 || list.Sessions[0].Name != "renamed" {
+		t.Errorf("sessions = %v", list.Sessions)
+	}
+
+	if _, err := s.client.DeleteSession(ctx, &smollmv1.DeleteSessionRequest{Name: "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.client.GetSession(ctx, &smollmv1.GetSessionRequest{Name: "renamed"})
+	checkStatus(t, err, codes.NotFound, "")
+}
+
+func TestLockSessions(t *testing.T) {
+	s := newTestService(t, nil)
+	server, err := NewServer(Options{Model: s.model})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock := server.lockSessions("b", "a", "b")
+	locked := make(chan struct{})
+	go func() {
+		s.model.LockSession("b")()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("session b was not locked")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+}
+
+func TestWithoutDependencies(t *testing.T) {
+	s := newTestService(t, func(opts *Options) {
+		opts.Sessions = nil
+		opts.Feedback = nil
+	})
+	ctx := testContext(t)
+
+	_, err := s.client.ListSessions(ctx, &smollmv1.ListSessionsRequest{})
+	checkStatus(t, err, codes.FailedPrecondition, "model.store_not_set")
+	_, _, err = s.generate(ctx, &smollmv1.GenerateRequest{Session: "chat", Prompt: "Hi"})
+	checkStatus(t, err, codes.FailedPrecondition, "model.store_not_set")
+	_, err = s.client.SubmitFeedback(ctx, &smollmv1.SubmitFeedbackRequest{Rating: 5})
+	checkStatus(t, err, codes.Unavailable, "rpc.feedback_disabled")
+	_, err = s.client.ExecuteCode(ctx, &smollmv1.ExecuteCodeRequest{Language: "python", Code: "print(1)"})
+	checkStatus(t, err, codes.Unavailable, "rpc.sandbox_disabled")
+}
+
+func TestExecuteCode(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not installed")
+	}
+	s := newTestService(t, func(opts *Options) {
+		opts.Sandbox = sandbox.NewEnvironment()
+	})
+	ctx := testContext(t)
+
+	result, err := s.client.ExecuteCode(ctx, &smollmv1.ExecuteCodeRequest{Language: "python", Code: "print(6 * 7)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || strings.TrimSpace(result.Output) != "42" {
+		t.Errorf("result = %v", result)
+	}
+
+	result, err = s.client.ExecuteCode(ctx, &smollmv1.ExecuteCodeRequest{Language: "python", Code: "raise SystemExit(3)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || result.ExitCode != 3 {
+		t.Errorf("failed program result = %v", result)
+	}
+
+	result, err = s.client.ExecuteFile(ctx, &smollmv1.ExecuteFileRequest{Filename: "../main.py", Content: []byte("print('file')")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || strings.TrimSpace(result.Output) != "file" || result.Language != "py" {
+		t.Errorf("file result = %v", result)
+	}
+
+	_, err = s.client.ExecuteCode(ctx, &smollmv1.ExecuteCodeRequest{Language: "cobol", Code: "DISPLAY 1"})
+	checkStatus(t, err, codes.InvalidArgument, "")
+	_, err = s.client.ExecuteCode(ctx, &smollmv1.ExecuteCodeRequest{Language: "python"})
+	checkStatus(t, err, codes.InvalidArgument, "rpc.no_code")
+	_, err = s.client.ExecuteFile(ctx, &smollmv1.ExecuteFileRequest{Filename: "noext", Content: []byte("1")})
+	checkStatus(t, err, codes.InvalidArgument, "rpc.invalid_filename")
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestService(t, func(opts *Options) {
+		opts.APIKeys = []string{"other", TEST_API_KEY}
+	})
+
+	tests := []struct {
+		name string
+		auth string
+		code codes.Code
+	}{
+		{"missing key", "", codes.Unauthenticated},
+		{"wrong key", "Bearer wrong", codes.Unauthenticated},
+		{"without bearer", TEST_API_KEY, codes.Unauthenticated},
+		{"valid key", "Bearer " + TEST_API_KEY, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testContext(t)
+			if tt.auth != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.auth)
+			}
+
+			_, err := s.client.ListSessions(ctx, &smollmv1.ListSessionsRequest{})
+			if status.Code(err) != tt.code {
+				t.Errorf("ListSessions = %v, want %s", err, tt.code)
+			}
+			_, _, err = s.generate(ctx, &smollmv1.GenerateRequest{Prompt: "Hi", Owner: "user"})
+			if status.Code(err) != tt.code {
+				t.Errorf("Generate = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestRequestOwner(t *testing.T) {
+	ctx := context.WithValue(context.Background(), apiKeyContextKey{}, 1)
+
+	tests := []struct {
+		ctx  context.Context
+		user string
+		want string
+	}{
+		{context.Background(), "", "grpc"},
+		{context.Background(), "bob", "grpc:bob"},
+		{ctx, "", "grpc:key2"},
+		{ctx, "bob", "grpc:key2:bob"},
+	}
+
+	for _, tt := range tests {
+		if got := requestOwner(tt.ctx, tt.user); got != tt.want {
+			t.Errorf("requestOwner(%q) = %q, want %q", tt.user, got, tt.want)
+		}
+	}
+}
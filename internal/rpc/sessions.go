@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"context"
+	"slices"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/pkg/api/smollmv1"
+)
+
+// CreateSession создает пустую сессию с системной инструкцией профиля
+func (s *Server) CreateSession(ctx context.Context, req *smollmv1.CreateSessionRequest) (*smollmv1.Session, error) {
+	if err := s.requireSessions(); err != nil {
+		return nil, err
+	}
+
+	unlock := s.model.LockSession(req.Name)
+	defer unlock()
+
+	session, err := s.model.NewSessionContext(req.Profile)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := s.sessions.CreateSession(req.Name, session); err != nil {
+		return nil, statusError(err)
+	}
+
+	return sessionToProto(req.Name, session, false), nil
+}
+
+// GetSession возвращает сессию с сообщениями активной ветки
+func (s *Server) GetSession(ctx context.Context, req *smollmv1.GetSessionRequest) (*smollmv1.Session, error) {
+	if err := s.requireSessions(); err != nil {
+		return nil, err
+	}
+
+	session, err := s.sessions.LoadSession(req.Name)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return sessionToProto(req.Name, session, true), nil
+}
+
+// ListSessions возвращает сохраненные сессии без сообщений
+func (s *Server) ListSessions(ctx context.Context, req *smollmv1.ListSessionsRequest) (*smollmv1.ListSessionsResponse, error) {
+	if err := s.requireSessions(); err != nil {
+		return nil, err
+	}
+
+	metas, err := s.sessions.ListSessions()
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &smollmv1.ListSessionsResponse{}
+	for _, meta := range metas {
+		response.Sessions = append(response.Sessions, sessionMetaToProto(meta))
+	}
+	return response, nil
+}
+
+// RenameSession переименовывает сессию
+func (s *Server) RenameSession(ctx context.Context, req *smollmv1.RenameSessionRequest) (*smollmv1.Session, error) {
+	if err := s.requireSessions(); err != nil {
+		return nil, err
+	}
+
+	unlock := s.lockSessions(req.Name, req.NewName)
+	defer unlock()
+
+	if err := s.sessions.RenameSession(req.Name, req.NewName); err != nil {
+		return nil, statusError(err)
+	}
+
+	meta, err := s.sessions.GetSessionMeta(req.NewName)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return sessionMetaToProto(meta), nil
+}
+
+// DeleteSession удаляет сессию
+func (s *Server) DeleteSession(ctx context.Context, req *smollmv1.DeleteSessionRequest) (*smollmv1.DeleteSessionResponse, error) {
+	if err := s.requireSessions(); err != nil {
+		return nil, err
+	}
+
+	unlock := s.model.LockSession(req.Name)
+	defer unlock()
+
+	if err := s.sessions.DeleteSession(req.Name); err != nil {
+		return nil, statusError(err)
+	}
+	return &smollmv1.DeleteSessionResponse{}, nil
+}
+
+// lockSessions захватывает блокировки сессий names в порядке имен, чтобы
+// встречные переименования не ждали друг друга, и возвращает функцию их
+// освобождения
+func (s *Server) lockSessions(names ...string) func() {
+	names = slices.Clone(names)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	unlocks := make([]func(), 0, len(names))
+	for _, name := range names {
+		unlocks = append(unlocks, s.model.LockSession(name))
+	}
+	return func() {
+		for _, unlock := range slices.Backward(unlocks) {
+			unlock()
+		}
+	}
+}
+
+// requireSessions возвращает ошибку, если хранилище сессий не подключено
+func (s *Server) requireSessions() error {
+	if s.sessions == nil {
+		return statusError(i18n.NewError("model.store_not_set").WithKind(model.ErrStoreNotSet))
+	}
+	return nil
+}
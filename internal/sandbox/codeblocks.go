@@ -0,0 +1,59 @@
+package sandbox
+
+import (
+	"strings"
+)
+
+// CodeBlock представляет блок кода, извлеченный из Markdown текста
+type CodeBlock struct {
+	Language string
+	Code     string
+}
+
+// ExtractCodeBlocks извлекает из текста блоки кода, оформленные тройными обратными кавычками.
+// Блоки без указания языка пропускаются
+func ExtractCodeBlocks(text string) []CodeBlock {
+	var blocks []CodeBlock
+	var current *CodeBlock
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if current == nil {
+			if strings.HasPrefix(trimmed, "```") {
+				language := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+				current = &CodeBlock{Language: normalizeLanguage(language)}
+				lines = nil
+			}
+			continue
+		}
+
+		if trimmed == "```" {
+			current.Code = strings.Join(lines, "\n")
+			if current.Language != "" && strings.TrimSpace(current.Code) != "" {
+				blocks = append(blocks, *current)
+			}
+			current = nil
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return blocks
+}
+
+// normalizeLanguage приводит обозначение языка из Markdown к имени, понятному ExecuteCode
+func normalizeLanguage(language string) string {
+	switch language {
+	case "py", "python3":
+		return "python"
+	case "node", "nodejs":
+		return "javascript"
+	case "shell":
+		return "bash"
+	default:
+		return language
+	}
+}
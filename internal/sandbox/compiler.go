@@ -3,13 +3,13 @@ package sandbox
 import (
 	"bytes"
 	"errors"
-	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 )
 
@@ -24,7 +24,8 @@ type Compiler struct {
 type CompileResult struct {
 	Success     bool
 	OutputFile  string
-	Error       string
+	Error       string // Вывод компилятора в stderr
+	Failure     error  // Причина неудачи с кодом сообщения; nil при успехе
 	CompileTime time.Duration
 }
 
@@ -45,7 +46,7 @@ func NewCompiler(workDir string) *Compiler {
 		Command:       "python3",
 		Args:          []string{"-m", "py_compile"},
 		NeedsCompiled: false,
-		ErrorCodes:    map[int]string{1: "Синтаксическая ошибка Python"},
+		ErrorCodes:    map[int]string{1: "sandbox.syntax_error_python"},
 	}
 
 	// JavaScript
@@ -53,7 +54,7 @@ func NewCompiler(workDir string) *Compiler {
 		Command:       "node",
 		Args:          []string{"--check"},
 		NeedsCompiled: false,
-		ErrorCodes:    map[int]string{1: "Синтаксическая ошибка JavaScript"},
+		ErrorCodes:    map[int]string{1: "sandbox.syntax_error_javascript"},
 	}
 
 	// Go
@@ -62,7 +63,7 @@ func NewCompiler(workDir string) *Compiler {
 		Args:          []string{"build"},
 		OutputFlag:    "-o",
 		NeedsCompiled: true,
-		ErrorCodes:    map[int]string{1: "Ошибка компиляции Go"},
+		ErrorCodes:    map[int]string{1: "sandbox.compile_error_go"},
 	}
 
 	// C
@@ -71,7 +72,7 @@ func NewCompiler(workDir string) *Compiler {
 		Args:          []string{"-Wall", "-O2"},
 		OutputFlag:    "-o",
 		NeedsCompiled: true,
-		ErrorCodes:    map[int]string{1: "Ошибка компиляции C"},
+		ErrorCodes:    map[int]string{1: "sandbox.compile_error_c"},
 	}
 
 	// C++
@@ -80,7 +81,7 @@ func NewCompiler(workDir string) *Compiler {
 		Args:          []string{"-Wall", "-O2", "-std=c++17"},
 		OutputFlag:    "-o",
 		NeedsCompiled: true,
-		ErrorCodes:    map[int]string{1: "Ошибка компиляции C++"},
+		ErrorCodes:    map[int]string{1: "sandbox.compile_error_cpp"},
 	}
 
 	// Bash
@@ -88,7 +89,7 @@ func NewCompiler(workDir string) *Compiler {
 		Command:       "bash",
 		Args:          []string{"-n"},
 		NeedsCompiled: false,
-		ErrorCodes:    map[int]string{1: "Синтаксическая ошибка Bash"},
+		ErrorCodes:    map[int]string{1: "sandbox.syntax_error_bash"},
 	}
 
 	return &Compiler{
@@ -108,7 +109,7 @@ func (c *Compiler) Compile(sourcePath string) (*CompileResult, error) {
 	// Проверяем наличие конфигурации для этого типа файла
 	config, ok := c.configs[ext]
 	if !ok {
-		return nil, fmt.Errorf("неподдерживаемый тип файла: %s", ext)
+		return nil, i18n.NewError("sandbox.unsupported_file_type", ext).WithKind(ErrUnsupportedFileType)
 	}
 
 	// Формируем имя выходного файла
@@ -165,21 +166,26 @@ func (c *Compiler) Compile(sourcePath string) (*CompileResult, error) {
 			exitCode := exitErr.ExitCode()
 
 			// Проверяем наличие известной ошибки
-			errorMsg := stderr.String()
-			if customMsg, ok := config.ErrorCodes[exitCode]; ok {
-				errorMsg = fmt.Sprintf("%s: %s", customMsg, errorMsg)
+			failure := &CompileError{
+				Language: ext,
+				ExitCode: exitCode,
+				Output:   stderr.String(),
+			}
+			if code, ok := config.ErrorCodes[exitCode]; ok {
+				failure.Known = i18n.NewError(code)
 			}
 
 			return &CompileResult{
 				Success:     false,
-				Error:       errorMsg,
+				Error:       stderr.String(),
+				Failure:     failure,
 				CompileTime: compileTime,
 			}, nil
 		}
 
 		return &CompileResult{
 			Success:     false,
-			Error:       fmt.Sprintf("ошибка запуска компилятора: %v", err),
+			Failure:     i18n.WrapError(err, "sandbox.compiler_start"),
 			CompileTime: compileTime,
 		}, nil
 	}
@@ -189,7 +195,7 @@ func (c *Compiler) Compile(sourcePath string) (*CompileResult, error) {
 		if _, err := os.Stat(outputFile); os.IsNotExist(err) {
 			return &CompileResult{
 				Success:     false,
-				Error:       "компиляция не создала выходной файл",
+				Failure:     i18n.NewError("sandbox.compile_no_output").WithKind(ErrCompileFailed),
 				CompileTime: compileTime,
 			}, nil
 		}
@@ -213,7 +219,7 @@ func (c *Compiler) CheckSyntax(sourcePath string) (bool, string, error) {
 	// Проверяем наличие конфигурации для этого типа файла
 	config, ok := c.configs[ext]
 	if !ok {
-		return false, "", fmt.Errorf("неподдерживаемый тип файла: %s", ext)
+		return false, "", i18n.NewError("sandbox.unsupported_file_type", ext).WithKind(ErrUnsupportedFileType)
 	}
 
 	// Формируем команду проверки синтаксиса
@@ -3,7 +3,6 @@ package sandbox
 import (
 	"bytes"
 	"errors"
-	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 )
 
@@ -34,7 +34,7 @@ type CompilerConfig struct {
 	Command       string
 	Args          []string
 	OutputFlag    string
-	ErrorCodes    map[int]string
+	ErrorCodes    map[int]string // Ключи сообщений каталога i18n по кодам выхода компилятора
 	NeedsCompiled bool
 }
 
@@ -77,35 +77,30 @@ func NewEnvironment() *Environment {
 	}
 }
 
-// Execute выполняет файл в песочнице
-func (e *Environment) Execute(filename string) (string, error) {
+// RunFile выполняет файл в песочнице и возвращает результат без форматирования
+func (e *Environment) RunFile(filename string) (*ExecuteResult, error) {
 	e.logger.Info("Executing file: %s", filename)
 
 	// Выполняем файл через executor
 	result, err := e.executor.ExecuteFile(filename)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Обновляем метрики
 	e.logger.GetMetrics().IncrementExecutions()
 
-	// Формируем вывод
-	var output string
-	if result.Success {
-		output = fmt.Sprintf("Выполнение успешно завершено за %v\n\n", result.ExecuteTime)
-		output += result.Output
-	} else {
-		output = fmt.Sprintf("Ошибка выполнения (код %d):\n", result.ExitCode)
-		if result.Error != "" {
-			output += result.Error + "\n"
-		}
-		if result.Output != "" {
-			output += "\nВывод программы:\n" + result.Output
-		}
+	return result, nil
+}
+
+// Execute выполняет файл в песочнице
+func (e *Environment) Execute(filename string) (string, error) {
+	result, err := e.RunFile(filename)
+	if err != nil {
+		return "", err
 	}
 
-	return output, nil
+	return FormatResult(result, i18n.Default()), nil
 }
 
 // getCompilerConfig возвращает настройки компилятора для указанного расширения файла
@@ -116,14 +111,14 @@ func (e *Environment) getCompilerConfig(fileExt string) (CompilerConfig, error)
 			Command:       "python3",
 			Args:          []string{"-m", "py_compile"},
 			NeedsCompiled: false,
-			ErrorCodes:    map[int]string{1: "Синтаксическая ошибка Python"},
+			ErrorCodes:    map[int]string{1: "sandbox.syntax_error_python"},
 		}, nil
 	case ".js":
 		return CompilerConfig{
 			Command:       "node",
 			Args:          []string{"--check"},
 			NeedsCompiled: false,
-			ErrorCodes:    map[int]string{1: "Синтаксическая ошибка JavaScript"},
+			ErrorCodes:    map[int]string{1: "sandbox.syntax_error_javascript"},
 		}, nil
 	case ".go":
 		return CompilerConfig{
@@ -131,7 +126,7 @@ func (e *Environment) getCompilerConfig(fileExt string) (CompilerConfig, error)
 			Args:          []string{"build"},
 			OutputFlag:    "-o",
 			NeedsCompiled: true,
-			ErrorCodes:    map[int]string{1: "Ошибка компиляции Go"},
+			ErrorCodes:    map[int]string{1: "sandbox.compile_error_go"},
 		}, nil
 	case ".c":
 		return CompilerConfig{
@@ -139,7 +134,7 @@ func (e *Environment) getCompilerConfig(fileExt string) (CompilerConfig, error)
 			Args:          []string{},
 			OutputFlag:    "-o",
 			NeedsCompiled: true,
-			ErrorCodes:    map[int]string{1: "Ошибка компиляции C"},
+			ErrorCodes:    map[int]string{1: "sandbox.compile_error_c"},
 		}, nil
 	case ".cpp":
 		return CompilerConfig{
@@ -147,17 +142,17 @@ func (e *Environment) getCompilerConfig(fileExt string) (CompilerConfig, error)
 			Args:          []string{},
 			OutputFlag:    "-o",
 			NeedsCompiled: true,
-			ErrorCodes:    map[int]string{1: "Ошибка компиляции C++"},
+			ErrorCodes:    map[int]string{1: "sandbox.compile_error_cpp"},
 		}, nil
 	case ".sh":
 		return CompilerConfig{
 			Command:       "bash",
 			Args:          []string{"-n"},
 			NeedsCompiled: false,
-			ErrorCodes:    map[int]string{1: "Синтаксическая ошибка Bash"},
+			ErrorCodes:    map[int]string{1: "sandbox.syntax_error_bash"},
 		}, nil
 	default:
-		return CompilerConfig{}, fmt.Errorf("неподдерживаемый тип файла: %s", fileExt)
+		return CompilerConfig{}, i18n.NewError("sandbox.unsupported_file_type", fileExt).WithKind(ErrUnsupportedFileType)
 	}
 }
 
@@ -217,26 +212,28 @@ func (e *Environment) compile(filename string, config CompilerConfig) (string, e
 				exitCode := exitErr.ExitCode()
 
 				// Проверяем наличие известной ошибки
-				if errorMsg, ok := config.ErrorCodes[exitCode]; ok {
-					return "", fmt.Errorf("%s (код %d): %s", errorMsg, exitCode, stderr.String())
+				if code, ok := config.ErrorCodes[exitCode]; ok {
+					return "", i18n.NewError("sandbox.compile_known_error", i18n.NewError(code), exitCode, stderr.String()).WithKind(ErrCompileFailed)
 				}
 
-				return "", fmt.Errorf("ошибка компиляции (код %d): %s", exitCode, stderr.String())
+				return "", i18n.NewError("sandbox.compile_exit_code", exitCode, stderr.String()).WithKind(ErrCompileFailed)
 			}
 
-			return "", fmt.Errorf("ошибка запуска компилятора: %v", err)
+			return "", i18n.WrapError(err, "sandbox.compiler_start")
 		}
 	case <-time.After(time.Duration(timeout) * time.Second):
 		// Убиваем процесс, если он превысил таймаут
 		cmd.Process.Kill()
-		return "", fmt.Errorf("превышено время компиляции (%d секунд)", timeout)
+		return "", i18n.NewError("sandbox.compile_timeout", timeout).WithKind(ErrTimeout)
 	}
 
 	// Если дошли сюда, значит компиляция прошла успешно
 	return outputFile, nil
 }
 
-// runFile запускает файл в песочнице
+// runFile запускает файл в песочнице. Таймаут и превышение лимита вывода
+// возвращаются ошибкой (ErrTimeout, ErrOutputTooLarge); ExecuteFile в тех же
+// случаях возвращает результат с Success=false и той же ошибкой в Failure
 func (e *Environment) runFile(filename string, config CompilerConfig) (ExecutionResult, error) {
 	e.logger.Info("Running file: %s", filename)
 
@@ -256,7 +253,7 @@ func (e *Environment) runFile(filename string, config CompilerConfig) (Execution
 		if config.NeedsCompiled {
 			cmd = exec.Command(filename)
 		} else {
-			return ExecutionResult{}, fmt.Errorf("неподдерживаемый тип файла: %s", ext)
+			return ExecutionResult{}, i18n.NewError("sandbox.unsupported_file_type", ext).WithKind(ErrUnsupportedFileType)
 		}
 	}
 
@@ -304,14 +301,14 @@ func (e *Environment) runFile(filename string, config CompilerConfig) (Execution
 	case <-time.After(time.Duration(timeout) * time.Second):
 		// Убиваем группу процессов, если превышен таймаут
 		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
-		return ExecutionResult{}, fmt.Errorf("превышено время выполнения (%d секунд)", timeout)
+		return ExecutionResult{}, i18n.NewError("sandbox.timeout", timeout).WithKind(ErrTimeout)
 	}
 
 	executeTime := time.Since(startTime)
 
 	// Проверяем размер вывода
 	if stdout.Len() > MAX_OUTPUT_SIZE {
-		return ExecutionResult{}, fmt.Errorf("превышен максимальный размер вывода (%d байт)", MAX_OUTPUT_SIZE)
+		return ExecutionResult{}, i18n.NewError("sandbox.output_too_large", MAX_OUTPUT_SIZE).WithKind(ErrOutputTooLarge)
 	}
 
 	return ExecutionResult{
@@ -322,42 +319,70 @@ func (e *Environment) runFile(filename string, config CompilerConfig) (Execution
 	}, nil
 }
 
-// ExecuteCode выполняет строку кода указанного языка
-func (e *Environment) ExecuteCode(code string, language string) (string, error) {
+// RunCode выполняет строку кода указанного языка и возвращает результат без форматирования
+func (e *Environment) RunCode(code string, language string) (*ExecuteResult, error) {
 	e.logger.Info("Executing code snippet in language: %s", language)
 
 	// Выполняем код через executor
 	result, err := e.executor.ExecuteCode(code, language)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Обновляем метрики
 	e.logger.GetMetrics().IncrementExecutions()
 
-	// Формируем вывод
+	return result, nil
+}
+
+// ExecuteCode выполняет строку кода указанного языка
+func (e *Environment) ExecuteCode(code string, language string) (string, error) {
+	result, err := e.RunCode(code, language)
+	if err != nil {
+		return "", err
+	}
+
+	return FormatResult(result, i18n.Default()), nil
+}
+
+// FormatResult формирует текстовый отчет о выполнении кода на языке локализатора loc
+func FormatResult(result *ExecuteResult, loc *i18n.Localizer) string {
 	var output string
 	if result.Success {
-		output = fmt.Sprintf("Выполнение успешно завершено за %v\n\n", result.ExecuteTime)
+		output = loc.T("sandbox.result_success", result.ExecuteTime) + "\n\n"
 		if result.Compiled {
-			output += fmt.Sprintf("Время компиляции: %v\n", result.CompileTime)
+			output += loc.T("sandbox.result_compile_time", result.CompileTime) + "\n"
 		}
 		output += result.Output
 	} else {
-		if result.Compiled && !result.Success {
-			output = "Ошибка компиляции:\n" + result.Error
+		// Неудачная компиляция не доходит до запуска программы
+		if result.Compiled && result.ExecuteTime == 0 {
+			// Известная ошибка компилятора заменяет общий заголовок
+			output = loc.T("sandbox.result_compile_error")
+			var compileErr *CompileError
+			if errors.As(result.Failure, &compileErr) {
+				if compileErr.Known != nil {
+					output = loc.Error(compileErr.Known) + ":"
+				}
+			} else if result.Failure != nil {
+				output = loc.Error(result.Failure) + ":"
+			}
+			output += "\n" + result.Error
 		} else {
-			output = fmt.Sprintf("Ошибка выполнения (код %d):\n", result.ExitCode)
+			output = loc.T("sandbox.result_runtime_error", result.ExitCode) + "\n"
+			if result.Failure != nil {
+				output += loc.Error(result.Failure) + "\n"
+			}
 			if result.Error != "" {
 				output += result.Error + "\n"
 			}
 			if result.Output != "" {
-				output += "\nВывод программы:\n" + result.Output
+				output += "\n" + loc.T("sandbox.result_program_output") + "\n" + result.Output
 			}
 		}
 	}
 
-	return output, nil
+	return output
 }
 
 // GetSupportedLanguages возвращает список поддерживаемых языков
@@ -376,7 +401,7 @@ func (e *Environment) GetSupportedLanguages() []string {
 func (e *Environment) CheckFileSecurity(filename string) error {
 	// Проверяем существование файла
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return fmt.Errorf("файл не существует: %s", filename)
+		return i18n.NewError("sandbox.file_not_found", filename).WithKind(ErrFileNotFound)
 	}
 
 	// Проверяем размер файла
@@ -388,7 +413,7 @@ func (e *Environment) CheckFileSecurity(filename string) error {
 	// Максимальный размер файла (10MB)
 	maxSize := int64(10 * 1024 * 1024)
 	if fileInfo.Size() > maxSize {
-		return fmt.Errorf("превышен максимальный размер файла: %d байт (максимум %d)", fileInfo.Size(), maxSize)
+		return i18n.NewError("sandbox.file_too_large", fileInfo.Size(), maxSize).WithKind(ErrFileTooLarge)
 	}
 
 	// Проверяем расширение файла
@@ -407,7 +432,7 @@ func (e *Environment) CheckFileSecurity(filename string) error {
 	}
 
 	if !supportedExts[ext] {
-		return fmt.Errorf("неподдерживаемый тип файла: %s", ext)
+		return i18n.NewError("sandbox.unsupported_file_type", ext).WithKind(ErrUnsupportedFileType)
 	}
 
 	// Проверяем на подозрительное содержимое
@@ -456,7 +481,7 @@ func (e *Environment) CleanupTempFiles() error {
 	// Читаем все файлы в рабочей директории
 	files, err := os.ReadDir(e.workDir)
 	if err != nil {
-		return fmt.Errorf("ошибка чтения директории: %v", err)
+		return i18n.WrapError(err, "sandbox.dir_read")
 	}
 
 	// Удаляем временные файлы
@@ -465,13 +490,13 @@ func (e *Environment) CleanupTempFiles() error {
 		if !file.IsDir() && strings.HasPrefix(file.Name(), "tmp_") {
 			path := filepath.Join(e.workDir, file.Name())
 			if err := os.Remove(path); err != nil {
-				errors = append(errors, fmt.Sprintf("не удалось удалить %s: %v", file.Name(), err))
+				errors = append(errors, i18n.WrapError(err, "sandbox.file_remove", file.Name()).Error())
 			}
 		}
 	}
 
 	if len(errors) > 0 {
-		return fmt.Errorf("ошибки при очистке: %s", strings.Join(errors, "; "))
+		return i18n.NewError("sandbox.cleanup_failed", strings.Join(errors, "; "))
 	}
 
 	return nil
@@ -0,0 +1,46 @@
+package sandbox
+
+import (
+	"errors"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// Виды ошибок песочницы: ограничения выполнения кода и проверки файлов
+var (
+	ErrTimeout             = errors.New("sandbox: timeout")
+	ErrOutputTooLarge      = errors.New("sandbox: output too large")
+	ErrUnsupportedLanguage = errors.New("sandbox: unsupported language")
+	ErrUnsupportedFileType = errors.New("sandbox: unsupported file type")
+	ErrFileNotFound        = errors.New("sandbox: file not found")
+	ErrFileTooLarge        = errors.New("sandbox: file too large")
+	ErrCompileFailed       = errors.New("sandbox: compilation failed")
+)
+
+// CompileError описывает неудачную компиляцию исходного файла.
+// Соответствует ErrCompileFailed
+type CompileError struct {
+	Language string // Расширение исходного файла
+	ExitCode int    // Код выхода компилятора
+	Output   string // Вывод компилятора в stderr
+	Known    error  // Известная ошибка компилятора с кодом сообщения; может быть nil
+}
+
+// Error возвращает текст ошибки на языке по умолчанию
+func (e *CompileError) Error() string {
+	return e.Unwrap().Error()
+}
+
+// Unwrap возвращает ошибку с кодом сообщения: известную ошибку компилятора
+// или общее сообщение с кодом выхода
+func (e *CompileError) Unwrap() error {
+	if e.Known != nil {
+		return e.Known
+	}
+	return i18n.NewError("sandbox.compile_failed", e.ExitCode)
+}
+
+// Is сообщает, что ошибка относится к ErrCompileFailed
+func (e *CompileError) Is(target error) bool {
+	return target == ErrCompileFailed
+}
@@ -3,6 +3,7 @@ package sandbox
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"syscall"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 )
 
@@ -28,7 +30,8 @@ type Executor struct {
 type ExecuteResult struct {
 	Success     bool
 	Output      string
-	Error       string
+	Error       string // Вывод программы или компилятора в stderr
+	Failure     error  // Причина неудачи: ErrTimeout, ErrOutputTooLarge, *CompileError и т.п.
 	ExitCode    int
 	ExecuteTime time.Duration
 	CompileTime time.Duration
@@ -64,13 +67,15 @@ func NewExecutor(workDir string) *Executor {
 	}
 }
 
-// ExecuteFile выполняет указанный файл
+// ExecuteFile выполняет указанный файл. Ошибка возвращается, только если
+// запуск невозможен; таймаут, лимит вывода и ошибки компиляции попадают
+// в результат с Success=false и распознаются через errors.Is(result.Failure, ...)
 func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 	e.logger.Info("Executing file: %s", filePath)
 
 	// Проверяем существование файла
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("файл не существует: %s", filePath)
+		return nil, i18n.NewError("sandbox.file_not_found", filePath).WithKind(ErrFileNotFound)
 	}
 
 	// Определяем тип файла по расширению
@@ -80,8 +85,12 @@ func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 	baseName := filepath.Base(filePath)
 	tempFile := filepath.Join(e.workDir, baseName)
 
-	if err := copyFile(filePath, tempFile); err != nil {
-		return nil, fmt.Errorf("ошибка копирования файла: %v", err)
+	// Код из ExecuteCode уже лежит в рабочей директории: копирование файла
+	// в самого себя обнулило бы его
+	if absPath, _ := filepath.Abs(filePath); absPath != tempFile {
+		if err := copyFile(filePath, tempFile); err != nil {
+			return nil, i18n.WrapError(err, "sandbox.file_copy")
+		}
 	}
 
 	// Исполняемый файл
@@ -93,13 +102,14 @@ func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 	if ext == ".c" || ext == ".cpp" || ext == ".go" {
 		compileResult, err = e.compiler.Compile(tempFile)
 		if err != nil {
-			return nil, fmt.Errorf("ошибка компиляции: %v", err)
+			return nil, i18n.WrapError(err, "sandbox.compile")
 		}
 
 		if !compileResult.Success {
 			return &ExecuteResult{
 				Success:     false,
 				Error:       compileResult.Error,
+				Failure:     compileResult.Failure,
 				CompileTime: compileResult.CompileTime,
 				Compiled:    true,
 				Language:    ext,
@@ -133,7 +143,7 @@ func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 	case ".c", ".cpp", ".go":
 		cmd = exec.Command(executablePath)
 	default:
-		return nil, fmt.Errorf("неподдерживаемый тип файла: %s", ext)
+		return nil, i18n.NewError("sandbox.unsupported_file_type", ext).WithKind(ErrUnsupportedFileType)
 	}
 
 	// Настраиваем ограничения
@@ -153,7 +163,7 @@ func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 	startTime := time.Now()
 	err = cmd.Start()
 	if err != nil {
-		return nil, fmt.Errorf("ошибка запуска процесса: %v", err)
+		return nil, i18n.WrapError(err, "sandbox.process_start")
 	}
 
 	// Завершение процесса с обработкой таймаута
@@ -168,7 +178,7 @@ func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 	case <-ctx.Done():
 		// Превышен таймаут, убиваем процесс
 		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
-		executeErr = fmt.Errorf("превышено время выполнения (%d секунд)", timeout)
+		executeErr = i18n.NewError("sandbox.timeout", timeout).WithKind(ErrTimeout)
 	case err := <-doneCh:
 		executeErr = err
 	}
@@ -178,17 +188,21 @@ func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 
 	// Проверяем размер вывода
 	if stdout.Len() > e.maxOutputSize {
-		executeErr = fmt.Errorf("превышен максимальный размер вывода (%d байт)", e.maxOutputSize)
+		executeErr = i18n.NewError("sandbox.output_too_large", e.maxOutputSize).WithKind(ErrOutputTooLarge)
 	}
 
 	// Анализируем результат
 	exitCode := 0
 	success := true
+	var failure error
 
 	if executeErr != nil {
 		success = false
-		if exitErr, ok := executeErr.(*exec.ExitError); ok {
+		var exitErr *exec.ExitError
+		if errors.As(executeErr, &exitErr) {
 			exitCode = exitErr.ExitCode()
+		} else {
+			failure = executeErr
 		}
 	}
 
@@ -197,6 +211,7 @@ func (e *Executor) ExecuteFile(filePath string) (*ExecuteResult, error) {
 		Success:     success,
 		Output:      stdout.String(),
 		Error:       stderr.String(),
+		Failure:     failure,
 		ExitCode:    exitCode,
 		ExecuteTime: executeTime,
 		Language:    ext,
@@ -257,7 +272,7 @@ func (e *Executor) ExecuteCode(code string, language string) (*ExecuteResult, er
 	case "bash", "sh":
 		ext = ".sh"
 	default:
-		return nil, fmt.Errorf("неподдерживаемый язык: %s", language)
+		return nil, i18n.NewError("sandbox.unsupported_language", language).WithKind(ErrUnsupportedLanguage)
 	}
 
 	// Создаем временный файл для кода
@@ -267,7 +282,7 @@ func (e *Executor) ExecuteCode(code string, language string) (*ExecuteResult, er
 
 	// Записываем код во временный файл
 	if err := os.WriteFile(filePath, []byte(code), 0755); err != nil {
-		return nil, fmt.Errorf("ошибка записи во временный файл: %v", err)
+		return nil, i18n.WrapError(err, "sandbox.temp_file_write")
 	}
 
 	// Выполняем файл
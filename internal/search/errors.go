@@ -0,0 +1,10 @@
+package search
+
+import "errors"
+
+// Виды ошибок поискового индекса
+var (
+	ErrCorruptIndex = errors.New("search: corrupt index")
+	ErrIndexIO      = errors.New("search: index read or write failed")
+	ErrInvalidQuery = errors.New("search: invalid query")
+)
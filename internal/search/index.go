@@ -0,0 +1,300 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/storage"
+)
+
+const (
+	// Версия формата индекса; индекс другой версии перестраивается
+	INDEX_VERSION = 2
+
+	// Файлы индекса в директории INDEX_DIR корня хранилища: описание индекса,
+	// блокировка и директория сегментов - по файлу на источник документов
+	INDEX_DIR          = "index"
+	INDEX_FILE         = "search.json"
+	INDEX_LOCK_FILE    = ".lock"
+	INDEX_SEGMENTS_DIR = "search"
+)
+
+// Типы индексируемых документов
+const (
+	TYPE_SESSION  = "session"
+	TYPE_THOUGHT  = "thought"
+	TYPE_FEEDBACK = "feedback"
+)
+
+// Document представляет индексируемый фрагмент: сообщение сессии,
+// журнал размышлений или комментарий обратной связи
+type Document struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`   // session, thought или feedback
+	Source    string    `json:"source"` // Имя сессии, файла размышлений или ID обратной связи
+	Title     string    `json:"title"`  // Краткое описание для результатов поиска
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"`
+	Length    int       `json:"length"` // Количество термов в тексте
+}
+
+// indexManifest - содержимое файла INDEX_FILE; индекс построен, если файл
+// существует и версия совпадает
+type indexManifest struct {
+	Version int       `json:"version"`
+	BuiltAt time.Time `json:"built_at"`
+}
+
+// segment - файл с документами одного источника. Сохранение сессии
+// перезаписывает только ее сегмент, а не весь индекс
+type segment struct {
+	Version int               `json:"version"`
+	Type    string            `json:"type"`
+	Source  string            `json:"source"`
+	Docs    []indexedDocument `json:"docs"`
+}
+
+// indexedDocument - документ сегмента с частотами его термов
+type indexedDocument struct {
+	Document
+	Terms map[string]int `json:"terms"`
+}
+
+// indexData - индекс в памяти, собранный из сегментов
+type indexData struct {
+	Docs     map[string]Document
+	Postings map[string]map[string]int // терм -> ID документа -> частота
+}
+
+// cachedSegment - прочитанный сегмент, время изменения и размер его файла
+type cachedSegment struct {
+	modTime time.Time
+	size    int64
+	docs    []indexedDocument
+}
+
+// Index - инвертированный индекс, хранящийся в корне хранилища. Изменения
+// выполняются под межпроцессной блокировкой, поэтому CLI и Telegram бот
+// могут обновлять индекс одновременно
+type Index struct {
+	logger *logging.Logger
+	fs     *storage.FileSystem
+	dir    string
+	mu     sync.Mutex
+
+	// Сегменты перечитываются только после изменения их файлов
+	segments map[string]cachedSegment
+	cached   *indexData
+}
+
+// NewIndex создает индекс в директории INDEX_DIR хранилища
+func NewIndex(fs *storage.FileSystem) *Index {
+	logger := logging.NewLogger()
+
+	dir := filepath.Join(fs.GetRootDir(), INDEX_DIR)
+	if err := os.MkdirAll(filepath.Join(dir, INDEX_SEGMENTS_DIR), 0755); err != nil {
+		logger.Error("Failed to create index directory: %v", err)
+	}
+
+	return &Index{
+		logger:   logger,
+		fs:       fs,
+		dir:      dir,
+		segments: make(map[string]cachedSegment),
+	}
+}
+
+// Exists проверяет, построен ли индекс текущей версии
+func (ix *Index) Exists() bool {
+	raw, err := ix.fs.ReadFile(filepath.Join(ix.dir, INDEX_FILE))
+	if err != nil {
+		return false
+	}
+
+	var manifest indexManifest
+	return json.Unmarshal(raw, &manifest) == nil && manifest.Version == INDEX_VERSION
+}
+
+// Replace заменяет все документы источника новыми
+func (ix *Index) Replace(docType, source string, docs []Document) error {
+	return ix.withLock(func() error {
+		return ix.writeSegment(docType, source, docs)
+	})
+}
+
+// Remove удаляет все документы источника
+func (ix *Index) Remove(docType, source string) error {
+	return ix.withLock(func() error {
+		return ix.writeSegment(docType, source, nil)
+	})
+}
+
+// withLock выполняет change под блокировкой индекса
+func (ix *Index) withLock(change func() error) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	lock, err := storage.LockFile(filepath.Join(ix.dir, INDEX_LOCK_FILE))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return change()
+}
+
+// writeSegment атомарно записывает сегмент источника; без документов
+// сегмент удаляется
+func (ix *Index) writeSegment(docType, source string, docs []Document) error {
+	path := ix.segmentPath(docType, source)
+	if len(docs) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return i18n.WrapError(err, "search.index_write").WithKind(ErrIndexIO)
+		}
+		return nil
+	}
+
+	seg := segment{Version: INDEX_VERSION, Type: docType, Source: source}
+	for _, doc := range docs {
+		seg.Docs = append(seg.Docs, newIndexedDocument(doc))
+	}
+
+	raw, err := json.Marshal(seg)
+	if err != nil {
+		return i18n.WrapError(err, "search.index_encode").WithKind(ErrIndexIO)
+	}
+	if err := ix.fs.WriteFileAtomic(path, raw); err != nil {
+		return i18n.WrapError(err, "search.index_write").WithKind(ErrIndexIO)
+	}
+	return nil
+}
+
+// load собирает индекс из сегментов. Поврежденный сегмент пропускается:
+// остальные документы остаются доступными, а сегмент перезапишется при
+// следующем сохранении источника или перестроении индекса
+func (ix *Index) load() (*indexData, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	entries, err := os.ReadDir(ix.segmentsDir())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, i18n.WrapError(err, "search.index_read").WithKind(ErrIndexIO)
+	}
+
+	changed := false
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Сегмент удален после чтения директории
+			continue
+		}
+
+		name := entry.Name()
+		present[name] = true
+		if cached, ok := ix.segments[name]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			continue
+		}
+
+		docs, err := ix.readSegment(filepath.Join(ix.segmentsDir(), name))
+		if err != nil {
+			ix.logger.Warn("Skipping unreadable search index segment %s: %v", name, err)
+		}
+		ix.segments[name] = cachedSegment{modTime: info.ModTime(), size: info.Size(), docs: docs}
+		changed = true
+	}
+	for name := range ix.segments {
+		if !present[name] {
+			delete(ix.segments, name)
+			changed = true
+		}
+	}
+
+	if ix.cached != nil && !changed {
+		return ix.cached, nil
+	}
+
+	data := newIndexData()
+	for _, cached := range ix.segments {
+		for _, doc := range cached.docs {
+			data.add(doc)
+		}
+	}
+	ix.cached = data
+
+	return data, nil
+}
+
+// readSegment читает документы файла сегмента
+func (ix *Index) readSegment(path string) ([]indexedDocument, error) {
+	raw, err := ix.fs.ReadFile(path)
+	if err != nil {
+		return nil, i18n.WrapError(err, "search.index_read").WithKind(ErrIndexIO)
+	}
+
+	var seg segment
+	if err := json.Unmarshal(raw, &seg); err != nil {
+		return nil, i18n.WrapError(err, "search.index_decode").WithKind(ErrCorruptIndex)
+	}
+	if seg.Version != INDEX_VERSION {
+		return nil, i18n.NewError("search.index_version", seg.Version).WithKind(ErrCorruptIndex)
+	}
+
+	return seg.Docs, nil
+}
+
+// segmentsDir возвращает директорию сегментов
+func (ix *Index) segmentsDir() string {
+	return filepath.Join(ix.dir, INDEX_SEGMENTS_DIR)
+}
+
+// segmentPath возвращает путь к сегменту источника. Имя источника
+// экранируется, чтобы оно не могло выйти за пределы директории сегментов
+func (ix *Index) segmentPath(docType, source string) string {
+	return filepath.Join(ix.segmentsDir(), docType+"_"+url.PathEscape(source)+".json")
+}
+
+// newIndexedDocument подсчитывает термы документа
+func newIndexedDocument(doc Document) indexedDocument {
+	terms := Tokenize(doc.Text)
+	doc.Length = len(terms)
+
+	frequencies := make(map[string]int)
+	for _, term := range terms {
+		frequencies[term]++
+	}
+	return indexedDocument{Document: doc, Terms: frequencies}
+}
+
+// newIndexData создает пустой индекс
+func newIndexData() *indexData {
+	return &indexData{
+		Docs:     make(map[string]Document),
+		Postings: make(map[string]map[string]int),
+	}
+}
+
+// add добавляет документ в индекс
+func (d *indexData) add(doc indexedDocument) {
+	d.Docs[doc.ID] = doc.Document
+
+	for term, freq := range doc.Terms {
+		postings, ok := d.Postings[term]
+		if !ok {
+			postings = make(map[string]int)
+			d.Postings[term] = postings
+		}
+		postings[doc.ID] += freq
+	}
+}
@@ -0,0 +1,186 @@
+package search
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/storage"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	os.Exit(m.Run())
+}
+
+// sources возвращает источники результатов поиска
+func sources(results []Result) map[string]bool {
+	found := make(map[string]bool)
+	for _, result := range results {
+		found[result.Source] = true
+	}
+	return found
+}
+
+func TestIndexReplaceAndRemove(t *testing.T) {
+	ix := NewIndex(storage.NewFileSystem(t.TempDir()))
+
+	docs := func(source, text string) []Document {
+		return []Document{{ID: TYPE_SESSION + ":" + source + ":m1", Type: TYPE_SESSION, Source: source, Text: text}}
+	}
+	if err := ix.Replace(TYPE_SESSION, "alpha", docs("alpha", "сортировка пузырьком")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Replace(TYPE_SESSION, "beta/../x", docs("beta/../x", "быстрая сортировка")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		change func() error
+		query  string
+		want   map[string]bool
+	}{
+		{"both sources", nil, "сортировка", map[string]bool{"alpha": true, "beta/../x": true}},
+		{"prefix match", nil, "сортиров", map[string]bool{"alpha": true, "beta/../x": true}},
+		{"single source", nil, "пузырьком", map[string]bool{"alpha": true}},
+		{
+			"replace drops old text",
+			func() error { return ix.Replace(TYPE_SESSION, "alpha", docs("alpha", "слияние")) },
+			"пузырьком",
+			map[string]bool{},
+		},
+		{
+			"remove keeps other sources",
+			func() error { return ix.Remove(TYPE_SESSION, "alpha") },
+			"сортировка",
+			map[string]bool{"beta/../x": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.change != nil {
+				if err := tt.change(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			results, err := ix.Search(Query{Text: tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sources(results); len(got) != len(tt.want) || !equalSets(got, tt.want) {
+				t.Errorf("Search(%q) sources = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	// Экранированное имя источника не выходит за пределы директории сегментов
+	entries, err := os.ReadDir(ix.segmentsDir())
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("segments = %v, %v; want one file", entries, err)
+	}
+}
+
+func TestSearchSourcesFilter(t *testing.T) {
+	ix := NewIndex(storage.NewFileSystem(t.TempDir()))
+
+	for _, source := range []string{"tg_1", "tg_2"} {
+		doc := Document{ID: TYPE_SESSION + ":" + source + ":m1", Type: TYPE_SESSION, Source: source, Text: "пароль от сервера"}
+		if err := ix.Replace(TYPE_SESSION, source, []Document{doc}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := ix.Search(Query{Text: "пароль", Sources: []string{"tg_2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sources(results); !equalSets(got, map[string]bool{"tg_2": true}) {
+		t.Errorf("Search with Sources = %v, want only tg_2", got)
+	}
+}
+
+func TestIndexCorruptSegmentSkipped(t *testing.T) {
+	ix := NewIndex(storage.NewFileSystem(t.TempDir()))
+	doc := Document{ID: "feedback:f1", Type: TYPE_FEEDBACK, Source: "f1", Text: "отличный ответ"}
+	if err := ix.Replace(TYPE_FEEDBACK, "f1", []Document{doc}); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(ix.segmentsDir(), "session_broken.json")
+	if err := os.WriteFile(broken, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.readSegment(broken); !errors.Is(err, ErrCorruptIndex) {
+		t.Errorf("readSegment(broken) = %v, want ErrCorruptIndex", err)
+	}
+
+	results, err := ix.Search(Query{Text: "ответ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Source != "f1" {
+		t.Errorf("results = %+v, want document f1", results)
+	}
+
+	// Запись другого источника не затрагивает существующие сегменты
+	if err := ix.Replace(TYPE_FEEDBACK, "f2", []Document{{ID: "feedback:f2", Type: TYPE_FEEDBACK, Source: "f2", Text: "ответ"}}); err != nil {
+		t.Fatal(err)
+	}
+	results, _ = ix.Search(Query{Text: "ответ"})
+	if len(results) != 2 {
+		t.Errorf("results = %d, want 2", len(results))
+	}
+}
+
+func TestIndexRebuild(t *testing.T) {
+	fs := storage.NewFileSystem(t.TempDir())
+	sessions := storage.NewSessionManager(fs, "sessions")
+	ix := NewIndex(fs)
+
+	session := model.NewContext()
+	session.AddUserMessage("как работает рекурсия")
+	if err := sessions.SaveSession("demo", session); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Replace(TYPE_SESSION, "deleted", []Document{{ID: "session:deleted:m1", Type: TYPE_SESSION, Source: "deleted", Text: "рекурсия"}}); err != nil {
+		t.Fatal(err)
+	}
+	if ix.Exists() {
+		t.Fatal("index exists before rebuild")
+	}
+
+	items := []feedback.FeedbackItem{{ID: "f1", Type: feedback.ModelOutput, Comment: "рекурсия объяснена", Rating: 4}}
+	if err := ix.Rebuild(sessions, filepath.Join(fs.GetRootDir(), "thoughts"), items); err != nil {
+		t.Fatal(err)
+	}
+	if !ix.Exists() {
+		t.Fatal("index does not exist after rebuild")
+	}
+
+	results, err := ix.Search(Query{Text: "рекурсия"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sources(results), map[string]bool{"demo": true, "f1": true}; !equalSets(got, want) {
+		t.Errorf("sources = %v, want %v", got, want)
+	}
+}
+
+// equalSets сравнивает множества
+func equalSets(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if !b[key] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+package search
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+const (
+	// Параметры ранжирования BM25
+	BM25_K1 = 1.2
+	BM25_B  = 0.75
+
+	// Вес совпадения по префиксу относительно точного совпадения терма
+	PREFIX_WEIGHT = 0.5
+
+	// Количество результатов по умолчанию
+	DEFAULT_LIMIT = 10
+
+	// Размер фрагмента текста вокруг найденного терма в символах
+	SNIPPET_RADIUS = 80
+)
+
+// Query описывает поисковый запрос
+type Query struct {
+	Text    string    // Текст запроса
+	Type    string    // Тип документов; пусто - все типы
+	Sources []string  // Только документы указанных источников; пусто - все
+	Since   time.Time // Только документы не старше указанного времени
+	Limit   int       // Максимальное количество результатов
+}
+
+// Result представляет найденный документ
+type Result struct {
+	Type      string    `json:"type"`
+	Source    string    `json:"source"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Score     float64   `json:"score"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Search ищет документы по запросу и возвращает их в порядке релевантности
+func (ix *Index) Search(query Query) ([]Result, error) {
+	terms := Tokenize(query.Text)
+	if len(terms) == 0 {
+		return nil, i18n.NewError("search.empty_query").WithKind(ErrInvalidQuery)
+	}
+	if query.Limit <= 0 {
+		query.Limit = DEFAULT_LIMIT
+	}
+
+	// Сегменты заменяются атомарно, поэтому читаем их без блокировки
+	data, err := ix.load()
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Docs) == 0 {
+		return nil, nil
+	}
+
+	var totalLength int
+	for _, doc := range data.Docs {
+		totalLength += doc.Length
+	}
+	avgLength := float64(totalLength) / float64(len(data.Docs))
+
+	scores := make(map[string]float64)
+	for _, term := range terms {
+		for indexTerm, weight := range matchingTerms(data, term) {
+			postings := data.Postings[indexTerm]
+			idf := math.Log(1 + (float64(len(data.Docs))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+			for id, freq := range postings {
+				doc := data.Docs[id]
+				if !matchesFilters(doc, query) {
+					continue
+				}
+
+				tf := float64(freq)
+				norm := tf * (BM25_K1 + 1) / (tf + BM25_K1*(1-BM25_B+BM25_B*float64(doc.Length)/avgLength))
+				scores[id] += weight * idf * norm
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for id, score := range scores {
+		doc := data.Docs[id]
+		results = append(results, Result{
+			Type:      doc.Type,
+			Source:    doc.Source,
+			Title:     doc.Title,
+			Snippet:   snippet(doc.Text, terms),
+			Score:     score,
+			Timestamp: doc.Timestamp,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Timestamp.After(results[j].Timestamp)
+	})
+
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// matchingTerms возвращает термы индекса, совпадающие с термом запроса,
+// с их весами: точное совпадение и совпадения по префиксу
+func matchingTerms(data *indexData, term string) map[string]float64 {
+	matches := make(map[string]float64)
+	if _, ok := data.Postings[term]; ok {
+		matches[term] = 1
+	}
+
+	// Префиксный поиск помогает с окончаниями слов ("сортиров" -> "сортировка")
+	if len([]rune(term)) >= 4 {
+		for indexTerm := range data.Postings {
+			if indexTerm != term && strings.HasPrefix(indexTerm, term) {
+				matches[indexTerm] = PREFIX_WEIGHT
+			}
+		}
+	}
+
+	return matches
+}
+
+// matchesFilters проверяет документ на соответствие фильтрам запроса
+func matchesFilters(doc Document, query Query) bool {
+	if query.Type != "" && doc.Type != query.Type {
+		return false
+	}
+	if len(query.Sources) > 0 && !slices.Contains(query.Sources, doc.Source) {
+		return false
+	}
+	if !query.Since.IsZero() && doc.Timestamp.Before(query.Since) {
+		return false
+	}
+	return true
+}
+
+// snippet возвращает фрагмент текста вокруг первого найденного терма
+func snippet(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Смена регистра изменила длину текста: позиции не совпадут
+		runes = lower
+	}
+
+	pos := -1
+	for _, term := range terms {
+		if i := runeIndex(lower, []rune(term)); i >= 0 && (pos < 0 || i < pos) {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	start := pos - SNIPPET_RADIUS
+	if start < 0 {
+		start = 0
+	}
+	end := pos + SNIPPET_RADIUS
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	result := strings.Join(strings.FieldsFunc(string(runes[start:end]), unicode.IsSpace), " ")
+	if start > 0 {
+		result = "..." + result
+	}
+	if end < len(runes) {
+		result += "..."
+	}
+
+	return result
+}
+
+// runeIndex ищет подпоследовательность символов
+func runeIndex(haystack, needle []rune) int {
+	if len(needle) == 0 || len(needle) > len(haystack) {
+		return -1
+	}
+
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// ParseSince разбирает ограничение по времени: длительность с суффиксом
+// m, h, d или w ("30m", "12h", "7d", "2w") либо дату в формате 2006-01-02
+func ParseSince(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+
+	units := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return time.Time{}, i18n.NewError("search.invalid_since", value).WithKind(ErrInvalidQuery)
+	}
+
+	amount, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || amount < 0 {
+		return time.Time{}, i18n.NewError("search.invalid_since", value).WithKind(ErrInvalidQuery)
+	}
+
+	return time.Now().Add(-time.Duration(amount) * unit), nil
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/storage"
+)
+
+// SessionSaved переиндексирует сохраненную сессию (реализует storage.SessionListener)
+func (ix *Index) SessionSaved(name string, context *model.Context) {
+	if err := ix.Replace(TYPE_SESSION, name, sessionDocuments(name, context)); err != nil {
+		ix.logger.Error("Failed to index session %s: %v", name, err)
+	}
+}
+
+// SessionDeleted удаляет сессию из индекса (реализует storage.SessionListener)
+func (ix *Index) SessionDeleted(name string) {
+	if err := ix.Remove(TYPE_SESSION, name); err != nil {
+		ix.logger.Error("Failed to remove session %s from index: %v", name, err)
+	}
+}
+
+// IndexThought индексирует файл журнала размышлений
+func (ix *Index) IndexThought(path string) error {
+	docs, err := thoughtDocuments(path)
+	if err != nil {
+		return err
+	}
+
+	return ix.Replace(TYPE_THOUGHT, filepath.Base(path), docs)
+}
+
+// IndexFeedback индексирует элемент обратной связи
+func (ix *Index) IndexFeedback(item feedback.FeedbackItem) error {
+	return ix.Replace(TYPE_FEEDBACK, item.ID, []Document{feedbackDocument(item)})
+}
+
+// Rebuild полностью перестраивает индекс по сохраненным сессиям, журналам
+// размышлений из thoughtsDir и элементам обратной связи
+func (ix *Index) Rebuild(sessions *storage.SessionManager, thoughtsDir string, items []feedback.FeedbackItem) error {
+	fresh := make(map[string][]Document)
+	add := func(docType, source string, docs []Document) {
+		path := ix.segmentPath(docType, source)
+		fresh[path] = append(fresh[path], docs...)
+	}
+
+	metas, err := sessions.ListSessions()
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		context, err := sessions.LoadSession(meta.Name)
+		if err != nil {
+			ix.logger.Warn("Skipping session %s: %v", meta.Name, err)
+			continue
+		}
+		add(TYPE_SESSION, meta.Name, sessionDocuments(meta.Name, context))
+	}
+
+	entries, err := os.ReadDir(thoughtsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return i18n.WrapError(err, "search.thoughts_read").WithKind(ErrIndexIO)
+	}
+	for _, entry := range entries {
+		// Записи размышлений для повтора дублируют журналы
+		if entry.IsDir() || filepath.Ext(entry.Name()) == model.THOUGHT_RECORD_EXTENSION {
+			continue
+		}
+		docs, err := thoughtDocuments(filepath.Join(thoughtsDir, entry.Name()))
+		if err != nil {
+			ix.logger.Warn("Skipping thought journal %s: %v", entry.Name(), err)
+			continue
+		}
+		add(TYPE_THOUGHT, entry.Name(), docs)
+	}
+
+	for _, item := range items {
+		add(TYPE_FEEDBACK, item.ID, []Document{feedbackDocument(item)})
+	}
+
+	return ix.withLock(func() error {
+		for _, docs := range fresh {
+			if len(docs) == 0 {
+				continue
+			}
+			if err := ix.writeSegment(docs[0].Type, docs[0].Source, docs); err != nil {
+				return err
+			}
+		}
+
+		// Сегменты источников, которых больше нет
+		existing, err := os.ReadDir(ix.segmentsDir())
+		if err != nil {
+			return i18n.WrapError(err, "search.index_read").WithKind(ErrIndexIO)
+		}
+		for _, entry := range existing {
+			path := filepath.Join(ix.segmentsDir(), entry.Name())
+			if _, ok := fresh[path]; !ok || len(fresh[path]) == 0 {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					return i18n.WrapError(err, "search.index_write").WithKind(ErrIndexIO)
+				}
+			}
+		}
+
+		raw, err := json.Marshal(indexManifest{Version: INDEX_VERSION, BuiltAt: time.Now()})
+		if err != nil {
+			return i18n.WrapError(err, "search.index_encode").WithKind(ErrIndexIO)
+		}
+		if err := ix.fs.WriteFileAtomic(filepath.Join(ix.dir, INDEX_FILE), raw); err != nil {
+			return i18n.WrapError(err, "search.index_write").WithKind(ErrIndexIO)
+		}
+		return nil
+	})
+}
+
+// sessionDocuments создает документы для сообщений всех веток сессии
+func sessionDocuments(name string, context *model.Context) []Document {
+	docs := make([]Document, 0, len(context.Messages))
+	for _, msg := range context.Messages {
+		if msg.Role == "system" || strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+
+		docs = append(docs, Document{
+			ID:        fmt.Sprintf("%s:%s:%s", TYPE_SESSION, name, msg.ID),
+			Type:      TYPE_SESSION,
+			Source:    name,
+			Title:     fmt.Sprintf("%s (%s)", name, msg.Role),
+			Text:      msg.Content,
+			Timestamp: msg.Timestamp,
+		})
+	}
+	return docs
+}
+
+// thoughtDocuments разбивает журнал размышлений на абзацы
+func thoughtDocuments(path string) ([]Document, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, i18n.WrapError(err, "search.thought_read", filepath.Base(path)).WithKind(ErrIndexIO)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.WrapError(err, "search.thought_read", filepath.Base(path)).WithKind(ErrIndexIO)
+	}
+
+	name := filepath.Base(path)
+	var docs []Document
+	for i, paragraph := range strings.Split(string(content), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		// Заголовки журнала не несут содержания
+		if paragraph == "" || strings.HasPrefix(paragraph, "#") {
+			continue
+		}
+
+		docs = append(docs, Document{
+			ID:        fmt.Sprintf("%s:%s:%d", TYPE_THOUGHT, name, i),
+			Type:      TYPE_THOUGHT,
+			Source:    name,
+			Title:     name,
+			Text:      paragraph,
+			Timestamp: info.ModTime(),
+		})
+	}
+
+	return docs, nil
+}
+
+// feedbackDocument создает документ для элемента обратной связи
+func feedbackDocument(item feedback.FeedbackItem) Document {
+	title := i18n.T("search.feedback_title", item.Type, item.Rating)
+	if sessionID, ok := item.MetadataString(feedback.META_SESSION_ID); ok {
+		title += i18n.T("search.feedback_session", sessionID)
+	}
+
+	return Document{
+		ID:        fmt.Sprintf("%s:%s", TYPE_FEEDBACK, item.ID),
+		Type:      TYPE_FEEDBACK,
+		Source:    item.ID,
+		Title:     title,
+		Text:      strings.TrimSpace(item.Comment + "\n" + item.Content),
+		Timestamp: item.Timestamp,
+	}
+}
@@ -0,0 +1,26 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MIN_TERM_LENGTH - минимальная длина терма в символах
+const MIN_TERM_LENGTH = 2
+
+// Tokenize разбивает текст на термы в нижнем регистре. Буквы и цифры любых
+// алфавитов образуют термы, все остальные символы - разделители
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+
+	terms := fields[:0]
+	for _, field := range fields {
+		if len([]rune(field)) >= MIN_TERM_LENGTH {
+			terms = append(terms, field)
+		}
+	}
+
+	return terms
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+)
+
+const (
+	// Кэш ответов модели хранится в RESPONSE_CACHE_DIR временной директории
+	RESPONSE_CACHE_DIR = "response_cache"
+
+	// Размер кэша ответов по умолчанию в байтах
+	DEFAULT_RESPONSE_CACHE_SIZE = 64 * 1024 * 1024
+)
+
+// ResponseCache хранит ответы модели в файлах временной директории и
+// реализует model.ResponseCache. Когда размер кэша превышает maxSize,
+// удаляются записи, которые дольше всех не читались
+type ResponseCache struct {
+	logger  *logging.Logger
+	fs      *FileSystem
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+}
+
+// cacheEntry - запись кэша ответов
+type cacheEntry struct {
+	Key       string                  `json:"key"`
+	Response  model.InferenceResponse `json:"response"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+// NewResponseCache создает кэш ответов размером не больше maxSize байт
+// (0 - DEFAULT_RESPONSE_CACHE_SIZE)
+func NewResponseCache(fs *FileSystem, maxSize int64) (*ResponseCache, error) {
+	if maxSize <= 0 {
+		maxSize = DEFAULT_RESPONSE_CACHE_SIZE
+	}
+
+	dir := filepath.Join(fs.tempDir, RESPONSE_CACHE_DIR)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, i18n.WrapError(err, "storage.cache_dir_create")
+	}
+
+	return &ResponseCache{
+		logger:  logging.NewLogger(),
+		fs:      fs,
+		dir:     dir,
+		maxSize: maxSize,
+	}, nil
+}
+
+// Get возвращает сохраненный ответ по ключу запроса
+func (c *ResponseCache) Get(key string) (*model.InferenceResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		c.logger.Warn("Removing corrupt response cache entry %s", path)
+		os.Remove(path)
+		return nil, false
+	}
+
+	// Время изменения файла - время последнего чтения для вытеснения
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return &entry.Response, true
+}
+
+// Put сохраняет ответ на запрос с ключом key и вытесняет старые записи,
+// если кэш превысил допустимый размер
+func (c *ResponseCache) Put(key string, response *model.InferenceResponse) error {
+	data, err := json.Marshal(cacheEntry{
+		Key:       key,
+		Response:  *response,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return i18n.WrapError(err, "storage.cache_encode")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.fs.WriteFileAtomic(c.path(key), data); err != nil {
+		return i18n.WrapError(err, "storage.cache_write")
+	}
+	return c.evict()
+}
+
+// path возвращает путь файла записи с ключом key
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// evict удаляет давно не читавшиеся записи, пока размер кэша больше
+// maxSize. Вызывается с захваченным мьютексом
+func (c *ResponseCache) evict() error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return i18n.WrapError(err, "storage.cache_read")
+	}
+
+	var files []os.FileInfo
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+		size += info.Size()
+	}
+	if size <= c.maxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	removed := 0
+	for _, info := range files {
+		if size <= c.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, info.Name())); err != nil {
+			c.logger.Warn("Failed to remove response cache entry %s: %v", info.Name(), err)
+			continue
+		}
+		size -= info.Size()
+		removed++
+	}
+
+	c.logger.Info("Evicted %d response cache entries, cache size: %d bytes", removed, size)
+	return nil
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	os.Exit(m.Run())
+}
+
+// newTestCache создает кэш ответов размером maxSize во временной директории теста
+func newTestCache(t *testing.T, maxSize int64) *ResponseCache {
+	t.Helper()
+	cache, err := NewResponseCache(NewFileSystem(t.TempDir()), maxSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}
+
+// touch задает время последнего чтения записи key
+func touch(t *testing.T, c *ResponseCache, key string, at time.Time) {
+	t.Helper()
+	if err := os.Chtimes(c.path(key), at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResponseCacheGetPut(t *testing.T) {
+	c := newTestCache(t, 0)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Get(missing) found an entry")
+	}
+
+	want := model.InferenceResponse{Text: "ответ", TokensUsed: 3}
+	if err := c.Put("key", &want); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := c.Get("key")
+	if !ok || got.Text != want.Text || got.TokensUsed != want.TokensUsed {
+		t.Errorf("Get = %+v, %v; want %+v", got, ok, want)
+	}
+
+	// Запись с чужим ключом считается поврежденной и удаляется
+	if err := os.WriteFile(c.path("other"), []byte(`{"key":"key"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("other"); ok {
+		t.Error("Get returned an entry stored under another key")
+	}
+	if _, err := os.Stat(c.path("other")); !os.IsNotExist(err) {
+		t.Errorf("corrupt entry was not removed: %v", err)
+	}
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	response := &model.InferenceResponse{Text: strings.Repeat("x", 100)}
+
+	probe := newTestCache(t, 0)
+	if err := probe.Put("a", response); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(probe.path("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// В кэш помещаются две записи, третья вытесняет давно не читавшуюся
+	c := newTestCache(t, 2*info.Size()+info.Size()/2)
+	now := time.Now()
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch(t, c, "a", now.Add(-2*time.Hour))
+	touch(t, c, "b", now.Add(-time.Hour))
+
+	// Чтение обновляет время записи a, поэтому вытесняется b
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+	if err := c.Put("c", response); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%s) found = %v, want %v", key, ok, want)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(c.fs.tempDir, RESPONSE_CACHE_DIR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("cache holds %d entries, want 2", len(entries))
+	}
+}
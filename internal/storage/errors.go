@@ -0,0 +1,17 @@
+package storage
+
+import "errors"
+
+// Виды ошибок хранилища файлов, сессий и памяти пользователей
+var (
+	ErrPathOutsideRoot    = errors.New("storage: path outside root")
+	ErrInvalidSessionName = errors.New("storage: invalid session name")
+	ErrSessionNotFound    = errors.New("storage: session not found")
+	ErrSessionExists      = errors.New("storage: session already exists")
+	ErrSessionConflict    = errors.New("storage: session changed concurrently")
+	ErrUnsupportedVersion = errors.New("storage: unsupported session version")
+	ErrCorruptSession     = errors.New("storage: corrupt session")
+	ErrInvalidUserID      = errors.New("storage: invalid user id")
+	ErrMemoryFactNotFound = errors.New("storage: memory fact not found")
+	ErrLockFailed         = errors.New("storage: lock failed")
+)
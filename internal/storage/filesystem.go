@@ -1,8 +1,6 @@
 package storage
 
 import (
-	"errors"
-	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -10,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 )
 
@@ -33,15 +32,6 @@ type FileInfo struct {
 	Permission string    `json:"permission"`
 }
 
-// SessionInfo содержит информацию о сессии
-type SessionInfo struct {
-	Name       string    `json:"name"`
-	Path       string    `json:"path"`
-	CreatedAt  time.Time `json:"created_at"`
-	ModifiedAt time.Time `json:"modified_at"`
-	Size       int64     `json:"size"`
-}
-
 // NewFileSystem создает новый экземпляр FileSystem
 func NewFileSystem(rootDir string) *FileSystem {
 	logger := logging.NewLogger()
@@ -76,7 +66,7 @@ func (fs *FileSystem) CreateFile(dir, name string) (string, error) {
 	// Проверяем, что директория существует внутри нашего корня
 	fullDir := filepath.Join(fs.rootDir, dir)
 	if !fs.isPathSafe(fullDir) {
-		return "", errors.New("путь находится за пределами разрешенной директории")
+		return "", i18n.NewError("storage.path_outside_root").WithKind(ErrPathOutsideRoot)
 	}
 
 	// Создаем директорию, если она не существует
@@ -103,17 +93,59 @@ func (fs *FileSystem) CreateFile(dir, name string) (string, error) {
 func (fs *FileSystem) WriteFile(path string, data []byte) error {
 	// Проверяем, что путь находится внутри нашего корня
 	if !fs.isPathSafe(path) {
-		return errors.New("путь находится за пределами разрешенной директории")
+		return i18n.NewError("storage.path_outside_root").WithKind(ErrPathOutsideRoot)
 	}
 
 	return os.WriteFile(path, data, 0644)
 }
 
+// WriteFileAtomic записывает данные во временный файл и переименовывает его,
+// чтобы читатели никогда не видели частично записанный файл
+func (fs *FileSystem) WriteFileAtomic(path string, data []byte) error {
+	// Проверяем, что путь находится внутри нашего корня
+	if !fs.isPathSafe(path) {
+		return i18n.NewError("storage.path_outside_root").WithKind(ErrPathOutsideRoot)
+	}
+
+	// Временный файл создаем в той же директории, чтобы rename был атомарным
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
+
 // ReadFile читает данные из файла
 func (fs *FileSystem) ReadFile(path string) ([]byte, error) {
 	// Проверяем, что путь находится внутри нашего корня
 	if !fs.isPathSafe(path) {
-		return nil, errors.New("путь находится за пределами разрешенной директории")
+		return nil, i18n.NewError("storage.path_outside_root").WithKind(ErrPathOutsideRoot)
 	}
 
 	return os.ReadFile(path)
@@ -123,7 +155,7 @@ func (fs *FileSystem) ReadFile(path string) ([]byte, error) {
 func (fs *FileSystem) DeleteFile(path string) error {
 	// Проверяем, что путь находится внутри нашего корня
 	if !fs.isPathSafe(path) {
-		return errors.New("путь находится за пределами разрешенной директории")
+		return i18n.NewError("storage.path_outside_root").WithKind(ErrPathOutsideRoot)
 	}
 
 	return os.Remove(path)
@@ -134,12 +166,12 @@ func (fs *FileSystem) ListFiles(dir string) ([]FileInfo, error) {
 	// Проверяем, что директория существует внутри нашего корня
 	fullDir := filepath.Join(fs.rootDir, dir)
 	if !fs.isPathSafe(fullDir) {
-		return nil, errors.New("путь находится за пределами разрешенной директории")
+		return nil, i18n.NewError("storage.path_outside_root").WithKind(ErrPathOutsideRoot)
 	}
 
 	// Проверяем существование директории
 	if _, err := os.Stat(fullDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("директория не существует: %s", dir)
+		return nil, i18n.NewError("storage.dir_not_found", dir)
 	}
 
 	// Читаем содержимое директории
@@ -197,7 +229,7 @@ func (fs *FileSystem) ListFiles(dir string) ([]FileInfo, error) {
 func (fs *FileSystem) CopyFile(src, dst string) error {
 	// Проверяем, что оба пути находятся внутри нашего корня
 	if !fs.isPathSafe(src) || !fs.isPathSafe(dst) {
-		return errors.New("путь находится за пределами разрешенной директории")
+		return i18n.NewError("storage.path_outside_root").WithKind(ErrPathOutsideRoot)
 	}
 
 	// Проверяем существование исходного файла
@@ -206,7 +238,7 @@ func (fs *FileSystem) CopyFile(src, dst string) error {
 		return err
 	}
 	if srcInfo.IsDir() {
-		return errors.New("копирование директорий не поддерживается")
+		return i18n.NewError("storage.copy_dir_unsupported")
 	}
 
 	// Открываем исходный файл
@@ -233,63 +265,9 @@ func (fs *FileSystem) CopyFile(src, dst string) error {
 	return os.Chmod(dst, srcInfo.Mode())
 }
 
-// SaveSession сохраняет сессию
-func (fs *FileSystem) SaveSession(name string, data []byte) error {
-	sessionPath := filepath.Join(fs.sessionDir, name+".json")
-	return fs.WriteFile(sessionPath, data)
-}
-
-// LoadSession загружает сессию
-func (fs *FileSystem) LoadSession(name string) ([]byte, error) {
-	sessionPath := filepath.Join(fs.sessionDir, name+".json")
-	return fs.ReadFile(sessionPath)
-}
-
-// ListSessions возвращает список доступных сессий
-func (fs *FileSystem) ListSessions() ([]SessionInfo, error) {
-	// Проверяем существование директории с сессиями
-	if _, err := os.Stat(fs.sessionDir); os.IsNotExist(err) {
-		return nil, errors.New("директория сессий не существует")
-	}
-
-	// Читаем содержимое директории
-	entries, err := os.ReadDir(fs.sessionDir)
-	if err != nil {
-		return nil, err
-	}
-
-	// Подготавливаем результат
-	result := make([]SessionInfo, 0, len(entries))
-	for _, entry := range entries {
-		// Пропускаем не JSON файлы
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
-			info, err := entry.Info()
-			if err != nil {
-				fs.logger.Warn("Failed to get info for %s: %v", entry.Name(), err)
-				continue
-			}
-
-			path := filepath.Join(fs.sessionDir, entry.Name())
-			name := entry.Name()[:len(entry.Name())-len(".json")]
-
-			sessionInfo := SessionInfo{
-				Name:       name,
-				Path:       path,
-				CreatedAt:  info.ModTime(),
-				ModifiedAt: info.ModTime(),
-				Size:       info.Size(),
-			}
-
-			result = append(result, sessionInfo)
-		}
-	}
-
-	// Сортируем по времени изменения (от новых к старым)
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].ModifiedAt.After(result[j].ModifiedAt)
-	})
-
-	return result, nil
+// GetRootDir возвращает корневую директорию хранилища
+func (fs *FileSystem) GetRootDir() string {
+	return fs.rootDir
 }
 
 // isPathSafe проверяет, что путь находится внутри корневой директории
@@ -305,6 +283,6 @@ func (fs *FileSystem) isPathSafe(path string) bool {
 		return false
 	}
 
-	// Проверяем, что путь начинается с корневой директории
-	return strings.HasPrefix(absPath, absRoot)
+	// Проверяем, что путь совпадает с корневой директорией или находится внутри нее
+	return absPath == absRoot || strings.HasPrefix(absPath, absRoot+string(filepath.Separator))
 }
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"os"
+	"syscall"
+
+	"smollm-sandbox/internal/i18n"
+)
+
+// FileLock представляет межпроцессную блокировку на основе flock(2).
+// Используется, чтобы CLI и Telegram бот не перезаписывали общие файлы
+// хранилища (сессии, поисковый индекс) друг друга
+type FileLock struct {
+	file *os.File
+}
+
+// LockFile захватывает исключительную блокировку файла path, создавая его
+// при необходимости. Вызов блокируется, пока блокировку держит другой процесс
+func LockFile(path string) (*FileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, i18n.WrapError(err, "storage.lock_open").WithKind(ErrLockFailed)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, i18n.WrapError(err, "storage.lock_acquire").WithKind(ErrLockFailed)
+	}
+
+	return &FileLock{file: file}, nil
+}
+
+// Unlock освобождает блокировку
+func (l *FileLock) Unlock() error {
+	defer l.file.Close()
+	return syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+}
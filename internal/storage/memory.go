@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+)
+
+const (
+	// Личные данные пользователей хранятся в USERS_DIR/<id> корня хранилища
+	USERS_DIR = "users"
+
+	// Файлы долговременной памяти в директории пользователя
+	MEMORY_FILE      = "memory.json"
+	MEMORY_LOCK_FILE = ".memory.lock"
+
+	// Максимальное количество фактов; при переполнении удаляются самые старые
+	MAX_MEMORY_FACTS = 50
+
+	// Максимальная длина одного факта в символах
+	MAX_MEMORY_FACT_LENGTH = 300
+)
+
+// UserMemory хранит долговременную память пользователя и реализует model.MemoryStore
+type UserMemory struct {
+	logger *logging.Logger
+	fs     *FileSystem
+	userID string
+	dir    string
+}
+
+// NewUserMemory создает память пользователя userID. Идентификатор может
+// содержать только латинские буквы, цифры, '-' и '_'
+func NewUserMemory(fs *FileSystem, userID string) (*UserMemory, error) {
+	if !isValidSessionName(userID) {
+		return nil, i18n.NewError("storage.invalid_user_id", userID).WithKind(ErrInvalidUserID)
+	}
+
+	dir := filepath.Join(fs.rootDir, USERS_DIR, userID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, i18n.WrapError(err, "storage.user_dir_create")
+	}
+
+	return &UserMemory{
+		logger: logging.NewLogger(),
+		fs:     fs,
+		userID: userID,
+		dir:    dir,
+	}, nil
+}
+
+// Facts возвращает сохраненные факты в порядке добавления
+func (m *UserMemory) Facts() ([]model.MemoryFact, error) {
+	return m.load()
+}
+
+// Remember добавляет факт. Повторно тот же факт не сохраняется
+func (m *UserMemory) Remember(text, source string) (model.MemoryFact, error) {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return model.MemoryFact{}, i18n.NewError("storage.memory_empty_fact")
+	}
+	if runes := []rune(text); len(runes) > MAX_MEMORY_FACT_LENGTH {
+		text = string(runes[:MAX_MEMORY_FACT_LENGTH])
+	}
+
+	fact := model.MemoryFact{
+		Text:      text,
+		Source:    source,
+		CreatedAt: time.Now(),
+	}
+
+	err := m.update(func(facts []model.MemoryFact) []model.MemoryFact {
+		for _, existing := range facts {
+			if strings.EqualFold(existing.Text, text) {
+				fact = existing
+				return facts
+			}
+		}
+
+		facts = append(facts, fact)
+		if len(facts) > MAX_MEMORY_FACTS {
+			facts = facts[len(facts)-MAX_MEMORY_FACTS:]
+		}
+		return facts
+	})
+	if err != nil {
+		return model.MemoryFact{}, err
+	}
+
+	m.logger.Info("Remembered fact for user %s (%s)", m.userID, source)
+	return fact, nil
+}
+
+// Forget удаляет факт с номером index (с единицы) из списка Facts
+func (m *UserMemory) Forget(index int) (model.MemoryFact, error) {
+	var removed model.MemoryFact
+
+	err := m.update(func(facts []model.MemoryFact) []model.MemoryFact {
+		if index < 1 || index > len(facts) {
+			return nil
+		}
+		removed = facts[index-1]
+		return append(facts[:index-1], facts[index:]...)
+	})
+	if err != nil {
+		return model.MemoryFact{}, err
+	}
+	if removed.Text == "" {
+		return model.MemoryFact{}, i18n.NewError("storage.memory_fact_not_found", index).WithKind(ErrMemoryFactNotFound)
+	}
+
+	return removed, nil
+}
+
+// Clear удаляет все факты пользователя
+func (m *UserMemory) Clear() error {
+	return m.update(func(facts []model.MemoryFact) []model.MemoryFact {
+		return []model.MemoryFact{}
+	})
+}
+
+// update загружает факты под блокировкой, изменяет и атомарно сохраняет их.
+// Если change возвращает nil, файл не изменяется
+func (m *UserMemory) update(change func(facts []model.MemoryFact) []model.MemoryFact) error {
+	lock, err := LockFile(filepath.Join(m.dir, MEMORY_LOCK_FILE))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	facts, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	facts = change(facts)
+	if facts == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(facts, "", "  ")
+	if err != nil {
+		return i18n.WrapError(err, "storage.memory_encode")
+	}
+
+	return m.fs.WriteFileAtomic(m.path(), data)
+}
+
+// load читает файл памяти. Отсутствующий файл означает пустую память
+func (m *UserMemory) load() ([]model.MemoryFact, error) {
+	data, err := m.fs.ReadFile(m.path())
+	if errors.Is(err, fs.ErrNotExist) {
+		return []model.MemoryFact{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var facts []model.MemoryFact
+	if err := json.Unmarshal(data, &facts); err != nil {
+		return nil, i18n.WrapError(err, "storage.memory_decode")
+	}
+
+	return facts, nil
+}
+
+// path возвращает путь к файлу памяти
+func (m *UserMemory) path() string {
+	return filepath.Join(m.dir, MEMORY_FILE)
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// SessionMigration преобразует сырые данные сессии из версии From в From+1.
+// Миграции работают с map, а не с model.Context, потому что структура
+// контекста всегда соответствует только текущей версии формата
+type SessionMigration struct {
+	From        int
+	Description string
+	Apply       func(data map[string]any) error
+}
+
+// sessionMigrations содержит зарегистрированные миграции по исходной версии
+var sessionMigrations = map[int]SessionMigration{}
+
+// RegisterSessionMigration добавляет миграцию формата сессии
+func RegisterSessionMigration(migration SessionMigration) {
+	if _, exists := sessionMigrations[migration.From]; exists {
+		panic(fmt.Sprintf("миграция сессий с версии %d уже зарегистрирована", migration.From))
+	}
+	sessionMigrations[migration.From] = migration
+}
+
+func init() {
+	RegisterSessionMigration(SessionMigration{
+		From:        0,
+		Description: "добавлено поле schema_version, восстановлены отсутствующие metadata и state",
+		Apply:       migrateSessionV0,
+	})
+	RegisterSessionMigration(SessionMigration{
+		From:        1,
+		Description: "сообщения связаны в дерево через id и parent_id, добавлен active_leaf",
+		Apply:       migrateSessionV1,
+	})
+}
+
+// sessionSchemaVersion возвращает версию формата сырых данных сессии.
+// Файлы без поля schema_version считаются версией 0
+func sessionSchemaVersion(data map[string]any) int {
+	if version, ok := data["schema_version"].(float64); ok {
+		return int(version)
+	}
+	return 0
+}
+
+// MigrateSessionData приводит JSON сессии к текущей версии формата.
+// Возвращает новые данные и исходную версию; если миграция не нужна,
+// возвращаются исходные данные без изменений
+func MigrateSessionData(raw []byte) ([]byte, int, error) {
+	var data map[string]any
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, 0, i18n.WrapError(err, "storage.session_decode").WithKind(ErrCorruptSession)
+	}
+
+	from := sessionSchemaVersion(data)
+	if from == model.CONTEXT_SCHEMA_VERSION {
+		return raw, from, nil
+	}
+	if from > model.CONTEXT_SCHEMA_VERSION {
+		return nil, from, i18n.NewError("storage.session_too_new", from, model.CONTEXT_SCHEMA_VERSION).WithKind(ErrUnsupportedVersion)
+	}
+
+	for version := from; version < model.CONTEXT_SCHEMA_VERSION; version++ {
+		migration, ok := sessionMigrations[version]
+		if !ok {
+			return nil, from, i18n.NewError("storage.migration_missing", version).WithKind(ErrUnsupportedVersion)
+		}
+		if err := migration.Apply(data); err != nil {
+			return nil, from, i18n.WrapError(err, "storage.migration_failed", version)
+		}
+		data["schema_version"] = version + 1
+	}
+
+	migrated, err := json.Marshal(data)
+	if err != nil {
+		return nil, from, i18n.WrapError(err, "storage.session_encode")
+	}
+
+	return migrated, from, nil
+}
+
+// migrateSessionV0 восстанавливает поля, которые старые версии могли не записывать
+func migrateSessionV0(data map[string]any) error {
+	if _, ok := data["messages"].([]any); !ok {
+		data["messages"] = []any{}
+	}
+
+	metadata, ok := data["metadata"].(map[string]any)
+	if !ok {
+		metadata = map[string]any{}
+		data["metadata"] = metadata
+	}
+	if _, ok := metadata["properties"].(map[string]any); !ok {
+		metadata["properties"] = map[string]any{}
+	}
+
+	state, ok := data["state"].(map[string]any)
+	if !ok {
+		state = map[string]any{}
+		data["state"] = state
+	}
+	defaults := map[string]any{
+		"mode":             "chat",
+		"tokens_used":      0,
+		"temperature":      model.TEMPERATURE,
+		"top_p":            model.TOP_P,
+		"thinking_enabled": false,
+	}
+	for key, value := range defaults {
+		if _, ok := state[key]; !ok {
+			state[key] = value
+		}
+	}
+
+	return nil
+}
+
+// migrateSessionV1 превращает линейный список сообщений в одну ветку дерева
+func migrateSessionV1(data map[string]any) error {
+	messages, _ := data["messages"].([]any)
+
+	parentID := ""
+	for i, item := range messages {
+		msg, ok := item.(map[string]any)
+		if !ok {
+			return i18n.NewError("storage.message_malformed", i+1).WithKind(ErrCorruptSession)
+		}
+
+		id := fmt.Sprintf("m%d", i+1)
+		msg["id"] = id
+		if parentID != "" {
+			msg["parent_id"] = parentID
+		}
+		parentID = id
+	}
+
+	data["active_leaf"] = parentID
+	return nil
+}
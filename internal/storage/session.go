@@ -4,29 +4,69 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
+	"smollm-sandbox/internal/i18n"
 	"smollm-sandbox/internal/logging"
 	"smollm-sandbox/internal/model"
 )
 
-// SessionManager управляет сессиями нейросети
+// Имя файла блокировки в директории сессий
+const SESSIONS_LOCK_FILE = ".lock"
+
+// SessionManager управляет сессиями нейросети и реализует model.SessionStore
 type SessionManager struct {
 	logger      *logging.Logger
 	fs          *FileSystem
 	sessionsDir string
+	listeners   []SessionListener
+}
+
+// SessionListener получает уведомления об изменении сохраненных сессий,
+// например для обновления поискового индекса
+type SessionListener interface {
+	SessionSaved(name string, context *model.Context)
+	SessionDeleted(name string)
 }
 
 // SessionMeta представляет метаинформацию о сессии
 type SessionMeta struct {
-	Name         string    `json:"name"`
-	Path         string    `json:"path"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	MessageCount int       `json:"message_count"`
+	Name          string    `json:"name"`
+	Path          string    `json:"path"`
+	SchemaVersion int       `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	MessageCount  int       `json:"message_count"`
+}
+
+// SessionMigrationReport описывает результат миграции одного файла сессии
+type SessionMigrationReport struct {
+	Name        string `json:"name"`
+	FromVersion int    `json:"from_version"`
+	ToVersion   int    `json:"to_version"`
+	Migrated    bool   `json:"migrated"`         // Файл был (или при dry-run будет) обновлен
+	Backup      string `json:"backup,omitempty"` // Путь к копии исходного файла
+	Error       string `json:"error,omitempty"`
+
+	err error // Исходная ошибка с кодом сообщения
+}
+
+// fail записывает ошибку миграции в отчет
+func (r *SessionMigrationReport) fail(err error) SessionMigrationReport {
+	r.err = err
+	r.Error = err.Error()
+	return *r
+}
+
+// Err возвращает ошибку миграции или nil
+func (r SessionMigrationReport) Err() error {
+	return r.err
 }
 
 // NewSessionManager создает новый экземпляр SessionManager
@@ -48,28 +88,107 @@ func NewSessionManager(fs *FileSystem, sessionsDir string) *SessionManager {
 	}
 }
 
-// SaveSession сохраняет контекст сессии
+// AddListener подписывает слушателя на изменения сессий
+func (sm *SessionManager) AddListener(listener SessionListener) {
+	sm.listeners = append(sm.listeners, listener)
+}
+
+// SaveSession сохраняет контекст сессии. Запись выполняется атомарно под
+// блокировкой, поэтому CLI и Telegram бот могут работать с одним хранилищем.
+// Если сохраненная копия той же сессии содержит сообщения, которых нет в
+// context (ее изменил другой процесс после загрузки), файл не
+// перезаписывается и возвращается ошибка вида ErrSessionConflict
 func (sm *SessionManager) SaveSession(name string, context *model.Context) error {
+	return sm.writeSession(name, context, false)
+}
+
+// CreateSession сохраняет контекст новой сессии. Если сессия name уже
+// существует, возвращается ошибка вида ErrSessionExists
+func (sm *SessionManager) CreateSession(name string, context *model.Context) error {
+	return sm.writeSession(name, context, true)
+}
+
+// writeSession записывает файл сессии под блокировкой. При create файл
+// записывается, только если сессии еще нет
+func (sm *SessionManager) writeSession(name string, context *model.Context, create bool) error {
 	// Проверяем название сессии на допустимые символы
 	if !isValidSessionName(name) {
-		return errors.New("недопустимое имя сессии (разрешены только буквы, цифры, дефисы и подчеркивания)")
+		return i18n.NewError("storage.invalid_session_name_hint").WithKind(ErrInvalidSessionName)
+	}
+
+	lock, err := sm.lock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	// Существование и содержимое файла проверяются под той же блокировкой,
+	// под которой он записывается
+	if create && sm.SessionExists(name) {
+		return i18n.NewError("storage.session_exists", name).WithKind(ErrSessionExists)
+	}
+	if !create {
+		if err := sm.checkConflict(name, context); err != nil {
+			return err
+		}
 	}
 
 	// Сериализуем контекст в JSON
 	data, err := context.ToJSON()
 	if err != nil {
-		return fmt.Errorf("ошибка сериализации контекста: %v", err)
+		return i18n.WrapError(err, "storage.context_encode")
 	}
 
 	// Создаем путь к файлу сессии
-	sessionPath := filepath.Join(sm.sessionsDir, name+".json")
+	sessionPath := sm.sessionPath(name)
 
 	// Сохраняем файл
-	if err := sm.fs.WriteFile(sessionPath, data); err != nil {
-		return fmt.Errorf("ошибка записи файла сессии: %v", err)
+	if err := sm.fs.WriteFileAtomic(sessionPath, data); err != nil {
+		return i18n.WrapError(err, "storage.session_file_write")
 	}
 
 	sm.logger.Info("Сессия успешно сохранена: %s", name)
+	for _, listener := range sm.listeners {
+		listener.SessionSaved(name, context)
+	}
+	return nil
+}
+
+// checkConflict проверяет, что сохранение context не потеряет сообщений
+// сохраненной сессии name. Сообщения контекста только добавляются, поэтому
+// сохраненная копия той же сессии должна быть началом context. Другая
+// сессия, сохраняемая под тем же именем, заменяет файл. Вызывается под
+// блокировкой
+func (sm *SessionManager) checkConflict(name string, context *model.Context) error {
+	data, err := sm.fs.ReadFile(sm.sessionPath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return sessionReadError(err)
+	}
+
+	data, _, err = MigrateSessionData(data)
+	if err != nil {
+		return err
+	}
+	stored := model.NewContext()
+	if err := stored.FromJSON(data); err != nil {
+		return i18n.WrapError(err, "storage.context_decode").WithKind(ErrCorruptSession)
+	}
+	if stored.SessionID != context.SessionID {
+		return nil
+	}
+
+	conflict := i18n.NewError("storage.session_conflict", name).WithKind(ErrSessionConflict)
+	if len(stored.Messages) > len(context.Messages) {
+		return conflict
+	}
+	for i, msg := range stored.Messages {
+		if msg.ID != context.Messages[i].ID || !msg.Timestamp.Equal(context.Messages[i].Timestamp) {
+			return conflict
+		}
+	}
 	return nil
 }
 
@@ -77,22 +196,35 @@ func (sm *SessionManager) SaveSession(name string, context *model.Context) error
 func (sm *SessionManager) LoadSession(name string) (*model.Context, error) {
 	// Проверяем название сессии
 	if !isValidSessionName(name) {
-		return nil, errors.New("недопустимое имя сессии")
+		return nil, i18n.NewError("storage.invalid_session_name").WithKind(ErrInvalidSessionName)
 	}
 
 	// Формируем путь к файлу сессии
-	sessionPath := filepath.Join(sm.sessionsDir, name+".json")
+	sessionPath := sm.sessionPath(name)
 
 	// Загружаем файл
 	data, err := sm.fs.ReadFile(sessionPath)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения файла сессии: %v", err)
+		return nil, sessionReadError(err)
+	}
+
+	// Файлы старых версий обновляем на диске, сохраняя копию оригинала
+	if _, from, err := MigrateSessionData(data); err != nil {
+		return nil, err
+	} else if from != model.CONTEXT_SCHEMA_VERSION {
+		report := sm.migrateSession(name, false)
+		if err := report.Err(); err != nil {
+			return nil, err
+		}
+		if data, err = sm.fs.ReadFile(sessionPath); err != nil {
+			return nil, sessionReadError(err)
+		}
 	}
 
 	// Десериализуем контекст из JSON
 	context := model.NewContext()
 	if err := context.FromJSON(data); err != nil {
-		return nil, fmt.Errorf("ошибка десериализации контекста: %v", err)
+		return nil, i18n.WrapError(err, "storage.context_decode").WithKind(ErrCorruptSession)
 	}
 
 	sm.logger.Info("Сессия успешно загружена: %s", name)
@@ -103,18 +235,27 @@ func (sm *SessionManager) LoadSession(name string) (*model.Context, error) {
 func (sm *SessionManager) DeleteSession(name string) error {
 	// Проверяем название сессии
 	if !isValidSessionName(name) {
-		return errors.New("недопустимое имя сессии")
+		return i18n.NewError("storage.invalid_session_name").WithKind(ErrInvalidSessionName)
+	}
+
+	lock, err := sm.lock()
+	if err != nil {
+		return err
 	}
+	defer lock.Unlock()
 
 	// Формируем путь к файлу сессии
-	sessionPath := filepath.Join(sm.sessionsDir, name+".json")
+	sessionPath := sm.sessionPath(name)
 
 	// Удаляем файл
 	if err := sm.fs.DeleteFile(sessionPath); err != nil {
-		return fmt.Errorf("ошибка удаления файла сессии: %v", err)
+		return i18n.WrapError(err, "storage.session_file_delete")
 	}
 
 	sm.logger.Info("Сессия успешно удалена: %s", name)
+	for _, listener := range sm.listeners {
+		listener.SessionDeleted(name)
+	}
 	return nil
 }
 
@@ -123,7 +264,7 @@ func (sm *SessionManager) ListSessions() ([]SessionMeta, error) {
 	// Получаем список файлов в директории сессий
 	files, err := sm.fs.ListFiles(sm.sessionsDir)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения директории сессий: %v", err)
+		return nil, i18n.WrapError(err, "storage.sessions_dir_read")
 	}
 
 	// Фильтруем и собираем информацию о сессиях
@@ -138,7 +279,7 @@ func (sm *SessionManager) ListSessions() ([]SessionMeta, error) {
 		name := strings.TrimSuffix(file.Name, ".json")
 
 		// Читаем файл для получения дополнительной информации
-		data, err := sm.fs.ReadFile(filepath.Join(sm.sessionsDir, file.Name))
+		data, err := sm.fs.ReadFile(file.Path)
 		if err != nil {
 			sm.logger.Warn("Не удалось прочитать файл сессии %s: %v", file.Name, err)
 			continue
@@ -152,8 +293,8 @@ func (sm *SessionManager) ListSessions() ([]SessionMeta, error) {
 		}
 
 		// Извлекаем информацию о сессии
+		schemaVersion := sessionSchemaVersion(contextData)
 		var createdAt, updatedAt time.Time
-		var messageCount int
 
 		if metadata, ok := contextData["metadata"].(map[string]interface{}); ok {
 			if createdAtStr, ok := metadata["created_at"].(string); ok {
@@ -164,23 +305,264 @@ func (sm *SessionManager) ListSessions() ([]SessionMeta, error) {
 			}
 		}
 
-		if messages, ok := contextData["messages"].([]interface{}); ok {
-			messageCount = len(messages)
-		}
-
 		// Добавляем в результат
 		sessions = append(sessions, SessionMeta{
-			Name:         name,
-			Path:         filepath.Join(sm.sessionsDir, file.Name),
-			CreatedAt:    createdAt,
-			UpdatedAt:    updatedAt,
-			MessageCount: messageCount,
+			Name:          name,
+			Path:          file.Path,
+			SchemaVersion: schemaVersion,
+			CreatedAt:     createdAt,
+			UpdatedAt:     updatedAt,
+			MessageCount:  activeMessageCount(data, contextData),
 		})
 	}
 
+	// Сортируем по времени изменения (от новых к старым)
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+
 	return sessions, nil
 }
 
+// GetSessionMeta возвращает метаинформацию об одной сессии
+func (sm *SessionManager) GetSessionMeta(name string) (SessionMeta, error) {
+	context, err := sm.LoadSession(name)
+	if err != nil {
+		return SessionMeta{}, err
+	}
+
+	return SessionMeta{
+		Name:          name,
+		Path:          sm.sessionPath(name),
+		SchemaVersion: context.SchemaVersion,
+		CreatedAt:     context.Metadata.CreatedAt,
+		UpdatedAt:     context.Metadata.UpdatedAt,
+		MessageCount:  len(context.ActiveBranch()),
+	}, nil
+}
+
+// activeMessageCount возвращает число сообщений активной ветки сессии, как
+// в Context.GetSummary. Файлы старых версий приводятся к текущему формату в
+// памяти; если это невозможно, учитываются все сообщения файла
+func activeMessageCount(data []byte, contextData map[string]interface{}) int {
+	if migrated, _, err := MigrateSessionData(data); err == nil {
+		context := model.NewContext()
+		if err := context.FromJSON(migrated); err == nil {
+			return len(context.ActiveBranch())
+		}
+	}
+
+	if messages, ok := contextData["messages"].([]interface{}); ok {
+		return len(messages)
+	}
+	return 0
+}
+
+// SessionExists проверяет, существует ли сессия с указанным именем
+func (sm *SessionManager) SessionExists(name string) bool {
+	if !isValidSessionName(name) {
+		return false
+	}
+
+	_, err := os.Stat(sm.sessionPath(name))
+	return err == nil
+}
+
+// RenameSession переименовывает сессию
+func (sm *SessionManager) RenameSession(oldName, newName string) error {
+	if !isValidSessionName(oldName) || !isValidSessionName(newName) {
+		return i18n.NewError("storage.invalid_session_name").WithKind(ErrInvalidSessionName)
+	}
+
+	if err := sm.renameSessionFile(oldName, newName); err != nil {
+		return err
+	}
+
+	sm.logger.Info("Сессия переименована: %s -> %s", oldName, newName)
+
+	// Уведомляем после снятия блокировки: загрузка сессии может ее захватить
+	if len(sm.listeners) > 0 {
+		context, err := sm.LoadSession(newName)
+		for _, listener := range sm.listeners {
+			listener.SessionDeleted(oldName)
+			if err == nil {
+				listener.SessionSaved(newName, context)
+			}
+		}
+	}
+	return nil
+}
+
+// renameSessionFile переименовывает файл сессии под блокировкой
+func (sm *SessionManager) renameSessionFile(oldName, newName string) error {
+	lock, err := sm.lock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	if !sm.SessionExists(oldName) {
+		return i18n.NewError("storage.session_not_found", oldName).WithKind(ErrSessionNotFound)
+	}
+	if sm.SessionExists(newName) {
+		return i18n.NewError("storage.session_exists", newName).WithKind(ErrSessionExists)
+	}
+
+	if err := os.Rename(sm.sessionPath(oldName), sm.sessionPath(newName)); err != nil {
+		return i18n.WrapError(err, "storage.session_file_rename")
+	}
+
+	return nil
+}
+
+// ForkSession создает копию сессии под новым именем с новым идентификатором
+func (sm *SessionManager) ForkSession(sourceName, targetName string) error {
+	context, err := sm.LoadSession(sourceName)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	context.SessionID = fmt.Sprintf("session_%d", now.UnixNano())
+	context.Metadata.CreatedAt = now
+	context.SetProperty("forked_from", sourceName)
+
+	return sm.CreateSession(targetName, context)
+}
+
+// ExportSession записывает сессию в формате JSON
+func (sm *SessionManager) ExportSession(name string, w io.Writer) error {
+	context, err := sm.LoadSession(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(context, "", "  ")
+	if err != nil {
+		return i18n.WrapError(err, "storage.context_encode")
+	}
+
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return i18n.WrapError(err, "storage.session_write")
+	}
+
+	return nil
+}
+
+// ImportSession сохраняет сессию из JSON под указанным именем
+func (sm *SessionManager) ImportSession(name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return i18n.WrapError(err, "storage.session_read")
+	}
+
+	// Экспорт мог быть сделан в старой версии формата
+	data, _, err = MigrateSessionData(data)
+	if err != nil {
+		return err
+	}
+
+	context := model.NewContext()
+	if err := context.FromJSON(data); err != nil {
+		return i18n.WrapError(err, "storage.context_decode").WithKind(ErrCorruptSession)
+	}
+
+	return sm.CreateSession(name, context)
+}
+
+// MigrateSessions приводит все файлы сессий к текущей версии формата.
+// В режиме dryRun файлы не изменяются, а только проверяются
+func (sm *SessionManager) MigrateSessions(dryRun bool) ([]SessionMigrationReport, error) {
+	files, err := sm.fs.ListFiles(sm.sessionsDir)
+	if err != nil {
+		return nil, i18n.WrapError(err, "storage.sessions_dir_read")
+	}
+
+	reports := make([]SessionMigrationReport, 0, len(files))
+	for _, file := range files {
+		if file.IsDir || !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+
+		reports = append(reports, sm.migrateSession(strings.TrimSuffix(file.Name, ".json"), dryRun))
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Name < reports[j].Name
+	})
+
+	return reports, nil
+}
+
+// migrateSession обновляет файл сессии до текущей версии формата под
+// блокировкой, предварительно сохраняя копию исходного файла
+func (sm *SessionManager) migrateSession(name string, dryRun bool) SessionMigrationReport {
+	report := SessionMigrationReport{
+		Name:      name,
+		ToVersion: model.CONTEXT_SCHEMA_VERSION,
+	}
+
+	if !dryRun {
+		lock, err := sm.lock()
+		if err != nil {
+			return report.fail(err)
+		}
+		defer lock.Unlock()
+	}
+
+	// Перечитываем файл под блокировкой: его мог обновить другой процесс
+	sessionPath := sm.sessionPath(name)
+	data, err := sm.fs.ReadFile(sessionPath)
+	if err != nil {
+		return report.fail(sessionReadError(err))
+	}
+
+	migrated, from, err := MigrateSessionData(data)
+	report.FromVersion = from
+	if err != nil {
+		return report.fail(err)
+	}
+	if from == model.CONTEXT_SCHEMA_VERSION {
+		return report
+	}
+
+	report.Migrated = true
+	report.Backup = fmt.Sprintf("%s.v%d.bak", sessionPath, from)
+	if dryRun {
+		return report
+	}
+
+	if err := sm.fs.WriteFileAtomic(report.Backup, data); err != nil {
+		return report.fail(i18n.WrapError(err, "storage.backup_failed"))
+	}
+	if err := sm.fs.WriteFileAtomic(sessionPath, migrated); err != nil {
+		return report.fail(i18n.WrapError(err, "storage.session_file_write"))
+	}
+
+	sm.logger.Info("Сессия %s обновлена с версии формата %d до %d", name, from, model.CONTEXT_SCHEMA_VERSION)
+	return report
+}
+
+// lock захватывает блокировку директории сессий для изменения файлов
+func (sm *SessionManager) lock() (*FileLock, error) {
+	return LockFile(filepath.Join(sm.fs.rootDir, sm.sessionsDir, SESSIONS_LOCK_FILE))
+}
+
+// sessionPath возвращает полный путь к файлу сессии
+func (sm *SessionManager) sessionPath(name string) string {
+	return filepath.Join(sm.fs.rootDir, sm.sessionsDir, name+".json")
+}
+
+// sessionReadError оборачивает ошибку чтения файла сессии; отсутствующий
+// файл дополнительно отмечается как ErrSessionNotFound
+func sessionReadError(err error) error {
+	readErr := i18n.WrapError(err, "storage.session_file_read")
+	if errors.Is(err, fs.ErrNotExist) {
+		readErr.WithKind(ErrSessionNotFound)
+	}
+	return readErr
+}
+
 // isValidSessionName проверяет допустимость имени сессии
 func isValidSessionName(name string) bool {
 	if name == "" || len(name) > 64 {
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"smollm-sandbox/internal/model"
+)
+
+// newTestSessions создает хранилище сессий во временной директории теста
+func newTestSessions(t *testing.T) *SessionManager {
+	t.Helper()
+	return NewSessionManager(NewFileSystem(t.TempDir()), "sessions")
+}
+
+func TestSaveSessionConflict(t *testing.T) {
+	sm := newTestSessions(t)
+
+	session := model.NewContext()
+	session.AddUserMessage("hello")
+	if err := sm.SaveSession("chat", session); err != nil {
+		t.Fatal(err)
+	}
+
+	// Два процесса загружают сессию и добавляют в нее ходы
+	first, err := sm.LoadSession("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := sm.LoadSession("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.AddUserMessage("from first")
+	second.AddUserMessage("from second")
+
+	if err := sm.SaveSession("chat", first); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+	if err := sm.SaveSession("chat", second); !errors.Is(err, ErrSessionConflict) {
+		t.Fatalf("second save = %v, want ErrSessionConflict", err)
+	}
+
+	// Следующие ходы сохраняются поверх своей же копии
+	first.AddAssistantMessage("reply")
+	if err := sm.SaveSession("chat", first); err != nil {
+		t.Fatalf("repeated save: %v", err)
+	}
+
+	stored, err := sm.LoadSession("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Messages) != 3 || stored.Messages[1].Content != "from first" {
+		t.Errorf("stored messages = %+v", stored.Messages)
+	}
+
+	// Другая сессия под тем же именем заменяет файл явно
+	other := model.NewContext()
+	other.SessionID = "other"
+	if err := sm.SaveSession("chat", other); err != nil {
+		t.Errorf("save of another session: %v", err)
+	}
+}
+
+func TestCreateSessionOnce(t *testing.T) {
+	sm := newTestSessions(t)
+
+	source := model.NewContext()
+	source.AddUserMessage("hello")
+	if err := sm.SaveSession("source", source); err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 8
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				errs[i] = sm.ForkSession("source", "copy")
+			} else {
+				errs[i] = sm.ImportSession("copy", strings.NewReader(`{"schema_version":2,"session_id":"imported","messages":[]}`))
+			}
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrSessionExists):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("created %d times, want exactly once", created)
+	}
+}
+
+func TestSessionMessageCountActiveBranch(t *testing.T) {
+	sm := newTestSessions(t)
+
+	session := model.NewContext()
+	session.AddSystemMessage("system")
+	session.AddUserMessage("question")
+	session.AddAssistantMessage("first answer")
+	if _, err := session.PrepareRetry(); err != nil {
+		t.Fatal(err)
+	}
+	session.AddAssistantMessage("second answer")
+	if err := sm.SaveSession("chat", session); err != nil {
+		t.Fatal(err)
+	}
+
+	want := len(session.ActiveBranch())
+	if want != 3 {
+		t.Fatalf("active branch has %d messages, want 3", want)
+	}
+
+	meta, err := sm.GetSessionMeta("chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.MessageCount != want {
+		t.Errorf("GetSessionMeta().MessageCount = %d, want %d", meta.MessageCount, want)
+	}
+
+	list, err := sm.ListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].MessageCount != want {
+		t.Errorf("ListSessions() = %+v, want one session with %d messages", list, want)
+	}
+}
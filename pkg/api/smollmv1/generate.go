@@ -0,0 +1,4 @@
+// Package smollmv1 содержит код, сгенерированный по proto/smollm/v1/smollm.proto
+package smollmv1
+
+//go:generate protoc -I ../../../proto --go_out=. --go_opt=module=smollm-sandbox/pkg/api/smollmv1 --go-grpc_out=. --go-grpc_opt=module=smollm-sandbox/pkg/api/smollmv1 smollm/v1/smollm.proto
@@ -0,0 +1,199 @@
+// Package client - клиент gRPC сервиса SmolLM Sandbox для внутренних сервисов
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"smollm-sandbox/pkg/api/smollmv1"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Домен ErrorInfo ошибок сервиса
+const ERROR_DOMAIN = "smollm-sandbox"
+
+// Options содержит параметры подключения
+type Options struct {
+	APIKey string // Ключ доступа; передается в заголовке authorization
+
+	// Дополнительные параметры подключения. Без параметров транспорта
+	// используется соединение без TLS, как во внутренней сети
+	DialOptions []grpc.DialOption
+}
+
+// Client - клиент сервиса smollm.v1.SmolLM
+type Client struct {
+	conn *grpc.ClientConn
+	rpc  smollmv1.SmolLMClient
+}
+
+// GenerateCallbacks получает события генерации до итогового результата
+type GenerateCallbacks struct {
+	OnQueued func(position int) // Запрос ждет в очереди на позиции position
+	OnText   func(delta string) // Очередной фрагмент текста ответа
+}
+
+// New создает клиент сервиса по адресу target (например, localhost:9090)
+func New(target string, opts Options) (*Client, error) {
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if opts.APIKey != "" {
+		dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(apiKeyCredentials(opts.APIKey)))
+	}
+	dialOptions = append(dialOptions, opts.DialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromConn(conn), nil
+}
+
+// NewFromConn создает клиент поверх готового соединения
+func NewFromConn(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn, rpc: smollmv1.NewSmolLMClient(conn)}
+}
+
+// Close закрывает соединение
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// RPC возвращает сгенерированный клиент для вызовов без оберток
+func (c *Client) RPC() smollmv1.SmolLMClient {
+	return c.rpc
+}
+
+// Generate генерирует ответ и возвращает итоговый результат. Позиции в
+// очереди и фрагменты текста передаются в callbacks по мере поступления
+func (c *Client) Generate(ctx context.Context, req *smollmv1.GenerateRequest, callbacks GenerateCallbacks) (*smollmv1.GenerateResult, error) {
+	stream, err := c.rpc.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch event := response.Event.(type) {
+		case *smollmv1.GenerateResponse_Queued:
+			if callbacks.OnQueued != nil {
+				callbacks.OnQueued(int(event.Queued.Position))
+			}
+		case *smollmv1.GenerateResponse_Delta:
+			if callbacks.OnText != nil {
+				callbacks.OnText(event.Delta)
+			}
+		case *smollmv1.GenerateResponse_Result:
+			return event.Result, nil
+		}
+	}
+}
+
+// Ask отвечает на prompt в сессии session (пусто - без сохранения) и возвращает текст ответа
+func (c *Client) Ask(ctx context.Context, session, prompt string) (string, error) {
+	result, err := c.Generate(ctx, &smollmv1.GenerateRequest{Session: session, Prompt: prompt}, GenerateCallbacks{})
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// ExecuteCode выполняет фрагмент кода языка language в песочнице
+func (c *Client) ExecuteCode(ctx context.Context, language, code string) (*smollmv1.ExecuteResult, error) {
+	return c.rpc.ExecuteCode(ctx, &smollmv1.ExecuteCodeRequest{Language: language, Code: code})
+}
+
+// ExecuteFile отправляет локальный файл path на выполнение в песочнице
+func (c *Client) ExecuteFile(ctx context.Context, path string) (*smollmv1.ExecuteResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.ExecuteFileContent(ctx, filepath.Base(path), content)
+}
+
+// ExecuteFileContent выполняет в песочнице файл filename с содержимым content
+func (c *Client) ExecuteFileContent(ctx context.Context, filename string, content []byte) (*smollmv1.ExecuteResult, error) {
+	return c.rpc.ExecuteFile(ctx, &smollmv1.ExecuteFileRequest{Filename: filename, Content: content})
+}
+
+// CreateSession создает сессию name с профилем profile (пусто - по умолчанию)
+func (c *Client) CreateSession(ctx context.Context, name, profile string) (*smollmv1.Session, error) {
+	return c.rpc.CreateSession(ctx, &smollmv1.CreateSessionRequest{Name: name, Profile: profile})
+}
+
+// GetSession возвращает сессию с сообщениями активной ветки
+func (c *Client) GetSession(ctx context.Context, name string) (*smollmv1.Session, error) {
+	return c.rpc.GetSession(ctx, &smollmv1.GetSessionRequest{Name: name})
+}
+
+// ListSessions возвращает сохраненные сессии
+func (c *Client) ListSessions(ctx context.Context) ([]*smollmv1.Session, error) {
+	response, err := c.rpc.ListSessions(ctx, &smollmv1.ListSessionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return response.Sessions, nil
+}
+
+// RenameSession переименовывает сессию
+func (c *Client) RenameSession(ctx context.Context, name, newName string) (*smollmv1.Session, error) {
+	return c.rpc.RenameSession(ctx, &smollmv1.RenameSessionRequest{Name: name, NewName: newName})
+}
+
+// DeleteSession удаляет сессию
+func (c *Client) DeleteSession(ctx context.Context, name string) error {
+	_, err := c.rpc.DeleteSession(ctx, &smollmv1.DeleteSessionRequest{Name: name})
+	return err
+}
+
+// SubmitFeedback сохраняет оценку и возвращает ее идентификатор
+func (c *Client) SubmitFeedback(ctx context.Context, req *smollmv1.SubmitFeedbackRequest) (string, error) {
+	response, err := c.rpc.SubmitFeedback(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return response.Id, nil
+}
+
+// ErrorCode возвращает код сообщения ошибки сервиса (например,
+// storage.session_not_found) или пустую строку
+func ErrorCode(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == ERROR_DOMAIN {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+// apiKeyCredentials передает ключ доступа с каждым вызовом
+type apiKeyCredentials string
+
+// GetRequestMetadata возвращает заголовок authorization
+func (k apiKeyCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(k)}, nil
+}
+
+// RequireTransportSecurity разрешает ключ без TLS: сервис работает во внутренней сети
+func (k apiKeyCredentials) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,358 @@
+// Package smollm - стабильный интерфейс для встраивания SmolLM Sandbox в
+// другие программы: сессии, генерация ответов, выполнение кода в песочнице
+// и обратная связь. Внутренние пакеты приложения остаются деталями реализации
+package smollm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"smollm-sandbox/internal/config"
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/rag"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+)
+
+const (
+	// Пользователь запросов без WithOwner
+	DEFAULT_OWNER = "smollm"
+
+	// Файл логов относительно корня хранилища, если не задан файл конфигурации
+	DEFAULT_LOG_FILE = "logs/smollm.log"
+)
+
+// Client - модель с хранилищем сессий, песочницей и сбором обратной связи.
+// Методы клиента безопасны для одновременного вызова
+type Client struct {
+	logger   *logging.Logger
+	model    *model.SmolLM
+	sessions *storage.SessionManager
+	feedback *feedback.Collector
+
+	sandbox      *sandbox.Environment
+	sandboxMutex sync.Mutex // Песочница выполняет программы по одной
+
+	cancel     context.CancelFunc // Отменяет фоновые задачи клиента
+	background sync.WaitGroup     // Фоновые задачи клиента
+	ingestErr  error              // Ошибка фоновой индексации документов
+	closeOnce  sync.Once
+	closeErr   error
+}
+
+// New создает клиент. Без WithConfigFile используются настройки по
+// умолчанию и директория хранилища ~/.smollm-sandbox
+func New(ctx context.Context, opts ...Option) (*Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s := &settings{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	cfg := config.Default()
+	if s.configFile != "" {
+		loaded, err := config.Load(s.configFile)
+		if err != nil {
+			return nil, i18n.WrapError(err, "smollm.config_load", s.configFile)
+		}
+		cfg = loaded
+	}
+	s.apply(cfg)
+
+	// Язык и логирование общие для процесса: без явных настроек клиент их не меняет
+	if s.language != "" || s.configFile != "" {
+		i18n.SetLanguage(cfg.Language)
+	}
+	rootDir := config.ExpandPath(cfg.Storage.RootDir)
+	if s.configFile != "" || s.logOutput != nil {
+		logFile := filepath.Join(rootDir, DEFAULT_LOG_FILE)
+		if s.configFile != "" {
+			logFile = config.ExpandPath(cfg.Logging.File)
+		}
+		logging.SetDefaultLogConfig(logging.LogConfig{
+			Level:         logging.ParseLevel(cfg.Logging.Level),
+			EnableFile:    logFile != "",
+			FilePath:      logFile,
+			EnableConsole: s.logOutput != nil,
+			Console:       s.logOutput,
+		})
+	}
+
+	c := &Client{logger: logging.NewLogger()}
+	c.logger.Info("Initializing SmolLM client with storage in %s", rootDir)
+
+	// Хранилище
+	if err := os.MkdirAll(rootDir, 0755); err != nil {
+		return nil, err
+	}
+	store := storage.NewFileSystem(rootDir)
+	c.sessions = storage.NewSessionManager(store, cfg.Storage.SessionsDir)
+	c.feedback = feedback.NewCollector(filepath.Join(rootDir, "feedback"))
+
+	// Модель. Текущая сессия модели клиентом не используется: ответы в
+	// сессиях строятся по сохраненной истории
+	c.model = model.NewSmolLMWithOptions(model.Options{
+		Path:         config.ExpandPath(cfg.Model.Path),
+		Backend:      cfg.Model.Backend,
+		Generation:   cfg.Model.Parameters,
+		ThinkingSeed: cfg.Model.Thinking.Seed,
+		Timeout:      time.Duration(cfg.Model.Timeout) * time.Second,
+		Parallel:     cfg.Model.Queue.Parallel,
+		QueueSize:    cfg.Model.Queue.MaxSize,
+		Server:       s.server,
+	})
+	c.model.SetSessionStore(c.sessions)
+
+	if cfg.Model.Cache.Enabled {
+		cache, err := storage.NewResponseCache(store, cfg.Model.Cache.MaxSize)
+		if err != nil {
+			c.logger.Warn("Response cache is unavailable: %v", err)
+		} else {
+			c.model.SetResponseCache(cache)
+		}
+	}
+
+	// Профили (персоны) модели
+	profiles, err := cfg.Profiles.LoadProfiles()
+	if err != nil {
+		c.logger.Warn("Failed to load profiles: %v", err)
+	}
+	if err := c.model.SetProfiles(profiles); err != nil {
+		c.model.Close()
+		return nil, err
+	}
+	if cfg.Profiles.Default != "" {
+		if err := c.model.UseProfile(cfg.Profiles.Default); err != nil {
+			c.model.Close()
+			return nil, err
+		}
+	}
+
+	// Поиск по папке документов. Индексация выполняется в фоне до Close, до
+	// ее завершения используется ранее построенный индекс
+	var background context.Context
+	background, c.cancel = context.WithCancel(context.Background())
+	if cfg.RAG.Enabled {
+		ragIndex := rag.NewIndex(store, cfg.RAG)
+		c.model.SetRetriever(ragIndex)
+		c.background.Add(1)
+		go func() {
+			defer c.background.Done()
+			if _, err := ragIndex.Ingest(background); err != nil && background.Err() == nil {
+				c.logger.Error("Failed to ingest documents: %v", err)
+				c.ingestErr = err
+			}
+		}()
+	}
+
+	if !s.noSandbox {
+		c.sandbox = sandbox.NewEnvironment()
+	}
+
+	return c, nil
+}
+
+// apply переносит параметры New в конфигурацию
+func (s *settings) apply(cfg *config.Config) {
+	if s.modelPath != "" {
+		cfg.Model.Path = s.modelPath
+	}
+	if s.backend != "" {
+		cfg.Model.Backend = s.backend
+	}
+	if s.storageDir != "" {
+		cfg.Storage.RootDir = s.storageDir
+	}
+	if s.language != "" {
+		cfg.Language = s.language
+	}
+	if s.profile != "" {
+		cfg.Profiles.Default = s.profile
+	}
+	if s.parallel > 0 {
+		cfg.Model.Queue.Parallel = s.parallel
+	}
+	if s.queueSize > 0 {
+		cfg.Model.Queue.MaxSize = s.queueSize
+	}
+	cfg.Model.Parameters = cfg.Model.Parameters.Merge(s.generation.internal())
+}
+
+// Close прерывает фоновую индексацию документов, дожидается ее, затем
+// останавливает модель. Возвращает ошибку индексации, если она завершилась
+// неудачей до Close. Повторный вызов возвращает тот же результат
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		c.background.Wait()
+		c.model.Close()
+		c.logger.Close()
+		c.closeErr = c.ingestErr
+	})
+	return c.closeErr
+}
+
+// Complete отвечает на диалог messages без сохранения. Последнее сообщение -
+// запрос пользователя
+func (c *Client) Complete(ctx context.Context, messages []Message, opts ...RequestOption) (*Reply, error) {
+	options, err := processOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.model.Complete(internalMessages(messages), options)
+	if err != nil {
+		return nil, err
+	}
+	return newReply(result), nil
+}
+
+// Ask отвечает на одиночный запрос prompt без сохранения
+func (c *Client) Ask(ctx context.Context, prompt string, opts ...RequestOption) (*Reply, error) {
+	return c.Complete(ctx, []Message{{Role: ROLE_USER, Content: prompt}}, opts...)
+}
+
+// CreateSession создает пустую сессию name с системной инструкцией профиля
+// profile (пусто - профиль по умолчанию)
+func (c *Client) CreateSession(ctx context.Context, name, profile string) (*Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	unlock := c.model.LockSession(name)
+	defer unlock()
+
+	session, err := c.model.NewSessionContext(profile)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.sessions.CreateSession(name, session); err != nil {
+		return nil, err
+	}
+	return &Session{client: c, name: name}, nil
+}
+
+// OpenSession открывает сохраненную сессию name
+func (c *Client) OpenSession(ctx context.Context, name string) (*Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if _, err := c.sessions.GetSessionMeta(name); err != nil {
+		return nil, err
+	}
+	return &Session{client: c, name: name}, nil
+}
+
+// Sessions возвращает сохраненные сессии
+func (c *Client) Sessions(ctx context.Context) ([]SessionInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	metas, err := c.sessions.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SessionInfo, 0, len(metas))
+	for _, meta := range metas {
+		result = append(result, newSessionInfo(meta))
+	}
+	return result, nil
+}
+
+// DeleteSession удаляет сессию name
+func (c *Client) DeleteSession(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	unlock := c.model.LockSession(name)
+	defer unlock()
+
+	return c.sessions.DeleteSession(name)
+}
+
+// RunCode выполняет фрагмент кода языка language в песочнице. Ошибка
+// компиляции или выполнения программы - результат с Success = false
+func (c *Client) RunCode(ctx context.Context, language, code string) (*ExecResult, error) {
+	return c.execute(ctx, func() (*sandbox.ExecuteResult, error) {
+		return c.sandbox.RunCode(code, language)
+	})
+}
+
+// RunFile выполняет файл path в песочнице; язык выбирается по расширению
+func (c *Client) RunFile(ctx context.Context, path string) (*ExecResult, error) {
+	return c.execute(ctx, func() (*sandbox.ExecuteResult, error) {
+		return c.sandbox.RunFile(path)
+	})
+}
+
+// Languages возвращает языки, поддерживаемые песочницей
+func (c *Client) Languages(ctx context.Context) ([]string, error) {
+	if err := c.requireSandbox(ctx); err != nil {
+		return nil, err
+	}
+	return c.sandbox.GetSupportedLanguages(), nil
+}
+
+// execute выполняет run в песочнице, дожидаясь предыдущих выполнений.
+// Выполнение ограничено таймаутом песочницы и отменой ctx не прерывается
+func (c *Client) execute(ctx context.Context, run func() (*sandbox.ExecuteResult, error)) (*ExecResult, error) {
+	if err := c.requireSandbox(ctx); err != nil {
+		return nil, err
+	}
+
+	c.sandboxMutex.Lock()
+	defer c.sandboxMutex.Unlock()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := run()
+	if err != nil {
+		return nil, err
+	}
+	return newExecResult(result), nil
+}
+
+// requireSandbox возвращает ошибку, если песочница отключена или ctx отменен
+func (c *Client) requireSandbox(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.sandbox == nil {
+		return i18n.NewError("smollm.sandbox_disabled").WithKind(ErrSandboxDisabled)
+	}
+	return nil
+}
+
+// SubmitFeedback сохраняет оценку и возвращает ее идентификатор
+func (c *Client) SubmitFeedback(ctx context.Context, fb Feedback) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	feedbackType := feedback.FeedbackType(fb.Type)
+	if fb.Type == "" {
+		feedbackType = feedback.ModelOutput
+	}
+
+	metadata := map[string]interface{}{}
+	if fb.Session != "" {
+		metadata[feedback.META_SESSION_ID] = fb.Session
+	}
+	if fb.MessageID != "" {
+		metadata[feedback.META_MESSAGE_ID] = fb.MessageID
+	}
+	return c.feedback.AddFeedback(feedbackType, fb.Content, fb.Rating, fb.Comment, metadata)
+}
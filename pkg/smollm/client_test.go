@@ -0,0 +1,234 @@
+package smollm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"smollm-sandbox/internal/logging"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/model/modeltest"
+	"smollm-sandbox/internal/rag"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetDefaultLogConfig(logging.LogConfig{Level: logging.ERROR, Console: io.Discard})
+	os.Exit(m.Run())
+}
+
+// newTestClient создает клиент с хранилищем во временной директории и
+// моделью на поддельном сервере модели
+func newTestClient(t *testing.T, opts ...Option) (*Client, *modeltest.Server) {
+	t.Helper()
+	backend := modeltest.NewServer(t)
+	server := backend.Config()
+
+	opts = append([]Option{
+		WithStorageDir(t.TempDir()),
+		WithModelPath(t.TempDir()),
+		WithBackend(model.BACKEND_API),
+		WithModelServer(server.Host, server.Port),
+		WithoutSandbox(),
+	}, opts...)
+	c, err := New(context.Background(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := c.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	})
+	return c, backend
+}
+
+// writeConfig записывает файл конфигурации с хранилищем в root и
+// поиском по документам docsDir
+func writeConfig(t *testing.T, root, docsDir string) string {
+	t.Helper()
+	path := filepath.Join(root, "config.yaml")
+	data := fmt.Sprintf(`logging:
+  level: error
+  file: ""
+storage:
+  root_dir: %q
+rag:
+  enabled: true
+  docs_dir: %q
+  embeddings_url: ""
+`, root, docsDir)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Файл конфигурации меняет логирование процесса
+	logConfig := logging.DefaultLogConfig()
+	t.Cleanup(func() { logging.SetDefaultLogConfig(logConfig) })
+	return path
+}
+
+func TestNewKeepsProcessSettings(t *testing.T) {
+	before := logging.DefaultLogConfig()
+	newTestClient(t)
+
+	if after := logging.DefaultLogConfig(); !reflect.DeepEqual(after, before) {
+		t.Errorf("logging config changed to %+v without explicit logging options", after)
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	c, _ := newTestClient(t)
+	ctx := context.Background()
+
+	session, err := c.CreateSession(ctx, "chat", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreateSession(ctx, "chat", ""); !errors.Is(err, ErrSessionExists) {
+		t.Errorf("CreateSession(duplicate) = %v, want ErrSessionExists", err)
+	}
+
+	var streamed strings.Builder
+	reply, err := session.Send(ctx, "Hi", WithStream(func(delta string) { streamed.WriteString(delta) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Text != modeltest.REPLY || streamed.String() != modeltest.REPLY || reply.MessageID == "" {
+		t.Fatalf("reply = %+v, streamed %q", reply, streamed.String())
+	}
+	if reply.PromptTokens != modeltest.PROMPT_TOKENS || reply.CompletionTokens != modeltest.REPLY_TOKENS {
+		t.Errorf("tokens = %d, %d", reply.PromptTokens, reply.CompletionTokens)
+	}
+
+	opened, err := c.OpenSession(ctx, "chat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages, err := opened.Messages(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := messages[len(messages)-2:]
+	if last[0].Role != ROLE_USER || last[0].Content != "Hi" || last[1].ID != reply.MessageID || last[1].Content != modeltest.REPLY {
+		t.Errorf("messages = %+v", messages)
+	}
+
+	id, err := opened.Feedback(ctx, reply.MessageID, 5, "good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, ok := c.feedback.GetFeedback(id)
+	if !ok || item.Content != modeltest.REPLY || item.Rating != 5 || item.Comment != "good" {
+		t.Errorf("feedback = %+v, %v", item, ok)
+	}
+
+	_, err = opened.Feedback(ctx, "m999", 5, "")
+	if !errors.Is(err, ErrMessageNotFound) || ErrorCode(err) != "smollm.message_not_found" {
+		t.Errorf("Feedback(unknown) = %v, want ErrMessageNotFound", err)
+	}
+	if _, err := opened.Feedback(ctx, reply.MessageID, 9, ""); !errors.Is(err, ErrInvalidRating) {
+		t.Errorf("Feedback(rating 9) = %v, want ErrInvalidRating", err)
+	}
+
+	sessions, err := c.Sessions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "chat" {
+		t.Errorf("sessions = %+v", sessions)
+	}
+
+	if err := c.DeleteSession(ctx, "chat"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.OpenSession(ctx, "chat"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("OpenSession(deleted) = %v, want ErrSessionNotFound", err)
+	}
+	if _, err := session.Send(ctx, "Hi"); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Send(deleted) = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	c, backend := newTestClient(t)
+	ctx := context.Background()
+
+	reply, err := c.Ask(ctx, "Hi", WithRequestGeneration(GenerationOptions{Temperature: Float(0), Seed: Int(7)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Text != modeltest.REPLY {
+		t.Errorf("reply = %+v", reply)
+	}
+	if model.ValueOf(reply.Generation.Temperature) != 0 || model.ValueOf(reply.Generation.Seed) != 7 {
+		t.Errorf("generation = %+v", reply.Generation)
+	}
+	requests := backend.Requests()
+	if len(requests) != 1 || requests[0].Temperature != 0 || requests[0].Seed != 7 {
+		t.Errorf("model requests = %+v", requests)
+	}
+
+	if _, err := c.Complete(ctx, []Message{{Role: ROLE_ASSISTANT, Content: "Hi"}}); !errors.Is(err, ErrInvalidMessages) {
+		t.Errorf("Complete(no user message) = %v, want ErrInvalidMessages", err)
+	}
+	if _, err := c.Ask(ctx, "Hi", WithRequestGeneration(GenerationOptions{Temperature: Float(5)})); !errors.Is(err, ErrInvalidGeneration) {
+		t.Errorf("Ask(temperature 5) = %v, want ErrInvalidGeneration", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Ask(canceled, "Hi"); !errors.Is(err, ErrCanceled) {
+		t.Errorf("Ask(canceled) = %v, want ErrCanceled", err)
+	}
+	if _, err := c.RunCode(ctx, "python", "print(1)"); !errors.Is(err, ErrSandboxDisabled) {
+		t.Errorf("RunCode = %v, want ErrSandboxDisabled", err)
+	}
+}
+
+func TestClose(t *testing.T) {
+	root := t.TempDir()
+	docs := filepath.Join(root, "docs")
+	if err := os.MkdirAll(docs, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(docs, "guide.md"), []byte("# Guide\nSandbox runs Python."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := newTestClient(t, WithConfigFile(writeConfig(t, root, docs)))
+	if err := c.Close(); err != nil {
+		t.Errorf("Close = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("second Close = %v", err)
+	}
+}
+
+func TestCloseReportsIngestError(t *testing.T) {
+	root := t.TempDir()
+	backend := modeltest.NewServer(t)
+	server := backend.Config()
+
+	c, err := New(context.Background(),
+		WithConfigFile(writeConfig(t, root, filepath.Join(root, "missing"))),
+		WithBackend(model.BACKEND_API),
+		WithModelPath(t.TempDir()),
+		WithModelServer(server.Host, server.Port),
+		WithoutSandbox(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Индексация отсутствующей папки завершается ошибкой до Close
+	c.background.Wait()
+	if err := c.Close(); !errors.Is(err, rag.ErrDocsUnavailable) {
+		t.Errorf("Close = %v, want ErrDocsUnavailable", err)
+	}
+}
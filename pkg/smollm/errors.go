@@ -0,0 +1,54 @@
+package smollm
+
+import (
+	"errors"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+)
+
+// Виды ошибок, которые возвращает клиент. Ошибки проверяются через
+// errors.Is; текст на языке клиента возвращает ErrorMessage
+var (
+	ErrSandboxDisabled = errors.New("smollm: sandbox disabled")
+	ErrMessageNotFound = errors.New("smollm: message not found")
+	ErrStoreNotSet     = model.ErrStoreNotSet
+
+	ErrSessionNotFound    = storage.ErrSessionNotFound
+	ErrSessionExists      = storage.ErrSessionExists
+	ErrSessionConflict    = storage.ErrSessionConflict
+	ErrInvalidSessionName = storage.ErrInvalidSessionName
+	ErrCorruptSession     = storage.ErrCorruptSession
+
+	ErrModelUnavailable  = model.ErrModelUnavailable
+	ErrGenerationFailed  = model.ErrGenerationFailed
+	ErrProfileNotFound   = model.ErrProfileNotFound
+	ErrInvalidGeneration = model.ErrInvalidGeneration
+	ErrInvalidMessages   = model.ErrInvalidMessages
+	ErrQueueFull         = model.ErrQueueFull
+	ErrCanceled          = model.ErrCanceled
+	ErrGenerationTimeout = model.ErrGenerationTimeout
+
+	ErrUnsupportedLanguage = sandbox.ErrUnsupportedLanguage
+	ErrUnsupportedFileType = sandbox.ErrUnsupportedFileType
+	ErrFileNotFound        = sandbox.ErrFileNotFound
+	ErrTimeout             = sandbox.ErrTimeout
+	ErrOutputTooLarge      = sandbox.ErrOutputTooLarge
+	ErrCompileFailed       = sandbox.ErrCompileFailed
+
+	ErrInvalidRating = feedback.ErrInvalidRating
+)
+
+// ErrorCode возвращает код сообщения ошибки (например,
+// storage.session_not_found) или пустую строку
+func ErrorCode(err error) string {
+	return i18n.Code(err)
+}
+
+// ErrorMessage возвращает текст ошибки на языке клиента (WithLanguage)
+func ErrorMessage(err error) string {
+	return i18n.LocalizeError(err)
+}
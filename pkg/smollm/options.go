@@ -0,0 +1,153 @@
+package smollm
+
+import (
+	"context"
+	"io"
+
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/modelserver"
+)
+
+// Option настраивает клиент при создании через New
+type Option func(*settings)
+
+// settings содержит настройки клиента поверх файла конфигурации
+type settings struct {
+	configFile string
+	modelPath  string
+	backend    string
+	storageDir string
+	language   string
+	profile    string
+	generation GenerationOptions
+	parallel   int
+	queueSize  int
+	noSandbox  bool
+	logOutput  io.Writer
+	server     modelserver.Config
+}
+
+// WithConfigFile загружает настройки из файла конфигурации (configs/config.yaml);
+// остальные параметры New применяются поверх него
+func WithConfigFile(path string) Option {
+	return func(s *settings) { s.configFile = path }
+}
+
+// WithModelPath задает директорию модели
+func WithModelPath(path string) Option {
+	return func(s *settings) { s.modelPath = path }
+}
+
+// WithBackend задает способ генерации: auto, native, server, worker или api
+func WithBackend(backend string) Option {
+	return func(s *settings) { s.backend = backend }
+}
+
+// WithStorageDir задает корневую директорию хранилища сессий, обратной
+// связи, кэша и логов
+func WithStorageDir(dir string) Option {
+	return func(s *settings) { s.storageDir = dir }
+}
+
+// WithLanguage задает язык сообщений и ошибок (ru или en). Язык общий
+// для всего процесса
+func WithLanguage(language string) Option {
+	return func(s *settings) { s.language = language }
+}
+
+// WithProfile задает профиль новых сессий по умолчанию
+func WithProfile(name string) Option {
+	return func(s *settings) { s.profile = name }
+}
+
+// WithGeneration задает параметры генерации модели поверх конфигурации
+func WithGeneration(opts GenerationOptions) Option {
+	return func(s *settings) { s.generation = opts }
+}
+
+// WithQueue задает число одновременных генераций и максимальную длину
+// очереди (0 - значения по умолчанию)
+func WithQueue(parallel, maxSize int) Option {
+	return func(s *settings) {
+		s.parallel = parallel
+		s.queueSize = maxSize
+	}
+}
+
+// WithoutSandbox отключает песочницу: RunCode и RunFile возвращают ErrSandboxDisabled
+func WithoutSandbox() Option {
+	return func(s *settings) { s.noSandbox = true }
+}
+
+// WithModelServer задает адрес сервера модели для способа генерации api.
+// Если по адресу уже отвечает сервер модели, клиент использует его вместо
+// запуска своего
+func WithModelServer(host string, port int) Option {
+	return func(s *settings) {
+		s.server.Host = host
+		s.server.Port = port
+	}
+}
+
+// WithLogOutput выводит логи в w вместе с файлом логов в хранилище.
+// Настройки логирования общие для всего процесса: без этого параметра и
+// WithConfigFile клиент их не меняет
+func WithLogOutput(w io.Writer) Option {
+	return func(s *settings) { s.logOutput = w }
+}
+
+// RequestOption настраивает отдельный запрос генерации
+type RequestOption func(*requestSettings)
+
+// requestSettings содержит параметры отдельного запроса
+type requestSettings struct {
+	generation   GenerationOptions
+	systemPrompt string
+	owner        string
+	onText       func(delta string)
+	onQueued     func(position int)
+}
+
+// WithRequestGeneration задает параметры генерации запроса поверх
+// параметров сессии и модели
+func WithRequestGeneration(opts GenerationOptions) RequestOption {
+	return func(r *requestSettings) { r.generation = opts }
+}
+
+// WithSystemPrompt заменяет системную инструкцию для запроса
+func WithSystemPrompt(prompt string) RequestOption {
+	return func(r *requestSettings) { r.systemPrompt = prompt }
+}
+
+// WithStream передает ответ в onText по частям по мере генерации
+func WithStream(onText func(delta string)) RequestOption {
+	return func(r *requestSettings) { r.onText = onText }
+}
+
+// WithQueueCallback сообщает позицию запроса в очереди генерации, пока он ждет
+func WithQueueCallback(onQueued func(position int)) RequestOption {
+	return func(r *requestSettings) { r.onQueued = onQueued }
+}
+
+// WithOwner задает пользователя запроса: очередь чередует запросы разных пользователей
+func WithOwner(owner string) RequestOption {
+	return func(r *requestSettings) { r.owner = owner }
+}
+
+// processOptions собирает параметры запроса и проверяет параметры генерации
+func processOptions(ctx context.Context, opts []RequestOption) (model.ProcessOptions, error) {
+	request := requestSettings{owner: DEFAULT_OWNER}
+	for _, opt := range opts {
+		opt(&request)
+	}
+
+	options := model.ProcessOptions{
+		Context:      ctx,
+		SystemPrompt: request.systemPrompt,
+		Owner:        request.owner,
+		OnText:       request.onText,
+		OnQueued:     request.onQueued,
+		Generation:   request.generation.internal(),
+	}
+	return options, options.Generation.Validate()
+}
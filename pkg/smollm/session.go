@@ -0,0 +1,75 @@
+package smollm
+
+import (
+	"context"
+	"slices"
+
+	"smollm-sandbox/internal/i18n"
+	"smollm-sandbox/internal/model"
+)
+
+// Session - сохраненная сессия диалога. Запросы в одной сессии выполняются
+// по очереди, в разных сессиях - параллельно в пределах очереди модели
+type Session struct {
+	client *Client
+	name   string
+}
+
+// Name возвращает имя сессии
+func (s *Session) Name() string {
+	return s.name
+}
+
+// Send отвечает на text с учетом истории сессии и сохраняет запрос и ответ
+func (s *Session) Send(ctx context.Context, text string, opts ...RequestOption) (*Reply, error) {
+	options, err := processOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.client.model.CompleteSession(s.name, text, options)
+	if err != nil {
+		return nil, err
+	}
+	return newReply(result), nil
+}
+
+// Messages возвращает сообщения активной ветки сессии
+func (s *Session) Messages(ctx context.Context) ([]Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	session, err := s.client.sessions.LoadSession(s.name)
+	if err != nil {
+		return nil, err
+	}
+	return newMessages(session.ActiveBranch()), nil
+}
+
+// Feedback сохраняет оценку ответа messageID этой сессии. Сообщение ищется
+// во всех ветках сессии; для неизвестного messageID возвращается
+// ErrMessageNotFound
+func (s *Session) Feedback(ctx context.Context, messageID string, rating int, comment string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	session, err := s.client.sessions.LoadSession(s.name)
+	if err != nil {
+		return "", err
+	}
+	index := slices.IndexFunc(session.Messages, func(msg model.Message) bool { return msg.ID == messageID })
+	if index < 0 {
+		return "", i18n.NewError("smollm.message_not_found", s.name, messageID).WithKind(ErrMessageNotFound)
+	}
+
+	return s.client.SubmitFeedback(ctx, Feedback{
+		Type:      FEEDBACK_MODEL_OUTPUT,
+		Content:   session.Messages[index].Content,
+		Rating:    rating,
+		Comment:   comment,
+		Session:   s.name,
+		MessageID: messageID,
+	})
+}
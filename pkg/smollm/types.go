@@ -0,0 +1,215 @@
+package smollm
+
+import (
+	"time"
+
+	"smollm-sandbox/internal/feedback"
+	"smollm-sandbox/internal/model"
+	"smollm-sandbox/internal/sandbox"
+	"smollm-sandbox/internal/storage"
+)
+
+// Роли сообщений диалога
+const (
+	ROLE_SYSTEM    = "system"
+	ROLE_USER      = "user"
+	ROLE_ASSISTANT = "assistant"
+)
+
+// Message - сообщение диалога
+type Message struct {
+	ID      string // Идентификатор в сессии; пусто у сообщений клиента
+	Role    string // ROLE_*
+	Content string
+	Time    time.Time
+}
+
+// GenerationOptions - параметры генерации. Поля nil не меняют параметры
+// сессии и модели; значения задаются через Float и Int, и заданный ноль
+// тоже действует: Temperature: Float(0) включает жадный выбор токена
+type GenerationOptions struct {
+	Temperature       *float64
+	TopP              *float64
+	TopK              *int
+	MinP              *float64
+	RepetitionPenalty *float64
+	FrequencyPenalty  *float64
+	PresencePenalty   *float64
+	Stop              []string // Заменяет стоп-строки модели
+	MaxTokens         *int
+	Seed              *int // Ненулевой seed делает ответ воспроизводимым и кэшируемым
+}
+
+// Float возвращает значение дробного параметра генерации
+func Float(value float64) *float64 {
+	return model.Float(value)
+}
+
+// Int возвращает значение целого параметра генерации
+func Int(value int) *int {
+	return model.Int(value)
+}
+
+// Reply - ответ модели
+type Reply struct {
+	Text             string
+	MessageID        string // Сообщение ответа в сессии, для обратной связи
+	PromptTokens     int
+	CompletionTokens int
+	Latency          time.Duration
+	Cached           bool              // Ответ взят из кэша ответов
+	Generation       GenerationOptions // Фактические параметры, включая seed
+	Citations        []Citation        // Фрагменты документов, добавленные в контекст
+}
+
+// Citation - фрагмент документа, добавленный в контекст
+type Citation struct {
+	Source    string // Путь к файлу относительно папки документов
+	StartLine int
+	EndLine   int
+	Text      string
+	Score     float64
+}
+
+// ExecResult - результат выполнения кода в песочнице
+type ExecResult struct {
+	Success     bool
+	ExitCode    int
+	Output      string
+	Stderr      string // Вывод программы или компилятора в stderr
+	Failure     error  // Причина неудачи: ErrTimeout, ErrOutputTooLarge, ErrCompileFailed и т.п.
+	Compiled    bool
+	CompileTime time.Duration
+	ExecuteTime time.Duration
+	Language    string
+}
+
+// SessionInfo описывает сохраненную сессию
+type SessionInfo struct {
+	Name         string
+	MessageCount int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// FeedbackType - тип обратной связи
+type FeedbackType string
+
+const (
+	FEEDBACK_MODEL_OUTPUT   FeedbackType = FeedbackType(feedback.ModelOutput)
+	FEEDBACK_CODE_EXECUTION FeedbackType = FeedbackType(feedback.CodeExecution)
+	FEEDBACK_SYSTEM_ERROR   FeedbackType = FeedbackType(feedback.SystemError)
+	FEEDBACK_THINKING       FeedbackType = FeedbackType(feedback.Thinking)
+)
+
+// Feedback - оценка ответа модели или выполнения кода
+type Feedback struct {
+	Type      FeedbackType // Пусто - FEEDBACK_MODEL_OUTPUT
+	Content   string       // Оцениваемый текст
+	Rating    int          // От 1 до 5
+	Comment   string
+	Session   string // Связывает оценку с сессией
+	MessageID string // и сообщением в ней
+}
+
+// internal преобразует параметры генерации во внутренние
+func (o GenerationOptions) internal() model.GenerationOptions {
+	return model.GenerationOptions{
+		Temperature:       o.Temperature,
+		TopP:              o.TopP,
+		TopK:              o.TopK,
+		MinP:              o.MinP,
+		RepetitionPenalty: o.RepetitionPenalty,
+		FrequencyPenalty:  o.FrequencyPenalty,
+		PresencePenalty:   o.PresencePenalty,
+		Stop:              o.Stop,
+		MaxTokens:         o.MaxTokens,
+		Seed:              o.Seed,
+	}
+}
+
+// newGenerationOptions преобразует внутренние параметры генерации
+func newGenerationOptions(o model.GenerationOptions) GenerationOptions {
+	return GenerationOptions{
+		Temperature:       o.Temperature,
+		TopP:              o.TopP,
+		TopK:              o.TopK,
+		MinP:              o.MinP,
+		RepetitionPenalty: o.RepetitionPenalty,
+		FrequencyPenalty:  o.FrequencyPenalty,
+		PresencePenalty:   o.PresencePenalty,
+		Stop:              o.Stop,
+		MaxTokens:         o.MaxTokens,
+		Seed:              o.Seed,
+	}
+}
+
+// newReply преобразует результат генерации
+func newReply(result *model.ProcessResult) *Reply {
+	reply := &Reply{
+		Text:             result.Text,
+		MessageID:        result.MessageID,
+		PromptTokens:     result.PromptTokens,
+		CompletionTokens: result.TokensUsed - result.PromptTokens,
+		Latency:          result.Latency,
+		Cached:           result.Cached,
+		Generation:       newGenerationOptions(result.Generation),
+	}
+	for _, passage := range result.Citations {
+		reply.Citations = append(reply.Citations, Citation{
+			Source:    passage.Source,
+			StartLine: passage.StartLine,
+			EndLine:   passage.EndLine,
+			Text:      passage.Text,
+			Score:     passage.Score,
+		})
+	}
+	return reply
+}
+
+// newMessages преобразует сообщения сессии
+func newMessages(messages []model.Message) []Message {
+	result := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, Message{ID: msg.ID, Role: msg.Role, Content: msg.Content, Time: msg.Timestamp})
+	}
+	return result
+}
+
+// internalMessages преобразует сообщения клиента
+func internalMessages(messages []Message) []model.Message {
+	result := make([]model.Message, 0, len(messages))
+	for _, msg := range messages {
+		timestamp := msg.Time
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		result = append(result, model.Message{ID: msg.ID, Role: msg.Role, Content: msg.Content, Timestamp: timestamp})
+	}
+	return result
+}
+
+// newExecResult преобразует результат песочницы
+func newExecResult(result *sandbox.ExecuteResult) *ExecResult {
+	return &ExecResult{
+		Success:     result.Success,
+		ExitCode:    result.ExitCode,
+		Output:      result.Output,
+		Stderr:      result.Error,
+		Failure:     result.Failure,
+		Compiled:    result.Compiled,
+		CompileTime: result.CompileTime,
+		ExecuteTime: result.ExecuteTime,
+		Language:    result.Language,
+	}
+}
+
+// newSessionInfo преобразует метаданные сессии
+func newSessionInfo(meta storage.SessionMeta) SessionInfo {
+	return SessionInfo{
+		Name:         meta.Name,
+		MessageCount: meta.MessageCount,
+		CreatedAt:    meta.CreatedAt,
+		UpdatedAt:    meta.UpdatedAt,
+	}
+}
@@ -46,6 +46,7 @@ cd "$TMP_DIR"
 echo "Сборка приложения..."
 go build -o smollm-cli ./cmd/cli
 go build -o smollm-telegram ./cmd/telegram
+go build -o smollm-server ./cmd/server
 
 # Создание директорий
 echo "Создание директорий..."
@@ -55,7 +56,8 @@ mkdir -p "$CONFIG_DIR" "$MODEL_DIR"
 echo "Установка исполняемых файлов..."
 cp smollm-cli "$INSTALL_DIR/"
 cp smollm-telegram "$INSTALL_DIR/"
-chmod +x "$INSTALL_DIR/smollm-cli" "$INSTALL_DIR/smollm-telegram"
+cp smollm-server "$INSTALL_DIR/"
+chmod +x "$INSTALL_DIR/smollm-cli" "$INSTALL_DIR/smollm-telegram" "$INSTALL_DIR/smollm-server"
 
 # Копирование конфигурационных файлов
 echo "Установка конфигурационных файлов..."
@@ -100,4 +102,5 @@ systemctl enable smollm-sandbox.service
 
 echo "Установка завершена успешно!"
 echo "Для запуска CLI интерфейса: smollm-cli --interactive"
+echo "Для запуска API сервера: smollm-server"
 echo "Для запуска сервиса: systemctl start smollm-sandbox"
\ No newline at end of file